@@ -48,12 +48,22 @@ func FormatYAML(ctx context.Context) error {
 
 func GoModTidy(ctx context.Context) error {
 	sg.Logger(ctx).Println("tidying Go module files...")
-	return sg.Command(ctx, "go", "mod", "tidy", "-v").Run()
+	if err := sg.Command(ctx, "go", "mod", "tidy", "-v").Run(); err != nil {
+		return err
+	}
+	cmd := sg.Command(ctx, "go", "mod", "tidy", "-v")
+	cmd.Dir = sg.FromGitRoot("cmd", "buf-plugin-grpc-service-config")
+	return cmd.Run()
 }
 
 func GoTest(ctx context.Context) error {
 	sg.Logger(ctx).Println("running Go tests...")
-	return sggo.TestCommand(ctx).Run()
+	if err := sggo.TestCommand(ctx).Run(); err != nil {
+		return err
+	}
+	cmd := sggo.TestCommand(ctx)
+	cmd.Dir = sg.FromGitRoot("cmd", "buf-plugin-grpc-service-config")
+	return cmd.Run()
 }
 
 func GoReview(ctx context.Context) error {
@@ -0,0 +1,183 @@
+// Command buf-plugin-grpc-service-config is a buf check plugin, which reports problems in the service configs of
+// services as buf lint rules, and breaking service config changes as a buf breaking rule, with the same rule IDs as
+// the protoc plugin, so that buf.yaml selects and ignores them like the builtin rules of buf.
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"buf.build/go/bufplugin/check"
+	"buf.build/go/bufplugin/descriptor"
+	"buf.build/go/bufplugin/option"
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/plugin"
+)
+
+func main() {
+	check.Main(newSpec())
+}
+
+// newSpec returns the spec of the plugin, with a rule per validation and lint rule of the protoc plugin, and a rule for
+// breaking changes.
+func newSpec() *check.Spec {
+	spec := &check.Spec{
+		// Every rule reports from the same validation run, which is shared through the context.
+		Before: func(ctx context.Context, request check.Request) (context.Context, check.Request, error) {
+			return context.WithValue(ctx, resultKey{}, &result{}), request, nil
+		},
+	}
+	for _, rule := range plugin.BufCheckRules() {
+		ruleType := check.RuleTypeLint
+		if rule.Breaking {
+			ruleType = check.RuleTypeBreaking
+		}
+		spec.Rules = append(spec.Rules, &check.RuleSpec{
+			ID:      rule.ID,
+			Default: rule.Default,
+			Purpose: rule.Purpose,
+			Type:    ruleType,
+			Handler: newRuleHandler(rule.ID, rule.Breaking),
+		})
+	}
+	return spec
+}
+
+// resultKey is the context key of the result of a check request.
+type resultKey struct{}
+
+// result is the result of a check request, computed once for every lint rule and once for every breaking rule.
+type result struct {
+	lintOnce            sync.Once
+	lintAnnotations     []plugin.BufCheckAnnotation
+	lintErr             error
+	breakingOnce        sync.Once
+	breakingAnnotations []plugin.BufCheckAnnotation
+	breakingErr         error
+}
+
+// lint returns the problems found in the service configs of the services in the request.
+func (r *result) lint(request check.Request) ([]plugin.BufCheckAnnotation, error) {
+	r.lintOnce.Do(func() {
+		opts, err := parseOptions(request.Options())
+		if err != nil {
+			r.lintErr = err
+			return
+		}
+		r.lintAnnotations, r.lintErr = plugin.BufLint(bufCheckFiles(request.FileDescriptors()), opts)
+	})
+	return r.lintAnnotations, r.lintErr
+}
+
+// breaking returns the breaking changes between the service configs of the services in the request, and the service
+// configs of the same services in the against files of the request.
+func (r *result) breaking(request check.Request) ([]plugin.BufCheckAnnotation, error) {
+	r.breakingOnce.Do(func() {
+		opts, err := parseOptions(request.Options())
+		if err != nil {
+			r.breakingErr = err
+			return
+		}
+		r.breakingAnnotations, r.breakingErr = plugin.BufBreaking(
+			bufCheckFiles(request.FileDescriptors()),
+			bufCheckFiles(request.AgainstFileDescriptors()),
+			opts,
+		)
+	})
+	return r.breakingAnnotations, r.breakingErr
+}
+
+// bufCheckFiles returns the files of a check request.
+func bufCheckFiles(fileDescriptors []descriptor.FileDescriptor) []plugin.BufCheckFile {
+	result := make([]plugin.BufCheckFile, 0, len(fileDescriptors))
+	for _, file := range fileDescriptors {
+		result = append(result, plugin.BufCheckFile{Descriptor: file.FileDescriptorProto(), Import: file.IsImport()})
+	}
+	return result
+}
+
+// newRuleHandler returns a handler reporting the problems found by the rule.
+func newRuleHandler(ruleID string, breaking bool) check.RuleHandler {
+	return check.RuleHandlerFunc(func(ctx context.Context, w check.ResponseWriter, request check.Request) error {
+		r := ctx.Value(resultKey{}).(*result)
+		var annotations []plugin.BufCheckAnnotation
+		var err error
+		if breaking {
+			annotations, err = r.breaking(request)
+		} else {
+			annotations, err = r.lint(request)
+		}
+		if err != nil {
+			return err
+		}
+		for _, annotation := range annotations {
+			if annotation.RuleID != ruleID {
+				continue
+			}
+			options := []check.AddAnnotationOption{check.WithMessage(annotation.Message)}
+			if annotation.Descriptor != nil {
+				options = append(options, check.WithDescriptor(annotation.Descriptor))
+			}
+			if annotation.AgainstDescriptor != nil {
+				options = append(options, check.WithAgainstDescriptor(annotation.AgainstDescriptor))
+			}
+			w.AddAnnotation(options...)
+		}
+		return nil
+	})
+}
+
+// parseOptions parses the options of the plugin in buf.yaml, which have the names of the protoc plugin options.
+func parseOptions(options option.Options) (plugin.BufCheckOptions, error) {
+	var opts plugin.BufCheckOptions
+	var err error
+	if opts.Path, err = option.GetStringValue(options, "path"); err != nil {
+		return opts, err
+	}
+	if opts.Path == "" {
+		opts.Path = "."
+	}
+	if opts.Conflict, err = option.GetStringValue(options, "conflict"); err != nil {
+		return opts, err
+	}
+	maxConfigBytes, err := option.GetInt64Value(options, "max_config_bytes")
+	if err != nil {
+		return opts, err
+	}
+	opts.MaxConfigBytes = int(maxConfigBytes)
+	if opts.TargetGRPCGoVersion, err = option.GetStringValue(options, "target_grpc_go_version"); err != nil {
+		return opts, err
+	}
+	if opts.AgainstPath, err = option.GetStringValue(options, "against_path"); err != nil {
+		return opts, err
+	}
+	// YAML numbers without a fraction, such as 1, are integers.
+	switch value, _ := options.Get("breaking_timeout_ratio"); value := value.(type) {
+	case nil:
+		opts.BreakingTimeoutRatio = 0.5
+	case float64:
+		opts.BreakingTimeoutRatio = value
+	case int64:
+		opts.BreakingTimeoutRatio = float64(value)
+	default:
+		return opts, fmt.Errorf("invalid breaking_timeout_ratio option %v (expected a number)", value)
+	}
+	for key, value := range map[string]*time.Duration{
+		"lint_min_timeout":     &opts.LintMinTimeout,
+		"lint_max_timeout":     &opts.LintMaxTimeout,
+		"lint_gateway_timeout": &opts.LintGatewayTimeout,
+	} {
+		s, err := option.GetStringValue(options, key)
+		if err != nil {
+			return opts, err
+		}
+		if s == "" {
+			continue
+		}
+		if *value, err = time.ParseDuration(s); err != nil {
+			return opts, fmt.Errorf("invalid %s option: %w", key, err)
+		}
+	}
+	return opts, nil
+}
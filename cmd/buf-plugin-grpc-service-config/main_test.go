@@ -0,0 +1,171 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"buf.build/go/bufplugin/check/checktest"
+)
+
+const (
+	testProtoFile = "einride/example/freight/v1/freight_service.proto"
+	testProto     = `syntax = "proto3";
+
+package einride.example.freight.v1;
+
+service FreightService {
+  rpc GetShipper(GetShipperRequest) returns (Shipper);
+}
+
+message GetShipperRequest {
+  string name = 1;
+}
+
+message Shipper {
+  string name = 1;
+}
+`
+	testServiceConfigFile = "einride/example/freight/v1/freight_grpc_service_config.json"
+)
+
+// testServiceLocation is the location of the service in the proto file.
+var testServiceLocation = &checktest.ExpectedFileLocation{
+	FileName:    testProtoFile,
+	StartLine:   4,
+	StartColumn: 0,
+	EndLine:     6,
+	EndColumn:   1,
+}
+
+// writeTestFiles writes the proto file, and the service config JSON file unless empty, to a temporary directory, and
+// returns the directory.
+func writeTestFiles(t *testing.T, serviceConfig string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{testProtoFile: testProto}
+	if serviceConfig != "" {
+		files[testServiceConfigFile] = serviceConfig
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestSpec(t *testing.T) {
+	checktest.SpecTest(t, newSpec())
+}
+
+func TestLint(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		// serviceConfig is the service config JSON of the proto package, or empty for none.
+		serviceConfig string
+		ruleIDs       []string
+		// annotations are the rules of the annotations, at the service in the proto file, in order.
+		annotations []string
+	}{
+		{
+			name:          "valid",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+		},
+		{
+			name:          "invalid service config",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "forever"}]}`,
+			annotations:   []string{"INVALID_SERVICE_CONFIG"},
+		},
+		{
+			name: "missing service config is opt-in",
+		},
+		{
+			name:        "missing service config",
+			ruleIDs:     []string{"MISSING_SERVICE_CONFIG"},
+			annotations: []string{"MISSING_SERVICE_CONFIG"},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTestFiles(t, tt.serviceConfig)
+			expected := make([]checktest.ExpectedAnnotation, 0, len(tt.annotations))
+			for _, ruleID := range tt.annotations {
+				expected = append(expected, checktest.ExpectedAnnotation{RuleID: ruleID, FileLocation: testServiceLocation})
+			}
+			checktest.CheckTest{
+				Request: &checktest.RequestSpec{
+					Files: &checktest.ProtoFileSpec{
+						DirPaths:  []string{dir},
+						FilePaths: []string{testProtoFile},
+					},
+					RuleIDs: tt.ruleIDs,
+					Options: map[string]any{"path": dir},
+				},
+				Spec:                newSpec(),
+				ExpectedAnnotations: expected,
+			}.Run(t)
+		})
+	}
+}
+
+func TestBreaking(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		// serviceConfig and againstServiceConfig are the current and baseline service configs, or empty for none.
+		serviceConfig        string
+		againstServiceConfig string
+		// annotations are the rules of the annotations, at the service in the proto files, in order.
+		annotations []string
+	}{
+		{
+			name:                 "unchanged",
+			serviceConfig:        `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+			againstServiceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+		},
+		{
+			name:                 "removed service config",
+			againstServiceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+			annotations:          []string{"BREAKING_CHANGE"},
+		},
+		{
+			name:                 "shrunk timeout",
+			serviceConfig:        `{"methodConfig": [{"name": [{}], "timeout": "1s"}]}`,
+			againstServiceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+			annotations:          []string{"BREAKING_CHANGE"},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTestFiles(t, tt.serviceConfig)
+			againstDir := writeTestFiles(t, tt.againstServiceConfig)
+			expected := make([]checktest.ExpectedAnnotation, 0, len(tt.annotations))
+			for _, ruleID := range tt.annotations {
+				expected = append(expected, checktest.ExpectedAnnotation{
+					RuleID:              ruleID,
+					FileLocation:        testServiceLocation,
+					AgainstFileLocation: testServiceLocation,
+				})
+			}
+			checktest.CheckTest{
+				Request: &checktest.RequestSpec{
+					Files: &checktest.ProtoFileSpec{
+						DirPaths:  []string{dir},
+						FilePaths: []string{testProtoFile},
+					},
+					AgainstFiles: &checktest.ProtoFileSpec{
+						DirPaths:  []string{againstDir},
+						FilePaths: []string{testProtoFile},
+					},
+					RuleIDs: []string{"BREAKING_CHANGE"},
+					Options: map[string]any{"path": dir, "against_path": againstDir},
+				},
+				Spec:                newSpec(),
+				ExpectedAnnotations: expected,
+			}.Run(t)
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/plugin"
+)
+
+// builtinCommands are the commands handled by main itself, in addition to commands.
+var builtinCommands = []struct {
+	name        string
+	description string
+}{
+	{name: "help", description: "print the commands of the CLI"},
+	{name: "version", description: "print the version of the CLI and of grpc-go"},
+	{name: "completion", description: "print a bash, zsh or fish completion script"},
+	{name: "man", description: "print a man page in roff format"},
+}
+
+// commandFlags returns the flags of the command at index i of commands.
+func commandFlags(i int) []*flag.Flag {
+	return plugin.CommandFlags(commands[i].run, commands[i].subcommands...)
+}
+
+// isBoolFlag returns true if the flag does not take a value.
+func isBoolFlag(f *flag.Flag) bool {
+	boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
+
+// completion writes the completion script of the shell.
+func completion(w io.Writer, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: grpc-service-config completion bash|zsh|fish")
+	}
+	switch args[0] {
+	case "bash":
+		return bashCompletion(w)
+	case "zsh":
+		return zshCompletion(w)
+	case "fish":
+		return fishCompletion(w)
+	}
+	return fmt.Errorf("completion: unsupported shell %q (expected bash, zsh or fish)", args[0])
+}
+
+// bashCompletion writes a bash completion script, which completes commands, subcommands and flags, and falls back to
+// file names.
+func bashCompletion(w io.Writer) error {
+	var b strings.Builder
+	names := make([]string, 0, len(commands)+len(builtinCommands))
+	for _, command := range commands {
+		names = append(names, command.name)
+	}
+	for _, command := range builtinCommands {
+		names = append(names, command.name)
+	}
+	b.WriteString("# bash completion for grpc-service-config\n")
+	b.WriteString("_grpc_service_config() {\n")
+	b.WriteString("\tlocal cur=${COMP_WORDS[COMP_CWORD]}\n")
+	b.WriteString("\tif [[ $COMP_CWORD -eq 1 ]]; then\n")
+	fmt.Fprintf(&b, "\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(names, " "))
+	b.WriteString("\t\treturn\n")
+	b.WriteString("\tfi\n")
+	b.WriteString("\tcase ${COMP_WORDS[1]} in\n")
+	for i, command := range commands {
+		var flagNames []string
+		for _, f := range commandFlags(i) {
+			flagNames = append(flagNames, "-"+f.Name)
+		}
+		fmt.Fprintf(&b, "\t%s)\n", command.name)
+		if len(command.subcommands) > 0 {
+			b.WriteString("\t\tif [[ $COMP_CWORD -eq 2 ]]; then\n")
+			fmt.Fprintf(
+				&b,
+				"\t\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n",
+				strings.Join(command.subcommands, " "),
+			)
+			b.WriteString("\t\t\treturn\n")
+			b.WriteString("\t\tfi\n")
+		}
+		b.WriteString("\t\tif [[ $cur == -* ]]; then\n")
+		fmt.Fprintf(&b, "\t\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(flagNames, " "))
+		b.WriteString("\t\tfi\n")
+		b.WriteString("\t\t;;\n")
+	}
+	b.WriteString("\tcompletion)\n")
+	b.WriteString("\t\tCOMPREPLY=($(compgen -W \"bash zsh fish\" -- \"$cur\"))\n")
+	b.WriteString("\t\t;;\n")
+	b.WriteString("\tesac\n")
+	b.WriteString("}\n")
+	b.WriteString("complete -o default -F _grpc_service_config grpc-service-config\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// zshCompletion writes a zsh completion script, which completes commands, subcommands and flags with descriptions,
+// and file names.
+func zshCompletion(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("#compdef grpc-service-config\n\n")
+	b.WriteString("_grpc_service_config() {\n")
+	b.WriteString("\tlocal -a commands\n")
+	b.WriteString("\tcommands=(\n")
+	for _, command := range commands {
+		fmt.Fprintf(&b, "\t\t%s\n", zshQuote(command.name+":"+command.description))
+	}
+	for _, command := range builtinCommands {
+		fmt.Fprintf(&b, "\t\t%s\n", zshQuote(command.name+":"+command.description))
+	}
+	b.WriteString("\t)\n")
+	b.WriteString("\tif (( CURRENT == 2 )); then\n")
+	b.WriteString("\t\t_describe 'command' commands\n")
+	b.WriteString("\t\treturn\n")
+	b.WriteString("\tfi\n")
+	b.WriteString("\tlocal command=$words[2]\n")
+	b.WriteString("\twords=(${words[2,-1]})\n")
+	b.WriteString("\t(( CURRENT-- ))\n")
+	b.WriteString("\tcase $command in\n")
+	for i, command := range commands {
+		fmt.Fprintf(&b, "\t%s)\n", command.name)
+		if len(command.subcommands) > 0 {
+			b.WriteString("\t\tif (( CURRENT == 2 )); then\n")
+			fmt.Fprintf(&b, "\t\t\tcompadd %s\n", strings.Join(command.subcommands, " "))
+			b.WriteString("\t\t\treturn\n")
+			b.WriteString("\t\tfi\n")
+			b.WriteString("\t\twords=(${words[2,-1]})\n")
+			b.WriteString("\t\t(( CURRENT-- ))\n")
+		}
+		b.WriteString("\t\t_arguments")
+		for _, f := range commandFlags(i) {
+			description := zshEscape(f.Usage)
+			if isBoolFlag(f) {
+				fmt.Fprintf(&b, " \\\n\t\t\t%s", zshQuote("-"+f.Name+"["+description+"]"))
+				continue
+			}
+			fmt.Fprintf(&b, " \\\n\t\t\t%s", zshQuote("-"+f.Name+"=["+description+"]:"+f.Name+":_files"))
+		}
+		fmt.Fprintf(&b, " \\\n\t\t\t%s\n", zshQuote("*:file:_files"))
+		b.WriteString("\t\t;;\n")
+	}
+	b.WriteString("\tcompletion)\n")
+	b.WriteString("\t\tcompadd bash zsh fish\n")
+	b.WriteString("\t\t;;\n")
+	b.WriteString("\tesac\n")
+	b.WriteString("}\n\n")
+	b.WriteString("_grpc_service_config \"$@\"\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// zshEscape escapes the characters with special meaning in _arguments option descriptions.
+func zshEscape(s string) string {
+	return strings.NewReplacer("[", "\\[", "]", "\\]", ":", "\\:").Replace(s)
+}
+
+// zshQuote quotes a word for zsh.
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishCompletion writes a fish completion script, which completes commands, subcommands and flags with
+// descriptions.
+func fishCompletion(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# fish completion for grpc-service-config\n")
+	for _, command := range commands {
+		fmt.Fprintf(
+			&b,
+			"complete -c grpc-service-config -f -n __fish_use_subcommand -a %s -d %s\n",
+			command.name,
+			fishQuote(command.description),
+		)
+	}
+	for _, command := range builtinCommands {
+		fmt.Fprintf(
+			&b,
+			"complete -c grpc-service-config -f -n __fish_use_subcommand -a %s -d %s\n",
+			command.name,
+			fishQuote(command.description),
+		)
+	}
+	for i, command := range commands {
+		condition := fishQuote("__fish_seen_subcommand_from " + command.name)
+		if len(command.subcommands) > 0 {
+			fmt.Fprintf(
+				&b,
+				"complete -c grpc-service-config -f -n %s -a %s\n",
+				condition,
+				fishQuote(strings.Join(command.subcommands, " ")),
+			)
+		}
+		for _, f := range commandFlags(i) {
+			requiresValue := " -r"
+			if isBoolFlag(f) {
+				requiresValue = ""
+			}
+			fmt.Fprintf(
+				&b,
+				"complete -c grpc-service-config -n %s -o %s -d %s%s\n",
+				condition,
+				f.Name,
+				fishQuote(f.Usage),
+				requiresValue,
+			)
+		}
+	}
+	b.WriteString("complete -c grpc-service-config -f -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// fishQuote quotes a word for fish.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+// manPage writes a man page for the CLI in roff format, with every command and flag.
+func manPage(w io.Writer) error {
+	var b strings.Builder
+	// The date is left out, so that generating the man page is reproducible.
+	fmt.Fprintf(&b, ".TH GRPC-SERVICE-CONFIG 1 \"\" \"grpc-service-config %s\"\n", roffEscape(plugin.Version()))
+	b.WriteString(".SH NAME\n")
+	b.WriteString("grpc-service-config \\- work with gRPC service config files independent of protoc\n")
+	b.WriteString(".SH SYNOPSIS\n")
+	b.WriteString(".B grpc-service-config\n")
+	b.WriteString(".I command\n")
+	b.WriteString(".RI [ options ]\n")
+	b.WriteString(".RI [ arguments ]\n")
+	b.WriteString(".SH COMMANDS\n")
+	for i, command := range commands {
+		name := command.name
+		if len(command.subcommands) > 0 {
+			name += " " + strings.Join(command.subcommands, "|")
+		}
+		fmt.Fprintf(&b, ".SS %s\n", roffEscape(name))
+		fmt.Fprintf(&b, "%s.\n", roffEscape(strings.ToUpper(command.description[:1])+command.description[1:]))
+		for _, f := range commandFlags(i) {
+			b.WriteString(".TP\n")
+			if isBoolFlag(f) {
+				fmt.Fprintf(&b, ".B \\-%s\n", roffEscape(f.Name))
+			} else {
+				fmt.Fprintf(&b, ".BI \\-%s \" value\"\n", roffEscape(f.Name))
+			}
+			usage := f.Usage
+			if f.DefValue != "" && f.DefValue != "false" {
+				usage += " (default " + f.DefValue + ")"
+			}
+			fmt.Fprintf(&b, "%s\n", roffEscape(usage))
+		}
+	}
+	for _, command := range builtinCommands {
+		fmt.Fprintf(&b, ".SS %s\n", command.name)
+		fmt.Fprintf(&b, "%s.\n", roffEscape(strings.ToUpper(command.description[:1])+command.description[1:]))
+	}
+	b.WriteString(".SH SEE ALSO\n")
+	b.WriteString("https://github.com/einride/protoc-gen-go-grpc-service-config\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// roffEscape escapes text for roff, including lines that would otherwise start with a control character.
+func roffEscape(s string) string {
+	s = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
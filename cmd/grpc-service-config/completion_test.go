@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompletion(t *testing.T) {
+	for _, tt := range []struct {
+		shell    string
+		contains []string
+	}{
+		{
+			shell: "bash",
+			contains: []string{
+				"complete -o default -F _grpc_service_config grpc-service-config\n",
+				"\tfmt)\n\t\tif [[ $cur == -* ]]; then\n\t\t\tCOMPREPLY=($(compgen -W \"-check\" -- \"$cur\"))\n",
+				"\tmigrate)\n\t\tif [[ $COMP_CWORD -eq 2 ]]; then\n\t\t\tCOMPREPLY=($(compgen -W \"gapic\" -- \"$cur\"))\n",
+			},
+		},
+		{
+			shell: "zsh",
+			contains: []string{
+				"#compdef grpc-service-config\n",
+				"\t\t'fmt:rewrite service config JSON files in canonical form'\n",
+				"\t\t\t'-check[list files not in canonical form and fail instead of rewriting them]'",
+				"\t\t\t'-o=[output file (default stdout)]:o:_files'",
+			},
+		},
+		{
+			shell: "fish",
+			contains: []string{
+				"complete -c grpc-service-config -f -n __fish_use_subcommand -a fmt" +
+					" -d 'rewrite service config JSON files in canonical form'\n",
+				"complete -c grpc-service-config -n '__fish_seen_subcommand_from migrate' -o o" +
+					" -d 'output file (default stdout)' -r\n",
+				"complete -c grpc-service-config -f -n '__fish_seen_subcommand_from migrate' -a 'gapic'\n",
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.shell, func(t *testing.T) {
+			var b strings.Builder
+			if err := completion(&b, []string{tt.shell}); err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(b.String(), s) {
+					t.Errorf("expected completion to contain %q, got:\n%s", s, b.String())
+				}
+			}
+		})
+	}
+	if err := completion(&strings.Builder{}, []string{"powershell"}); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+}
+
+func TestManPage(t *testing.T) {
+	var b strings.Builder
+	if err := manPage(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		".SS fmt\nRewrite service config JSON files in canonical form.\n.TP\n.B \\-check\n",
+		".SS migrate gapic\n",
+		".BI \\-o \" value\"\noutput file (default stdout)\n",
+		".SS completion\nPrint a bash, zsh or fish completion script.\n",
+	} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("expected man page to contain %q, got:\n%s", s, b.String())
+		}
+	}
+}
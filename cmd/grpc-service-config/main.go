@@ -0,0 +1,139 @@
+// Command grpc-service-config works with gRPC service config files independent of protoc.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/plugin"
+)
+
+// commands are the commands of the CLI.
+var commands = []struct {
+	name        string
+	description string
+	// subcommands are the subcommands the command expects as its first argument, if any.
+	subcommands []string
+	run         func(args []string) error
+}{
+	{
+		name:        "validate",
+		description: "validate service config files against a descriptor set",
+		run:         plugin.ValidateCommand,
+	},
+	{
+		name:        "fmt",
+		description: "rewrite service config JSON files in canonical form",
+		run:         plugin.FormatCommand,
+	},
+	{
+		name:        "convert",
+		description: "convert between service config files and default_service_config annotations",
+		run:         plugin.ConvertCommand,
+	},
+	{
+		name:        "extract",
+		description: "write the service config JSON embedded in a generated Go file",
+		run:         plugin.ExtractCommand,
+	},
+	{
+		name:        "diff",
+		description: "report semantic changes between two service config files",
+		run:         plugin.DiffCommand,
+	},
+	{
+		name:        "merge",
+		description: "merge service config files, where later files take precedence",
+		run:         plugin.MergeCommand,
+	},
+	{
+		name:        "init",
+		description: "scaffold a starter service config file for a proto package",
+		run:         plugin.InitCommand,
+	},
+	{
+		name:        "migrate",
+		description: "convert GAPIC retry configuration to a service config file",
+		subcommands: []string{"gapic"},
+		run:         plugin.MigrateCommand,
+	},
+	{
+		name:        "resolve",
+		description: "print the resolved service config of every service in a Buf image",
+		run:         plugin.ResolveCommand,
+	},
+	{
+		name:        "explain",
+		description: "print the effective timeout, retries and limits of every method in a Buf image",
+		run:         plugin.ExplainCommand,
+	},
+	{
+		name:        "doctor",
+		description: "check a repository for orphaned, misnamed, conflicting and missing service configs",
+		run:         plugin.DoctorCommand,
+	},
+	{
+		name:        "dashboard",
+		description: "render an HTML dashboard of the service config coverage of a Buf image",
+		run:         plugin.DashboardCommand,
+	},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	name, args := os.Args[1], os.Args[2:]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		usage(os.Stdout)
+		return
+	case "version", "-version", "--version":
+		fmt.Println("grpc-service-config", plugin.VersionInfo())
+		return
+	case "completion":
+		if err := completion(os.Stdout, args); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		return
+	case "man":
+		if err := manPage(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	for _, command := range commands {
+		if command.name != name {
+			continue
+		}
+		if err := command.run(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	usage(os.Stderr)
+	os.Exit(2)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: grpc-service-config <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, command := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", command.name, command.description)
+	}
+	for _, command := range builtinCommands {
+		fmt.Fprintf(w, "  %-12s %s\n", command.name, command.description)
+	}
+}
@@ -0,0 +1,68 @@
+// Package configjson is the service config JSON format shared by the plugin and the runtime packages.
+// It only depends on the standard library and gRPC status codes, so that client binaries using the runtime packages
+// do not link the plugin.
+package configjson
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/codes"
+)
+
+// MaxAttemptsLimit is the limit of retry and hedging attempts in gRPC.
+// Larger values of maxAttempts are silently reduced to the limit.
+const MaxAttemptsLimit = 5
+
+// ServiceConfig is the service config JSON format. Numbers are decoded as json.Number, since the protobuf JSON format
+// also allows numbers as strings.
+type ServiceConfig struct {
+	LoadBalancingConfig []map[string]json.RawMessage `json:"loadBalancingConfig"`
+	LoadBalancingPolicy string                       `json:"loadBalancingPolicy"`
+	MethodConfig        []MethodConfig               `json:"methodConfig"`
+	RetryThrottling     *RetryThrottling             `json:"retryThrottling"`
+	HealthCheckConfig   *HealthCheckConfig           `json:"healthCheckConfig"`
+}
+
+// MethodConfig is a method config in the service config JSON format.
+type MethodConfig struct {
+	Name                    []Name         `json:"name"`
+	WaitForReady            *bool          `json:"waitForReady"`
+	Timeout                 *string        `json:"timeout"`
+	MaxRequestMessageBytes  json.Number    `json:"maxRequestMessageBytes"`
+	MaxResponseMessageBytes json.Number    `json:"maxResponseMessageBytes"`
+	RetryPolicy             *RetryPolicy   `json:"retryPolicy"`
+	HedgingPolicy           *HedgingPolicy `json:"hedgingPolicy"`
+}
+
+// Name is a name in the service config JSON format. Field names are matched case-insensitively when decoding.
+type Name struct {
+	Service string
+	Method  string
+}
+
+// RetryPolicy is a retry policy in the service config JSON format.
+type RetryPolicy struct {
+	MaxAttempts          json.Number  `json:"maxAttempts"`
+	InitialBackoff       string       `json:"initialBackoff"`
+	MaxBackoff           string       `json:"maxBackoff"`
+	BackoffMultiplier    json.Number  `json:"backoffMultiplier"`
+	RetryableStatusCodes []codes.Code `json:"retryableStatusCodes"`
+}
+
+// HedgingPolicy is a hedging policy in the service config JSON format.
+type HedgingPolicy struct {
+	MaxAttempts         json.Number  `json:"maxAttempts"`
+	HedgingDelay        *string      `json:"hedgingDelay"`
+	NonFatalStatusCodes []codes.Code `json:"nonFatalStatusCodes"`
+}
+
+// RetryThrottling is a retry throttling policy in the service config JSON format.
+type RetryThrottling struct {
+	MaxTokens  json.Number `json:"maxTokens"`
+	TokenRatio json.Number `json:"tokenRatio"`
+}
+
+// HealthCheckConfig is a health check config in the service config JSON format.
+type HealthCheckConfig struct {
+	ServiceName string `json:"serviceName"`
+}
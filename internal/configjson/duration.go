@@ -0,0 +1,60 @@
+package configjson
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseDuration parses a duration in the protobuf JSON format, such as "1.5s".
+func ParseDuration(s string) (time.Duration, error) {
+	if !strings.HasSuffix(s, "s") {
+		return 0, fmt.Errorf("malformed duration %q", s)
+	}
+	seconds, nanos := strings.TrimSuffix(s, "s"), ""
+	if i := strings.IndexByte(seconds, '.'); i != -1 {
+		seconds, nanos = seconds[:i], seconds[i+1:]
+		if nanos == "" || len(nanos) > 9 {
+			return 0, fmt.Errorf("malformed duration %q", s)
+		}
+	}
+	negative := strings.HasPrefix(seconds, "-")
+	seconds = strings.TrimPrefix(seconds, "-")
+	if seconds == "" && nanos == "" {
+		return 0, fmt.Errorf("malformed duration %q", s)
+	}
+	var d time.Duration
+	if seconds != "" {
+		n, err := strconv.ParseInt(seconds, 10, 64)
+		if err != nil || n > int64(math.MaxInt64/time.Second) {
+			return 0, fmt.Errorf("malformed duration %q", s)
+		}
+		d = time.Duration(n) * time.Second
+	}
+	if nanos != "" {
+		n, err := strconv.ParseInt(nanos+strings.Repeat("0", 9-len(nanos)), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("malformed duration %q", s)
+		}
+		d += time.Duration(n)
+	}
+	if negative {
+		d = -d
+	}
+	return d, nil
+}
+
+// FormatDuration formats a duration in the shortest protobuf JSON format, such as "1.5s".
+func FormatDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	seconds, nanos := d/time.Second, d%time.Second
+	if nanos == 0 {
+		return fmt.Sprintf("%s%ds", sign, seconds)
+	}
+	return fmt.Sprintf("%s%d.%ss", sign, seconds, strings.TrimRight(fmt.Sprintf("%09d", nanos), "0"))
+}
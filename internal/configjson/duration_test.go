@@ -0,0 +1,54 @@
+package configjson
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected time.Duration
+		err      bool
+	}{
+		{input: "1s", expected: time.Second},
+		{input: "1.5s", expected: 1500 * time.Millisecond},
+		{input: "0.000000001s", expected: time.Nanosecond},
+		{input: ".5s", expected: 500 * time.Millisecond},
+		{input: "-2.5s", expected: -2500 * time.Millisecond},
+		{input: "1", err: true},
+		{input: "1m", err: true},
+		{input: "1.s", err: true},
+		{input: "s", err: true},
+		{input: "1.0000000001s", err: true},
+		{input: "9223372037s", err: true},
+	} {
+		actual, err := ParseDuration(tt.input)
+		if tt.err {
+			if err == nil {
+				t.Errorf("expected %q to be invalid, got %v", tt.input, actual)
+			}
+			continue
+		}
+		if err != nil || actual != tt.expected {
+			t.Errorf("expected %q to be %v, got %v, %v", tt.input, tt.expected, actual, err)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for _, tt := range []struct {
+		input    time.Duration
+		expected string
+	}{
+		{input: 0, expected: "0s"},
+		{input: 10 * time.Second, expected: "10s"},
+		{input: 1500 * time.Millisecond, expected: "1.5s"},
+		{input: time.Nanosecond, expected: "0.000000001s"},
+		{input: -2500 * time.Millisecond, expected: "-2.5s"},
+	} {
+		if actual := FormatDuration(tt.input); actual != tt.expected {
+			t.Errorf("expected %v to be formatted as %q, got %q", tt.input, tt.expected, actual)
+		}
+	}
+}
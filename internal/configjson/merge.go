@@ -0,0 +1,138 @@
+package configjson
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Merge merges the overlay service config into the base service config.
+// Fields in the overlay replace fields in the base, except for method configs: method configs from both are kept, and
+// names in the overlay take precedence over the same names in the base.
+func Merge(base, overlay string) (string, error) {
+	var baseFields, overlayFields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(base), &baseFields); err != nil {
+		return "", fmt.Errorf("merge service configs: base: %w", err)
+	}
+	if err := json.Unmarshal([]byte(overlay), &overlayFields); err != nil {
+		return "", fmt.Errorf("merge service configs: overlay: %w", err)
+	}
+	if baseFields == nil {
+		baseFields = map[string]json.RawMessage{}
+	}
+	for key, value := range overlayFields {
+		if key == "methodConfig" || key == "method_config" {
+			continue
+		}
+		baseFields[key] = value
+	}
+	methodConfigs, err := mergeMethodConfigs(MethodConfigsField(baseFields), MethodConfigsField(overlayFields))
+	if err != nil {
+		return "", fmt.Errorf("merge service configs: %w", err)
+	}
+	delete(baseFields, "method_config")
+	if len(methodConfigs) > 0 {
+		data, err := json.Marshal(methodConfigs)
+		if err != nil {
+			return "", fmt.Errorf("merge service configs: %w", err)
+		}
+		baseFields["methodConfig"] = data
+	}
+	data, err := json.Marshal(baseFields)
+	if err != nil {
+		return "", fmt.Errorf("merge service configs: %w", err)
+	}
+	return string(data), nil
+}
+
+// MethodConfigsField returns the method configs field of a service config, by its JSON name or proto name.
+func MethodConfigsField(fields map[string]json.RawMessage) json.RawMessage {
+	if value, ok := fields["methodConfig"]; ok {
+		return value
+	}
+	return fields["method_config"]
+}
+
+// mergeMethodConfigs merges the overlay method configs into the base method configs.
+// Names matched by an overlay method config are removed from the base method configs, and base method configs left
+// without names are dropped.
+func mergeMethodConfigs(base, overlay json.RawMessage) ([]map[string]json.RawMessage, error) {
+	var baseMethodConfigs, overlayMethodConfigs []map[string]json.RawMessage
+	if len(base) > 0 {
+		if err := json.Unmarshal(base, &baseMethodConfigs); err != nil {
+			return nil, fmt.Errorf("base methodConfig: %w", err)
+		}
+	}
+	if len(overlay) > 0 {
+		if err := json.Unmarshal(overlay, &overlayMethodConfigs); err != nil {
+			return nil, fmt.Errorf("overlay methodConfig: %w", err)
+		}
+	}
+	overlayNames := map[Name]struct{}{}
+	for _, methodConfig := range overlayMethodConfigs {
+		names, err := methodConfigNames(methodConfig)
+		if err != nil {
+			return nil, fmt.Errorf("overlay methodConfig: %w", err)
+		}
+		for _, name := range names {
+			overlayNames[name.name] = struct{}{}
+		}
+	}
+	result := make([]map[string]json.RawMessage, 0, len(baseMethodConfigs)+len(overlayMethodConfigs))
+	for _, methodConfig := range baseMethodConfigs {
+		names, err := methodConfigNames(methodConfig)
+		if err != nil {
+			return nil, fmt.Errorf("base methodConfig: %w", err)
+		}
+		remaining := make([]json.RawMessage, 0, len(names))
+		for _, name := range names {
+			if _, ok := overlayNames[name.name]; !ok {
+				remaining = append(remaining, name.raw)
+			}
+		}
+		if len(remaining) == len(names) {
+			result = append(result, methodConfig)
+			continue
+		}
+		if len(remaining) == 0 {
+			continue
+		}
+		data, err := json.Marshal(remaining)
+		if err != nil {
+			return nil, err
+		}
+		merged := make(map[string]json.RawMessage, len(methodConfig))
+		for key, value := range methodConfig {
+			merged[key] = value
+		}
+		merged["name"] = data
+		result = append(result, merged)
+	}
+	return append(result, overlayMethodConfigs...), nil
+}
+
+// rawName is a name in a method config, together with its original JSON.
+type rawName struct {
+	name Name
+	raw  json.RawMessage
+}
+
+// methodConfigNames returns the names of a method config.
+func methodConfigNames(methodConfig map[string]json.RawMessage) ([]rawName, error) {
+	data, ok := methodConfig["name"]
+	if !ok {
+		return nil, nil
+	}
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return nil, err
+	}
+	names := make([]rawName, 0, len(raws))
+	for _, raw := range raws {
+		var name Name
+		if err := json.Unmarshal(raw, &name); err != nil {
+			return nil, err
+		}
+		names = append(names, rawName{name: name, raw: raw})
+	}
+	return names, nil
+}
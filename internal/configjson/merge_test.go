@@ -0,0 +1,78 @@
+package configjson
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		base     string
+		overlay  string
+		expected string
+		// err is a prefix of the error, or empty for no error.
+		err string
+	}{
+		{
+			name:     "overlay fields take precedence",
+			base:     `{"loadBalancingPolicy": "pick_first", "retryThrottling": {"maxTokens": 10, "tokenRatio": 0.1}}`,
+			overlay:  `{"loadBalancingPolicy": "round_robin"}`,
+			expected: `{"loadBalancingPolicy":"round_robin","retryThrottling":{"maxTokens":10,"tokenRatio":0.1}}`,
+		},
+		{
+			name:    "method configs are kept",
+			base:    `{"methodConfig": [{"name": [{"service": "a.B"}], "timeout": "1s"}]}`,
+			overlay: `{"methodConfig": [{"name": [{"service": "a.C"}], "timeout": "2s"}]}`,
+			expected: `{"methodConfig":[` +
+				`{"name":[{"service":"a.B"}],"timeout":"1s"},` +
+				`{"name":[{"service":"a.C"}],"timeout":"2s"}]}`,
+		},
+		{
+			name: "overlay names take precedence",
+			base: `{"method_config": [
+  {"name": [{"service": "a.B"}, {"service": "a.C"}], "timeout": "1s"},
+  {"name": [{"service": "a.D"}], "timeout": "1s"}
+]}`,
+			overlay: `{"methodConfig": [{"name": [{"service": "a.C"}, {"service": "a.D"}], "timeout": "2s"}]}`,
+			expected: `{"methodConfig":[` +
+				`{"name":[{"service":"a.B"}],"timeout":"1s"},` +
+				`{"name":[{"service":"a.C"},{"service":"a.D"}],"timeout":"2s"}]}`,
+		},
+		{
+			name:     "empty overlay",
+			base:     `{"methodConfig": [{"name": [{}], "timeout": "1s"}]}`,
+			overlay:  `{}`,
+			expected: `{"methodConfig":[{"name":[{}],"timeout":"1s"}]}`,
+		},
+		{
+			name:    "invalid base",
+			base:    `[]`,
+			overlay: `{}`,
+			err:     "merge service configs: base: json: cannot unmarshal array",
+		},
+		{
+			name:    "invalid overlay names",
+			base:    `{}`,
+			overlay: `{"methodConfig": [{"name": {}}]}`,
+			err:     "merge service configs: overlay methodConfig: json: cannot unmarshal object",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := Merge(tt.base, tt.overlay)
+			if tt.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Fatalf("expected error starting with %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if merged != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, merged)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package configjson
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// OverrideMethodConfig returns the service config with fields of the method config of the name replaced, where a nil
+// field is removed. The method config the name resolves to is copied into a new method config for exactly that name,
+// so that the other names of the method config are unaffected.
+func OverrideMethodConfig(serviceConfig string, name Name, fields map[string]json.RawMessage) (string, error) {
+	if name.Service == "" && name.Method != "" {
+		return "", fmt.Errorf("override method config: method %s without service", name.Method)
+	}
+	var serviceConfigFields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(serviceConfig), &serviceConfigFields); err != nil {
+		return "", fmt.Errorf("override method config: %w", err)
+	}
+	var methodConfigs []map[string]json.RawMessage
+	if data := MethodConfigsField(serviceConfigFields); len(data) > 0 {
+		if err := json.Unmarshal(data, &methodConfigs); err != nil {
+			return "", fmt.Errorf("override method config: methodConfig: %w", err)
+		}
+	}
+	resolved, err := resolveMethodConfig(methodConfigs, name)
+	if err != nil {
+		return "", fmt.Errorf("override method config: %w", err)
+	}
+	overridden := make(map[string]json.RawMessage, len(resolved)+len(fields))
+	for key, value := range resolved {
+		overridden[key] = value
+	}
+	for key, value := range fields {
+		if value == nil {
+			delete(overridden, key)
+			continue
+		}
+		overridden[key] = value
+	}
+	nameFields := map[string]string{}
+	if name.Service != "" {
+		nameFields["service"] = name.Service
+	}
+	if name.Method != "" {
+		nameFields["method"] = name.Method
+	}
+	names, err := json.Marshal([]map[string]string{nameFields})
+	if err != nil {
+		return "", fmt.Errorf("override method config: %w", err)
+	}
+	overridden["name"] = names
+	overlay, err := json.Marshal(map[string]interface{}{
+		"methodConfig": []map[string]json.RawMessage{overridden},
+	})
+	if err != nil {
+		return "", fmt.Errorf("override method config: %w", err)
+	}
+	return Merge(serviceConfig, string(overlay))
+}
+
+// resolveMethodConfig returns the method config that applies to the name, following the gRPC matching rules, or nil
+// when no method config applies. A service name resolves to the service or the default method config, and the
+// default name only to the default method config.
+func resolveMethodConfig(
+	methodConfigs []map[string]json.RawMessage,
+	name Name,
+) (map[string]json.RawMessage, error) {
+	candidates := []Name{name}
+	if name.Method != "" {
+		candidates = append(candidates, Name{Service: name.Service})
+	}
+	if name.Service != "" {
+		candidates = append(candidates, Name{})
+	}
+	for _, candidate := range candidates {
+		for i, methodConfig := range methodConfigs {
+			names, err := methodConfigNames(methodConfig)
+			if err != nil {
+				return nil, fmt.Errorf("methodConfig[%d]: %w", i, err)
+			}
+			for _, methodConfigName := range names {
+				if methodConfigName.name == candidate {
+					return methodConfig, nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,32 @@
+package configjson
+
+import "google.golang.org/grpc/codes"
+
+// statusCodeNames are the service config names of status codes, indexed by code.
+var statusCodeNames = []string{
+	"OK",
+	"CANCELLED",
+	"UNKNOWN",
+	"INVALID_ARGUMENT",
+	"DEADLINE_EXCEEDED",
+	"NOT_FOUND",
+	"ALREADY_EXISTS",
+	"PERMISSION_DENIED",
+	"RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION",
+	"ABORTED",
+	"OUT_OF_RANGE",
+	"UNIMPLEMENTED",
+	"INTERNAL",
+	"UNAVAILABLE",
+	"DATA_LOSS",
+	"UNAUTHENTICATED",
+}
+
+// StatusCodeName returns the service config name of the status code, such as UNAVAILABLE.
+func StatusCodeName(code codes.Code) string {
+	if int(code) < len(statusCodeNames) {
+		return statusCodeNames[code]
+	}
+	return code.String()
+}
@@ -0,0 +1,21 @@
+package configjson
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestStatusCodeName(t *testing.T) {
+	for code, expected := range map[codes.Code]string{
+		codes.OK:              "OK",
+		codes.Canceled:        "CANCELLED",
+		codes.Unavailable:     "UNAVAILABLE",
+		codes.Unauthenticated: "UNAUTHENTICATED",
+		codes.Code(17):        "Code(17)",
+	} {
+		if actual := StatusCodeName(code); actual != expected {
+			t.Errorf("expected %s, got %s", expected, actual)
+		}
+	}
+}
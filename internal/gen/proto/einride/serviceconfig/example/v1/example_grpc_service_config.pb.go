@@ -1,4 +1,7 @@
 // Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.
+// versions:
+// 	grpc-go v1.48.0
+
 package examplev1
 
 // DefaultServiceConfig is the default service config for all services in the package.
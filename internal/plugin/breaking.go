@@ -0,0 +1,250 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// breakingOptions configures breaking change detection.
+type breakingOptions struct {
+	// baseline is the directory of previously generated files to compare against, or empty to not detect breaking
+	// changes.
+	baseline string
+	// timeoutRatio is the smallest allowed ratio between a new and a previous timeout, or 0 to allow any timeout.
+	timeoutRatio float64
+}
+
+// validate returns an error if the breaking change options are invalid.
+func (o breakingOptions) validate() error {
+	if o.timeoutRatio < 0 || o.timeoutRatio > 1 {
+		return fmt.Errorf("invalid breaking timeout ratio %v (expected a value between 0 and 1)", o.timeoutRatio)
+	}
+	return nil
+}
+
+// resolveBaselineServiceConfig resolves the service config of a service from the files previously generated in the
+// baseline directory.
+func (p *plugin) resolveBaselineServiceConfig(
+	baseline string,
+	file *protogen.File,
+	service *protogen.Service,
+) (resolvedServiceConfig, bool, error) {
+	candidates := []struct {
+		filename string
+		constant string
+	}{
+		{
+			filename: p.generatedFromJSONFilename(file),
+			constant: "ServiceConfig",
+		},
+		{
+			filename: p.generatedFromProtoFilename(file),
+			constant: "DefaultServiceConfig",
+		},
+	}
+	for _, candidate := range candidates {
+		filename := filepath.Join(baseline, filepath.FromSlash(candidate.filename))
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return resolvedServiceConfig{}, false, fmt.Errorf("resolve %s baseline: %w", service.Desc.FullName(), err)
+		}
+		serviceConfig, ok, err := stringConstant(filename, data, candidate.constant)
+		if err != nil {
+			return resolvedServiceConfig{}, false, fmt.Errorf("resolve %s baseline: %w", service.Desc.FullName(), err)
+		}
+		if ok {
+			return resolvedServiceConfig{source: filename, json: serviceConfig}, true, nil
+		}
+	}
+	return resolvedServiceConfig{}, false, nil
+}
+
+// stringConstant returns the value of a string constant declared in a Go source file.
+func stringConstant(filename string, data []byte, name string) (string, bool, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), filename, data, 0)
+	if err != nil {
+		return "", false, err
+	}
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			for i, ident := range valueSpec.Names {
+				if ident.Name != name || i >= len(valueSpec.Values) {
+					continue
+				}
+				if selector, ok := valueSpec.Values[i].(*ast.SelectorExpr); ok {
+					return "", false, fmt.Errorf(
+						"%s: constant %s refers to a constant in package %s, which is deduplicated",
+						filename,
+						name,
+						selector.X,
+					)
+				}
+				lit, ok := valueSpec.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					return "", false, fmt.Errorf("%s: constant %s is not a string literal", filename, name)
+				}
+				value, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					return "", false, fmt.Errorf("%s: constant %s: %w", filename, name, err)
+				}
+				return value, true, nil
+			}
+		}
+	}
+	return "", false, nil
+}
+
+// checkBreakingChanges reports changes to the method configs of a service that break clients relying on the
+// previously generated service config: dropped method coverage, removed retries and shrunk timeouts.
+func checkBreakingChanges(
+	diagnostics *diagnostics,
+	opts breakingOptions,
+	service *protogen.Service,
+	baseline resolvedServiceConfig,
+	serviceConfig resolvedServiceConfig,
+	serviceConfigContent serviceConfigJSON,
+) {
+	var baselineContent serviceConfigJSON
+	if err := json.Unmarshal([]byte(baseline.json), &baselineContent); err != nil {
+		diagnostics.errorf(ruleBreakingChange, location{file: baseline.source}, "invalid baseline service config: %v", err)
+		return
+	}
+	for _, method := range service.Methods {
+		baselineIndex, ok := baselineContent.methodConfigFor(method.Desc)
+		if !ok {
+			continue
+		}
+		baselinePath := fmt.Sprintf("methodConfig[%d]", baselineIndex)
+		index, ok := serviceConfigContent.methodConfigFor(method.Desc)
+		if !ok {
+			diagnostics.errorf(
+				ruleBreakingChange,
+				serviceConfig.locate("methodConfig"),
+				"method %s is no longer covered by a method config (previously %s in %s)",
+				method.Desc.FullName(),
+				baselinePath,
+				baseline.source,
+			)
+			continue
+		}
+		path := fmt.Sprintf("methodConfig[%d]", index)
+		baselineMethodConfig := baselineContent.MethodConfigs[baselineIndex]
+		methodConfig := serviceConfigContent.MethodConfigs[index]
+		if baselineMethodConfig.hasRetries() && !methodConfig.hasRetries() {
+			diagnostics.errorf(
+				ruleBreakingChange,
+				serviceConfig.locate(path),
+				"method %s no longer has a retry or hedging policy in %s",
+				method.Desc.FullName(),
+				path,
+			)
+		}
+		if methodConfig.Timeout == nil || opts.timeoutRatio == 0 {
+			continue
+		}
+		timeout, err := configjson.ParseDuration(*methodConfig.Timeout)
+		if err != nil {
+			continue
+		}
+		if baselineMethodConfig.Timeout == nil {
+			diagnostics.errorf(
+				ruleBreakingChange,
+				serviceConfig.locate(path+".timeout"),
+				"method %s has a new timeout of %s in %s, but previously had no timeout",
+				method.Desc.FullName(),
+				timeout,
+				path,
+			)
+			continue
+		}
+		baselineTimeout, err := configjson.ParseDuration(*baselineMethodConfig.Timeout)
+		if err != nil {
+			continue
+		}
+		if float64(timeout) < opts.timeoutRatio*float64(baselineTimeout) {
+			diagnostics.errorf(
+				ruleBreakingChange,
+				serviceConfig.locate(path+".timeout"),
+				"method %s has its timeout shrunk from %s to %s in %s",
+				method.Desc.FullName(),
+				baselineTimeout,
+				timeout,
+				path,
+			)
+		}
+	}
+}
+
+// checkBreakingChangesAgainst reports breaking changes between the service configs of the services to generate and
+// the service configs the same services resolve to in a baseline, such as the images and service config files of a
+// previous branch. Services that lose their service config are also reported.
+func (p *plugin) checkBreakingChangesAgainst(diagnostics *diagnostics, baseline *plugin, opts breakingOptions) error {
+	for _, file := range p.gen.Files {
+		if !file.Generate {
+			continue
+		}
+		for _, service := range file.Services {
+			descriptor, err := baseline.registry().FindDescriptorByName(service.Desc.FullName())
+			if err != nil {
+				continue
+			}
+			baselineService, ok := descriptor.(protoreflect.ServiceDescriptor)
+			if !ok {
+				continue
+			}
+			baselineServiceConfig, ok, err := baseline.resolveServiceConfig(baselineService)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			serviceConfig, ok, err := p.resolveServiceConfig(service.Desc)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				diagnostics.errorf(
+					ruleBreakingChange,
+					location{file: file.Desc.Path()},
+					"service %s no longer has a service config (previously %s)",
+					service.Desc.FullName(),
+					baselineServiceConfig.source,
+				)
+				diagnostics.describe(len(diagnostics.list)-1, service.Desc)
+				continue
+			}
+			var serviceConfigContent serviceConfigJSON
+			if err := json.Unmarshal([]byte(serviceConfig.json), &serviceConfigContent); err != nil {
+				// Invalid service configs are reported by validation.
+				continue
+			}
+			if !serviceConfig.annotation && !serviceConfig.merged {
+				// Positions are only used to improve diagnostics, so failing to parse them is not an error.
+				serviceConfig.positions, _ = parseJSONPositions(serviceConfig.source, []byte(serviceConfig.json))
+			}
+			start := len(diagnostics.list)
+			checkBreakingChanges(diagnostics, opts, service, baselineServiceConfig, serviceConfig, serviceConfigContent)
+			diagnostics.describe(start, service.Desc)
+		}
+	}
+	return nil
+}
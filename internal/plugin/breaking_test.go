@@ -0,0 +1,221 @@
+package plugin
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBreakingOptionsValidate(t *testing.T) {
+	for _, tt := range []struct {
+		timeoutRatio float64
+		err          string
+	}{
+		{timeoutRatio: 0},
+		{timeoutRatio: 0.5},
+		{timeoutRatio: 1},
+		{timeoutRatio: -0.1, err: "invalid breaking timeout ratio -0.1 (expected a value between 0 and 1)"},
+		{timeoutRatio: 1.5, err: "invalid breaking timeout ratio 1.5 (expected a value between 0 and 1)"},
+	} {
+		err := breakingOptions{timeoutRatio: tt.timeoutRatio}.validate()
+		if tt.err == "" && err != nil {
+			t.Errorf("ratio %v: unexpected error: %v", tt.timeoutRatio, err)
+		}
+		if tt.err != "" && (err == nil || err.Error() != tt.err) {
+			t.Errorf("ratio %v: expected error %q, got %v", tt.timeoutRatio, tt.err, err)
+		}
+	}
+}
+
+func TestStringConstant(t *testing.T) {
+	const source = `package freightv1
+
+// ServiceConfig is the service config.
+const ServiceConfig = "{\"methodConfig\": []}"
+
+const (
+	Other = ` + "`other`" + `
+	Number = 1
+)
+`
+	for _, tt := range []struct {
+		name     string
+		constant string
+		value    string
+		found    bool
+		err      string
+	}{
+		{name: "quoted string", constant: "ServiceConfig", value: `{"methodConfig": []}`, found: true},
+		{name: "raw string", constant: "Other", value: "other", found: true},
+		{name: "missing", constant: "DefaultServiceConfig"},
+		{name: "not a string", constant: "Number", err: "a.go: constant Number is not a string literal"},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			value, found, err := stringConstant("a.go", []byte(source), tt.constant)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value != tt.value || found != tt.found {
+				t.Errorf("expected %q (found %v), got %q (found %v)", tt.value, tt.found, value, found)
+			}
+		})
+	}
+}
+
+func TestResolveBaselineServiceConfig(t *testing.T) {
+	p := newTestPlugin(t, pluginOptions{conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+	file := p.gen.FilesByPath["einride/example/freight/v1/freight_service.proto"]
+	service := file.Services[0]
+	if _, ok, err := p.resolveBaselineServiceConfig(t.TempDir(), file, service); err != nil || ok {
+		t.Fatalf("expected no baseline in an empty directory, got %v, %v", ok, err)
+	}
+	baseline := writeTestFiles(t, map[string]string{
+		p.generatedFromJSONFilename(file): "package freightv1\n\n" +
+			"const ServiceConfig = `{\"methodConfig\": []}`\n",
+	})
+	serviceConfig, ok, err := p.resolveBaselineServiceConfig(baseline, file, service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("expected a baseline")
+	}
+	if serviceConfig.json != `{"methodConfig": []}` {
+		t.Errorf("unexpected baseline service config %q", serviceConfig.json)
+	}
+	if !strings.HasPrefix(serviceConfig.source, baseline+string(filepath.Separator)) {
+		t.Errorf("expected the baseline source to be in %s, got %s", baseline, serviceConfig.source)
+	}
+}
+
+func TestCheckBreakingChanges(t *testing.T) {
+	p := newTestPlugin(t, pluginOptions{conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+	service := p.gen.FilesByPath["einride/example/freight/v1/freight_service.proto"].Services[0]
+	for _, tt := range []struct {
+		name          string
+		timeoutRatio  float64
+		baseline      string
+		serviceConfig string
+		diagnostics   []string
+	}{
+		{
+			name:          "unchanged",
+			timeoutRatio:  0.5,
+			baseline:      `{"methodConfig": [{"name": [{}], "timeout": "10s", "retryPolicy": {}}]}`,
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s", "retryPolicy": {}}]}`,
+		},
+		{
+			name:         "dropped method coverage",
+			timeoutRatio: 0.5,
+			baseline: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}], "timeout": "1s"}
+]}`,
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "ListShippers"}], "timeout": "1s"}
+]}`,
+			diagnostics: []string{
+				"a.json: error: method einride.example.freight.v1.FreightService.GetShipper is no longer covered" +
+					" by a method config (previously methodConfig[0] in b.json) (BREAKING_CHANGE)",
+			},
+		},
+		{
+			name:         "removed retries",
+			timeoutRatio: 0.5,
+			baseline: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}], "retryPolicy": {}},
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "ListShippers"}], "hedgingPolicy": {}}
+]}`,
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}]},
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "ListShippers"}], "retryPolicy": {}}
+]}`,
+			diagnostics: []string{
+				"a.json: error: method einride.example.freight.v1.FreightService.GetShipper no longer has a retry" +
+					" or hedging policy in methodConfig[0] (BREAKING_CHANGE)",
+			},
+		},
+		{
+			name:         "shrunk timeouts",
+			timeoutRatio: 0.5,
+			baseline:     `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}], "timeout": "4s"}
+]}`,
+			diagnostics: []string{
+				"a.json: error: method einride.example.freight.v1.FreightService.GetShipper has its timeout shrunk" +
+					" from 10s to 4s in methodConfig[0] (BREAKING_CHANGE)",
+				"a.json: error: method einride.example.freight.v1.FreightService.UpdateShipper has its timeout shrunk" +
+					" from 10s to 4s in methodConfig[0] (BREAKING_CHANGE)",
+				"a.json: error: method einride.example.freight.v1.FreightService.CreateShipper has its timeout shrunk" +
+					" from 10s to 4s in methodConfig[0] (BREAKING_CHANGE)",
+				"a.json: error: method einride.example.freight.v1.FreightService.ImportShippers has its timeout shrunk" +
+					" from 10s to 4s in methodConfig[0] (BREAKING_CHANGE)",
+				"a.json: error: method einride.example.freight.v1.FreightService.ListShippers has its timeout shrunk" +
+					" from 10s to 4s in methodConfig[0] (BREAKING_CHANGE)",
+				"a.json: error: method einride.example.freight.v1.FreightService.WatchShippers has its timeout shrunk" +
+					" from 10s to 4s in methodConfig[0] (BREAKING_CHANGE)",
+			},
+		},
+		{
+			name:         "new timeout",
+			timeoutRatio: 0.5,
+			baseline: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}]}
+]}`,
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}], "timeout": "1s"}
+]}`,
+			diagnostics: []string{
+				"a.json: error: method einride.example.freight.v1.FreightService.GetShipper has a new timeout of 1s" +
+					" in methodConfig[0], but previously had no timeout (BREAKING_CHANGE)",
+			},
+		},
+		{
+			name:          "any timeout",
+			baseline:      `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "1s"}]}`,
+		},
+		{
+			name:          "invalid baseline",
+			baseline:      `{"methodConfig": {}}`,
+			serviceConfig: `{"methodConfig": []}`,
+			diagnostics: []string{
+				"b.json: error: invalid baseline service config: json: cannot unmarshal object into Go struct field" +
+					" serviceConfigJSON.methodConfig of type []plugin.methodConfigJSON (BREAKING_CHANGE)",
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			serviceConfig := resolvedServiceConfig{source: "a.json", json: tt.serviceConfig}
+			serviceConfigContent, err := parseTestServiceConfig(serviceConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var found diagnostics
+			checkBreakingChanges(
+				&found,
+				breakingOptions{timeoutRatio: tt.timeoutRatio},
+				service,
+				resolvedServiceConfig{source: "b.json", json: tt.baseline},
+				serviceConfig,
+				serviceConfigContent,
+			)
+			if len(found.list) != len(tt.diagnostics) {
+				t.Fatalf("expected %d diagnostics, got %v", len(tt.diagnostics), found.list)
+			}
+			for i, diagnostic := range found.list {
+				if diagnostic.String() != tt.diagnostics[i] {
+					t.Errorf("expected diagnostic %q, got %q", tt.diagnostics[i], diagnostic)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package plugin
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// BufCheckRule is a rule of the buf check plugin, which reports the problems found by a validation or lint rule.
+type BufCheckRule struct {
+	// ID is the ID of the rule, such as INVALID_SERVICE_CONFIG, the same as in the lint configuration file.
+	ID string
+	// Purpose describes the rule.
+	Purpose string
+	// Default is true for rules that buf checks unless buf.yaml selects rules with use.
+	Default bool
+	// Breaking is true for the rule that buf breaking checks against the files of a baseline, and false for the rules
+	// that buf lint checks.
+	Breaking bool
+}
+
+// bufCheckOptInRules are the rules that buf only checks when buf.yaml selects them, since the plugin options that
+// enable them are off by default.
+var bufCheckOptInRules = map[rule]struct{}{
+	ruleMissingServiceConfig:       {},
+	ruleBreakingChange:             {},
+	ruleLossless:                   {},
+	ruleDuplicateConfig:            {},
+	ruleRequireTimeout:             {},
+	ruleRequireDefaultMethodConfig: {},
+	ruleNonIdempotentRetry:         {},
+	ruleWaitForReady:               {},
+}
+
+// bufCheckLintRules are the lint rules, which the buf check plugin enables all of, since buf reports the problems of
+// the rules selected in buf.yaml.
+var bufCheckLintRules = []rule{
+	ruleRequireTimeout,
+	ruleRequireDefaultMethodConfig,
+	ruleTimeoutRange,
+	ruleGatewayTimeout,
+	ruleStreamingRetry,
+	ruleNonIdempotentRetry,
+	ruleHedgingSideEffects,
+	ruleRetryUnsafeStatusCode,
+	ruleWaitForReady,
+}
+
+// BufCheckRules returns the rules of the buf check plugin.
+func BufCheckRules() []BufCheckRule {
+	result := make([]BufCheckRule, 0, len(rules))
+	for _, r := range rules {
+		_, optIn := bufCheckOptInRules[r.rule]
+		result = append(result, BufCheckRule{
+			ID:       string(r.rule),
+			Purpose:  r.description,
+			Default:  !optIn,
+			Breaking: r.rule == ruleBreakingChange,
+		})
+	}
+	return result
+}
+
+// BufCheckOptions configures the buf check plugin, with the options of the plugin in buf.yaml.
+type BufCheckOptions struct {
+	// Path is the input path of service config JSON files, like the path plugin option.
+	Path string
+	// Conflict is the conflict policy, like the conflict plugin option, or empty for prefer_json.
+	Conflict string
+	// MaxConfigBytes is the maximum size of a compacted service config, like the max_config_bytes plugin option, or
+	// 0 for no limit.
+	MaxConfigBytes int
+	// TargetGRPCGoVersion is the oldest grpc-go release to validate against, like the target_grpc_go_version plugin
+	// option, or empty.
+	TargetGRPCGoVersion string
+	// LintMinTimeout is the shortest allowed method timeout, like the lint_min_timeout plugin option, or 0.
+	LintMinTimeout time.Duration
+	// LintMaxTimeout is the longest allowed method timeout, like the lint_max_timeout plugin option, or 0.
+	LintMaxTimeout time.Duration
+	// LintGatewayTimeout is the timeout of the HTTP gateway, like the lint_gateway_timeout plugin option, or 0.
+	LintGatewayTimeout time.Duration
+	// AgainstPath is the input path of the service config JSON files of the baseline that buf breaking checks against,
+	// such as a checkout of the main branch, since images do not contain service config JSON files.
+	AgainstPath string
+	// BreakingTimeoutRatio is the smallest allowed ratio between a new and a previous timeout, like the
+	// breaking_timeout_ratio plugin option, or 0 to allow any timeout.
+	BreakingTimeoutRatio float64
+}
+
+// BufCheckFile is a file of a buf check request.
+type BufCheckFile struct {
+	// Descriptor is the file descriptor.
+	Descriptor *descriptorpb.FileDescriptorProto
+	// Import is true for files that are only imported by the files to check, which are not checked themselves.
+	Import bool
+}
+
+// BufCheckAnnotation is a problem found by the buf check plugin.
+type BufCheckAnnotation struct {
+	// RuleID is the ID of the rule that found the problem.
+	RuleID string
+	// Message describes the problem, prefixed with its location in the service config file when known.
+	Message string
+	// Descriptor is the proto descriptor the problem applies to, such as the service whose service config has the
+	// problem, or nil when unknown. Buf ignores problems by the file of the descriptor.
+	Descriptor protoreflect.Descriptor
+	// AgainstDescriptor is the proto descriptor in the baseline that a breaking change applies to, or nil when unknown.
+	AgainstDescriptor protoreflect.Descriptor
+}
+
+// BufLint returns the problems found by every validation and lint rule in the service configs of the files that are
+// not imports. Buf filters them by the rules and ignores configured in buf.yaml.
+func BufLint(files []BufCheckFile, opts BufCheckOptions) ([]BufCheckAnnotation, error) {
+	p, err := newBufCheckPlugin(files, opts.Path, opts.Conflict)
+	if err != nil {
+		return nil, err
+	}
+	var targetGRPCGoVersion *grpcGoVersion
+	if opts.TargetGRPCGoVersion != "" {
+		version, err := parseGRPCGoVersion(opts.TargetGRPCGoVersion)
+		if err != nil {
+			return nil, err
+		}
+		targetGRPCGoVersion = &version
+	}
+	levels := make(map[rule]lintLevel, len(bufCheckLintRules))
+	for _, rule := range bufCheckLintRules {
+		levels[rule] = lintLevelWarn
+	}
+	diagnostics, err := p.validationDiagnostics(validateOptions{
+		required:            true,
+		requireLossless:     true,
+		maxConfigBytes:      opts.MaxConfigBytes,
+		targetGRPCGoVersion: targetGRPCGoVersion,
+		lint: lintOptions{
+			levels:         levels,
+			minTimeout:     opts.LintMinTimeout,
+			maxTimeout:     opts.LintMaxTimeout,
+			gatewayTimeout: opts.LintGatewayTimeout,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bufCheckAnnotations(diagnostics.list), nil
+}
+
+// BufBreaking returns the breaking changes from the service configs of the against files, with the service config
+// JSON files in the against path, to the service configs of the files that are not imports.
+func BufBreaking(files, againstFiles []BufCheckFile, opts BufCheckOptions) ([]BufCheckAnnotation, error) {
+	if opts.AgainstPath == "" {
+		return nil, fmt.Errorf("missing against_path option")
+	}
+	breaking := breakingOptions{baseline: opts.AgainstPath, timeoutRatio: opts.BreakingTimeoutRatio}
+	if err := breaking.validate(); err != nil {
+		return nil, err
+	}
+	p, err := newBufCheckPlugin(files, opts.Path, opts.Conflict)
+	if err != nil {
+		return nil, err
+	}
+	baseline, err := newBufCheckPlugin(againstFiles, opts.AgainstPath, opts.Conflict)
+	if err != nil {
+		return nil, fmt.Errorf("against: %w", err)
+	}
+	var diagnostics diagnostics
+	if err := p.checkBreakingChangesAgainst(&diagnostics, baseline, breaking); err != nil {
+		return nil, err
+	}
+	result := bufCheckAnnotations(diagnostics.list)
+	for i, annotation := range result {
+		if annotation.Descriptor == nil {
+			continue
+		}
+		if descriptor, err := baseline.registry().FindDescriptorByName(annotation.Descriptor.FullName()); err == nil {
+			result[i].AgainstDescriptor = descriptor
+		}
+	}
+	return result, nil
+}
+
+// newBufCheckPlugin returns a plugin for the services in the files of a buf check request.
+func newBufCheckPlugin(files []BufCheckFile, path, conflict string) (*plugin, error) {
+	if conflict == "" {
+		conflict = string(conflictPreferJSON)
+	}
+	sorted, err := sortBufCheckFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	descriptors := make([]*descriptorpb.FileDescriptorProto, 0, len(sorted))
+	imports := map[string]struct{}{}
+	for _, file := range sorted {
+		descriptors = append(descriptors, file.Descriptor)
+		if file.Import {
+			imports[file.Descriptor.GetName()] = struct{}{}
+		}
+	}
+	return newPluginFromFiles(descriptors, imports, pluginOptions{path: path, conflict: conflictPolicy(conflict)})
+}
+
+// sortBufCheckFiles returns the files in topological order, with every file after its dependencies, which buf check
+// requests do not guarantee.
+func sortBufCheckFiles(files []BufCheckFile) ([]BufCheckFile, error) {
+	byName := make(map[string]BufCheckFile, len(files))
+	for _, file := range files {
+		byName[file.Descriptor.GetName()] = file
+	}
+	result := make([]BufCheckFile, 0, len(files))
+	added := make(map[string]bool, len(files))
+	var add func(name string) error
+	add = func(name string) error {
+		if visited, ok := added[name]; ok {
+			if !visited {
+				return fmt.Errorf("import cycle at %s", name)
+			}
+			return nil
+		}
+		file, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("missing dependency %s", name)
+		}
+		added[name] = false
+		for _, dependency := range file.Descriptor.GetDependency() {
+			if err := add(dependency); err != nil {
+				return err
+			}
+		}
+		added[name] = true
+		result = append(result, file)
+		return nil
+	}
+	for _, file := range files {
+		if err := add(file.Descriptor.GetName()); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// bufCheckAnnotations returns the diagnostics as buf check annotations.
+func bufCheckAnnotations(diagnostics []diagnostic) []BufCheckAnnotation {
+	result := make([]BufCheckAnnotation, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		message := diagnostic.message
+		if diagnostic.descriptor == nil || diagnostic.location.file != diagnostic.descriptor.ParentFile().Path() {
+			message = diagnostic.location.String() + ": " + message
+		}
+		result = append(result, BufCheckAnnotation{
+			RuleID:     string(diagnostic.rule),
+			Message:    message,
+			Descriptor: diagnostic.descriptor,
+		})
+	}
+	return result
+}
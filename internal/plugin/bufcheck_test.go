@@ -0,0 +1,176 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// testBufCheckFiles returns the files of a buf check request for the freight service, in reverse topological order,
+// since buf check requests do not guarantee that files are in topological order.
+func testBufCheckFiles(t *testing.T) []BufCheckFile {
+	t.Helper()
+	request := testRequest(t, "", testFile(t, testFreightServiceFile))
+	result := make([]BufCheckFile, 0, len(request.GetProtoFile()))
+	for i := len(request.GetProtoFile()) - 1; i >= 0; i-- {
+		file := request.GetProtoFile()[i]
+		result = append(result, BufCheckFile{
+			Descriptor: file,
+			Import:     file.GetName() != request.GetFileToGenerate()[0],
+		})
+	}
+	return result
+}
+
+func TestBufLint(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		serviceConfig string
+		opts          BufCheckOptions
+		// annotations are the rules and descriptors of the annotations, in order.
+		annotations []string
+	}{
+		{
+			name:          "valid",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+		},
+		{
+			name:          "config size",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+			opts:          BufCheckOptions{MaxConfigBytes: 16},
+			annotations:   []string{"CONFIG_SIZE einride.example.freight.v1.FreightService"},
+		},
+		{
+			name:          "invalid service config",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "forever"}]}`,
+			annotations:   []string{"INVALID_SERVICE_CONFIG einride.example.freight.v1.FreightService"},
+		},
+		{
+			name:        "missing service config",
+			annotations: []string{"MISSING_SERVICE_CONFIG einride.example.freight.v1.FreightService"},
+		},
+		{
+			name: "lint rules",
+			serviceConfig: `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "1s"},
+    {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}], "timeout": "10s"}
+  ]
+}`,
+			opts:        BufCheckOptions{LintMaxTimeout: 5 * time.Second},
+			annotations: []string{"TIMEOUT_RANGE einride.example.freight.v1.FreightService"},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{}
+			if tt.serviceConfig != "" {
+				files[testFreightServiceConfigFile] = tt.serviceConfig
+			}
+			dir := writeTestFiles(t, files)
+			opts := tt.opts
+			opts.Path = dir
+			annotations, err := BufLint(testBufCheckFiles(t), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := make([]string, 0, len(annotations))
+			for _, annotation := range annotations {
+				actual = append(actual, annotation.RuleID+" "+string(annotation.Descriptor.FullName()))
+			}
+			if strings.Join(actual, "\n") != strings.Join(tt.annotations, "\n") {
+				t.Errorf("expected annotations %q, got %q:\n%v", tt.annotations, actual, annotations)
+			}
+		})
+	}
+}
+
+func TestBufBreaking(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		// serviceConfig and againstServiceConfig are the current and baseline service configs, or empty for none.
+		serviceConfig        string
+		againstServiceConfig string
+		// annotations are the rules, descriptors and against descriptors of the annotations, in order.
+		annotations []string
+		err         string
+	}{
+		{
+			name:                 "unchanged",
+			serviceConfig:        testRetryServiceConfig,
+			againstServiceConfig: testRetryServiceConfig,
+		},
+		{
+			name:          "new service config",
+			serviceConfig: testRetryServiceConfig,
+		},
+		{
+			name:                 "removed service config",
+			againstServiceConfig: testRetryServiceConfig,
+			annotations: []string{
+				"BREAKING_CHANGE einride.example.freight.v1.FreightService einride.example.freight.v1.FreightService",
+			},
+		},
+		{
+			name: "shrunk timeout",
+			serviceConfig: `{
+  "methodConfig": [{
+    "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+    "timeout": "1s"
+  }]
+}`,
+			againstServiceConfig: `{
+  "methodConfig": [{
+    "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+    "timeout": "10s"
+  }]
+}`,
+			annotations: []string{
+				"BREAKING_CHANGE einride.example.freight.v1.FreightService einride.example.freight.v1.FreightService",
+			},
+		},
+		{
+			name:                 "timeout shrunk within the ratio",
+			serviceConfig:        `{"methodConfig": [{"name": [{}], "timeout": "6s"}]}`,
+			againstServiceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+		},
+		{
+			name: "missing against path",
+			err:  "missing against_path option",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{}
+			if tt.serviceConfig != "" {
+				files[testFreightServiceConfigFile] = tt.serviceConfig
+			}
+			opts := BufCheckOptions{Path: writeTestFiles(t, files), BreakingTimeoutRatio: 0.5}
+			if tt.err == "" {
+				againstFiles := map[string]string{}
+				if tt.againstServiceConfig != "" {
+					againstFiles[testFreightServiceConfigFile] = tt.againstServiceConfig
+				}
+				opts.AgainstPath = writeTestFiles(t, againstFiles)
+			}
+			annotations, err := BufBreaking(testBufCheckFiles(t), testBufCheckFiles(t), opts)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := make([]string, 0, len(annotations))
+			for _, annotation := range annotations {
+				actual = append(actual, annotation.RuleID+" "+string(annotation.Descriptor.FullName())+" "+
+					string(annotation.AgainstDescriptor.FullName()))
+			}
+			if strings.Join(actual, "\n") != strings.Join(tt.annotations, "\n") {
+				t.Errorf("expected annotations %q, got %q:\n%v", tt.annotations, actual, annotations)
+			}
+		})
+	}
+}
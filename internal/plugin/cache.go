@@ -0,0 +1,250 @@
+package plugin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// generationCacheVersion is part of every generation cache key, and changes when the format of entries changes.
+const generationCacheVersion = "2"
+
+// generationCache is the entry of a plugin run in a generation cache directory, which stores the responses of runs by
+// a hash of their inputs, so that repeated runs with unchanged inputs replay the previous response and warnings instead
+// of resolving, validating and generating service configs again.
+type generationCache struct {
+	// dir is the generation cache directory.
+	dir string
+	// key is the hex-encoded SHA-256 hash of the inputs of the run.
+	key string
+}
+
+// cachedRun is a run stored in a generation cache.
+type cachedRun struct {
+	// Response is the marshaled response of the run.
+	Response []byte `json:"response"`
+	// Stderr is what the run wrote to stderr, such as warnings, which is replayed with the response.
+	Stderr []byte `json:"stderr,omitempty"`
+}
+
+// newGenerationCache returns the generation cache entry of a run with the inputs: the request, including the plugin
+// options, the plugin executable, the files in the service config directories of the files to generate, and the input
+// files of plugin options, such as a lint configuration file.
+func newGenerationCache(
+	dir string,
+	gen *protogen.Plugin,
+	serviceConfigPath string,
+	inputFiles ...string,
+) (*generationCache, error) {
+	h := sha256.New()
+	writeHashField(h, generationCacheVersion)
+	writeHashField(h, VersionInfo())
+	// Development builds share a version, so the content of the executable tells them apart.
+	if err := writeHashExecutable(h); err != nil {
+		return nil, err
+	}
+	request, err := proto.MarshalOptions{Deterministic: true}.Marshal(gen.Request)
+	if err != nil {
+		return nil, fmt.Errorf("generation cache: %w", err)
+	}
+	writeHashField(h, string(request))
+	dirs := map[string]struct{}{}
+	for _, file := range gen.Files {
+		if file.Generate && len(file.Services) > 0 {
+			// Proto file paths are slash-separated on every OS.
+			dirs[filepath.Join(serviceConfigPath, filepath.FromSlash(path.Dir(file.Desc.Path())))] = struct{}{}
+		}
+	}
+	sortedDirs := make([]string, 0, len(dirs))
+	for configDir := range dirs {
+		sortedDirs = append(sortedDirs, configDir)
+	}
+	sort.Strings(sortedDirs)
+	for _, configDir := range sortedDirs {
+		// Service config files, and their per-environment variants, are found by name in the directories, so files
+		// that are added or removed change the key as well as changed files.
+		entries, err := os.ReadDir(configDir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("generation cache: %w", err)
+		}
+		writeHashField(h, configDir)
+		for _, entry := range entries {
+			if entry.Type().IsRegular() {
+				if err := writeHashFile(h, filepath.Join(configDir, entry.Name())); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	inputFiles = append(inputFiles, filepath.Join(serviceConfigPath, lintConfigFileName))
+	for _, inputFile := range inputFiles {
+		if inputFile != "" {
+			if err := writeHashFile(h, inputFile); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &generationCache{dir: dir, key: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// run runs the plugin like protogen.Options.Run, where f writes to stderr through the writer it is passed. With a
+// generation cache entry, which cache returns for the request, the cached response and stderr of a previous run with
+// the same inputs are written instead of running f, and otherwise the response and stderr of f are stored in the cache
+// when it succeeds.
+func run(
+	opts protogen.Options,
+	cache func(gen *protogen.Plugin) (*generationCache, error),
+	f func(gen *protogen.Plugin, stderr io.Writer) error,
+) {
+	if err := runWithCache(opts, cache, f); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", filepath.Base(os.Args[0]), err)
+		os.Exit(1)
+	}
+}
+
+func runWithCache(
+	opts protogen.Options,
+	cache func(gen *protogen.Plugin) (*generationCache, error),
+	f func(gen *protogen.Plugin, stderr io.Writer) error,
+) error {
+	if len(os.Args) > 1 {
+		return fmt.Errorf("unknown argument %q (this program should be run by protoc, not directly)", os.Args[1])
+	}
+	in, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	req := &pluginpb.CodeGeneratorRequest{}
+	if err := proto.Unmarshal(in, req); err != nil {
+		return err
+	}
+	gen, err := opts.New(req)
+	if err != nil {
+		return err
+	}
+	entry, err := cache(gen)
+	if err != nil {
+		return err
+	}
+	if entry != nil {
+		if cached, ok := entry.load(); ok {
+			if _, err := os.Stderr.Write(cached.Stderr); err != nil {
+				return err
+			}
+			_, err := os.Stdout.Write(cached.Response)
+			return err
+		}
+	}
+	var stderr bytes.Buffer
+	if err := f(gen, io.MultiWriter(os.Stderr, &stderr)); err != nil {
+		// Errors of the plugin are reported in the response, like protogen.Options.Run does.
+		gen.Error(err)
+	}
+	resp := gen.Response()
+	out, err := proto.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	if entry != nil && resp.Error == nil {
+		if err := entry.store(cachedRun{Response: out, Stderr: stderr.Bytes()}); err != nil {
+			return err
+		}
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
+// writeHashField writes a length-prefixed field to a hash, so that the boundaries of fields are part of the hash.
+func writeHashField(h hash.Hash, field string) {
+	_, _ = fmt.Fprintf(h, "%d:%s", len(field), field)
+}
+
+// writeHashExecutable writes the content hash of the running executable to a hash.
+func writeHashExecutable(h hash.Hash) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("generation cache: %w", err)
+	}
+	f, err := os.Open(executable)
+	if err != nil {
+		return fmt.Errorf("generation cache: %w", err)
+	}
+	defer f.Close()
+	contentHash := sha256.New()
+	if _, err := io.Copy(contentHash, f); err != nil {
+		return fmt.Errorf("generation cache: %w", err)
+	}
+	writeHashField(h, hex.EncodeToString(contentHash.Sum(nil)))
+	return nil
+}
+
+// writeHashFile writes the name and content of a file to a hash, where a missing file has no content.
+func writeHashFile(h hash.Hash, filename string) error {
+	writeHashField(h, filename)
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		writeHashField(h, "")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("generation cache: %w", err)
+	}
+	contentHash := sha256.Sum256(data)
+	writeHashField(h, hex.EncodeToString(contentHash[:]))
+	return nil
+}
+
+// filename returns the name of the file of the entry.
+func (c *generationCache) filename() string {
+	return filepath.Join(c.dir, c.key+".json")
+}
+
+// load returns the cached run, or false when there is none.
+// Unreadable entries are treated as missing, since they are replaced by the next store.
+func (c *generationCache) load() (cachedRun, bool) {
+	var result cachedRun
+	data, err := os.ReadFile(c.filename())
+	if err != nil || json.Unmarshal(data, &result) != nil {
+		return cachedRun{}, false
+	}
+	return result, true
+}
+
+// store stores a run, replacing an existing entry atomically, so that concurrent runs never load partial entries.
+func (c *generationCache) store(run cachedRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("generation cache: %w", err)
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("generation cache: %w", err)
+	}
+	f, err := os.CreateTemp(c.dir, c.key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("generation cache: %w", err)
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.filename())
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("generation cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestNewGenerationCache(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		testFreightServiceConfigFile: testRetryServiceConfig,
+		"lint.yaml":                  "rules: []\n",
+	})
+	gen, err := protogen.Options{}.New(testRequest(t, "", testFile(t, testFreightServiceFile)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := func() string {
+		t.Helper()
+		entry, err := newGenerationCache(t.TempDir(), gen, dir, filepath.Join(dir, "lint.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return entry.key
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	previous := key()
+	if actual := key(); actual != previous {
+		t.Fatalf("expected the key %s of unchanged inputs, got %s", previous, actual)
+	}
+	for _, tt := range []struct {
+		name   string
+		change func()
+	}{
+		{name: "changed service config", change: func() { write(testFreightServiceConfigFile, "{}") }},
+		{
+			name:   "added service config variant",
+			change: func() { write("einride/example/freight/v1/freight_grpc_service_config.prod.json", "{}") },
+		},
+		{name: "changed input file", change: func() { write("lint.yaml", "rules: [timeout]\n") }},
+	} {
+		tt.change()
+		actual := key()
+		if actual == previous {
+			t.Errorf("%s: expected the key to change", tt.name)
+		}
+		previous = actual
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "other"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write("other/service_config.json", "{}")
+	if actual := key(); actual != previous {
+		t.Errorf("expected files outside of the service config directories to keep the key %s, got %s", previous, actual)
+	}
+}
+
+func TestGenerationCacheStoreLoad(t *testing.T) {
+	entry := &generationCache{dir: filepath.Join(t.TempDir(), "cache"), key: "key"}
+	if _, ok := entry.load(); ok {
+		t.Fatal("expected no cached run")
+	}
+	for _, run := range []cachedRun{
+		{Response: []byte("first"), Stderr: []byte("warning\n")},
+		{Response: []byte("second")},
+	} {
+		if err := entry.store(run); err != nil {
+			t.Fatal(err)
+		}
+		actual, ok := entry.load()
+		if !ok || !reflect.DeepEqual(actual, run) {
+			t.Errorf("expected the cached run %q, got %q (%t)", run, actual, ok)
+		}
+	}
+	files, err := os.ReadDir(entry.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name() != "key.json" {
+		t.Errorf("expected only the entry file key.json, got %v", files)
+	}
+}
+
+// runTestWithCache runs runWithCache with a request on stdin, and returns the response written to stdout and what was
+// written to stderr.
+func runTestWithCache(
+	t *testing.T,
+	request *pluginpb.CodeGeneratorRequest,
+	cache func(gen *protogen.Plugin) (*generationCache, error),
+	f func(gen *protogen.Plugin, stderr io.Writer) error,
+) (*pluginpb.CodeGeneratorResponse, string) {
+	t.Helper()
+	in, err := proto.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "stdin"), in, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stdin, err := os.Open(filepath.Join(dir, "stdin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+	stderr, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stderr.Close()
+	args, oldStdin, oldStdout, oldStderr := os.Args, os.Stdin, os.Stdout, os.Stderr
+	os.Args, os.Stdin, os.Stdout, os.Stderr = os.Args[:1], stdin, stdout, stderr
+	defer func() {
+		os.Args, os.Stdin, os.Stdout, os.Stderr = args, oldStdin, oldStdout, oldStderr
+	}()
+	if err := runWithCache(protogen.Options{}, cache, f); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := &pluginpb.CodeGeneratorResponse{}
+	if err := proto.Unmarshal(out, response); err != nil {
+		t.Fatal(err)
+	}
+	errOut, err := os.ReadFile(stderr.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response, string(errOut)
+}
+
+func TestRunWithCache(t *testing.T) {
+	request := testRequest(t, "", testFile(t, testFreightServiceFile))
+	entry := &generationCache{dir: t.TempDir(), key: "key"}
+	cache := func(*protogen.Plugin) (*generationCache, error) {
+		return entry, nil
+	}
+	var runs int
+	generate := func(gen *protogen.Plugin, stderr io.Writer) error {
+		runs++
+		gen.NewGeneratedFile("out.txt", "").P("run ", runs)
+		fmt.Fprintf(stderr, "warning of run %d\n", runs)
+		return nil
+	}
+	first, firstStderr := runTestWithCache(t, request, cache, generate)
+	second, secondStderr := runTestWithCache(t, request, cache, generate)
+	if runs != 1 {
+		t.Errorf("expected 1 run, got %d", runs)
+	}
+	if !proto.Equal(first, second) {
+		t.Errorf("expected the cached response %v, got %v", first, second)
+	}
+	if firstStderr != "warning of run 1\n" || secondStderr != firstStderr {
+		t.Errorf("expected cache hits to replay the warnings %q, got %q", firstStderr, secondStderr)
+	}
+	failing := &generationCache{dir: t.TempDir(), key: "key"}
+	response, _ := runTestWithCache(
+		t,
+		request,
+		func(*protogen.Plugin) (*generationCache, error) { return failing, nil },
+		func(*protogen.Plugin, io.Writer) error { return errors.New("boom") },
+	)
+	if response.GetError() != "boom" {
+		t.Errorf("expected the error boom in the response, got %q", response.GetError())
+	}
+	if _, ok := failing.load(); ok {
+		t.Error("expected failed runs not to be cached")
+	}
+	uncached, _ := runTestWithCache(
+		t,
+		request,
+		func(*protogen.Plugin) (*generationCache, error) { return nil, nil },
+		generate,
+	)
+	if runs != 2 || !strings.Contains(uncached.GetFile()[0].GetContent(), "run 2") {
+		t.Errorf("expected runs without a cache entry to run, got %d runs", runs)
+	}
+}
+
+func TestNewRunCache(t *testing.T) {
+	for _, tt := range []struct {
+		parameter string
+		cached    bool
+	}{
+		{parameter: "cache_dir=cache", cached: true},
+		{parameter: "cache_dir=cache,report=-", cached: true},
+		{parameter: "cache_dir=cache,report=report.json"},
+		{parameter: "cache_dir=cache,profile=cpu"},
+		{parameter: "cache_dir=cache,dry_run=true"},
+		{parameter: ""},
+	} {
+		opts, cache, _ := newRun()
+		gen, err := opts.New(testRequest(t, tt.parameter, testFile(t, testFreightServiceFile)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry, err := cache(gen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cached := entry != nil; cached != tt.cached {
+			t.Errorf("%q: expected cached %t, got %t", tt.parameter, tt.cached, cached)
+		}
+	}
+}
@@ -0,0 +1,742 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/yamljson"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// ValidateCommand runs the validate command of the standalone CLI, which validates service config JSON and YAML files
+// against the services in a descriptor set, independent of protoc.
+func ValidateCommand(args []string) error {
+	flags := newCommandFlagSet("validate")
+	var (
+		descriptorSet   = flags.String("descriptor_set", "", "path of a FileDescriptorSet or Buf image (required)")
+		strict          = flags.Bool("strict", false, "treat validation warnings as errors")
+		targetGRPCGo    = flags.String("target_grpc_go_version", "", "oldest grpc-go release to validate against")
+		requireLossless = flags.Bool("require_lossless", false, "require service configs to survive a proto round trip")
+		maxConfigBytes  = flags.Int("max_config_bytes", 0, "maximum size of a compacted service config, or 0")
+		reportFile      = flags.String("report", "", "output path of a machine-readable validation report")
+		reportFmt       = flags.String(
+			"report_format",
+			string(reportFormatJSON),
+			"validation report format (json or sarif)",
+		)
+		errorFmt = flags.String(
+			"error_format",
+			string(errorFormatText),
+			"format of diagnostics written to stderr (text, github or json)",
+		)
+		watch         = flags.Bool("watch", false, "re-validate whenever the descriptor set or a service config changes")
+		watchInterval = flags.Duration("watch_interval", 500*time.Millisecond, "how often to check files for changes")
+		jobs          = flags.Int("jobs", 0, "maximum number of service configs validated concurrently (0 for GOMAXPROCS)")
+		failFast      = flags.Bool("fail_fast", false, "stop validation at the first service config that fails validation")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: grpc-service-config validate -descriptor_set=<file> [options] <file>...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *descriptorSet == "" {
+		flags.Usage()
+		return fmt.Errorf("validate: missing descriptor_set")
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("validate: no service config files")
+	}
+	var targetGRPCGoVersion *grpcGoVersion
+	if *targetGRPCGo != "" {
+		version, err := parseGRPCGoVersion(*targetGRPCGo)
+		if err != nil {
+			return err
+		}
+		targetGRPCGoVersion = &version
+	}
+	opts := validateOptions{
+		strict:              *strict,
+		reportFile:          *reportFile,
+		reportFormat:        reportFormat(*reportFmt),
+		errorFormat:         errorFormat(*errorFmt),
+		requireLossless:     *requireLossless,
+		maxConfigBytes:      *maxConfigBytes,
+		targetGRPCGoVersion: targetGRPCGoVersion,
+		jobs:                *jobs,
+		failFast:            *failFast,
+	}
+	var fromStdin bool
+	for _, filename := range flags.Args() {
+		fromStdin = fromStdin || filename == stdinFilename
+	}
+	if fromStdin && (*watch || *descriptorSet == stdinFilename || flags.NArg() > 1) {
+		return fmt.Errorf("validate: a service config from stdin must be the only file, and can not be watched")
+	}
+	if *watch {
+		return watchValidation(os.Stdout, *descriptorSet, flags.Args(), opts, *watchInterval)
+	}
+	files, err := loadDescriptorSet(*descriptorSet)
+	if err != nil {
+		return err
+	}
+	p := &plugin{files: files}
+	if err := p.validateFiles(flags.Args(), opts); err != nil {
+		return err
+	}
+	if fromStdin {
+		// Write the validated service config in canonical form, for use in pipelines.
+		data, err := readServiceConfigFile(stdinFilename)
+		if err != nil {
+			return err
+		}
+		formatted, err := formatServiceConfig(data)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(formatted)
+		return err
+	}
+	return nil
+}
+
+// FormatCommand runs the fmt command of the standalone CLI, which rewrites service config JSON files in canonical
+// form.
+func FormatCommand(args []string) error {
+	flags := newCommandFlagSet("fmt")
+	check := flags.Bool("check", false, "list files not in canonical form and fail instead of rewriting them")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: grpc-service-config fmt [-check] <file>... (- for stdin to stdout)")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("fmt: no service config files")
+	}
+	var unformatted []string
+	for _, filename := range flags.Args() {
+		if yamljson.IsYAMLFile(filename) {
+			return fmt.Errorf("fmt %s: only JSON files can be formatted", filename)
+		}
+		var original []byte
+		var err error
+		if filename == stdinFilename {
+			original, err = readStdin()
+		} else {
+			original, err = os.ReadFile(filename)
+		}
+		if err != nil {
+			return fmt.Errorf("fmt: %w", err)
+		}
+		data, err := readServiceConfigFile(filename)
+		if err != nil {
+			return fmt.Errorf("fmt: %w", err)
+		}
+		formatted, err := formatServiceConfig(data)
+		if err != nil {
+			return fmt.Errorf("fmt %s: %s", filename, describeJSONError(data, err))
+		}
+		if filename == stdinFilename && !*check {
+			// A service config from stdin is written to stdout, for use in pipelines.
+			if _, err := os.Stdout.Write(formatted); err != nil {
+				return fmt.Errorf("fmt: %w", err)
+			}
+			continue
+		}
+		if bytes.Equal(original, formatted) {
+			continue
+		}
+		if filename == stdinFilename {
+			unformatted = append(unformatted, stdinSource)
+			continue
+		}
+		if *check {
+			unformatted = append(unformatted, filename)
+			continue
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			return fmt.Errorf("fmt: %w", err)
+		}
+		if err := os.WriteFile(filename, formatted, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("fmt: %w", err)
+		}
+	}
+	if len(unformatted) > 0 {
+		return fmt.Errorf(
+			"fmt: %d file(s) not in canonical form (run grpc-service-config fmt to fix):\n\t%s",
+			len(unformatted),
+			strings.Join(unformatted, "\n\t"),
+		)
+	}
+	return nil
+}
+
+// ConvertCommand runs the convert command of the standalone CLI, which converts a service config JSON or YAML file to
+// an equivalent default_service_config file option, and a proto file with the option back to JSON.
+func ConvertCommand(args []string) error {
+	flags := newCommandFlagSet("convert")
+	output := flags.String("o", "", "output file (default stdout)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: grpc-service-config convert [-o <file>] <file>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("convert: expected exactly one file")
+	}
+	filename := flags.Arg(0)
+	var result []byte
+	if isProtoFile(filename) {
+		data, err := readServiceConfigFile(filename)
+		if err != nil {
+			return fmt.Errorf("convert: %w", err)
+		}
+		result, err = serviceConfigAnnotationToJSON(data, strings.EqualFold(filepath.Ext(filename), ".proto"))
+		if err != nil {
+			return fmt.Errorf("convert %s: %w", filename, err)
+		}
+	} else {
+		serviceConfig, err := readServiceConfigSource(filename)
+		if err != nil {
+			return fmt.Errorf("convert: %w", err)
+		}
+		annotation, err := serviceConfigJSONToAnnotation([]byte(serviceConfig.json))
+		if err != nil {
+			return fmt.Errorf("convert %s: %w", filename, err)
+		}
+		result = []byte(annotation)
+	}
+	if *output == "" {
+		_, err := os.Stdout.Write(result)
+		return err
+	}
+	return os.WriteFile(*output, result, 0o600)
+}
+
+// ExtractCommand runs the extract command of the standalone CLI, which writes the service config JSON embedded in a
+// generated Go file back to a file.
+func ExtractCommand(args []string) error {
+	flags := newCommandFlagSet("extract")
+	output := flags.String("o", "", "output file (default stdout)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: grpc-service-config extract [-o <file>] <generated Go file>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("extract: expected exactly one file")
+	}
+	filename := flags.Arg(0)
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("extract: %w", err)
+	}
+	var serviceConfig string
+	var found bool
+	// Files generated from JSON files declare ServiceConfig, and files generated from annotations declare
+	// DefaultServiceConfig.
+	for _, constant := range []string{"ServiceConfig", "DefaultServiceConfig"} {
+		serviceConfig, found, err = stringConstant(filename, data, constant)
+		if err != nil {
+			return fmt.Errorf("extract: %w", err)
+		}
+		if found {
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("extract: %s: no ServiceConfig or DefaultServiceConfig constant found", filename)
+	}
+	if !strings.HasSuffix(serviceConfig, "\n") {
+		serviceConfig += "\n"
+	}
+	if *output == "" {
+		_, err := io.WriteString(os.Stdout, serviceConfig)
+		return err
+	}
+	return os.WriteFile(*output, []byte(serviceConfig), 0o600)
+}
+
+// DiffCommand runs the diff command of the standalone CLI, which reports the semantic changes between two service
+// config files.
+func DiffCommand(args []string) error {
+	flags := newCommandFlagSet("diff")
+	exitCode := flags.Bool("exit_code", false, "fail when the service configs differ")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: grpc-service-config diff [-exit_code] <old file> <new file>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return fmt.Errorf("diff: expected exactly two files")
+	}
+	oldServiceConfig, err := readServiceConfigSource(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+	newServiceConfig, err := readServiceConfigSource(flags.Arg(1))
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+	changes, err := diffServiceConfigs([]byte(oldServiceConfig.json), []byte(newServiceConfig.json))
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	if *exitCode && len(changes) > 0 {
+		return fmt.Errorf("diff: %d change(s) found", len(changes))
+	}
+	return nil
+}
+
+// MergeCommand runs the merge command of the standalone CLI, which merges service config files with the same rules
+// as the merge conflict policy, where later files take precedence, and writes the result in canonical form.
+func MergeCommand(args []string) error {
+	flags := newCommandFlagSet("merge")
+	output := flags.String("o", "", "output file (default stdout)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: grpc-service-config merge [-o <file>] <file> <file>...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return fmt.Errorf("merge: expected at least two files")
+	}
+	var merged string
+	for i, filename := range flags.Args() {
+		var serviceConfig string
+		if isProtoFile(filename) {
+			data, err := readServiceConfigFile(filename)
+			if err != nil {
+				return fmt.Errorf("merge: %w", err)
+			}
+			serviceConfigJSON, err := serviceConfigAnnotationToJSON(
+				data,
+				strings.EqualFold(filepath.Ext(filename), ".proto"),
+			)
+			if err != nil {
+				return fmt.Errorf("merge %s: %w", filename, err)
+			}
+			serviceConfig = string(serviceConfigJSON)
+		} else {
+			source, err := readServiceConfigSource(filename)
+			if err != nil {
+				return fmt.Errorf("merge: %w", err)
+			}
+			if err := json.Unmarshal([]byte(source.json), &serviceConfigJSON{}); err != nil {
+				return fmt.Errorf(
+					"merge: invalid service config file %s: %s",
+					jsonErrorLocation(filename, []byte(source.json), err),
+					describeJSONError([]byte(source.json), err),
+				)
+			}
+			serviceConfig = source.json
+		}
+		if i == 0 {
+			merged = serviceConfig
+			continue
+		}
+		var err error
+		if merged, err = configjson.Merge(merged, serviceConfig); err != nil {
+			return fmt.Errorf("merge %s: %w", filename, err)
+		}
+	}
+	normalized, _, err := normalizeNumericStrings(merged)
+	if err != nil {
+		return fmt.Errorf("merge: %w", err)
+	}
+	result, err := formatServiceConfig([]byte(normalized))
+	if err != nil {
+		return fmt.Errorf("merge: %w", err)
+	}
+	if *output == "" {
+		_, err := os.Stdout.Write(result)
+		return err
+	}
+	return os.WriteFile(*output, result, 0o600)
+}
+
+// InitCommand runs the init command of the standalone CLI, which scaffolds a starter service config JSON file for
+// the services in a package.
+func InitCommand(args []string) error {
+	flags := newCommandFlagSet("init")
+	var (
+		descriptorSet = flags.String("descriptor_set", "", "path of a FileDescriptorSet or Buf image (required)")
+		packageName   = flags.String("package", "", "proto package to scaffold a service config for (required)")
+		path          = flags.String("path", ".", "input path of service config JSON files, like the path plugin option")
+		timeout       = flags.Duration("timeout", 10*time.Second, "timeout of scaffolded method configs")
+		output        = flags.String("o", "", "output file (default <path>/<package dir>/<package>_grpc_service_config.json)")
+		force         = flags.Bool("force", false, "overwrite an existing service config file")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: grpc-service-config init -descriptor_set=<file> -package=<package> [options]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *descriptorSet == "" || *packageName == "" {
+		flags.Usage()
+		return fmt.Errorf("init: missing descriptor_set or package")
+	}
+	files, err := loadDescriptorSet(*descriptorSet)
+	if err != nil {
+		return err
+	}
+	data, err := scaffoldServiceConfig(files, protoreflect.FullName(*packageName), *timeout)
+	if err != nil {
+		return fmt.Errorf("init: %w", err)
+	}
+	filename := *output
+	if filename == "" {
+		p := &plugin{pluginOptions: pluginOptions{path: *path}}
+		files.RangeFilesByPackage(protoreflect.FullName(*packageName), func(file protoreflect.FileDescriptor) bool {
+			if file.Services().Len() == 0 {
+				return true
+			}
+			filename = p.resolveServiceConfigJSONFile(file.Services().Get(0))
+			return false
+		})
+	}
+	if _, err := os.Stat(filename); err == nil && !*force {
+		return fmt.Errorf("init: %s already exists (use -force to overwrite it)", filename)
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return fmt.Errorf("init: %w", err)
+	}
+	if err := os.WriteFile(filename, data, 0o600); err != nil {
+		return fmt.Errorf("init: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "wrote %s\n", filename)
+	fmt.Fprintln(os.Stderr, "review the timeouts, and only keep retry policies for methods that are safe to retry")
+	return nil
+}
+
+// MigrateCommand runs the migrate command of the standalone CLI, which converts retry and timeout settings from
+// other formats to a service config JSON file.
+func MigrateCommand(args []string) error {
+	if len(args) == 0 || args[0] != "gapic" {
+		fmt.Fprintln(os.Stderr, "usage: grpc-service-config migrate gapic [-o <file>] <gapic yaml file>")
+		return fmt.Errorf("migrate: expected a source format (gapic)")
+	}
+	flags := newCommandFlagSet("migrate gapic")
+	output := flags.String("o", "", "output file (default stdout)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: grpc-service-config migrate gapic [-o <file>] <gapic yaml file>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("migrate gapic: expected exactly one file")
+	}
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("migrate gapic: %w", err)
+	}
+	result, err := gapicToServiceConfig(data)
+	if err != nil {
+		return fmt.Errorf("migrate gapic %s: %w", flags.Arg(0), err)
+	}
+	if *output == "" {
+		_, err := os.Stdout.Write(result)
+		return err
+	}
+	return os.WriteFile(*output, result, 0o600)
+}
+
+// ResolveCommand runs the resolve command of the standalone CLI, which prints where the service config of every
+// service in a Buf image is resolved from, and the service config JSON the way it is embedded in generated code.
+func ResolveCommand(args []string) error {
+	flags := newCommandFlagSet("resolve")
+	var (
+		image    = flags.String("image", "-", "path of a Buf image or FileDescriptorSet, or - for stdin")
+		path     = flags.String("path", ".", "input path of service config JSON files, like the path plugin option")
+		conflict = flags.String("conflict", string(conflictPreferJSON), "conflict policy, like the plugin option")
+		fix      = flags.Bool("fix", false, "rewrite deprecated service config fields, like the plugin option")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: buf build -o - | grpc-service-config resolve [options]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	p, err := newPluginFromImage(*image, pluginOptions{path: *path, conflict: conflictPolicy(*conflict), fix: *fix})
+	if err != nil {
+		return err
+	}
+	return p.writeResolution(os.Stdout)
+}
+
+// ExplainCommand runs the explain command of the standalone CLI, which prints a table per service in a Buf image with
+// the effective timeout, retry schedule, hedging behavior, and message size limits of every method.
+func ExplainCommand(args []string) error {
+	flags := newCommandFlagSet("explain")
+	var (
+		image    = flags.String("image", "-", "path of a Buf image or FileDescriptorSet, or - for stdin")
+		path     = flags.String("path", ".", "input path of service config JSON files, like the path plugin option")
+		conflict = flags.String("conflict", string(conflictPreferJSON), "conflict policy, like the plugin option")
+		fix      = flags.Bool("fix", false, "rewrite deprecated service config fields, like the plugin option")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: buf build -o - | grpc-service-config explain [options] [service]...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	p, err := newPluginFromImage(*image, pluginOptions{path: *path, conflict: conflictPolicy(*conflict), fix: *fix})
+	if err != nil {
+		return err
+	}
+	return p.writeExplanation(os.Stdout, flags.Args())
+}
+
+// DashboardCommand runs the dashboard command of the standalone CLI, which renders an HTML dashboard of the service
+// config coverage of the services in a Buf image, for platform reviews: which services have a service config, the
+// distribution of method timeouts, the use of retries and hedging, and the findings of validation and lint rules.
+func DashboardCommand(args []string) error {
+	flags := newCommandFlagSet("dashboard")
+	var (
+		image          = flags.String("image", "-", "path of a Buf image or FileDescriptorSet, or - for stdin")
+		path           = flags.String("path", ".", "input path of service config JSON files, like the path plugin option")
+		conflict       = flags.String("conflict", string(conflictPreferJSON), "conflict policy, like the plugin option")
+		fix            = flags.Bool("fix", false, "rewrite deprecated service config fields, like the plugin option")
+		lintConfigFile = flags.String("lint_config", "", "path of a lint configuration file")
+		out            = flags.String("o", "-", "output path of the HTML dashboard, or - for stdout")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: buf build -o - | grpc-service-config dashboard [options]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	p, err := newPluginFromImage(*image, pluginOptions{path: *path, conflict: conflictPolicy(*conflict), fix: *fix})
+	if err != nil {
+		return err
+	}
+	lintConfig, err := loadLintConfig(*lintConfigFile, *path)
+	if err != nil {
+		return err
+	}
+	diagnostics, err := p.validationDiagnostics(validateOptions{
+		lint: lintOptions{
+			levels: map[rule]lintLevel{
+				ruleStreamingRetry:        lintLevelWarn,
+				ruleHedgingSideEffects:    lintLevelWarn,
+				ruleRetryUnsafeStatusCode: lintLevelWarn,
+			},
+			config: lintConfig,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	dashboard, err := p.newDashboard(diagnostics.effective(false))
+	if err != nil {
+		return err
+	}
+	return writeDashboard(*out, dashboard)
+}
+
+// DoctorCommand runs the doctor command of the standalone CLI, which checks a repository for service config problems
+// and prints a prioritized fix list.
+func DoctorCommand(args []string) error {
+	flags := newCommandFlagSet("doctor")
+	var (
+		image = flags.String("image", "-", "path of a Buf image or FileDescriptorSet, or - for stdin")
+		path  = flags.String("path", ".", "input path of service config JSON files, like the path plugin option")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: buf build -o - | grpc-service-config doctor [options]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	p, err := newPluginFromImage(*image, pluginOptions{path: *path, conflict: conflictPreferJSON})
+	if err != nil {
+		return err
+	}
+	findings, err := p.doctor()
+	if err != nil {
+		return err
+	}
+	if err := writeDoctorFindings(os.Stdout, findings); err != nil {
+		return err
+	}
+	if len(findings) > 0 {
+		return fmt.Errorf("doctor: %d problem(s) found", len(findings))
+	}
+	return nil
+}
+
+// capturedFlagSets collects the flag sets of commands when not nil, to describe the flags of commands.
+var capturedFlagSets *[]*flag.FlagSet
+
+// newCommandFlagSet returns a new flag set for a command of the standalone CLI.
+func newCommandFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	if capturedFlagSets != nil {
+		flags.SetOutput(io.Discard)
+		*capturedFlagSets = append(*capturedFlagSets, flags)
+	}
+	return flags
+}
+
+// CommandFlags returns the flags of a command of the standalone CLI, in lexicographical order, for generating shell
+// completions and documentation. The command is run with args followed by -h, so that it returns before doing anything.
+func CommandFlags(run func(args []string) error, args ...string) []*flag.Flag {
+	var flagSets []*flag.FlagSet
+	capturedFlagSets = &flagSets
+	defer func() {
+		capturedFlagSets = nil
+	}()
+	_ = run(append(args, "-h"))
+	var result []*flag.Flag
+	for _, flags := range flagSets {
+		flags.VisitAll(func(f *flag.Flag) {
+			result = append(result, f)
+		})
+	}
+	return result
+}
+
+// loadDescriptorSet loads a FileDescriptorSet, or a Buf image, in the binary or JSON format.
+// Buf images are supersets of FileDescriptorSets, and their additional fields are discarded.
+func loadDescriptorSet(filename string) (*protoregistry.Files, error) {
+	descriptorSet, _, err := readDescriptorSet(filename)
+	if err != nil {
+		return nil, err
+	}
+	files, err := protodesc.NewFiles(descriptorSet)
+	if err != nil {
+		return nil, fmt.Errorf("load descriptor set %s: %w", filename, err)
+	}
+	return files, nil
+}
+
+// validateFiles validates service config files, independent of the services they apply to, and reports every
+// problem found.
+func (p *plugin) validateFiles(filenames []string, opts validateOptions) error {
+	if err := opts.reportFormat.validate(); err != nil {
+		return err
+	}
+	if err := opts.errorFormat.validate(); err != nil {
+		return err
+	}
+	diagnostics, err := p.fileDiagnostics(filenames, opts)
+	if err != nil {
+		return err
+	}
+	return finishValidation(diagnostics, opts)
+}
+
+// fileDiagnostics returns every problem found in service config files, independent of the services they apply to.
+func (p *plugin) fileDiagnostics(filenames []string, opts validateOptions) (*diagnostics, error) {
+	var diagnostics diagnostics
+	serviceConfigs := make([]resolvedServiceConfig, 0, len(filenames))
+	for _, filename := range filenames {
+		serviceConfig, err := readServiceConfigSource(filename)
+		if err != nil {
+			diagnostics.errorf(ruleInvalidJSON, location{file: filename}, "invalid service config: %v", err)
+			continue
+		}
+		serviceConfigs = append(serviceConfigs, serviceConfig)
+	}
+	if err := p.checkServiceConfigs(&diagnostics, serviceConfigs, opts); err != nil {
+		return nil, err
+	}
+	return &diagnostics, nil
+}
+
+// checkServiceConfigs validates the content of service configs, independent of the services they apply to.
+func (p *plugin) checkServiceConfigs(
+	diagnostics *diagnostics,
+	serviceConfigs []resolvedServiceConfig,
+	opts validateOptions,
+) error {
+	session, err := startValidationSession()
+	if err != nil {
+		return err
+	}
+	defer session.close()
+	tasks := make([]*validationTask, 0, len(serviceConfigs))
+	for _, serviceConfig := range serviceConfigs {
+		serviceConfig := serviceConfig
+		task := &validationTask{}
+		tasks = append(tasks, task)
+		var serviceConfigContent serviceConfigJSON
+		if err := json.Unmarshal([]byte(serviceConfig.json), &serviceConfigContent); err != nil {
+			task.diagnostics.errorf(
+				ruleInvalidJSON,
+				jsonErrorLocation(serviceConfig.source, []byte(serviceConfig.json), err),
+				"invalid service config: %s",
+				describeJSONError([]byte(serviceConfig.json), err),
+			)
+			continue
+		}
+		if !serviceConfig.annotation && !serviceConfig.merged && !yamljson.IsYAMLFile(serviceConfig.source) {
+			// Positions are only used to improve diagnostics, so failing to parse them is not an error.
+			serviceConfig.positions, _ = parseJSONPositions(serviceConfig.source, []byte(serviceConfig.json))
+		}
+		task.run = func() error {
+			return p.validateServiceConfig(&task.diagnostics, session, opts, serviceConfig, serviceConfigContent)
+		}
+	}
+	_, err = p.runValidationTasks(diagnostics, tasks, opts)
+	return err
+}
+
+// readServiceConfigSource reads a service config JSON or YAML file.
+// YAML files are converted to JSON.
+func readServiceConfigSource(filename string) (resolvedServiceConfig, error) {
+	data, err := readServiceConfigFile(filename)
+	if err != nil {
+		return resolvedServiceConfig{}, err
+	}
+	if yamljson.IsYAMLFile(filename) {
+		data, err = yamljson.ToJSON(data)
+		if err != nil {
+			return resolvedServiceConfig{}, fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+	if filename == stdinFilename {
+		return resolvedServiceConfig{source: stdinSource, json: string(data)}, nil
+	}
+	return resolvedServiceConfig{source: filename, json: string(data)}, nil
+}
@@ -0,0 +1,578 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// runTestCommand runs a command of the standalone CLI with the arguments, and returns what it wrote to stdout.
+// Diagnostics written to stderr are discarded.
+func runTestCommand(t *testing.T, command func(args []string) error, args ...string) (string, error) {
+	t.Helper()
+	stdout, stderr := os.Stdout, os.Stderr
+	output, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout, os.Stderr = output, devNull
+	defer func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		_ = output.Close()
+		_ = devNull.Close()
+	}()
+	commandErr := command(args)
+	data, err := os.ReadFile(output.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data), commandErr
+}
+
+func TestCommands(t *testing.T) {
+	descriptorSet, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: testRequest(t, "", testFile(t, testFreightServiceFile)).GetProtoFile(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const serviceConfig = `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`
+	for _, tt := range []struct {
+		name    string
+		command func(args []string) error
+		// files are written to a temporary directory, in addition to the descriptor set in image.binpb.
+		files map[string]string
+		// args are the arguments of the command, where $dir is replaced with the temporary directory.
+		args []string
+		// contains are strings expected in the output of the command.
+		contains []string
+		err      string
+	}{
+		{
+			name:    "validate",
+			command: ValidateCommand,
+			files:   map[string]string{"service_config.json": serviceConfig},
+			args:    []string{"-descriptor_set=$dir/image.binpb", "$dir/service_config.json"},
+		},
+		{
+			name:    "validate YAML",
+			command: ValidateCommand,
+			files: map[string]string{
+				"service_config.yaml": "methodConfig:\n  - name: [{}]\n    timeout: 10s\n",
+			},
+			args: []string{"-descriptor_set=$dir/image.binpb", "$dir/service_config.yaml"},
+		},
+		{
+			name:    "validate invalid",
+			command: ValidateCommand,
+			files: map[string]string{
+				"service_config.json": `{"methodConfig": [{"name": [{}], "timeout": "forever"}]}`,
+			},
+			args: []string{"-descriptor_set=$dir/image.binpb", "$dir/service_config.json"},
+			err:  "validate: 1 problem(s) found",
+		},
+		{
+			name:    "validate invalid YAML",
+			command: ValidateCommand,
+			files:   map[string]string{"service_config.yaml": "methodConfig: [\n"},
+			args:    []string{"-descriptor_set=$dir/image.binpb", "$dir/service_config.yaml"},
+			err:     "validate: 1 problem(s) found",
+		},
+		{
+			name:    "validate size",
+			command: ValidateCommand,
+			files:   map[string]string{"service_config.json": serviceConfig},
+			args:    []string{"-descriptor_set=$dir/image.binpb", "-max_config_bytes=32", "$dir/service_config.json"},
+			err:     "validate: 1 problem(s) found",
+		},
+		{
+			name:    "validate without descriptor set",
+			command: ValidateCommand,
+			files:   map[string]string{"service_config.json": serviceConfig},
+			args:    []string{"$dir/service_config.json"},
+			err:     "validate: missing descriptor_set",
+		},
+		{
+			name:    "validate without files",
+			command: ValidateCommand,
+			args:    []string{"-descriptor_set=$dir/image.binpb"},
+			err:     "validate: no service config files",
+		},
+		{
+			name:    "validate invalid descriptor set",
+			command: ValidateCommand,
+			files:   map[string]string{"image.json": "{"},
+			args:    []string{"-descriptor_set=$dir/image.json", "$dir/service_config.json"},
+			err:     "load descriptor set",
+		},
+		{
+			name:    "fmt check",
+			command: FormatCommand,
+			files: map[string]string{
+				"service_config.json": `{"methodConfig":[{"name":[{}],"timeout":"10s"}]}`,
+			},
+			args: []string{"-check", "$dir/service_config.json"},
+			err:  "fmt: 1 file(s) not in canonical form",
+		},
+		{
+			name:    "fmt check canonical",
+			command: FormatCommand,
+			files: map[string]string{
+				"service_config.json": "{\n  \"methodConfig\": []\n}\n",
+			},
+			args: []string{"-check", "$dir/service_config.json"},
+		},
+		{
+			name:    "fmt YAML",
+			command: FormatCommand,
+			files:   map[string]string{"service_config.yaml": "methodConfig: []\n"},
+			args:    []string{"$dir/service_config.yaml"},
+			err:     "only JSON files can be formatted",
+		},
+		{
+			name:     "convert",
+			command:  ConvertCommand,
+			files:    map[string]string{"service_config.json": serviceConfig},
+			args:     []string{"$dir/service_config.json"},
+			contains: []string{"option (einride.serviceconfig.v1.default_service_config) = {\n"},
+		},
+		{
+			name:    "convert without file",
+			command: ConvertCommand,
+			err:     "convert: expected exactly one file",
+		},
+		{
+			name:    "diff",
+			command: DiffCommand,
+			files: map[string]string{
+				"old.json": serviceConfig,
+				"new.json": `{"methodConfig": [{"name": [{}], "timeout": "1s"}]}`,
+			},
+			args:     []string{"-exit_code", "$dir/old.json", "$dir/new.json"},
+			contains: []string{"default method config: timeout 10s → 1s\n"},
+			err:      "diff: 1 change(s) found",
+		},
+		{
+			name:    "diff without changes",
+			command: DiffCommand,
+			files: map[string]string{
+				"old.json": serviceConfig,
+				"new.json": serviceConfig,
+			},
+			args: []string{"-exit_code", "$dir/old.json", "$dir/new.json"},
+		},
+		{
+			name:    "init",
+			command: InitCommand,
+			args: []string{
+				"-descriptor_set=$dir/image.binpb",
+				"-package=einride.example.freight.v1",
+				"-o=$dir/service_config.json",
+			},
+		},
+		{
+			name:    "init existing file",
+			command: InitCommand,
+			files:   map[string]string{"service_config.json": serviceConfig},
+			args: []string{
+				"-descriptor_set=$dir/image.binpb",
+				"-package=einride.example.freight.v1",
+				"-o=$dir/service_config.json",
+			},
+			err: "service_config.json already exists (use -force to overwrite it)",
+		},
+		{
+			name:    "init without package",
+			command: InitCommand,
+			args:    []string{"-descriptor_set=$dir/image.binpb"},
+			err:     "init: missing descriptor_set or package",
+		},
+		{
+			name:    "migrate gapic",
+			command: MigrateCommand,
+			files: map[string]string{
+				"gapic.yaml": "interfaces: [{name: a.B, methods: [{name: C, timeout_millis: 1000}]}]\n",
+			},
+			args:     []string{"gapic", "$dir/gapic.yaml"},
+			contains: []string{`"timeout": "1s"`},
+		},
+		{
+			name:    "migrate without source format",
+			command: MigrateCommand,
+			err:     "migrate: expected a source format (gapic)",
+		},
+		{
+			name:     "resolve",
+			command:  ResolveCommand,
+			files:    map[string]string{testFreightServiceConfigFile: serviceConfig},
+			args:     []string{"-image=$dir/image.binpb", "-path=$dir"},
+			contains: []string{"einride.example.freight.v1.FreightService: ", "freight_grpc_service_config.json (JSON file)"},
+		},
+		{
+			name:     "resolve without service config",
+			command:  ResolveCommand,
+			args:     []string{"-image=$dir/image.binpb", "-path=$dir"},
+			contains: []string{"einride.example.freight.v1.FreightService: no service config"},
+		},
+		{
+			name:    "resolve missing image",
+			command: ResolveCommand,
+			args:    []string{"-image=$dir/missing.binpb"},
+			err:     "load descriptor set",
+		},
+		{
+			name:     "explain",
+			command:  ExplainCommand,
+			files:    map[string]string{testFreightServiceConfigFile: testRetryServiceConfig},
+			args:     []string{"-image=$dir/image.binpb", "-path=$dir", "einride.example.freight.v1.FreightService"},
+			contains: []string{"GetShipper", "3 attempts on UNAVAILABLE", "WatchShippers (streaming)"},
+		},
+		{
+			name:    "explain unknown service",
+			command: ExplainCommand,
+			args:    []string{"-image=$dir/image.binpb", "-path=$dir", "einride.example.freight.v1.Unknown"},
+			err:     "service einride.example.freight.v1.Unknown not found",
+		},
+		{
+			name:    "doctor",
+			command: DoctorCommand,
+			files:   map[string]string{testFreightServiceConfigFile: serviceConfig},
+			args:    []string{"-image=$dir/image.binpb", "-path=$dir"},
+		},
+		{
+			name:     "doctor missing service config",
+			command:  DoctorCommand,
+			args:     []string{"-image=$dir/image.binpb", "-path=$dir"},
+			contains: []string{"1. missing: ", "freight_grpc_service_config.json"},
+			err:      "doctor: 1 problem(s) found",
+		},
+		{
+			name:    "merge with one file",
+			command: MergeCommand,
+			files:   map[string]string{"service_config.json": serviceConfig},
+			args:    []string{"$dir/service_config.json"},
+			err:     "merge: expected at least two files",
+		},
+		{
+			name:    "merge invalid file",
+			command: MergeCommand,
+			files: map[string]string{
+				"service_config.json": serviceConfig,
+				"invalid.json":        `{"methodConfig": [}`,
+			},
+			args: []string{"$dir/service_config.json", "$dir/invalid.json"},
+			err:  "merge: invalid service config file",
+		},
+		{
+			name:    "extract",
+			command: ExtractCommand,
+			files: map[string]string{
+				"freight_grpc_service_config.json.go": "package freightv1\n\nconst ServiceConfig = `" + serviceConfig + "`\n",
+			},
+			args:     []string{"$dir/freight_grpc_service_config.json.go"},
+			contains: []string{serviceConfig + "\n"},
+		},
+		{
+			name:    "extract annotation",
+			command: ExtractCommand,
+			files: map[string]string{
+				"freight_grpc_service_config.pb.go": "package freightv1\n\nconst DefaultServiceConfig = `" +
+					serviceConfig + "`\n",
+			},
+			args:     []string{"$dir/freight_grpc_service_config.pb.go"},
+			contains: []string{serviceConfig + "\n"},
+		},
+		{
+			name:    "extract deduplicated",
+			command: ExtractCommand,
+			files: map[string]string{
+				"shipper_grpc_service_config.json.go": "package shipperv1\n\nimport v1 \"example.com/freight/v1\"\n\n" +
+					"const ServiceConfig = v1.ServiceConfig\n",
+			},
+			args: []string{"$dir/shipper_grpc_service_config.json.go"},
+			err:  "constant ServiceConfig refers to a constant in package v1, which is deduplicated",
+		},
+		{
+			name:    "extract without constant",
+			command: ExtractCommand,
+			files:   map[string]string{"freight.go": "package freightv1\n"},
+			args:    []string{"$dir/freight.go"},
+			err:     "no ServiceConfig or DefaultServiceConfig constant found",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTestFiles(t, tt.files)
+			if err := os.WriteFile(filepath.Join(dir, "image.binpb"), descriptorSet, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			args := make([]string, 0, len(tt.args))
+			for _, arg := range tt.args {
+				args = append(args, filepath.FromSlash(strings.ReplaceAll(arg, "$dir", filepath.ToSlash(dir))))
+			}
+			output, err := runTestCommand(t, tt.command, args...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(output, s) {
+					t.Errorf("expected output to contain %q, got:\n%s", s, output)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatCommand(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"service_config.json": `{"methodConfig":[{"name":[{}],"timeout":"10.0s"}]}`,
+	})
+	filename := filepath.Join(dir, "service_config.json")
+	if _, err := runTestCommand(t, FormatCommand, filename); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected = `{
+  "methodConfig": [
+    {
+      "name": [
+        {}
+      ],
+      "timeout": "10s"
+    }
+  ]
+}
+`
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+	}
+}
+
+// setTestStdin replaces stdin with the content, for the duration of the test.
+func setTestStdin(t *testing.T, content string) {
+	t.Helper()
+	dir := writeTestFiles(t, map[string]string{"stdin": content})
+	file, err := os.Open(filepath.Join(dir, "stdin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := os.Stdin
+	os.Stdin = file
+	stdin.once, stdin.data, stdin.err = sync.Once{}, nil, nil
+	t.Cleanup(func() {
+		os.Stdin = original
+		stdin.once, stdin.data, stdin.err = sync.Once{}, nil, nil
+		_ = file.Close()
+	})
+}
+
+func TestStdinCommands(t *testing.T) {
+	descriptorSet, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: testRequest(t, "", testFile(t, testFreightServiceFile)).GetProtoFile(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := writeTestFiles(t, map[string]string{"image.binpb": string(descriptorSet)})
+	const expected = `{
+  "methodConfig": [
+    {
+      "name": [
+        {}
+      ],
+      "timeout": "10s"
+    }
+  ]
+}
+`
+	for _, tt := range []struct {
+		name     string
+		command  func(args []string) error
+		args     []string
+		stdin    string
+		expected string
+		err      string
+	}{
+		{
+			name:     "validate",
+			command:  ValidateCommand,
+			args:     []string{"-descriptor_set=" + filepath.Join(dir, "image.binpb"), "-"},
+			stdin:    `{"methodConfig":[{"name":[{}],"timeout":"10.0s"}]}`,
+			expected: expected,
+		},
+		{
+			name:    "validate invalid",
+			command: ValidateCommand,
+			args:    []string{"-descriptor_set=" + filepath.Join(dir, "image.binpb"), "-"},
+			stdin:   `{"methodConfig":[{"name":[{}],"timeout":"forever"}]}`,
+			err:     "validate: 1 problem(s) found",
+		},
+		{
+			name:    "validate stdin with other files",
+			command: ValidateCommand,
+			args:    []string{"-descriptor_set=" + filepath.Join(dir, "image.binpb"), "-", "a.json"},
+			err:     "a service config from stdin must be the only file",
+		},
+		{
+			name:     "fmt",
+			command:  FormatCommand,
+			args:     []string{"-"},
+			stdin:    `{"methodConfig":[{"name":[{}],"timeout":"10.0s"}]}`,
+			expected: expected,
+		},
+		{
+			name:    "fmt check",
+			command: FormatCommand,
+			args:    []string{"-check", "-"},
+			stdin:   `{"methodConfig":[{"name":[{}],"timeout":"10.0s"}]}`,
+			err:     "<stdin>",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			setTestStdin(t, tt.stdin)
+			output, err := runTestCommand(t, tt.command, tt.args...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != tt.expected {
+				t.Errorf("expected output:\n%s\ngot:\n%s", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestMergeCommand(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"base.json": `{
+  "methodConfig": [
+    {"name": [{}, {"service": "a.B", "method": "C"}], "timeout": "10.0s"}
+  ],
+  "retryThrottling": {"maxTokens": 10, "tokenRatio": 0.1}
+}`,
+		"override.yaml": "methodConfig:\n" +
+			"  - name: [{service: a.B, method: C}]\n" +
+			"    timeout: 1s\n" +
+			"    retryPolicy: {maxAttempts: 3}\n" +
+			"retryThrottling: {maxTokens: 20, tokenRatio: 0.5}\n",
+	})
+	output := filepath.Join(dir, "merged.json")
+	if _, err := runTestCommand(
+		t,
+		MergeCommand,
+		"-o="+output,
+		filepath.Join(dir, "base.json"),
+		filepath.Join(dir, "override.yaml"),
+	); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected = `{
+  "methodConfig": [
+    {
+      "name": [
+        {}
+      ],
+      "timeout": "10s"
+    },
+    {
+      "name": [
+        {
+          "method": "C",
+          "service": "a.B"
+        }
+      ],
+      "retryPolicy": {
+        "maxAttempts": 3
+      },
+      "timeout": "1s"
+    }
+  ],
+  "retryThrottling": {
+    "maxTokens": 20,
+    "tokenRatio": 0.5
+  }
+}
+`
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+	}
+}
+
+func TestInitCommand(t *testing.T) {
+	dir := t.TempDir()
+	descriptorSet, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: testRequest(t, "", testFile(t, testFreightServiceFile)).GetProtoFile(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "image.binpb"), descriptorSet, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runTestCommand(
+		t,
+		InitCommand,
+		"-descriptor_set="+filepath.Join(dir, "image.binpb"),
+		"-package=einride.example.freight.v1",
+		"-path="+dir,
+	); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, testFreightServiceConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"service": "einride.example.freight.v1.FreightService"`) {
+		t.Errorf("expected a method config for the freight service, got:\n%s", data)
+	}
+}
+
+func TestCommandFlags(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		run      func(args []string) error
+		args     []string
+		expected []string
+	}{
+		{name: "fmt", run: FormatCommand, expected: []string{"check"}},
+		{name: "migrate gapic", run: MigrateCommand, args: []string{"gapic"}, expected: []string{"o"}},
+		{name: "diff", run: DiffCommand, expected: []string{"exit_code"}},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, f := range CommandFlags(tt.run, tt.args...) {
+				names = append(names, f.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected flags %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// grpcGoVersion is a grpc-go release version.
+type grpcGoVersion struct {
+	major, minor, patch int
+}
+
+// parseGRPCGoVersion parses a grpc-go release version, such as v1.48.0 or 1.48.
+func parseGRPCGoVersion(s string) (grpcGoVersion, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return grpcGoVersion{}, fmt.Errorf("invalid grpc-go version %q", s)
+	}
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return grpcGoVersion{}, fmt.Errorf("invalid grpc-go version %q", s)
+		}
+		numbers[i] = n
+	}
+	return grpcGoVersion{major: numbers[0], minor: numbers[1], patch: numbers[2]}, nil
+}
+
+// String implements fmt.Stringer.
+func (v grpcGoVersion) String() string {
+	return fmt.Sprintf("v%d.%d.%d", v.major, v.minor, v.patch)
+}
+
+// less returns true if v is an earlier release than other.
+func (v grpcGoVersion) less(other grpcGoVersion) bool {
+	if v.major != other.major {
+		return v.major < other.major
+	}
+	if v.minor != other.minor {
+		return v.minor < other.minor
+	}
+	return v.patch < other.patch
+}
+
+// grpcGoCapability is a service config feature and the first grpc-go release supporting it.
+type grpcGoCapability struct {
+	// feature describes the service config feature.
+	feature string
+	// since is the first grpc-go release supporting the feature by default, or nil if no release supports it.
+	since *grpcGoVersion
+}
+
+// grpcGoCapabilities is the built-in table of service config features with limited grpc-go support.
+// Features not in the table are assumed to be supported by all grpc-go releases supporting service configs.
+var grpcGoCapabilities = map[string]grpcGoCapability{
+	"retryPolicy":     {feature: "retryPolicy", since: &grpcGoVersion{major: 1, minor: 40}},
+	"retryThrottling": {feature: "retryThrottling", since: &grpcGoVersion{major: 1, minor: 40}},
+	"hedgingPolicy":   {feature: "hedgingPolicy"},
+	"healthCheckConfig": {
+		feature: "healthCheckConfig",
+		since:   &grpcGoVersion{major: 1, minor: 17},
+	},
+	"loadBalancingConfig": {
+		feature: "loadBalancingConfig",
+		since:   &grpcGoVersion{major: 1, minor: 20},
+	},
+	"loadBalancingConfig.ring_hash_experimental": {
+		feature: "the ring_hash_experimental load balancing policy",
+		since:   &grpcGoVersion{major: 1, minor: 41},
+	},
+	"loadBalancingConfig.outlier_detection_experimental": {
+		feature: "the outlier_detection_experimental load balancing policy",
+		since:   &grpcGoVersion{major: 1, minor: 50},
+	},
+	"loadBalancingConfig.weighted_target_experimental": {
+		feature: "the weighted_target_experimental load balancing policy",
+		since:   &grpcGoVersion{major: 1, minor: 31},
+	},
+	"loadBalancingConfig.xds_cluster_manager_experimental": {
+		feature: "the xds_cluster_manager_experimental load balancing policy",
+		since:   &grpcGoVersion{major: 1, minor: 34},
+	},
+	"loadBalancingConfig.weighted_round_robin": {
+		feature: "the weighted_round_robin load balancing policy",
+		since:   &grpcGoVersion{major: 1, minor: 56},
+	},
+	"loadBalancingConfig.pick_first.shuffleAddressList": {
+		feature: "shuffleAddressList in the pick_first load balancing policy",
+		since:   &grpcGoVersion{major: 1, minor: 56},
+	},
+	"loadBalancingConfig.least_request_experimental": {
+		feature: "the least_request_experimental load balancing policy",
+		since:   &grpcGoVersion{major: 1, minor: 58},
+	},
+}
+
+// grpcGoFeatureUse is a use of a service config feature with limited grpc-go support.
+type grpcGoFeatureUse struct {
+	// path is the JSON path of the feature.
+	path string
+	// capability is the grpc-go support of the feature.
+	capability grpcGoCapability
+}
+
+// grpcGoFeatureUses returns every use of a feature with limited grpc-go support in the service config.
+func (c serviceConfigJSON) grpcGoFeatureUses() []grpcGoFeatureUse {
+	var result []grpcGoFeatureUse
+	add := func(key string, path string) {
+		if capability, ok := grpcGoCapabilities[key]; ok {
+			result = append(result, grpcGoFeatureUse{path: path, capability: capability})
+		}
+	}
+	for i, methodConfig := range c.MethodConfigs {
+		if methodConfig.RetryPolicy != nil {
+			add("retryPolicy", fmt.Sprintf("methodConfig[%d].retryPolicy", i))
+		}
+		if methodConfig.HedgingPolicy != nil {
+			add("hedgingPolicy", fmt.Sprintf("methodConfig[%d].hedgingPolicy", i))
+		}
+	}
+	if c.RetryThrottling != nil {
+		add("retryThrottling", "retryThrottling")
+	}
+	if c.HealthCheckConfig != nil {
+		add("healthCheckConfig", "healthCheckConfig")
+	}
+	if c.LoadBalancingConfig != nil {
+		add("loadBalancingConfig", "loadBalancingConfig")
+	}
+	for i, policy := range c.LoadBalancingConfig {
+		for name, config := range policy {
+			path := fmt.Sprintf("loadBalancingConfig[%d].%s", i, name)
+			add("loadBalancingConfig."+name, path)
+			if name == "pick_first" {
+				var pickFirst pickFirstConfigJSON
+				if err := json.Unmarshal(config, &pickFirst); err == nil && pickFirst.ShuffleAddressList != nil {
+					add("loadBalancingConfig.pick_first.shuffleAddressList", path+".shuffleAddressList")
+				}
+			}
+		}
+	}
+	return result
+}
+
+// supportedBy returns true if the feature is supported by the grpc-go release.
+func (c grpcGoCapability) supportedBy(version grpcGoVersion) bool {
+	return c.since != nil && !version.less(*c.since)
+}
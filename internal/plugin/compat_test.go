@@ -0,0 +1,73 @@
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseGRPCGoVersion(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+		err      bool
+	}{
+		{input: "v1.48.0", expected: "v1.48.0"},
+		{input: "1.48", expected: "v1.48.0"},
+		{input: "v1.40.1", expected: "v1.40.1"},
+		{input: "v1", err: true},
+		{input: "v1.48.0.1", err: true},
+		{input: "v1.x.0", err: true},
+		{input: "v1.-1.0", err: true},
+	} {
+		version, err := parseGRPCGoVersion(tt.input)
+		if tt.err {
+			if err == nil {
+				t.Errorf("expected %q to be invalid, got %v", tt.input, version)
+			}
+			continue
+		}
+		if err != nil || version.String() != tt.expected {
+			t.Errorf("expected %q to be %s, got %v, %v", tt.input, tt.expected, version, err)
+		}
+	}
+}
+
+func TestGRPCGoFeatureUses(t *testing.T) {
+	var serviceConfig serviceConfigJSON
+	if err := json.Unmarshal([]byte(`{
+  "loadBalancingConfig": [
+    {"ring_hash_experimental": {}},
+    {"round_robin": {}},
+    {"pick_first": {"shuffleAddressList": true}}
+  ],
+  "methodConfig": [{"retryPolicy": {}}, {"hedgingPolicy": {}}],
+  "retryThrottling": {"maxTokens": 10, "tokenRatio": 0.1}
+}`), &serviceConfig); err != nil {
+		t.Fatal(err)
+	}
+	target := grpcGoVersion{major: 1, minor: 40}
+	var actual []string
+	for _, use := range serviceConfig.grpcGoFeatureUses() {
+		supported := "unsupported"
+		if use.capability.supportedBy(target) {
+			supported = "supported"
+		}
+		actual = append(actual, use.path+" "+supported)
+	}
+	expected := []string{
+		"methodConfig[0].retryPolicy supported",
+		"methodConfig[1].hedgingPolicy unsupported",
+		"retryThrottling supported",
+		"loadBalancingConfig supported",
+		"loadBalancingConfig[0].ring_hash_experimental unsupported",
+		"loadBalancingConfig[2].pick_first.shuffleAddressList unsupported",
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %q, got %q", expected, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], actual[i])
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// serviceConfigJSON is the subset of the service config JSON format inspected by validation.
+type serviceConfigJSON struct {
+	LoadBalancingPolicy *string                      `json:"loadBalancingPolicy"`
+	LoadBalancingConfig []map[string]json.RawMessage `json:"loadBalancingConfig"`
+	MethodConfigs       []methodConfigJSON           `json:"methodConfig"`
+	RetryThrottling     json.RawMessage              `json:"retryThrottling"`
+	HealthCheckConfig   json.RawMessage              `json:"healthCheckConfig"`
+}
+
+// methodConfigJSON is a method config in the service config JSON format.
+type methodConfigJSON struct {
+	Names                   []nameJSON         `json:"name"`
+	WaitForReady            *bool              `json:"waitForReady"`
+	Timeout                 *string            `json:"timeout"`
+	MaxRequestMessageBytes  json.RawMessage    `json:"maxRequestMessageBytes"`
+	MaxResponseMessageBytes json.RawMessage    `json:"maxResponseMessageBytes"`
+	RetryPolicy             *retryPolicyJSON   `json:"retryPolicy"`
+	HedgingPolicy           *hedgingPolicyJSON `json:"hedgingPolicy"`
+}
+
+// retryPolicyJSON is a retry policy in the service config JSON format.
+// Numeric fields are numbers, since the protobuf JSON format also accepts numeric strings, which are normalized.
+type retryPolicyJSON struct {
+	MaxAttempts          json.Number       `json:"maxAttempts"`
+	InitialBackoff       string            `json:"initialBackoff"`
+	MaxBackoff           string            `json:"maxBackoff"`
+	RetryableStatusCodes []json.RawMessage `json:"retryableStatusCodes"`
+}
+
+// hedgingPolicyJSON is a hedging policy in the service config JSON format.
+type hedgingPolicyJSON struct {
+	MaxAttempts         json.Number       `json:"maxAttempts"`
+	NonFatalStatusCodes []json.RawMessage `json:"nonFatalStatusCodes"`
+}
+
+// nameJSON is a name in the service config JSON format.
+type nameJSON = configjson.Name
+
+// duplicateName is a service and method pair matched by more than one name in a service config.
+type duplicateName struct {
+	// name is the gRPC path of the service and method pair.
+	name string
+	// firstPath is the JSON path of the first name matching the pair.
+	firstPath string
+	// path is the JSON path of the duplicate name.
+	path string
+}
+
+// duplicateNames returns every service and method pair matched by more than one name.
+// gRPC only applies one method config per method, so a duplicated name is almost certainly a mistake.
+func (c serviceConfigJSON) duplicateNames() []duplicateName {
+	var result []duplicateName
+	seen := map[string]string{}
+	for i, methodConfig := range c.MethodConfigs {
+		for j, name := range methodConfig.Names {
+			grpcPath := "/" + name.Service + "/" + name.Method
+			path := fmt.Sprintf("methodConfig[%d].name[%d]", i, j)
+			if firstPath, ok := seen[grpcPath]; ok {
+				result = append(result, duplicateName{name: grpcPath, firstPath: firstPath, path: path})
+				continue
+			}
+			seen[grpcPath] = path
+		}
+	}
+	return result
+}
+
+func (c serviceConfigJSON) hasService(service *protogen.Service) bool {
+	for _, methodConfig := range c.MethodConfigs {
+		for _, name := range methodConfig.Names {
+			if (name.Service == "" && name.Method == "") ||
+				(name.Service == string(service.Desc.FullName()) && name.Method == "") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// methodConfigFor returns the index of the method config that applies to the method, following the gRPC matching
+// rules: a name matching the method takes precedence over a name matching the service, which takes precedence over
+// the default name.
+func (c serviceConfigJSON) methodConfigFor(method protoreflect.MethodDescriptor) (int, bool) {
+	service := string(method.Parent().FullName())
+	methodName := string(method.Name())
+	serviceMatch, defaultMatch := -1, -1
+	for i, methodConfig := range c.MethodConfigs {
+		for _, name := range methodConfig.Names {
+			switch {
+			case name.Service == service && name.Method == methodName:
+				return i, true
+			case name.Service == service && name.Method == "" && serviceMatch == -1:
+				serviceMatch = i
+			case name.Service == "" && name.Method == "" && defaultMatch == -1:
+				defaultMatch = i
+			}
+		}
+	}
+	if serviceMatch != -1 {
+		return serviceMatch, true
+	}
+	if defaultMatch != -1 {
+		return defaultMatch, true
+	}
+	return 0, false
+}
+
+// match returns which kind of name of the method config matches the method: the method, the service, or the default
+// name, in order of precedence.
+func (c methodConfigJSON) match(method protoreflect.MethodDescriptor) string {
+	service := string(method.Parent().FullName())
+	result := ""
+	for _, name := range c.Names {
+		switch {
+		case name.Service == service && name.Method == string(method.Name()):
+			return "method name"
+		case name.Service == service && name.Method == "":
+			result = "service name"
+		case name.Service == "" && name.Method == "" && result == "":
+			result = "default name"
+		}
+	}
+	return result
+}
+
+// shadowedMethodConfigs returns the method configs that never apply to any method of the services, since every
+// method they match by a service name is matched by a more specific name. Method configs that only have duplicate
+// names or names of other services are not considered shadowed. Neither are method configs with a default name, which
+// also apply to methods and services added later, and which require_default_method_config requires.
+func (c serviceConfigJSON) shadowedMethodConfigs(services []*protogen.Service) []jsonFinding {
+	used := map[int]struct{}{}
+	inScope := map[string]struct{}{}
+	for _, service := range services {
+		inScope[string(service.Desc.FullName())] = struct{}{}
+		for _, method := range service.Methods {
+			if i, ok := c.methodConfigFor(method.Desc); ok {
+				used[i] = struct{}{}
+			}
+		}
+	}
+	duplicates := map[string]struct{}{}
+	for _, duplicate := range c.duplicateNames() {
+		duplicates[duplicate.path] = struct{}{}
+	}
+	var result []jsonFinding
+	for i, methodConfig := range c.MethodConfigs {
+		if _, ok := used[i]; ok {
+			continue
+		}
+		var general []string
+		var hasDefaultName bool
+		for j, name := range methodConfig.Names {
+			if _, ok := duplicates[fmt.Sprintf("methodConfig[%d].name[%d]", i, j)]; ok {
+				continue
+			}
+			switch {
+			case name.Service == "" && name.Method == "":
+				hasDefaultName = true
+			case name.Method == "":
+				if _, ok := inScope[name.Service]; ok {
+					general = append(general, "service "+name.Service)
+				}
+			}
+		}
+		if hasDefaultName || len(general) == 0 {
+			continue
+		}
+		result = append(result, jsonFinding{
+			path: fmt.Sprintf("methodConfig[%d]", i),
+			message: fmt.Sprintf(
+				"method config never applies, since every method matched by %s has a more specific name "+
+					"(gRPC applies the most specific name, regardless of the order of method configs)",
+				strings.Join(general, " and "),
+			),
+		})
+	}
+	return result
+}
+
+// hasDefaultMethodConfig returns true if the service config has a method config with an empty name.
+func (c serviceConfigJSON) hasDefaultMethodConfig() bool {
+	for _, methodConfig := range c.MethodConfigs {
+		if methodConfig.isDefault() {
+			return true
+		}
+	}
+	return false
+}
+
+// isNoOp returns true if the method config has no fields that change the behavior of calls.
+func (c methodConfigJSON) isNoOp() bool {
+	return c.WaitForReady == nil &&
+		c.Timeout == nil &&
+		len(c.MaxRequestMessageBytes) == 0 &&
+		len(c.MaxResponseMessageBytes) == 0 &&
+		c.RetryPolicy == nil &&
+		c.HedgingPolicy == nil
+}
+
+// hasRetries returns true if the method config has a retry or hedging policy.
+func (c methodConfigJSON) hasRetries() bool {
+	return c.RetryPolicy != nil || c.HedgingPolicy != nil
+}
+
+// isDefault returns true if the method config applies to all methods without a more specific method config.
+func (c methodConfigJSON) isDefault() bool {
+	for _, name := range c.Names {
+		if name.Service == "" && name.Method == "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package plugin
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// parseTestServiceConfig parses the content of a resolved service config.
+func parseTestServiceConfig(serviceConfig resolvedServiceConfig) (serviceConfigJSON, error) {
+	var result serviceConfigJSON
+	err := json.Unmarshal([]byte(serviceConfig.json), &result)
+	return result, err
+}
+
+func TestMethodConfigFor(t *testing.T) {
+	file, err := protodesc.NewFile(testFile(t, testFreightServiceFile), protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	methods := file.Services().ByName("FreightService").Methods()
+	for _, tt := range []struct {
+		name          string
+		serviceConfig string
+		method        protoreflect.Name
+		expected      int
+		found         bool
+	}{
+		{
+			name:          "default name",
+			serviceConfig: `{"methodConfig": [{"name": [{}]}]}`,
+			method:        "GetShipper",
+			expected:      0,
+			found:         true,
+		},
+		{
+			name: "service name takes precedence over default name",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{}]},
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}]}
+]}`,
+			method:   "GetShipper",
+			expected: 1,
+			found:    true,
+		},
+		{
+			name: "method name takes precedence over service name",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}]},
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}]}
+]}`,
+			method:   "GetShipper",
+			expected: 0,
+			found:    true,
+		},
+		{
+			name: "other method",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}]}
+]}`,
+			method: "UpdateShipper",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			serviceConfig, err := parseTestServiceConfig(resolvedServiceConfig{json: tt.serviceConfig})
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, found := serviceConfig.methodConfigFor(methods.ByName(tt.method))
+			if actual != tt.expected || found != tt.found {
+				t.Errorf("expected (%d, %v), got (%d, %v)", tt.expected, tt.found, actual, found)
+			}
+		})
+	}
+}
+
+func TestShadowedMethodConfigs(t *testing.T) {
+	p := newTestPlugin(t, pluginOptions{conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+	services := p.gen.FilesByPath["einride/example/freight/v1/freight_service.proto"].Services
+	const allMethods = `
+  {"name": [
+    {"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"},
+    {"service": "einride.example.freight.v1.FreightService", "method": "UpdateShipper"},
+    {"service": "einride.example.freight.v1.FreightService", "method": "CreateShipper"},
+    {"service": "einride.example.freight.v1.FreightService", "method": "ImportShippers"},
+    {"service": "einride.example.freight.v1.FreightService", "method": "ListShippers"},
+    {"service": "einride.example.freight.v1.FreightService", "method": "WatchShippers"}
+  ]}`
+	for _, tt := range []struct {
+		name          string
+		serviceConfig string
+		findings      []string
+	}{
+		{
+			name: "default and service names apply to other methods",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{}, {"service": "einride.example.freight.v1.ShipperService"}]},
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}]}
+]}`,
+		},
+		{
+			name: "default name is never shadowed",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{}]},
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}]}
+]}`,
+		},
+		{
+			name: "default and service names are never shadowed",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{}, {"service": "einride.example.freight.v1.FreightService"}]},` + allMethods + `
+]}`,
+		},
+		{
+			name: "shadowed service name",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}]},` + allMethods + `
+]}`,
+			findings: []string{
+				"methodConfig[0]: method config never applies, since every method matched by service" +
+					" einride.example.freight.v1.FreightService has a more specific name (gRPC applies the most" +
+					" specific name, regardless of the order of method configs)",
+			},
+		},
+		{
+			name: "names of other services",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.ShipperService"}]},` + allMethods + `
+]}`,
+		},
+		{
+			name: "duplicate names",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}]},
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}]}
+]}`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			serviceConfig, err := parseTestServiceConfig(resolvedServiceConfig{json: tt.serviceConfig})
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := jsonFindingStrings(serviceConfig.shadowedMethodConfigs(services))
+			if expected := strings.Join(tt.findings, "\n"); actual != expected {
+				t.Errorf("expected findings:\n%s\ngot:\n%s", expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package plugin
+
+import "fmt"
+
+// conflictPolicy decides which service config to resolve when a package has both a service config JSON file and a
+// default_service_config annotation.
+type conflictPolicy string
+
+const (
+	// conflictError fails when a package has both a service config JSON file and an annotation.
+	conflictError conflictPolicy = "error"
+	// conflictPreferJSON resolves the service config JSON file.
+	conflictPreferJSON conflictPolicy = "prefer_json"
+	// conflictPreferAnnotation resolves the default_service_config annotation.
+	conflictPreferAnnotation conflictPolicy = "prefer_annotation"
+	// conflictMerge resolves the annotation merged with the service config JSON file, where the JSON file takes
+	// precedence.
+	conflictMerge conflictPolicy = "merge"
+)
+
+// validate returns an error if the conflict policy is not supported.
+func (c conflictPolicy) validate() error {
+	switch c {
+	case conflictError, conflictPreferJSON, conflictPreferAnnotation, conflictMerge:
+		return nil
+	}
+	return fmt.Errorf(
+		"unsupported conflict policy %q (expected %q, %q, %q or %q)",
+		c,
+		conflictError,
+		conflictPreferJSON,
+		conflictPreferAnnotation,
+		conflictMerge,
+	)
+}
+
+// checkConflicts returns an error if any service to generate has conflicting service configs.
+func (p *plugin) checkConflicts() error {
+	for _, file := range p.gen.Files {
+		if !file.Generate {
+			continue
+		}
+		for _, service := range file.Services {
+			if _, _, err := p.resolveServiceConfig(service.Desc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
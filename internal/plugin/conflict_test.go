@@ -0,0 +1,118 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	serviceconfigv1 "go.buf.build/protocolbuffers/go/einride/grpc-service-config/einride/serviceconfig/v1"
+	"go.buf.build/protocolbuffers/go/grpc/grpc/grpc/service_config"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestConflictPolicyValidate(t *testing.T) {
+	for _, conflict := range []conflictPolicy{conflictError, conflictPreferJSON, conflictPreferAnnotation, conflictMerge} {
+		if err := conflict.validate(); err != nil {
+			t.Errorf("%s: unexpected error: %v", conflict, err)
+		}
+	}
+	const expected = `unsupported conflict policy "prefer"` +
+		` (expected "error", "prefer_json", "prefer_annotation" or "merge")`
+	if err := conflictPolicy("prefer").validate(); err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
+
+func TestResolveServiceConfigConflict(t *testing.T) {
+	const (
+		fromJSON       = `{"methodConfig": [{"name": [{"service": "einride.example.freight.v1.FreightService"}]}]}`
+		fromAnnotation = `{"loadBalancingPolicy": "ROUND_ROBIN"}`
+	)
+	for _, tt := range []struct {
+		conflict conflictPolicy
+		// expected is the resolved service config JSON, compacted.
+		expected string
+		// source is the source of the resolved service config.
+		source string
+		err    string
+	}{
+		{
+			conflict: conflictPreferJSON,
+			expected: fromJSON,
+			source:   testFreightServiceConfigFile,
+		},
+		{
+			conflict: conflictPreferAnnotation,
+			expected: fromAnnotation,
+			source:   "einride/example/freight/v1/freight_service.proto",
+		},
+		{
+			conflict: conflictMerge,
+			expected: `{"loadBalancingPolicy":"ROUND_ROBIN",` +
+				`"methodConfig":[{"name":[{"service":"einride.example.freight.v1.FreightService"}]}]}`,
+			source: testFreightServiceConfigFile,
+		},
+		{
+			conflict: conflictError,
+			err: testFreightServiceConfigFile + " and a default_service_config annotation in" +
+				" einride/example/freight/v1/freight_service.proto exist",
+		},
+	} {
+		tt := tt
+		t.Run(string(tt.conflict), func(t *testing.T) {
+			file := testFile(t, testFreightServiceFile)
+			var serviceConfig service_config.ServiceConfig
+			if err := protojson.Unmarshal([]byte(fromAnnotation), &serviceConfig); err != nil {
+				t.Fatal(err)
+			}
+			proto.SetExtension(file.GetOptions(), serviceconfigv1.E_DefaultServiceConfig, &serviceConfig)
+			dir := writeTestFiles(t, map[string]string{testFreightServiceConfigFile: fromJSON})
+			gen, err := protogen.Options{}.New(testRequest(t, "", file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := newPlugin(gen, pluginOptions{path: dir, conflict: tt.conflict})
+			if err != nil {
+				t.Fatal(err)
+			}
+			resolved, ok, err := p.resolveServiceConfig(gen.FilesByPath[file.GetName()].Services[0].Desc)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				if err := p.checkConflicts(); err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("expected conflicts error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Fatal("expected a service config")
+			}
+			if actual := compactTestJSON(t, resolved.json); actual != compactTestJSON(t, tt.expected) {
+				t.Errorf("expected service config %s, got %s", tt.expected, actual)
+			}
+			if !strings.HasSuffix(resolved.source, tt.source) {
+				t.Errorf("expected source %s, got %s", tt.source, resolved.source)
+			}
+			if err := p.checkConflicts(); err != nil {
+				t.Errorf("unexpected conflicts error: %v", err)
+			}
+		})
+	}
+}
+
+// compactTestJSON returns the JSON with insignificant whitespace removed.
+func compactTestJSON(t testing.TB, s string) string {
+	t.Helper()
+	var b bytes.Buffer
+	if err := json.Compact(&b, []byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
@@ -0,0 +1,149 @@
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"go.buf.build/protocolbuffers/go/grpc/grpc/grpc/service_config"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// defaultServiceConfigExtension is the full name of the default_service_config file option.
+const defaultServiceConfigExtension protoreflect.FullName = "einride.serviceconfig.v1.default_service_config"
+
+// isProtoFile returns true if the file has a proto or text format file extension.
+func isProtoFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".proto", ".textproto", ".txtpb", ".pbtxt":
+		return true
+	}
+	return false
+}
+
+// serviceConfigJSONToAnnotation converts service config JSON to an equivalent default_service_config file option.
+// Values that can not be represented in the annotation are rejected, instead of silently dropped.
+func serviceConfigJSONToAnnotation(data []byte) (string, error) {
+	lost, err := lostValues(data)
+	if err != nil {
+		return "", err
+	}
+	if len(lost) > 0 {
+		var b strings.Builder
+		_, _ = fmt.Fprintf(
+			&b,
+			"%d value(s) can not be represented in a %s annotation",
+			len(lost),
+			defaultServiceConfigExtension,
+		)
+		for _, value := range lost {
+			_, _ = fmt.Fprintf(&b, "\n\t%s: %s", value.path, value.message)
+		}
+		return "", errors.New(b.String())
+	}
+	var serviceConfig service_config.ServiceConfig
+	if err := protojson.Unmarshal(data, &serviceConfig); err != nil {
+		return "", err
+	}
+	text, err := prototext.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(&serviceConfig)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "option (%s) = {\n", defaultServiceConfigExtension)
+	for _, line := range strings.Split(strings.TrimSpace(string(text)), "\n") {
+		if line != "" {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("};\n")
+	return b.String(), nil
+}
+
+// serviceConfigAnnotationToJSON converts a default_service_config file option to service config JSON in canonical
+// form. The data is either a proto file with the option, or the text format of a service config.
+func serviceConfigAnnotationToJSON(data []byte, protoFile bool) ([]byte, error) {
+	text := string(data)
+	if protoFile && strings.Contains(text, string(defaultServiceConfigExtension)) {
+		block, err := defaultServiceConfigOptionValue(text)
+		if err != nil {
+			return nil, err
+		}
+		text = block
+	}
+	var serviceConfig service_config.ServiceConfig
+	if err := prototext.Unmarshal([]byte(text), &serviceConfig); err != nil {
+		return nil, err
+	}
+	serviceConfigJSON, err := protojson.Marshal(&serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+	return formatServiceConfig(serviceConfigJSON)
+}
+
+// defaultServiceConfigOptionValue returns the message literal of the default_service_config option in a proto file,
+// without the surrounding braces, and with comments removed since the text format does not support them.
+func defaultServiceConfigOptionValue(proto string) (string, error) {
+	start := strings.Index(proto, "("+string(defaultServiceConfigExtension)+")")
+	if start == -1 {
+		return "", fmt.Errorf("no %s option found", defaultServiceConfigExtension)
+	}
+	open := strings.Index(proto[start:], "{")
+	if open == -1 {
+		return "", fmt.Errorf("%s option is not a message literal", defaultServiceConfigExtension)
+	}
+	var result bytes.Buffer
+	depth := 0
+	for i := start + open; i < len(proto); i++ {
+		c := proto[i]
+		switch {
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(proto) && proto[end] != c {
+				if proto[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(proto) {
+				return "", fmt.Errorf("%s option has an unterminated string", defaultServiceConfigExtension)
+			}
+			result.WriteString(proto[i : end+1])
+			i = end
+			continue
+		case strings.HasPrefix(proto[i:], "//"):
+			end := strings.IndexByte(proto[i:], '\n')
+			if end == -1 {
+				end = len(proto) - i
+			}
+			i += end - 1
+			continue
+		case strings.HasPrefix(proto[i:], "/*"):
+			end := strings.Index(proto[i+2:], "*/")
+			if end == -1 {
+				return "", fmt.Errorf("%s option has an unterminated comment", defaultServiceConfigExtension)
+			}
+			i += end + 3
+			result.WriteByte(' ')
+			continue
+		case c == '{':
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return result.String(), nil
+			}
+		}
+		result.WriteByte(c)
+	}
+	return "", fmt.Errorf("%s option has an unterminated message literal", defaultServiceConfigExtension)
+}
@@ -0,0 +1,97 @@
+package plugin
+
+import "testing"
+
+func TestConvertServiceConfig(t *testing.T) {
+	const serviceConfig = `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}], "timeout": "1.5s"}
+]}`
+	annotation, err := serviceConfigJSONToAnnotation([]byte(serviceConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proto := "syntax = \"proto3\";\n\n" +
+		"// The service config of the package.\n" +
+		annotation +
+		"\npackage einride.example.freight.v1;\n"
+	actual, err := serviceConfigAnnotationToJSON([]byte(proto), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := formatServiceConfig([]byte(serviceConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != string(expected) {
+		t.Errorf("expected round trip through:\n%s\nto result in:\n%s\ngot:\n%s", annotation, expected, actual)
+	}
+	if _, err := serviceConfigJSONToAnnotation([]byte(`{"methodConfig": [}`)); err == nil {
+		t.Error("expected invalid JSON to fail")
+	}
+}
+
+func TestDefaultServiceConfigOptionValue(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		proto    string
+		expected string
+		err      string
+	}{
+		{
+			name: "message literal",
+			proto: `syntax = "proto3";
+option (einride.serviceconfig.v1.default_service_config) = {
+  method_config: { name: { service: "a.B" } timeout: { seconds: 1 } }
+};
+package a;`,
+			expected: `
+  method_config: { name: { service: "a.B" } timeout: { seconds: 1 } }
+`,
+		},
+		{
+			name: "comments and strings",
+			proto: `option (einride.serviceconfig.v1.default_service_config) = {
+  // The default method config.
+  method_config: { name: { service: "a.B" /* } */ method: "{}//\"" } }
+};`,
+			expected: "\n  \n  method_config: { name: { service: \"a.B\"   method: \"{}//\\\"\" } }\n",
+		},
+		{
+			name:  "no option",
+			proto: `syntax = "proto3";`,
+			err:   "no einride.serviceconfig.v1.default_service_config option found",
+		},
+		{
+			name:  "not a message literal",
+			proto: `option (einride.serviceconfig.v1.default_service_config) = 1;`,
+			err:   "einride.serviceconfig.v1.default_service_config option is not a message literal",
+		},
+		{
+			name:  "unterminated message literal",
+			proto: `option (einride.serviceconfig.v1.default_service_config) = { method_config: {}`,
+			err:   "einride.serviceconfig.v1.default_service_config option has an unterminated message literal",
+		},
+		{
+			name:  "unterminated string",
+			proto: `option (einride.serviceconfig.v1.default_service_config) = { method_config: { name: { service: "a.B`,
+			err:   "einride.serviceconfig.v1.default_service_config option has an unterminated string",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := defaultServiceConfigOptionValue(tt.proto)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// csharpServiceConfigJSON is the subset of the service config JSON format supported by Grpc.Net.Client.
+type csharpServiceConfigJSON struct {
+	LoadBalancingPolicy string                       `json:"loadBalancingPolicy"`
+	LoadBalancingConfig []map[string]json.RawMessage `json:"loadBalancingConfig"`
+	MethodConfigs       []csharpMethodConfigJSON     `json:"methodConfig"`
+	RetryThrottling     *struct {
+		MaxTokens  json.Number `json:"maxTokens"`
+		TokenRatio json.Number `json:"tokenRatio"`
+	} `json:"retryThrottling"`
+}
+
+// csharpMethodConfigJSON is a method config in the service config JSON format, with the fields of method configs,
+// supported or not by Grpc.Net.Client.
+type csharpMethodConfigJSON struct {
+	Names                   []nameJSON      `json:"name"`
+	WaitForReady            *bool           `json:"waitForReady"`
+	Timeout                 *string         `json:"timeout"`
+	MaxRequestMessageBytes  json.RawMessage `json:"maxRequestMessageBytes"`
+	MaxResponseMessageBytes json.RawMessage `json:"maxResponseMessageBytes"`
+	RetryPolicy             *struct {
+		MaxAttempts          json.Number       `json:"maxAttempts"`
+		InitialBackoff       string            `json:"initialBackoff"`
+		MaxBackoff           string            `json:"maxBackoff"`
+		BackoffMultiplier    json.Number       `json:"backoffMultiplier"`
+		RetryableStatusCodes []json.RawMessage `json:"retryableStatusCodes"`
+	} `json:"retryPolicy"`
+	HedgingPolicy *struct {
+		MaxAttempts         json.Number       `json:"maxAttempts"`
+		HedgingDelay        string            `json:"hedgingDelay"`
+		NonFatalStatusCodes []json.RawMessage `json:"nonFatalStatusCodes"`
+	} `json:"hedgingPolicy"`
+}
+
+// unsupportedFields returns the fields of the method config that Grpc.Net.Client does not support.
+func (c csharpMethodConfigJSON) unsupportedFields() []string {
+	var result []string
+	if c.Timeout != nil {
+		result = append(result, "timeout")
+	}
+	if c.WaitForReady != nil {
+		result = append(result, "waitForReady")
+	}
+	if len(c.MaxRequestMessageBytes) > 0 {
+		result = append(result, "maxRequestMessageBytes")
+	}
+	if len(c.MaxResponseMessageBytes) > 0 {
+		result = append(result, "maxResponseMessageBytes")
+	}
+	return result
+}
+
+// csharpPascalCase converts a proto name to PascalCase the way protoc does for C#, such as v1beta1 to V1Beta1.
+func csharpPascalCase(name string) string {
+	var b strings.Builder
+	capitalizeNext := true
+	for _, r := range name {
+		switch {
+		case r == '_':
+			capitalizeNext = true
+		case unicode.IsLetter(r):
+			if capitalizeNext {
+				r = unicode.ToUpper(r)
+			}
+			b.WriteRune(r)
+			capitalizeNext = false
+		default:
+			b.WriteRune(r)
+			capitalizeNext = true
+		}
+	}
+	return b.String()
+}
+
+// csharpNamespace returns the C# namespace of a proto file, which is the csharp_namespace option, or the proto
+// package in PascalCase.
+func csharpNamespace(file protoreflect.FileDescriptor) string {
+	if options, ok := file.Options().(*descriptorpb.FileOptions); ok && options.GetCsharpNamespace() != "" {
+		return options.GetCsharpNamespace()
+	}
+	parts := strings.Split(string(file.Package()), ".")
+	for i, part := range parts {
+		parts[i] = csharpPascalCase(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// csharpString returns a C# verbatim string literal.
+func csharpString(s string) string {
+	return `@"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// csharpTimeSpan returns a C# expression of a duration.
+func csharpTimeSpan(s string) (string, error) {
+	d, err := configjson.ParseDuration(s)
+	if err != nil {
+		return "", err
+	}
+	if d%time.Millisecond == 0 {
+		return fmt.Sprintf("TimeSpan.FromMilliseconds(%d)", d.Milliseconds()), nil
+	}
+	// A tick is 100 nanoseconds.
+	return fmt.Sprintf("TimeSpan.FromTicks(%d)", d/100), nil
+}
+
+// csharpStatusCodes returns the C# expressions of status codes, such as StatusCode.Unavailable.
+func csharpStatusCodes(values []json.RawMessage) ([]string, error) {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		code, err := statusCodeJSON{value: value}.parse()
+		if err != nil {
+			return nil, err
+		}
+		name := "OK"
+		if code != codes.OK {
+			name = csharpPascalCase(strings.ToLower(configjson.StatusCodeName(code)))
+		}
+		result = append(result, "StatusCode."+name)
+	}
+	return result, nil
+}
+
+// generateCSharpModule generates a C# static class with the service config of a package, and the Grpc.Net.Client
+// channel options that apply it to clients.
+func (p *plugin) generateCSharpModule(serviceConfig *packageServiceConfig) error {
+	formatted, err := formatServiceConfig([]byte(serviceConfig.json))
+	if err != nil {
+		return err
+	}
+	var config csharpServiceConfigJSON
+	if err := json.Unmarshal([]byte(serviceConfig.json), &config); err != nil {
+		return err
+	}
+	className := csharpPascalCase(string(serviceConfig.pkg.Parent().Name())) + "GrpcServiceConfig"
+	g := p.gen.NewGeneratedFile(path.Join(packagePath(serviceConfig.pkg), className+".cs"), "")
+	g.P("// <auto-generated>")
+	g.P("// Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.")
+	if version := Version(); version != develVersion {
+		g.P("// versions:")
+		g.P("// \tprotoc-gen-go-grpc-service-config ", version)
+	}
+	g.P("// Source: ", serviceConfig.source)
+	g.P("// </auto-generated>")
+	g.P()
+	g.P("using System;")
+	g.P("using Grpc.Core;")
+	g.P("using Grpc.Net.Client;")
+	g.P("using Grpc.Net.Client.Configuration;")
+	g.P()
+	g.P("namespace ", csharpNamespace(serviceConfig.services[0].ParentFile()))
+	g.P("{")
+	g.P("    /// <summary>The service config for all services in the ", serviceConfig.pkg, " package.</summary>")
+	g.P("    public static class ", className)
+	g.P("    {")
+	g.P("        /// <summary>The service config JSON for all services in the package.</summary>")
+	g.P("        public const string Json = ", csharpString(strings.TrimSuffix(string(formatted), "\n")), ";")
+	g.P()
+	g.P("        /// <summary>")
+	g.P("        /// Returns the service config for all services in the package. Grpc.Net.Client does not support")
+	g.P("        /// timeouts, waitForReady and message size limits, which are left out.")
+	g.P("        /// </summary>")
+	g.P("        public static ServiceConfig CreateServiceConfig()")
+	g.P("        {")
+	g.P("            var serviceConfig = new ServiceConfig();")
+	if err := writeCSharpServiceConfig(g, config); err != nil {
+		return err
+	}
+	g.P("            return serviceConfig;")
+	g.P("        }")
+	g.P()
+	g.P("        /// <summary>Returns channel options with the service config for all services in the package.</summary>")
+	g.P("        public static GrpcChannelOptions CreateChannelOptions()")
+	g.P("        {")
+	g.P("            return new GrpcChannelOptions { ServiceConfig = CreateServiceConfig() };")
+	g.P("        }")
+	g.P("    }")
+	g.P("}")
+	return nil
+}
+
+// writeCSharpServiceConfig writes the statements that populate the serviceConfig variable with the service config.
+func writeCSharpServiceConfig(g *protogen.GeneratedFile, config csharpServiceConfigJSON) error {
+	const indent = "            "
+	var loadBalancingPolicies []string
+	for _, lbConfig := range config.LoadBalancingConfig {
+		for name := range lbConfig {
+			loadBalancingPolicies = append(loadBalancingPolicies, name)
+		}
+	}
+	if len(loadBalancingPolicies) == 0 && config.LoadBalancingPolicy != "" {
+		loadBalancingPolicies = append(loadBalancingPolicies, strings.ToLower(config.LoadBalancingPolicy))
+	}
+	for _, name := range loadBalancingPolicies {
+		switch name {
+		case "round_robin":
+			g.P(indent, "serviceConfig.LoadBalancingConfigs.Add(new RoundRobinConfig());")
+		case "pick_first":
+			g.P(indent, "serviceConfig.LoadBalancingConfigs.Add(new PickFirstConfig());")
+		default:
+			g.P(indent, "// Not supported by Grpc.Net.Client: load balancing policy ", name, ".")
+		}
+	}
+	if config.RetryThrottling != nil {
+		g.P(indent, "serviceConfig.RetryThrottling = new RetryThrottlingPolicy")
+		g.P(indent, "{")
+		g.P(indent, "    MaxTokens = ", config.RetryThrottling.MaxTokens.String(), ",")
+		g.P(indent, "    TokenRatio = ", config.RetryThrottling.TokenRatio.String(), ",")
+		g.P(indent, "};")
+	}
+	for i, methodConfig := range config.MethodConfigs {
+		g.P(indent, "{")
+		g.P(indent, "    var methodConfig = new MethodConfig();")
+		for _, name := range methodConfig.Names {
+			switch {
+			case name.Service == "" && name.Method == "":
+				g.P(indent, "    methodConfig.Names.Add(MethodName.Default);")
+			case name.Method == "":
+				g.P(indent, "    methodConfig.Names.Add(new MethodName { Service = ", strconv.Quote(name.Service), " });")
+			default:
+				g.P(indent, "    methodConfig.Names.Add(new MethodName { Service = ", strconv.Quote(name.Service),
+					", Method = ", strconv.Quote(name.Method), " });")
+			}
+		}
+		if policy := methodConfig.RetryPolicy; policy != nil {
+			initialBackoff, err := csharpTimeSpan(policy.InitialBackoff)
+			if err != nil {
+				return fmt.Errorf("methodConfig[%d].retryPolicy.initialBackoff: %w", i, err)
+			}
+			maxBackoff, err := csharpTimeSpan(policy.MaxBackoff)
+			if err != nil {
+				return fmt.Errorf("methodConfig[%d].retryPolicy.maxBackoff: %w", i, err)
+			}
+			statusCodes, err := csharpStatusCodes(policy.RetryableStatusCodes)
+			if err != nil {
+				return fmt.Errorf("methodConfig[%d].retryPolicy.retryableStatusCodes: %w", i, err)
+			}
+			g.P(indent, "    methodConfig.RetryPolicy = new RetryPolicy")
+			g.P(indent, "    {")
+			g.P(indent, "        MaxAttempts = ", policy.MaxAttempts.String(), ",")
+			g.P(indent, "        InitialBackoff = ", initialBackoff, ",")
+			g.P(indent, "        MaxBackoff = ", maxBackoff, ",")
+			g.P(indent, "        BackoffMultiplier = ", policy.BackoffMultiplier.String(), ",")
+			g.P(indent, "    };")
+			for _, statusCode := range statusCodes {
+				g.P(indent, "    methodConfig.RetryPolicy.RetryableStatusCodes.Add(", statusCode, ");")
+			}
+		}
+		if policy := methodConfig.HedgingPolicy; policy != nil {
+			statusCodes, err := csharpStatusCodes(policy.NonFatalStatusCodes)
+			if err != nil {
+				return fmt.Errorf("methodConfig[%d].hedgingPolicy.nonFatalStatusCodes: %w", i, err)
+			}
+			g.P(indent, "    methodConfig.HedgingPolicy = new HedgingPolicy")
+			g.P(indent, "    {")
+			g.P(indent, "        MaxAttempts = ", policy.MaxAttempts.String(), ",")
+			if policy.HedgingDelay != "" {
+				hedgingDelay, err := csharpTimeSpan(policy.HedgingDelay)
+				if err != nil {
+					return fmt.Errorf("methodConfig[%d].hedgingPolicy.hedgingDelay: %w", i, err)
+				}
+				g.P(indent, "        HedgingDelay = ", hedgingDelay, ",")
+			}
+			g.P(indent, "    };")
+			for _, statusCode := range statusCodes {
+				g.P(indent, "    methodConfig.HedgingPolicy.NonFatalStatusCodes.Add(", statusCode, ");")
+			}
+		}
+		if unsupported := methodConfig.unsupportedFields(); len(unsupported) > 0 {
+			g.P(indent, "    // Not supported by Grpc.Net.Client: ", strings.Join(unsupported, ", "), ".")
+		}
+		g.P(indent, "    serviceConfig.MethodConfigs.Add(methodConfig);")
+		g.P(indent, "}")
+	}
+	return nil
+}
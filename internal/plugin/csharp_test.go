@@ -0,0 +1,173 @@
+package plugin
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCSharpPascalCase(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		expected string
+	}{
+		{name: "freight", expected: "Freight"},
+		{name: "v1beta1", expected: "V1Beta1"},
+		{name: "resource_exhausted", expected: "ResourceExhausted"},
+		{name: "Already", expected: "Already"},
+	} {
+		if actual := csharpPascalCase(tt.name); actual != tt.expected {
+			t.Errorf("csharpPascalCase(%q): expected %q, got %q", tt.name, tt.expected, actual)
+		}
+	}
+}
+
+func TestCSharpTimeSpan(t *testing.T) {
+	for _, tt := range []struct {
+		duration string
+		expected string
+	}{
+		{duration: "1s", expected: "TimeSpan.FromMilliseconds(1000)"},
+		{duration: "0.1s", expected: "TimeSpan.FromMilliseconds(100)"},
+		{duration: "0.0001s", expected: "TimeSpan.FromTicks(1000)"},
+	} {
+		actual, err := csharpTimeSpan(tt.duration)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != tt.expected {
+			t.Errorf("csharpTimeSpan(%q): expected %q, got %q", tt.duration, tt.expected, actual)
+		}
+	}
+}
+
+func TestGenerateCSharpModule(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		testFreightServiceConfigFile: `{
+  "loadBalancingConfig": [{"round_robin": {}}],
+  "retryThrottling": {"maxTokens": 10, "tokenRatio": 0.1},
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s"},
+    {
+      "name": [{"service": "einride.example.freight.v1.FreightService"}],
+      "retryPolicy": {
+        "maxAttempts": 3,
+        "initialBackoff": "0.1s",
+        "maxBackoff": "1s",
+        "backoffMultiplier": 2,
+        "retryableStatusCodes": ["UNAVAILABLE"]
+      }
+    }
+  ]
+}`,
+	})
+	p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+	if err := p.generateModules(languageCSharp); err != nil {
+		t.Fatal(err)
+	}
+	files := p.gen.Response().GetFile()
+	if len(files) != 1 {
+		t.Fatalf("expected 1 generated file, got %d", len(files))
+	}
+	const expectedName = "einride/example/freight/v1/FreightGrpcServiceConfig.cs"
+	if actual := files[0].GetName(); actual != expectedName {
+		t.Errorf("expected name %s, got %s", expectedName, actual)
+	}
+	expected := `// <auto-generated>
+// Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.
+// Source: ` + filepath.Join(dir, testFreightServiceConfigFile) + `
+// </auto-generated>
+
+using System;
+using Grpc.Core;
+using Grpc.Net.Client;
+using Grpc.Net.Client.Configuration;
+
+namespace Einride.Example.Freight.V1
+{
+    /// <summary>The service config for all services in the einride.example.freight.v1 package.</summary>
+    public static class FreightGrpcServiceConfig
+    {
+        /// <summary>The service config JSON for all services in the package.</summary>
+        public const string Json = @"{
+  ""loadBalancingConfig"": [
+    {
+      ""round_robin"": {}
+    }
+  ],
+  ""methodConfig"": [
+    {
+      ""name"": [
+        {}
+      ],
+      ""timeout"": ""10s""
+    },
+    {
+      ""name"": [
+        {
+          ""service"": ""einride.example.freight.v1.FreightService""
+        }
+      ],
+      ""retryPolicy"": {
+        ""backoffMultiplier"": 2,
+        ""initialBackoff"": ""0.1s"",
+        ""maxAttempts"": 3,
+        ""maxBackoff"": ""1s"",
+        ""retryableStatusCodes"": [
+          ""UNAVAILABLE""
+        ]
+      }
+    }
+  ],
+  ""retryThrottling"": {
+    ""maxTokens"": 10,
+    ""tokenRatio"": 0.1
+  }
+}";
+
+        /// <summary>
+        /// Returns the service config for all services in the package. Grpc.Net.Client does not support
+        /// timeouts, waitForReady and message size limits, which are left out.
+        /// </summary>
+        public static ServiceConfig CreateServiceConfig()
+        {
+            var serviceConfig = new ServiceConfig();
+            serviceConfig.LoadBalancingConfigs.Add(new RoundRobinConfig());
+            serviceConfig.RetryThrottling = new RetryThrottlingPolicy
+            {
+                MaxTokens = 10,
+                TokenRatio = 0.1,
+            };
+            {
+                var methodConfig = new MethodConfig();
+                methodConfig.Names.Add(MethodName.Default);
+                // Not supported by Grpc.Net.Client: timeout.
+                serviceConfig.MethodConfigs.Add(methodConfig);
+            }
+            {
+                var methodConfig = new MethodConfig();
+                methodConfig.Names.Add(new MethodName { Service = "einride.example.freight.v1.FreightService" });
+                methodConfig.RetryPolicy = new RetryPolicy
+                {
+                    MaxAttempts = 3,
+                    InitialBackoff = TimeSpan.FromMilliseconds(100),
+                    MaxBackoff = TimeSpan.FromMilliseconds(1000),
+                    BackoffMultiplier = 2,
+                };
+                methodConfig.RetryPolicy.RetryableStatusCodes.Add(StatusCode.Unavailable);
+                serviceConfig.MethodConfigs.Add(methodConfig);
+            }
+            return serviceConfig;
+        }
+
+        /// <summary>Returns channel options with the service config for all services in the package.</summary>
+        public static GrpcChannelOptions CreateChannelOptions()
+        {
+            return new GrpcChannelOptions { ServiceConfig = CreateServiceConfig() };
+        }
+    }
+}
+`
+	if actual := files[0].GetContent(); actual != expected {
+		t.Errorf("expected content:\n%s\ngot:\n%s", expected, actual)
+	}
+}
@@ -0,0 +1,111 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+)
+
+// csvHeader is the header of the summary of effective policies.
+var csvHeader = []string{
+	"service",
+	"method",
+	"source",
+	"timeout",
+	"max_attempts",
+	"retryable_status_codes",
+	"wait_for_ready",
+}
+
+// csvRecords returns a record with the effective policies of every method of the services to generate, after
+// applying the gRPC matching rules and the gRPC limit of attempts. Methods of services without a service config have
+// empty policies.
+func (p *plugin) csvRecords() ([][]string, error) {
+	var result [][]string
+	for _, file := range p.gen.Files {
+		if !file.Generate {
+			continue
+		}
+		for _, service := range file.Services {
+			serviceConfig, ok, err := p.resolveServiceConfig(service.Desc)
+			if err != nil {
+				return nil, err
+			}
+			var content serviceConfigJSON
+			var explainContent explainServiceConfigJSON
+			var source string
+			if ok {
+				source = serviceConfig.source
+				embedded, err := p.embeddedServiceConfig(serviceConfig)
+				if err != nil {
+					return nil, err
+				}
+				if err := json.Unmarshal([]byte(embedded), &content); err != nil {
+					return nil, fmt.Errorf("%s: %w", source, err)
+				}
+				if err := json.Unmarshal([]byte(embedded), &explainContent); err != nil {
+					return nil, fmt.Errorf("%s: %w", source, err)
+				}
+			}
+			for _, method := range service.Methods {
+				record := []string{string(service.Desc.FullName()), string(method.Desc.Name()), source, "", "", "", ""}
+				i, ok := content.methodConfigFor(method.Desc)
+				if !ok || i >= len(explainContent.MethodConfigs) {
+					result = append(result, record)
+					continue
+				}
+				methodConfig := explainContent.MethodConfigs[i]
+				if methodConfig.Timeout != nil {
+					timeout, err := configjson.ParseDuration(*methodConfig.Timeout)
+					if err != nil {
+						return nil, fmt.Errorf("%s: methodConfig[%d].timeout: %w", source, i, err)
+					}
+					record[3] = configjson.FormatDuration(timeout)
+				}
+				if policy := methodConfig.RetryPolicy; policy != nil {
+					maxAttempts, _, err := explainMaxAttempts(policy.MaxAttempts)
+					if err != nil {
+						return nil, fmt.Errorf("%s: methodConfig[%d].retryPolicy.maxAttempts: %w", source, i, err)
+					}
+					codes, err := explainStatusCodes(policy.RetryableStatusCodes)
+					if err != nil {
+						return nil, fmt.Errorf("%s: methodConfig[%d].retryPolicy.retryableStatusCodes: %w", source, i, err)
+					}
+					record[4], record[5] = strconv.FormatInt(maxAttempts, 10), codes
+				}
+				record[6] = strconv.FormatBool(methodConfig.WaitForReady != nil && *methodConfig.WaitForReady)
+				result = append(result, record)
+			}
+		}
+	}
+	return result, nil
+}
+
+// generateCSV writes a summary of the effective policies of every method of the services to generate to a file, as
+// comma-separated values, or tab-separated values when the file has the .tsv extension, for spreadsheet-based audits
+// and capacity planning.
+func (p *plugin) generateCSV(filename string) error {
+	records, err := p.csvRecords()
+	if err != nil {
+		return fmt.Errorf("emit CSV: %w", err)
+	}
+	var data bytes.Buffer
+	w := csv.NewWriter(&data)
+	if path.Ext(filename) == ".tsv" {
+		w.Comma = '\t'
+	}
+	if err := w.Write(csvHeader); err != nil {
+		return err
+	}
+	if err := w.WriteAll(records); err != nil {
+		return fmt.Errorf("emit CSV: %w", err)
+	}
+	g := p.gen.NewGeneratedFile(filename, "")
+	_, err = g.Write(data.Bytes())
+	return err
+}
@@ -0,0 +1,66 @@
+package plugin
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateCSV(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		testFreightServiceConfigFile: `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s", "waitForReady": true},
+    {
+      "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+      "timeout": "1.500s",
+      "retryPolicy": {
+        "maxAttempts": 8,
+        "initialBackoff": "0.1s",
+        "maxBackoff": "1s",
+        "backoffMultiplier": 2,
+        "retryableStatusCodes": ["UNAVAILABLE", "ABORTED"]
+      }
+    }
+  ]
+}`,
+	})
+	for _, tt := range []struct {
+		filename string
+		// expected is the content of the file, where $source is replaced with the service config file.
+		expected string
+	}{
+		{
+			filename: "summary.csv",
+			expected: "service,method,source,timeout,max_attempts,retryable_status_codes,wait_for_ready\n" +
+				"einride.example.freight.v1.FreightService,GetShipper,$source,1.5s,5,UNAVAILABLE|ABORTED,false\n" +
+				"einride.example.freight.v1.FreightService,CreateShipper,$source,10s,,,true\n",
+		},
+		{
+			filename: "summary.tsv",
+			expected: "service\tmethod\tsource\ttimeout\tmax_attempts\tretryable_status_codes\twait_for_ready\n" +
+				"einride.example.freight.v1.FreightService\tGetShipper\t$source\t1.5s\t5\tUNAVAILABLE|ABORTED\tfalse\n" +
+				"einride.example.freight.v1.FreightService\tCreateShipper\t$source\t10s\t\t\ttrue\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.filename, func(t *testing.T) {
+			opts := pluginOptions{path: dir, conflict: conflictPreferJSON}
+			p := newTestPlugin(t, opts, testFile(t, testRouteServiceFile))
+			if err := p.generateCSV(tt.filename); err != nil {
+				t.Fatal(err)
+			}
+			files := p.gen.Response().GetFile()
+			if len(files) != 1 {
+				t.Fatalf("expected 1 generated file, got %d", len(files))
+			}
+			if actual := files[0].GetName(); actual != tt.filename {
+				t.Errorf("expected name %s, got %s", tt.filename, actual)
+			}
+			expected := strings.ReplaceAll(tt.expected, "$source", filepath.Join(dir, testFreightServiceConfigFile))
+			if actual := files[0].GetContent(); actual != expected {
+				t.Errorf("expected content:\n%s\ngot:\n%s", expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"time"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+)
+
+// dashboardTimeoutBuckets are the upper bounds of the buckets of the timeout distribution of a dashboard.
+var dashboardTimeoutBuckets = []time.Duration{time.Second, 5 * time.Second, 30 * time.Second, 2 * time.Minute}
+
+// dashboard is the data of an HTML dashboard of the service config coverage of the services in a Buf image.
+type dashboard struct {
+	Services        []dashboardService
+	CoveredServices int
+	Methods         int
+	RetryMethods    int
+	HedgingMethods  int
+	Timeouts        []dashboardBucket
+	Findings        []dashboardFinding
+	Errors          int
+	Warnings        int
+}
+
+// dashboardService is a service in a dashboard.
+type dashboardService struct {
+	Name string
+	// Source is where the service config of the service is resolved from, or empty when it has none.
+	Source  string
+	Kind    string
+	Methods int
+	// Timeouts, Retries and Hedging are the numbers of methods of the service with the policies.
+	Timeouts int
+	Retries  int
+	Hedging  int
+}
+
+// dashboardBucket is a bucket of the timeout distribution of a dashboard.
+type dashboardBucket struct {
+	Label   string
+	Methods int
+	// Percent is the share of methods in the bucket.
+	Percent int
+}
+
+// dashboardFinding is a validation finding in a dashboard.
+type dashboardFinding struct {
+	Severity string
+	Rule     string
+	Location string
+	Message  string
+}
+
+// Percent returns the share of services with a service config.
+func (d *dashboard) Percent() int {
+	return percent(d.CoveredServices, len(d.Services))
+}
+
+// percent returns n as a rounded percentage of total.
+func percent(n, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return (200*n + total) / (2 * total)
+}
+
+// timeoutBucket returns the index of the bucket of the timeout distribution of a timeout, where the last bucket is
+// for longer timeouts, and the one after for methods without a timeout.
+func timeoutBucket(timeout *time.Duration) int {
+	if timeout == nil {
+		return len(dashboardTimeoutBuckets) + 1
+	}
+	for i, upperBound := range dashboardTimeoutBuckets {
+		if *timeout <= upperBound {
+			return i
+		}
+	}
+	return len(dashboardTimeoutBuckets)
+}
+
+// newDashboard returns the dashboard of the services to generate, with the diagnostics of validation.
+func (p *plugin) newDashboard(diagnostics []diagnostic) (*dashboard, error) {
+	var result dashboard
+	timeouts := make([]int, len(dashboardTimeoutBuckets)+2)
+	for _, file := range p.gen.Files {
+		if !file.Generate {
+			continue
+		}
+		for _, service := range file.Services {
+			dashboardService := dashboardService{
+				Name:    string(service.Desc.FullName()),
+				Methods: len(service.Methods),
+			}
+			result.Methods += len(service.Methods)
+			serviceConfig, ok, err := p.resolveServiceConfig(service.Desc)
+			if err != nil {
+				return nil, err
+			}
+			var content serviceConfigJSON
+			var explainContent explainServiceConfigJSON
+			if ok {
+				result.CoveredServices++
+				dashboardService.Source, dashboardService.Kind = serviceConfig.source, serviceConfig.kind()
+				embedded, err := p.embeddedServiceConfig(serviceConfig)
+				if err != nil {
+					return nil, err
+				}
+				// Invalid service configs are reported by validation, and count as having no method configs.
+				_ = json.Unmarshal([]byte(embedded), &content)
+				_ = json.Unmarshal([]byte(embedded), &explainContent)
+			}
+			for _, method := range service.Methods {
+				var timeout *time.Duration
+				if i, ok := content.methodConfigFor(method.Desc); ok && i < len(explainContent.MethodConfigs) {
+					methodConfig := explainContent.MethodConfigs[i]
+					if methodConfig.Timeout != nil {
+						if d, err := configjson.ParseDuration(*methodConfig.Timeout); err == nil {
+							timeout = &d
+							dashboardService.Timeouts++
+						}
+					}
+					if methodConfig.RetryPolicy != nil {
+						dashboardService.Retries++
+					}
+					if methodConfig.HedgingPolicy != nil {
+						dashboardService.Hedging++
+					}
+				}
+				timeouts[timeoutBucket(timeout)]++
+			}
+			result.RetryMethods += dashboardService.Retries
+			result.HedgingMethods += dashboardService.Hedging
+			result.Services = append(result.Services, dashboardService)
+		}
+	}
+	for i, n := range timeouts {
+		var label string
+		switch {
+		case i == 0:
+			label = "≤" + configjson.FormatDuration(dashboardTimeoutBuckets[i])
+		case i < len(dashboardTimeoutBuckets):
+			label = configjson.FormatDuration(dashboardTimeoutBuckets[i-1]) + "–" +
+				configjson.FormatDuration(dashboardTimeoutBuckets[i])
+		case i == len(dashboardTimeoutBuckets):
+			label = ">" + configjson.FormatDuration(dashboardTimeoutBuckets[i-1])
+		default:
+			label = "none"
+		}
+		result.Timeouts = append(result.Timeouts, dashboardBucket{
+			Label:   label,
+			Methods: n,
+			Percent: percent(n, result.Methods),
+		})
+	}
+	for _, diagnostic := range diagnostics {
+		switch diagnostic.severity {
+		case severityError:
+			result.Errors++
+		case severityWarning:
+			result.Warnings++
+		}
+		result.Findings = append(result.Findings, dashboardFinding{
+			Severity: diagnostic.severity.String(),
+			Rule:     string(diagnostic.rule),
+			Location: diagnostic.location.String(),
+			Message:  diagnostic.message,
+		})
+	}
+	return &result, nil
+}
+
+// dashboardTemplate is the HTML template of dashboards.
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>gRPC service config dashboard</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+.summary td { font-size: 1.4em; }
+.missing { color: #b00; }
+.bar { background: #4a7bd0; height: 1em; }
+.error { color: #b00; }
+.warning { color: #a60; }
+</style>
+</head>
+<body>
+<h1>gRPC service config dashboard</h1>
+<table class="summary">
+<tr><th>Coverage</th><th>Methods</th><th>With retries</th><th>With hedging</th><th>Errors</th><th>Warnings</th></tr>
+<tr>
+<td>{{.Percent}}% ({{.CoveredServices}} of {{len .Services}} services)</td>
+<td>{{.Methods}}</td>
+<td>{{.RetryMethods}}</td>
+<td>{{.HedgingMethods}}</td>
+<td>{{.Errors}}</td>
+<td>{{.Warnings}}</td>
+</tr>
+</table>
+<h2>Timeouts</h2>
+<table>
+<tr><th>Timeout</th><th>Methods</th><th></th></tr>
+{{- range .Timeouts}}
+<tr>
+<td>{{.Label}}</td>
+<td>{{.Methods}}</td>
+<td style="width: 20em"><div class="bar" style="width: {{.Percent}}%"></div></td>
+</tr>
+{{- end}}
+</table>
+<h2>Services</h2>
+<table>
+<tr>
+<th>Service</th><th>Service config</th><th>Methods</th><th>With timeouts</th><th>With retries</th><th>With hedging</th>
+</tr>
+{{- range .Services}}
+<tr>
+<td>{{.Name}}</td>
+{{- if .Source}}
+<td>{{.Source}} ({{.Kind}})</td>
+{{- else}}
+<td class="missing">none</td>
+{{- end}}
+<td>{{.Methods}}</td>
+<td>{{.Timeouts}}</td>
+<td>{{.Retries}}</td>
+<td>{{.Hedging}}</td>
+</tr>
+{{- end}}
+</table>
+<h2>Findings</h2>
+{{- if .Findings}}
+<table>
+<tr><th>Severity</th><th>Rule</th><th>Location</th><th>Message</th></tr>
+{{- range .Findings}}
+<tr><td class="{{.Severity}}">{{.Severity}}</td><td>{{.Rule}}</td><td>{{.Location}}</td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No findings.</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// writeDashboard writes the HTML dashboard to the file, where "-" writes it to stdout.
+func writeDashboard(file string, dashboard *dashboard) error {
+	var data bytes.Buffer
+	if err := dashboardTemplate.Execute(&data, dashboard); err != nil {
+		return fmt.Errorf("write dashboard: %w", err)
+	}
+	var err error
+	if file == "-" {
+		_, err = os.Stdout.Write(data.Bytes())
+	} else {
+		err = os.WriteFile(file, data.Bytes(), 0o600)
+	}
+	if err != nil {
+		return fmt.Errorf("write dashboard: %w", err)
+	}
+	return nil
+}
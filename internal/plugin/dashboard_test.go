@@ -0,0 +1,180 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeoutBucket(t *testing.T) {
+	duration := func(d time.Duration) *time.Duration {
+		return &d
+	}
+	for _, tt := range []struct {
+		timeout  *time.Duration
+		expected int
+	}{
+		{timeout: duration(time.Second), expected: 0},
+		{timeout: duration(2 * time.Second), expected: 1},
+		{timeout: duration(30 * time.Second), expected: 2},
+		{timeout: duration(time.Minute), expected: 3},
+		{timeout: duration(time.Hour), expected: 4},
+		{timeout: nil, expected: 5},
+	} {
+		if actual := timeoutBucket(tt.timeout); actual != tt.expected {
+			t.Errorf("timeoutBucket(%v): expected %d, got %d", tt.timeout, tt.expected, actual)
+		}
+	}
+}
+
+// newTestDashboard returns the dashboard of the freight service with two methods and a retry service config, with a
+// warning.
+func newTestDashboard(t *testing.T) (*dashboard, string) {
+	t.Helper()
+	dir := writeTestFiles(t, map[string]string{testFreightServiceConfigFile: testRetryServiceConfig})
+	p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testRouteServiceFile))
+	source := filepath.Join(dir, testFreightServiceConfigFile)
+	dashboard, err := p.newDashboard([]diagnostic{
+		{
+			rule:     ruleStreamingRetry,
+			severity: severityWarning,
+			location: location{file: source, line: 4, column: 5},
+			message:  "<retry>",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dashboard, source
+}
+
+func TestNewDashboard(t *testing.T) {
+	actual, source := newTestDashboard(t)
+	expected := &dashboard{
+		Services: []dashboardService{
+			{
+				Name:     "einride.example.freight.v1.FreightService",
+				Source:   source,
+				Kind:     "JSON file",
+				Methods:  2,
+				Timeouts: 2,
+				Retries:  1,
+			},
+		},
+		CoveredServices: 1,
+		Methods:         2,
+		RetryMethods:    1,
+		Timeouts: []dashboardBucket{
+			{Label: "≤1s", Methods: 1, Percent: 50},
+			{Label: "1s–5s"},
+			{Label: "5s–30s", Methods: 1, Percent: 50},
+			{Label: "30s–120s"},
+			{Label: ">120s"},
+			{Label: "none"},
+		},
+		Findings: []dashboardFinding{
+			{
+				Severity: "warning",
+				Rule:     "STREAMING_RETRY",
+				Location: source + ":4:5",
+				Message:  "<retry>",
+			},
+		},
+		Warnings: 1,
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+	if percent := actual.Percent(); percent != 100 {
+		t.Errorf("expected 100%% coverage, got %d%%", percent)
+	}
+}
+
+func TestWriteDashboard(t *testing.T) {
+	dashboard, source := newTestDashboard(t)
+	file := filepath.Join(t.TempDir(), "dashboard.html")
+	if err := writeDashboard(file, dashboard); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The head of the dashboard is static.
+	expected := `<body>
+<h1>gRPC service config dashboard</h1>
+<table class="summary">
+<tr><th>Coverage</th><th>Methods</th><th>With retries</th><th>With hedging</th><th>Errors</th><th>Warnings</th></tr>
+<tr>
+<td>100% (1 of 1 services)</td>
+<td>2</td>
+<td>1</td>
+<td>0</td>
+<td>0</td>
+<td>1</td>
+</tr>
+</table>
+<h2>Timeouts</h2>
+<table>
+<tr><th>Timeout</th><th>Methods</th><th></th></tr>
+<tr>
+<td>≤1s</td>
+<td>1</td>
+<td style="width: 20em"><div class="bar" style="width: 50%"></div></td>
+</tr>
+<tr>
+<td>1s–5s</td>
+<td>0</td>
+<td style="width: 20em"><div class="bar" style="width: 0%"></div></td>
+</tr>
+<tr>
+<td>5s–30s</td>
+<td>1</td>
+<td style="width: 20em"><div class="bar" style="width: 50%"></div></td>
+</tr>
+<tr>
+<td>30s–120s</td>
+<td>0</td>
+<td style="width: 20em"><div class="bar" style="width: 0%"></div></td>
+</tr>
+<tr>
+<td>&gt;120s</td>
+<td>0</td>
+<td style="width: 20em"><div class="bar" style="width: 0%"></div></td>
+</tr>
+<tr>
+<td>none</td>
+<td>0</td>
+<td style="width: 20em"><div class="bar" style="width: 0%"></div></td>
+</tr>
+</table>
+<h2>Services</h2>
+<table>
+<tr>
+<th>Service</th><th>Service config</th><th>Methods</th><th>With timeouts</th><th>With retries</th><th>With hedging</th>
+</tr>
+<tr>
+<td>einride.example.freight.v1.FreightService</td>
+<td>` + source + ` (JSON file)</td>
+<td>2</td>
+<td>2</td>
+<td>1</td>
+<td>0</td>
+</tr>
+</table>
+<h2>Findings</h2>
+<table>
+<tr><th>Severity</th><th>Rule</th><th>Location</th><th>Message</th></tr>
+<tr><td class="warning">warning</td><td>STREAMING_RETRY</td><td>` + source + `:4:5</td><td>&lt;retry&gt;</td></tr>
+</table>
+</body>
+</html>
+`
+	i := strings.Index(string(actual), "<body>\n")
+	if i == -1 || string(actual[i:]) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
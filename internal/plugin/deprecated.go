@@ -0,0 +1,82 @@
+package plugin
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// deprecatedField is a deprecated service config field.
+type deprecatedField struct {
+	// pattern is the JSON path of the field, where [*] matches any array index.
+	pattern string
+	// replacement recommends what to use instead of the field.
+	replacement string
+}
+
+// deprecatedFields are the deprecated service config fields, in addition to loadBalancingPolicy, which is reported by
+// its own rule.
+var deprecatedFields = []deprecatedField{
+	{
+		pattern:     "stickinessMetadataKey",
+		replacement: "session affinity is no longer configured with a metadata key, use the ring_hash load balancing policy",
+	},
+	{
+		pattern:     "loadBalancingConfig[*].xds",
+		replacement: "use the cds_experimental load balancing policy with an xds:/// target",
+	},
+	{
+		pattern:     "loadBalancingConfig[*].xds_experimental",
+		replacement: "use the cds_experimental load balancing policy with an xds:/// target",
+	},
+	{
+		pattern:     "loadBalancingConfig[*].eds_experimental",
+		replacement: "use the xds_cluster_resolver_experimental load balancing policy",
+	},
+}
+
+// arrayIndexRegexp matches array indices in JSON paths.
+var arrayIndexRegexp = regexp.MustCompile(`\[[0-9]+\]`)
+
+// deprecatedFieldUses returns every use of a deprecated field in the service config JSON.
+func deprecatedFieldUses(data []byte) ([]jsonFinding, error) {
+	value, err := decodeJSONValue(data)
+	if err != nil {
+		return nil, err
+	}
+	replacements := make(map[string]string, len(deprecatedFields))
+	for _, field := range deprecatedFields {
+		replacements[field.pattern] = field.replacement
+	}
+	var result []jsonFinding
+	var walk func(path string, value interface{})
+	walk = func(path string, value interface{}) {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(value))
+			for key := range value {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				fieldPath := key
+				if path != "" {
+					fieldPath = path + "." + key
+				}
+				if replacement, ok := replacements[arrayIndexRegexp.ReplaceAllString(fieldPath, "[*]")]; ok {
+					result = append(result, jsonFinding{
+						path:    fieldPath,
+						message: fmt.Sprintf("%s is deprecated: %s", key, replacement),
+					})
+				}
+				walk(fieldPath, value[key])
+			}
+		case []interface{}:
+			for i, item := range value {
+				walk(fmt.Sprintf("%s[%d]", path, i), item)
+			}
+		}
+	}
+	walk("", value)
+	return result, nil
+}
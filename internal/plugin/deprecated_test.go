@@ -0,0 +1,62 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeprecatedFieldUses(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		serviceConfig string
+		findings      []string
+	}{
+		{
+			name:          "no deprecated fields",
+			serviceConfig: `{"loadBalancingConfig": [{"ring_hash_experimental": {}}], "methodConfig": [{"name": [{}]}]}`,
+		},
+		{
+			name:          "stickiness metadata key",
+			serviceConfig: `{"stickinessMetadataKey": "session"}`,
+			findings: []string{
+				"stickinessMetadataKey: stickinessMetadataKey is deprecated: session affinity is no longer" +
+					" configured with a metadata key, use the ring_hash load balancing policy",
+			},
+		},
+		{
+			name: "deprecated load balancing policies",
+			serviceConfig: `{"loadBalancingConfig": [
+  {"round_robin": {}},
+  {"xds_experimental": {}},
+  {"eds_experimental": {"cluster": "a"}},
+  {"xds": {}}
+]}`,
+			findings: []string{
+				"loadBalancingConfig[1].xds_experimental: xds_experimental is deprecated: use the cds_experimental" +
+					" load balancing policy with an xds:/// target",
+				"loadBalancingConfig[2].eds_experimental: eds_experimental is deprecated: use the" +
+					" xds_cluster_resolver_experimental load balancing policy",
+				"loadBalancingConfig[3].xds: xds is deprecated: use the cds_experimental load balancing policy with" +
+					" an xds:/// target",
+			},
+		},
+		{
+			name:          "nested fields are not deprecated",
+			serviceConfig: `{"methodConfig": [{"stickinessMetadataKey": "session", "xds": {}}]}`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			uses, err := deprecatedFieldUses([]byte(tt.serviceConfig))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual, expected := jsonFindingStrings(uses), strings.Join(tt.findings, "\n"); actual != expected {
+				t.Errorf("expected findings:\n%s\ngot:\n%s", expected, actual)
+			}
+		})
+	}
+	if _, err := deprecatedFieldUses([]byte(`{`)); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,253 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// severity is the severity of a diagnostic.
+type severity int
+
+const (
+	// severityInfo is an informational diagnostic that never fails validation.
+	severityInfo severity = iota + 1
+	// severityWarning is a diagnostic that does not fail validation, unless running in strict mode.
+	severityWarning
+	// severityError is a diagnostic that fails validation.
+	severityError
+)
+
+// String implements fmt.Stringer.
+func (s severity) String() string {
+	switch s {
+	case severityInfo:
+		return "info"
+	case severityWarning:
+		return "warning"
+	case severityError:
+		return "error"
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// rule identifies the validation rule that found a problem.
+type rule string
+
+const (
+	// ruleMissingServiceConfig finds services without a service config.
+	ruleMissingServiceConfig rule = "MISSING_SERVICE_CONFIG"
+	// ruleExemptionReason finds services exempt from requiring a service config without a documented reason.
+	ruleExemptionReason rule = "EXEMPTION_REASON"
+	// ruleInvalidJSON finds service config files that are not valid JSON.
+	ruleInvalidJSON rule = "INVALID_JSON"
+	// ruleInvalidServiceConfig finds service configs rejected by gRPC.
+	ruleInvalidServiceConfig rule = "INVALID_SERVICE_CONFIG"
+	// ruleNoOpConfig finds empty service configs and method configs without behavior.
+	ruleNoOpConfig rule = "NO_OP_CONFIG"
+	// ruleDuplicateName finds service and method pairs matched by more than one name.
+	ruleDuplicateName rule = "DUPLICATE_NAME"
+	// ruleNameFormat finds names not accepted by every gRPC implementation.
+	ruleNameFormat rule = "NAME_FORMAT"
+	// ruleShadowedMethodConfig finds method configs that never apply, since more specific names take precedence.
+	ruleShadowedMethodConfig rule = "SHADOWED_METHOD_CONFIG"
+	// ruleDanglingName finds names referencing services or methods not present in the descriptor set.
+	ruleDanglingName rule = "DANGLING_NAME"
+	// ruleUngeneratedService finds names referencing services in files that are not generated.
+	ruleUngeneratedService rule = "UNGENERATED_SERVICE"
+	// ruleUnknownField finds fields not present in the service config schema.
+	ruleUnknownField rule = "UNKNOWN_FIELD"
+	// ruleConfigSize finds service configs exceeding the size budget.
+	ruleConfigSize rule = "CONFIG_SIZE"
+	// ruleGRPCGoCompatibility finds features not supported by the target grpc-go release.
+	ruleGRPCGoCompatibility rule = "GRPC_GO_COMPATIBILITY"
+	// ruleDeprecatedLoadBalancingPolicy finds service configs using the deprecated loadBalancingPolicy field.
+	ruleDeprecatedLoadBalancingPolicy rule = "DEPRECATED_LOAD_BALANCING_POLICY"
+	// ruleNumericRepresentation finds numeric fields encoded as strings, which grpc-go rejects.
+	ruleNumericRepresentation rule = "NUMERIC_REPRESENTATION"
+	// ruleDeprecatedField finds deprecated service config fields.
+	ruleDeprecatedField rule = "DEPRECATED_FIELD"
+	// ruleInvalidLoadBalancingConfig finds invalid load balancing policy configs.
+	ruleInvalidLoadBalancingConfig rule = "INVALID_LOAD_BALANCING_CONFIG"
+	// ruleInvalidStatusCode finds invalid retryable and non-fatal status codes.
+	ruleInvalidStatusCode rule = "INVALID_STATUS_CODE"
+	// ruleMaxAttempts finds retry and hedging policies with more attempts than gRPC allows.
+	ruleMaxAttempts rule = "MAX_ATTEMPTS"
+	// ruleLossless finds values dropped or changed by a round trip through the service config proto.
+	ruleLossless rule = "LOSSLESS"
+	// ruleBreakingChange finds changes that break clients relying on the previously generated service config.
+	ruleBreakingChange rule = "BREAKING_CHANGE"
+	// ruleDuplicateConfig finds byte-identical service configs in different packages.
+	ruleDuplicateConfig rule = "DUPLICATE_CONFIG"
+	// ruleJSONSchema finds values that do not match the service config JSON Schema.
+	ruleJSONSchema rule = "JSON_SCHEMA"
+	// ruleRequireTimeout finds unary methods without a timeout.
+	ruleRequireTimeout rule = "REQUIRE_TIMEOUT"
+	// ruleRequireDefaultMethodConfig finds service configs without a default method config.
+	ruleRequireDefaultMethodConfig rule = "REQUIRE_DEFAULT_METHOD_CONFIG"
+	// ruleTimeoutRange finds method timeouts outside of the allowed timeout range.
+	ruleTimeoutRange rule = "TIMEOUT_RANGE"
+	// ruleGatewayTimeout finds methods served by an HTTP gateway with timeouts exceeding the gateway timeout.
+	ruleGatewayTimeout rule = "GATEWAY_TIMEOUT"
+	// ruleStreamingRetry finds retry and hedging policies on streaming methods.
+	ruleStreamingRetry rule = "STREAMING_RETRY"
+	// ruleNonIdempotentRetry finds retry policies on non-idempotent methods.
+	ruleNonIdempotentRetry rule = "NON_IDEMPOTENT_RETRY"
+	// ruleHedgingSideEffects finds hedging policies on methods that may have side effects.
+	ruleHedgingSideEffects rule = "HEDGING_SIDE_EFFECTS"
+	// ruleRetryUnsafeStatusCode finds retries of status codes that may be returned after a request was processed.
+	ruleRetryUnsafeStatusCode rule = "RETRY_UNSAFE_STATUS_CODE"
+	// ruleWaitForReady finds method configs enabling waitForReady.
+	ruleWaitForReady rule = "WAIT_FOR_READY"
+)
+
+// rules are all validation rules and their descriptions.
+var rules = []struct {
+	rule        rule
+	description string
+}{
+	{rule: ruleMissingServiceConfig, description: "Every service must have a service config."},
+	{rule: ruleExemptionReason, description: "Services exempt from requiring a service config must document a reason."},
+	{rule: ruleInvalidJSON, description: "Service config files must be valid JSON."},
+	{rule: ruleInvalidServiceConfig, description: "Service configs must be accepted by gRPC."},
+	{rule: ruleNoOpConfig, description: "Service configs and method configs should configure behavior."},
+	{rule: ruleDuplicateName, description: "A service and method pair must only be matched by one name."},
+	{rule: ruleNameFormat, description: "Names should be accepted by every gRPC implementation."},
+	{rule: ruleShadowedMethodConfig, description: "Method configs should apply to at least one method."},
+	{rule: ruleDanglingName, description: "Names must reference services and methods in the descriptor set."},
+	{rule: ruleUngeneratedService, description: "Names should reference services in generated files."},
+	{rule: ruleUnknownField, description: "Service configs must only contain fields in the service config schema."},
+	{rule: ruleConfigSize, description: "Service configs must not exceed the size budget."},
+	{
+		rule:        ruleGRPCGoCompatibility,
+		description: "Service configs must only use features supported by the target grpc-go.",
+	},
+	{
+		rule:        ruleDeprecatedLoadBalancingPolicy,
+		description: "Service configs must use loadBalancingConfig instead of loadBalancingPolicy.",
+	},
+	{rule: ruleNumericRepresentation, description: "Numeric fields must be encoded as JSON numbers."},
+	{rule: ruleDeprecatedField, description: "Service configs should not use deprecated fields."},
+	{rule: ruleInvalidLoadBalancingConfig, description: "Load balancing policy configs must be valid."},
+	{rule: ruleInvalidStatusCode, description: "Retryable and non-fatal status codes must be valid failure codes."},
+	{rule: ruleMaxAttempts, description: "Retry and hedging policies must not exceed the gRPC limit of 5 attempts."},
+	{rule: ruleLossless, description: "Service configs must survive a round trip through the service config proto."},
+	{
+		rule:        ruleBreakingChange,
+		description: "Service configs must not break clients of the previously generated service config.",
+	},
+	{rule: ruleDuplicateConfig, description: "Identical service configs in different packages could be consolidated."},
+	{rule: ruleJSONSchema, description: "Service configs must match the service config JSON Schema."},
+	{rule: ruleRequireTimeout, description: "Unary methods must have a timeout."},
+	{rule: ruleRequireDefaultMethodConfig, description: "Service configs must have a default method config."},
+	{rule: ruleTimeoutRange, description: "Method timeouts must be within the allowed timeout range."},
+	{rule: ruleGatewayTimeout, description: "Methods served by an HTTP gateway must not outlive the gateway timeout."},
+	{rule: ruleStreamingRetry, description: "Streaming methods should not have retry or hedging policies."},
+	{rule: ruleNonIdempotentRetry, description: "Non-idempotent methods should not have retry policies."},
+	{rule: ruleHedgingSideEffects, description: "Methods that may have side effects should not have hedging policies."},
+	{rule: ruleRetryUnsafeStatusCode, description: "INTERNAL and UNKNOWN should not be retried."},
+	{rule: ruleWaitForReady, description: "Method configs should not enable waitForReady."},
+}
+
+// diagnostic is a problem found during validation.
+type diagnostic struct {
+	// rule is the rule that found the problem.
+	rule rule
+	// severity is the severity of the diagnostic.
+	severity severity
+	// location is the location the diagnostic applies to.
+	location location
+	// message describes the problem.
+	message string
+	// descriptor is the proto descriptor the problem applies to, such as the service whose service config has the
+	// problem, or nil when unknown.
+	descriptor protoreflect.Descriptor
+}
+
+// String implements fmt.Stringer.
+func (d diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", d.location, d.severity, d.message, d.rule)
+}
+
+// diagnostics collects every problem found during validation, so that all of them can be reported at once.
+type diagnostics struct {
+	list []diagnostic
+}
+
+// errorf adds an error diagnostic at the location.
+func (d *diagnostics) errorf(rule rule, location location, format string, args ...interface{}) {
+	d.list = append(d.list, diagnostic{
+		rule:     rule,
+		severity: severityError,
+		location: location,
+		message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// infof adds an informational diagnostic at the location.
+func (d *diagnostics) infof(rule rule, location location, format string, args ...interface{}) {
+	d.list = append(d.list, diagnostic{
+		rule:     rule,
+		severity: severityInfo,
+		location: location,
+		message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// warnf adds a warning diagnostic at the location.
+func (d *diagnostics) warnf(rule rule, location location, format string, args ...interface{}) {
+	d.list = append(d.list, diagnostic{
+		rule:     rule,
+		severity: severityWarning,
+		location: location,
+		message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// describe sets the descriptor of the diagnostics from index start on that have none.
+func (d *diagnostics) describe(start int, descriptor protoreflect.Descriptor) {
+	for i := start; i < len(d.list); i++ {
+		if d.list[i].descriptor == nil {
+			d.list[i].descriptor = descriptor
+		}
+	}
+}
+
+// effective returns the diagnostics with their effective severities.
+// In strict mode, warnings are reported as errors.
+func (d *diagnostics) effective(strict bool) []diagnostic {
+	result := make([]diagnostic, 0, len(d.list))
+	for _, diagnostic := range d.list {
+		if strict && diagnostic.severity == severityWarning {
+			diagnostic.severity = severityError
+		}
+		result = append(result, diagnostic)
+	}
+	return result
+}
+
+// report writes warnings to w and returns an error listing every error.
+func report(w io.Writer, diagnostics []diagnostic) error {
+	var errs []diagnostic
+	for _, diagnostic := range diagnostics {
+		if diagnostic.severity == severityError {
+			errs = append(errs, diagnostic)
+			continue
+		}
+		if _, err := fmt.Fprintln(w, diagnostic); err != nil {
+			return err
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "validate: %d problem(s) found", len(errs))
+	for _, err := range errs {
+		_, _ = fmt.Fprintf(&b, "\n\t%s", err)
+	}
+	return errors.New(b.String())
+}
@@ -0,0 +1,56 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	var found diagnostics
+	found.infof(ruleDuplicateConfig, location{file: "a.json", line: 1, column: 2}, "identical")
+	found.warnf(ruleDanglingName, location{file: "a.json", line: 3, column: 4}, "dangling")
+	found.errorf(ruleDuplicateName, location{file: "b.json"}, "duplicate")
+	found.errorf(ruleInvalidServiceConfig, location{file: "c.json", line: 1, column: 1}, "invalid")
+	for _, tt := range []struct {
+		name   string
+		strict bool
+		// output is the output of the diagnostics that are not errors.
+		output string
+		err    string
+	}{
+		{
+			name: "warnings are written and errors are aggregated",
+			output: "a.json:1:2: info: identical (DUPLICATE_CONFIG)\n" +
+				"a.json:3:4: warning: dangling (DANGLING_NAME)\n",
+			err: "validate: 2 problem(s) found\n" +
+				"\tb.json: error: duplicate (DUPLICATE_NAME)\n" +
+				"\tc.json:1:1: error: invalid (INVALID_SERVICE_CONFIG)",
+		},
+		{
+			name:   "warnings are errors in strict mode",
+			strict: true,
+			output: "a.json:1:2: info: identical (DUPLICATE_CONFIG)\n",
+			err: "validate: 3 problem(s) found\n" +
+				"\ta.json:3:4: error: dangling (DANGLING_NAME)\n" +
+				"\tb.json: error: duplicate (DUPLICATE_NAME)\n" +
+				"\tc.json:1:1: error: invalid (INVALID_SERVICE_CONFIG)",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			err := report(&output, found.effective(tt.strict))
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("expected error %q, got %v", tt.err, err)
+			}
+			if output.String() != tt.output {
+				t.Errorf("expected output %q, got %q", tt.output, output.String())
+			}
+		})
+	}
+	var valid diagnostics
+	valid.warnf(ruleDanglingName, location{file: "a.json"}, "dangling")
+	if err := report(&strings.Builder{}, valid.effective(false)); err != nil {
+		t.Errorf("expected no error for warnings, got %v", err)
+	}
+}
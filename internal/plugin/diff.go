@@ -0,0 +1,186 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// serviceConfigChange is a semantic change between two service configs.
+type serviceConfigChange struct {
+	// subject is what the change applies to, such as a method or the service config itself.
+	subject string
+	// message describes the change.
+	message string
+}
+
+// String implements fmt.Stringer.
+func (c serviceConfigChange) String() string {
+	return c.subject + ": " + c.message
+}
+
+// diffServiceConfigs returns the semantic changes from the old to the new service config JSON, comparing method
+// configs by the names they apply to.
+func diffServiceConfigs(oldData, newData []byte) ([]serviceConfigChange, error) {
+	oldValue, oldMethodConfigs, err := decodeServiceConfigForDiff(oldData)
+	if err != nil {
+		return nil, fmt.Errorf("old service config: %w", err)
+	}
+	newValue, newMethodConfigs, err := decodeServiceConfigForDiff(newData)
+	if err != nil {
+		return nil, fmt.Errorf("new service config: %w", err)
+	}
+	var result []serviceConfigChange
+	delete(oldValue, "methodConfig")
+	delete(newValue, "methodConfig")
+	for _, message := range diffJSONValues("", oldValue, newValue) {
+		result = append(result, serviceConfigChange{subject: "service config", message: message})
+	}
+	var names []nameJSON
+	seen := map[nameJSON]struct{}{}
+	for _, methodConfigs := range []methodConfigsByName{oldMethodConfigs, newMethodConfigs} {
+		for _, name := range methodConfigs.names {
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				names = append(names, name)
+			}
+		}
+	}
+	for _, name := range names {
+		oldMethodConfig, hadMethodConfig := oldMethodConfigs.byName[name]
+		newMethodConfig, hasMethodConfig := newMethodConfigs.byName[name]
+		subject := describeName(name)
+		switch {
+		case !hadMethodConfig:
+			result = append(result, serviceConfigChange{
+				subject: subject,
+				message: "method config added: " + describeJSONValue(newMethodConfig),
+			})
+		case !hasMethodConfig:
+			result = append(result, serviceConfigChange{
+				subject: subject,
+				message: "method config removed (was " + describeJSONValue(oldMethodConfig) + ")",
+			})
+		default:
+			for _, message := range diffJSONValues("", oldMethodConfig, newMethodConfig) {
+				result = append(result, serviceConfigChange{subject: subject, message: message})
+			}
+		}
+	}
+	return result, nil
+}
+
+// methodConfigsByName are the method configs of a service config, without their names, by the names they apply to.
+type methodConfigsByName struct {
+	// names are the names in the order they appear in the service config.
+	names []nameJSON
+	// byName maps names to the first method config they appear in.
+	byName map[nameJSON]map[string]interface{}
+}
+
+// decodeServiceConfigForDiff decodes a service config in canonical form, and indexes its method configs by name.
+func decodeServiceConfigForDiff(data []byte) (map[string]interface{}, methodConfigsByName, error) {
+	formatted, err := formatServiceConfig(data)
+	if err != nil {
+		return nil, methodConfigsByName{}, err
+	}
+	value, err := decodeJSONValue(formatted)
+	if err != nil {
+		return nil, methodConfigsByName{}, err
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, methodConfigsByName{}, fmt.Errorf("service config is not a JSON object")
+	}
+	var content serviceConfigJSON
+	if err := json.Unmarshal(formatted, &content); err != nil {
+		return nil, methodConfigsByName{}, err
+	}
+	result := methodConfigsByName{byName: map[nameJSON]map[string]interface{}{}}
+	methodConfigs, _ := object["methodConfig"].([]interface{})
+	for i, methodConfig := range content.MethodConfigs {
+		if i >= len(methodConfigs) {
+			break
+		}
+		methodConfigObject, ok := methodConfigs[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		delete(methodConfigObject, "name")
+		for _, name := range methodConfig.Names {
+			if _, ok := result.byName[name]; ok {
+				continue
+			}
+			result.byName[name] = methodConfigObject
+			result.names = append(result.names, name)
+		}
+	}
+	return object, result, nil
+}
+
+// describeName returns a human-readable description of what a method config name applies to.
+func describeName(name nameJSON) string {
+	switch {
+	case name.Service == "" && name.Method == "":
+		return "default method config"
+	case name.Method == "":
+		return "service " + name.Service
+	}
+	return "method " + name.Service + "/" + name.Method
+}
+
+// diffJSONValues returns human-readable descriptions of the differences between two decoded JSON values.
+// Objects are compared field by field, and other values are compared as a whole.
+func diffJSONValues(path string, oldValue, newValue interface{}) []string {
+	oldObject, oldIsObject := oldValue.(map[string]interface{})
+	newObject, newIsObject := newValue.(map[string]interface{})
+	if !oldIsObject || !newIsObject {
+		if describeJSONValue(oldValue) == describeJSONValue(newValue) {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s %s → %s", path, describeJSONValue(oldValue), describeJSONValue(newValue))}
+	}
+	keys := make([]string, 0, len(oldObject)+len(newObject))
+	for key := range oldObject {
+		keys = append(keys, key)
+	}
+	for key := range newObject {
+		if _, ok := oldObject[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	var result []string
+	for _, key := range keys {
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		oldField, hadField := oldObject[key]
+		newField, hasField := newObject[key]
+		switch {
+		case !hadField:
+			result = append(result, fmt.Sprintf("%s added: %s", fieldPath, describeJSONValue(newField)))
+		case !hasField:
+			result = append(result, fmt.Sprintf("%s removed (was %s)", fieldPath, describeJSONValue(oldField)))
+		default:
+			result = append(result, diffJSONValues(fieldPath, oldField, newField)...)
+		}
+	}
+	return result
+}
+
+// describeJSONValue returns a compact description of a decoded JSON value. Strings are not quoted.
+func describeJSONValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(bytes.TrimSpace(b.Bytes()))
+}
@@ -0,0 +1,78 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffServiceConfigs(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		old      string
+		new      string
+		expected []string
+	}{
+		{
+			name: "no changes",
+			old: `{"methodConfig": [
+  {"name": [{}], "timeout": "10s"},
+  {"name": [{"service": "a.B", "method": "C"}], "timeout": "1s"}
+]}`,
+			new: `{"methodConfig": [
+  {"name": [{"service": "a.B", "method": "C"}], "timeout": "1.000s"},
+  {"name": [{}], "timeout": "10s"}
+]}`,
+		},
+		{
+			name: "changed and removed fields",
+			old: `{"methodConfig": [{"name": [{"service": "a.B", "method": "C"}], "timeout": "5s", "retryPolicy": {
+  "maxAttempts": 3, "initialBackoff": "0.1s", "maxBackoff": "1s", "backoffMultiplier": 2,
+  "retryableStatusCodes": ["UNAVAILABLE"]
+}}]}`,
+			new: `{"methodConfig": [{"name": [{"service": "a.B", "method": "C"}], "timeout": "2s", "waitForReady": true}]}`,
+			expected: []string{
+				"method a.B/C: retryPolicy removed (was {\"backoffMultiplier\":2,\"initialBackoff\":\"0.1s\"," +
+					"\"maxAttempts\":3,\"maxBackoff\":\"1s\",\"retryableStatusCodes\":[\"UNAVAILABLE\"]})",
+				"method a.B/C: timeout 5s → 2s",
+				"method a.B/C: waitForReady added: true",
+			},
+		},
+		{
+			name: "added and removed method configs",
+			old:  `{"methodConfig": [{"name": [{"service": "a.B"}], "timeout": "1s"}]}`,
+			new:  `{"methodConfig": [{"name": [{}], "timeout": "1s"}]}`,
+			expected: []string{
+				`service a.B: method config removed (was {"timeout":"1s"})`,
+				`default method config: method config added: {"timeout":"1s"}`,
+			},
+		},
+		{
+			name: "service config fields",
+			old:  `{"loadBalancingConfig": [{"round_robin": {}}], "retryThrottling": {"maxTokens": 10, "tokenRatio": 0.1}}`,
+			new:  `{"loadBalancingConfig": [{"pick_first": {}}], "retryThrottling": {"maxTokens": 5, "tokenRatio": 0.1}}`,
+			expected: []string{
+				`service config: loadBalancingConfig [{"round_robin":{}}] → [{"pick_first":{}}]`,
+				"service config: retryThrottling.maxTokens 10 → 5",
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := diffServiceConfigs([]byte(tt.old), []byte(tt.new))
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := make([]string, 0, len(changes))
+			for _, change := range changes {
+				actual = append(actual, change.String())
+			}
+			if expected := strings.Join(tt.expected, "\n"); strings.Join(actual, "\n") != expected {
+				t.Errorf("expected changes:\n%s\ngot:\n%s", expected, strings.Join(actual, "\n"))
+			}
+		})
+	}
+	if _, err := diffServiceConfigs([]byte(`[]`), []byte(`{}`)); err == nil ||
+		err.Error() != "old service config: service config is not a JSON object" {
+		t.Errorf("expected an error for a service config that is not an object, got %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// serviceConfigJSONSuffix is the suffix of service config JSON file names.
+const serviceConfigJSONSuffix = "_grpc_service_config.json"
+
+// doctorFinding is a problem with the conventions of a repository, with how to fix it.
+type doctorFinding struct {
+	// priority orders findings, where findings with lower priorities should be fixed first.
+	priority int
+	// kind is a short description of the kind of problem.
+	kind string
+	// file is the file with the problem.
+	file string
+	// message describes the problem.
+	message string
+	// fix describes how to fix the problem.
+	fix string
+}
+
+// Priorities of doctor findings. Conflicts and misnamed files come first, since they silently change or drop service
+// configs that are expected to be used.
+const (
+	doctorPriorityConflict = iota + 1
+	doctorPriorityMisnamed
+	doctorPriorityOrphaned
+	doctorPriorityMissing
+)
+
+// doctorPackage is a proto package with services to generate, and where its service configs are resolved from.
+type doctorPackage struct {
+	name protoreflect.FullName
+	// jsonFile is the service config JSON file the package resolves.
+	jsonFile string
+	// annotationFile is the proto file with the default_service_config annotation of the package, if any.
+	annotationFile string
+	// required is true when a service of the package is not exempt from requiring a service config.
+	required bool
+}
+
+// doctor returns the problems with the service config conventions of the path directory and the services to
+// generate, sorted by priority.
+func (p *plugin) doctor() ([]doctorFinding, error) {
+	packagesByJSONFile := map[string]*doctorPackage{}
+	var packages []*doctorPackage
+	for _, file := range p.gen.Files {
+		if !file.Generate {
+			continue
+		}
+		for _, service := range file.Services {
+			jsonFile := filepath.Clean(p.resolveServiceConfigJSONFile(service.Desc))
+			pkg, ok := packagesByJSONFile[jsonFile]
+			if !ok {
+				pkg = &doctorPackage{name: file.Desc.Package(), jsonFile: jsonFile}
+				annotation, ok, err := p.resolveServiceConfigFromFileAnnotation(service.Desc)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					pkg.annotationFile = annotation.source
+				}
+				packagesByJSONFile[jsonFile] = pkg
+				packages = append(packages, pkg)
+			}
+			exempt, _, err := p.serviceExemption(service.Desc)
+			if err != nil {
+				return nil, err
+			}
+			pkg.required = pkg.required || !exempt
+		}
+	}
+	var result []doctorFinding
+	// misnamedDirs are the directories of packages with a misnamed service config file.
+	misnamedDirs := map[string]struct{}{}
+	candidates, err := serviceConfigFileCandidates(p.path)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range candidates {
+		if _, ok := packagesByJSONFile[candidate]; ok {
+			continue
+		}
+		if isServiceConfigVariant(packages, candidate) {
+			continue
+		}
+		var expected *doctorPackage
+		for _, pkg := range packages {
+			if filepath.Dir(pkg.jsonFile) == filepath.Dir(candidate) {
+				expected = pkg
+				break
+			}
+		}
+		switch {
+		case expected != nil && !fileExists(expected.jsonFile):
+			misnamedDirs[filepath.Dir(candidate)] = struct{}{}
+			result = append(result, doctorFinding{
+				priority: doctorPriorityMisnamed,
+				kind:     "misnamed",
+				file:     candidate,
+				message: fmt.Sprintf(
+					"service config file is ignored, since package %s expects another name",
+					expected.name,
+				),
+				fix: fmt.Sprintf("rename it to %s", filepath.Base(expected.jsonFile)),
+			})
+		case strings.HasSuffix(candidate, serviceConfigJSONSuffix) && expected == nil:
+			result = append(result, doctorFinding{
+				priority: doctorPriorityOrphaned,
+				kind:     "orphaned",
+				file:     candidate,
+				message:  "service config file is not in the directory of a proto package with services",
+				fix:      "move it next to the proto files of its package, or remove it",
+			})
+		case strings.HasSuffix(candidate, serviceConfigJSONSuffix):
+			result = append(result, doctorFinding{
+				priority: doctorPriorityOrphaned,
+				kind:     "orphaned",
+				file:     candidate,
+				message: fmt.Sprintf(
+					"service config file is ignored, since package %s uses %s",
+					expected.name,
+					filepath.Base(expected.jsonFile),
+				),
+				fix: "merge it into the service config file of the package, or remove it",
+			})
+		}
+	}
+	for _, pkg := range packages {
+		hasJSONFile := fileExists(pkg.jsonFile)
+		switch {
+		case hasJSONFile && pkg.annotationFile != "":
+			result = append(result, doctorFinding{
+				priority: doctorPriorityConflict,
+				kind:     "conflict",
+				file:     pkg.jsonFile,
+				message: fmt.Sprintf(
+					"package %s also has a %s annotation in %s, and which one is used depends on the conflict option",
+					pkg.name,
+					defaultServiceConfigExtension,
+					pkg.annotationFile,
+				),
+				fix: "remove the annotation or the file, or set the conflict option explicitly",
+			})
+		case !hasJSONFile && pkg.annotationFile == "" && pkg.required:
+			if _, ok := misnamedDirs[filepath.Dir(pkg.jsonFile)]; ok {
+				continue
+			}
+			result = append(result, doctorFinding{
+				priority: doctorPriorityMissing,
+				kind:     "missing",
+				file:     pkg.jsonFile,
+				message:  fmt.Sprintf("package %s has services without a service config", pkg.name),
+				fix:      fmt.Sprintf("run grpc-service-config init -package=%s, or exempt the services", pkg.name),
+			})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].priority != result[j].priority {
+			return result[i].priority < result[j].priority
+		}
+		return result[i].file < result[j].file
+	})
+	return result, nil
+}
+
+// isServiceConfigVariant returns true if the file is a per-environment variant of the service config JSON file of
+// one of the packages.
+func isServiceConfigVariant(packages []*doctorPackage, filename string) bool {
+	for _, pkg := range packages {
+		if _, ok := variantEnvironment(pkg.jsonFile, filename); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceConfigFileCandidates returns the files in the directory tree that look like service config JSON files:
+// files named like service config files, and JSON files with service config fields. Hidden directories are skipped.
+func serviceConfigFileCandidates(root string) ([]string, error) {
+	var result []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(entry.Name(), serviceConfigJSONSuffix) {
+			result = append(result, filepath.Clean(path))
+			return nil
+		}
+		if strings.ToLower(filepath.Ext(entry.Name())) != ".json" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if looksLikeServiceConfig(data) {
+			result = append(result, filepath.Clean(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("doctor: %w", err)
+	}
+	return result, nil
+}
+
+// looksLikeServiceConfig returns true if the data is a JSON object with a top-level service config field.
+func looksLikeServiceConfig(data []byte) bool {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return false
+	}
+	for _, field := range []string{
+		"methodConfig",
+		"loadBalancingConfig",
+		"loadBalancingPolicy",
+		"retryThrottling",
+		"healthCheckConfig",
+	} {
+		if _, ok := object[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// fileExists returns true if the file exists.
+func fileExists(filename string) bool {
+	_, err := os.Stat(filename)
+	return err == nil
+}
+
+// writeDoctorFindings writes a numbered fix list of findings, in priority order.
+func writeDoctorFindings(w io.Writer, findings []doctorFinding) error {
+	for i, finding := range findings {
+		if _, err := fmt.Fprintf(
+			w,
+			"%d. %s: %s: %s\n   fix: %s\n",
+			i+1,
+			finding.kind,
+			finding.file,
+			finding.message,
+			finding.fix,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
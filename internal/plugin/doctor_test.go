@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// serviceConfigForDoctor is a service config JSON file found by the doctor command.
+const serviceConfigForDoctor = `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`
+
+func TestDoctor(t *testing.T) {
+	const freightDir = "einride/example/freight/v1/"
+	for _, tt := range []struct {
+		name  string
+		files map[string]string
+		// expected is the fix list, with $dir replaced with the input path of service config JSON files.
+		expected string
+	}{
+		{
+			name:  "conventional",
+			files: map[string]string{testFreightServiceConfigFile: serviceConfigForDoctor},
+		},
+		{
+			name: "missing",
+			expected: "1. missing: $dir/" + testFreightServiceConfigFile +
+				": package einride.example.freight.v1 has services without a service config\n" +
+				"   fix: run grpc-service-config init -package=einride.example.freight.v1, or exempt the services\n",
+		},
+		{
+			name:  "misnamed",
+			files: map[string]string{freightDir + "freight_service_config.json": serviceConfigForDoctor},
+			expected: "1. misnamed: $dir/" + freightDir + "freight_service_config.json: service config file is ignored, " +
+				"since package einride.example.freight.v1 expects another name\n" +
+				"   fix: rename it to freight_grpc_service_config.json\n",
+		},
+		{
+			name: "orphaned",
+			files: map[string]string{
+				testFreightServiceConfigFile:                                serviceConfigForDoctor,
+				"einride/example/legacy/v1/legacy_grpc_service_config.json": serviceConfigForDoctor,
+			},
+			expected: "1. orphaned: $dir/einride/example/legacy/v1/legacy_grpc_service_config.json: " +
+				"service config file is not in the directory of a proto package with services\n" +
+				"   fix: move it next to the proto files of its package, or remove it\n",
+		},
+		{
+			name: "ignored in package directory",
+			files: map[string]string{
+				testFreightServiceConfigFile:                    serviceConfigForDoctor,
+				freightDir + "shipper_grpc_service_config.json": serviceConfigForDoctor,
+			},
+			expected: "1. orphaned: $dir/" + freightDir + "shipper_grpc_service_config.json: service config file is " +
+				"ignored, since package einride.example.freight.v1 uses freight_grpc_service_config.json\n" +
+				"   fix: merge it into the service config file of the package, or remove it\n",
+		},
+		{
+			name: "per-environment variant",
+			files: map[string]string{
+				testFreightServiceConfigFile:                            serviceConfigForDoctor,
+				freightDir + "freight_grpc_service_config.staging.json": serviceConfigForDoctor,
+			},
+		},
+		{
+			name: "priority order",
+			files: map[string]string{
+				"einride/example/legacy/v1/legacy_grpc_service_config.json": serviceConfigForDoctor,
+				"a/package.json":                    `{"name": "not a service config"}`,
+				".cache/x_grpc_service_config.json": serviceConfigForDoctor,
+			},
+			expected: "1. orphaned: $dir/einride/example/legacy/v1/legacy_grpc_service_config.json: " +
+				"service config file is not in the directory of a proto package with services\n" +
+				"   fix: move it next to the proto files of its package, or remove it\n" +
+				"2. missing: $dir/" + testFreightServiceConfigFile +
+				": package einride.example.freight.v1 has services without a service config\n" +
+				"   fix: run grpc-service-config init -package=einride.example.freight.v1, or exempt the services\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTestFiles(t, tt.files)
+			p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+			findings, err := p.doctor()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var output strings.Builder
+			if err := writeDoctorFindings(&output, findings); err != nil {
+				t.Fatal(err)
+			}
+			expected := strings.ReplaceAll(tt.expected, "$dir/", filepath.Clean(dir)+string(filepath.Separator))
+			if output.String() != expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", expected, output.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	// stdinFilename is the file name commands read a service config from stdin for.
+	stdinFilename = "-"
+	// stdinSource is the name of a service config read from stdin in diagnostics.
+	stdinSource = "<stdin>"
+)
+
+var (
+	// utf8BOM is the UTF-8 byte order mark.
+	utf8BOM = []byte{0xef, 0xbb, 0xbf}
+	// utf16BEBOM is the UTF-16 big-endian byte order mark.
+	utf16BEBOM = []byte{0xfe, 0xff}
+	// utf16LEBOM is the UTF-16 little-endian byte order mark.
+	utf16LEBOM = []byte{0xff, 0xfe}
+)
+
+// stdin is the content of stdin, read once, since several steps of a command may read the same service config.
+var stdin struct {
+	once sync.Once
+	data []byte
+	err  error
+}
+
+// readStdin reads stdin to the end, once.
+func readStdin() ([]byte, error) {
+	stdin.once.Do(func() {
+		stdin.data, stdin.err = io.ReadAll(os.Stdin)
+	})
+	return stdin.data, stdin.err
+}
+
+// readServiceConfigFile reads a service config JSON file, or stdin for the file name "-", and normalizes its encoding.
+// A UTF-8 byte order mark is removed and Windows line endings are replaced, since both are common artifacts of
+// editors. Files that are not UTF-8 encoded are rejected.
+func readServiceConfigFile(filename string) ([]byte, error) {
+	var data []byte
+	var err error
+	if filename == stdinFilename {
+		data, err = readStdin()
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err = normalizeServiceConfigEncoding(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service config file %s: %w", filename, err)
+	}
+	return data, nil
+}
+
+// normalizeServiceConfigEncoding removes a UTF-8 byte order mark and replaces Windows line endings, and returns an
+// error if the data is not UTF-8 encoded.
+func normalizeServiceConfigEncoding(data []byte) ([]byte, error) {
+	if bytes.HasPrefix(data, utf16BEBOM) || bytes.HasPrefix(data, utf16LEBOM) || isUTF16WithoutBOM(data) {
+		return nil, fmt.Errorf("file is UTF-16 encoded, save it as UTF-8 instead")
+	}
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if !utf8.Valid(data) {
+		offset := invalidUTF8Offset(data)
+		line := bytes.Count(data[:offset], []byte("\n")) + 1
+		return nil, fmt.Errorf("invalid UTF-8 at line %d (byte offset %d), save the file as UTF-8", line, offset)
+	}
+	if bytes.Contains(data, []byte("\r")) {
+		data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+		data = bytes.ReplaceAll(data, []byte("\r"), []byte("\n"))
+	}
+	return data, nil
+}
+
+// isUTF16WithoutBOM returns true if the data looks like UTF-16 without a byte order mark.
+// JSON documents start with an ASCII character, which is encoded with a zero byte in UTF-16.
+func isUTF16WithoutBOM(data []byte) bool {
+	return len(data) >= 2 && (data[0] == 0) != (data[1] == 0)
+}
+
+// invalidUTF8Offset returns the byte offset of the first invalid UTF-8 sequence in the data.
+func invalidUTF8Offset(data []byte) int {
+	for offset := 0; offset < len(data); {
+		r, size := utf8.DecodeRune(data[offset:])
+		if r == utf8.RuneError && size <= 1 {
+			return offset
+		}
+		offset += size
+	}
+	return len(data)
+}
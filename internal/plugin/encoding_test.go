@@ -0,0 +1,75 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeServiceConfigEncoding(t *testing.T) {
+	const utf16Error = "file is UTF-16 encoded, save it as UTF-8 instead"
+	for _, tt := range []struct {
+		name     string
+		input    string
+		expected string
+		err      string
+	}{
+		{name: "UTF-8", input: "{\n}", expected: "{\n}"},
+		{name: "UTF-8 BOM", input: "\xef\xbb\xbf{\n}", expected: "{\n}"},
+		{
+			name:     "Windows line endings",
+			input:    "{\r\n  \"methodConfig\": []\r\n}",
+			expected: "{\n  \"methodConfig\": []\n}",
+		},
+		{name: "classic Mac line endings", input: "{\r}", expected: "{\n}"},
+		{name: "UTF-16 big-endian BOM", input: "\xfe\xff\x00{\x00}", err: utf16Error},
+		{name: "UTF-16 little-endian BOM", input: "\xff\xfe{\x00}\x00", err: utf16Error},
+		{name: "UTF-16 without BOM", input: "{\x00}\x00", err: utf16Error},
+		{
+			name:  "invalid UTF-8",
+			input: "{\n  \"a\": \"\xe9\"\n}",
+			err:   "invalid UTF-8 at line 2 (byte offset 10), save the file as UTF-8",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := normalizeServiceConfigEncoding([]byte(tt.input))
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReadServiceConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "service_config.json")
+	if err := os.WriteFile(filename, []byte("\xef\xbb\xbf{}\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	data, err := readServiceConfigFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "{}\n" {
+		t.Errorf("expected %q, got %q", "{}\n", data)
+	}
+	if err := os.WriteFile(filename, []byte("{\"\xff\"}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = readServiceConfigFile(filename)
+	if expected := "invalid service config file " + filename + ": invalid UTF-8"; err == nil ||
+		!strings.HasPrefix(err.Error(), expected) {
+		t.Errorf("expected error with prefix %q, got %v", expected, err)
+	}
+}
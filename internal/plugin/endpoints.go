@@ -0,0 +1,77 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+)
+
+// endpointsBackendRule is a backend rule of a google.api.Service configuration.
+type endpointsBackendRule struct {
+	// selector is the full name of the method, such as einride.example.freight.v1.FreightService.GetShipper.
+	selector string
+	// deadline is the deadline of the method in seconds.
+	deadline float64
+}
+
+// endpointsBackendRules returns a backend rule with the timeout of the method config of every method of the services
+// of a package with a timeout.
+func endpointsBackendRules(serviceConfig *packageServiceConfig) ([]endpointsBackendRule, error) {
+	var config serviceConfigJSON
+	if err := json.Unmarshal([]byte(serviceConfig.json), &config); err != nil {
+		return nil, err
+	}
+	var result []endpointsBackendRule
+	for _, service := range serviceConfig.services {
+		for i := 0; i < service.Methods().Len(); i++ {
+			method := service.Methods().Get(i)
+			index, ok := config.methodConfigFor(method)
+			if !ok || config.MethodConfigs[index].Timeout == nil {
+				continue
+			}
+			timeout, err := configjson.ParseDuration(*config.MethodConfigs[index].Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("%s: methodConfig[%d].timeout: %w", method.FullName(), index, err)
+			}
+			result = append(result, endpointsBackendRule{
+				selector: string(method.FullName()),
+				deadline: timeout.Seconds(),
+			})
+		}
+	}
+	return result, nil
+}
+
+// generateEndpointsBackendRules writes the backend rules of a google.api.Service configuration with the timeouts of
+// the resolved service config of every package as deadlines to the directory, as
+// <dir>/<package path>/api_service_backend.yaml, for Cloud Endpoints and API Gateway deployments.
+func (p *plugin) generateEndpointsBackendRules(dir string) error {
+	serviceConfigs, err := p.packageServiceConfigs()
+	if err != nil {
+		return err
+	}
+	for _, serviceConfig := range serviceConfigs {
+		rules, err := endpointsBackendRules(serviceConfig)
+		if err != nil {
+			return fmt.Errorf("emit %s backend rules: %w", serviceConfig.pkg, err)
+		}
+		g := p.gen.NewGeneratedFile(path.Join(dir, packagePath(serviceConfig.pkg), "api_service_backend.yaml"), "")
+		g.P("# Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.")
+		g.P("# Source: ", serviceConfig.source)
+		g.P("# Backend rules of package ", serviceConfig.pkg, ", to merge into a google.api.Service configuration.")
+		g.P("backend:")
+		if len(rules) == 0 {
+			g.P("  rules: []")
+			continue
+		}
+		g.P("  rules:")
+		for _, rule := range rules {
+			g.P("  - selector: ", rule.selector)
+			g.P("    deadline: ", strconv.FormatFloat(rule.deadline, 'f', -1, 64))
+		}
+	}
+	return nil
+}
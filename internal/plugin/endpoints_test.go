@@ -0,0 +1,64 @@
+package plugin
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateEndpointsBackendRules(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		serviceConfig string
+		// expected is the generated file after the source comment.
+		expected string
+	}{
+		{
+			name: "deadlines",
+			serviceConfig: `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s"},
+    {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}], "timeout": "1.5s"}
+  ]
+}`,
+			expected: `# Backend rules of package einride.example.freight.v1, to merge into a google.api.Service configuration.
+backend:
+  rules:
+  - selector: einride.example.freight.v1.FreightService.GetShipper
+    deadline: 1.5
+  - selector: einride.example.freight.v1.FreightService.CreateShipper
+    deadline: 10
+`,
+		},
+		{
+			name:          "no timeouts",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "waitForReady": true}]}`,
+			expected: `# Backend rules of package einride.example.freight.v1, to merge into a google.api.Service configuration.
+backend:
+  rules: []
+`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTestFiles(t, map[string]string{testFreightServiceConfigFile: tt.serviceConfig})
+			p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testRouteServiceFile))
+			if err := p.generateEndpointsBackendRules("endpoints"); err != nil {
+				t.Fatal(err)
+			}
+			files := p.gen.Response().GetFile()
+			if len(files) != 1 {
+				t.Fatalf("expected 1 generated file, got %d", len(files))
+			}
+			const expectedName = "endpoints/einride/example/freight/v1/api_service_backend.yaml"
+			if actual := files[0].GetName(); actual != expectedName {
+				t.Errorf("expected name %s, got %s", expectedName, actual)
+			}
+			expected := "# Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.\n" +
+				"# Source: " + filepath.Join(dir, testFreightServiceConfigFile) + "\n" +
+				tt.expected
+			if actual := files[0].GetContent(); actual != expected {
+				t.Errorf("expected content:\n%s\ngot:\n%s", expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package plugin
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// environmentPattern matches the environment names of per-environment service config JSON files.
+var environmentPattern = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// serviceConfigVariant is a per-environment variant of a service config JSON file.
+type serviceConfigVariant struct {
+	// environment is the name of the environment, such as "staging".
+	environment string
+	// filename is the name of the variant file.
+	filename string
+}
+
+// serviceConfigVariants returns the per-environment variants of a service config JSON file, named like the file with
+// the environment before the extension, such as example_grpc_service_config.staging.json, sorted by environment.
+func serviceConfigVariants(serviceConfigFile string) ([]serviceConfigVariant, error) {
+	matches, err := filepath.Glob(globEscape(strings.TrimSuffix(serviceConfigFile, ".json")) + ".*.json")
+	if err != nil {
+		return nil, err
+	}
+	var result []serviceConfigVariant
+	for _, match := range matches {
+		if environment, ok := variantEnvironment(serviceConfigFile, match); ok {
+			result = append(result, serviceConfigVariant{environment: environment, filename: match})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].environment < result[j].environment
+	})
+	return result, nil
+}
+
+// variantEnvironment returns the environment of a per-environment variant of a service config JSON file, or false if
+// the file is not a variant of the service config JSON file.
+func variantEnvironment(serviceConfigFile, filename string) (string, bool) {
+	if filepath.Dir(filename) != filepath.Dir(serviceConfigFile) {
+		return "", false
+	}
+	prefix := strings.TrimSuffix(filepath.Base(serviceConfigFile), ".json") + "."
+	base := filepath.Base(filename)
+	if !strings.HasPrefix(base, prefix) || !strings.HasSuffix(base, ".json") {
+		return "", false
+	}
+	environment := strings.TrimSuffix(strings.TrimPrefix(base, prefix), ".json")
+	if !environmentPattern.MatchString(environment) {
+		return "", false
+	}
+	return environment, true
+}
+
+// globEscape escapes the characters with special meaning in filepath.Match patterns.
+func globEscape(s string) string {
+	return strings.NewReplacer(`*`, `[*]`, `?`, `[?]`, `[`, `[[]`).Replace(s)
+}
+
+// environmentConstantName returns the name of the generated constant of the service config of an environment, such
+// as ServiceConfigStaging.
+func environmentConstantName(environment string) string {
+	var b strings.Builder
+	b.WriteString("ServiceConfig")
+	for _, part := range strings.Split(environment, "_") {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// generateServiceConfigVariants generates a constant for each per-environment variant of a service config JSON file,
+// and a ServiceConfigFor function returning the service config of an environment, where the empty environment is
+// the ServiceConfig constant.
+func (p *plugin) generateServiceConfigVariants(g *protogen.GeneratedFile, serviceConfigFile string) error {
+	variants, err := serviceConfigVariants(serviceConfigFile)
+	if err != nil {
+		return err
+	}
+	if len(variants) == 0 {
+		return nil
+	}
+	constants := make(map[string]string, len(variants))
+	for _, variant := range variants {
+		constant := environmentConstantName(variant.environment)
+		if previous, ok := constants[constant]; ok {
+			return fmt.Errorf(
+				"%s: environments %s and %s both generate the constant %s",
+				serviceConfigFile,
+				previous,
+				variant.environment,
+				constant,
+			)
+		}
+		constants[constant] = variant.environment
+		serviceConfig, err := p.readGeneratedServiceConfig(variant.filename)
+		if err != nil {
+			return err
+		}
+		g.P()
+		g.P("// ", constant, " is the service config for all services in the package in the ", variant.environment,
+			" environment.")
+		g.P("// Source: ", filepath.Base(variant.filename), ".")
+		writeServiceConfigConstant(g, constant, serviceConfig)
+	}
+	g.P()
+	g.P("// ServiceConfigFor returns the service config for all services in the package in the environment.")
+	g.P("// The empty environment returns ServiceConfig.")
+	g.P("func ServiceConfigFor(environment string) (string, error) {")
+	g.P("switch environment {")
+	g.P(`case "":`)
+	g.P("return ServiceConfig, nil")
+	for _, variant := range variants {
+		g.P("case ", fmt.Sprintf("%q", variant.environment), ":")
+		g.P("return ", environmentConstantName(variant.environment), ", nil")
+	}
+	g.P("}")
+	g.P("return \"\", ", fmtPackage.Ident("Errorf"), `("no service config for environment %q", environment)`)
+	g.P("}")
+	return nil
+}
+
+// fmtPackage is the import path of the fmt package, used by generated code.
+const fmtPackage = protogen.GoImportPath("fmt")
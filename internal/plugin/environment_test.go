@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateServiceConfigVariants(t *testing.T) {
+	const (
+		serviceConfig        = `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`
+		stagingServiceConfig = `{"methodConfig": [{"name": [{}], "timeout": "20s"}]}`
+	)
+	variantFile := func(environment string) string {
+		return strings.TrimSuffix(testFreightServiceConfigFile, ".json") + "." + environment + ".json"
+	}
+	for _, tt := range []struct {
+		name  string
+		files map[string]string
+		// expected is the generated code after the ServiceConfig constant, or empty for no variants.
+		expected string
+		err      string
+	}{
+		{
+			name:  "no variants",
+			files: map[string]string{testFreightServiceConfigFile: serviceConfig},
+		},
+		{
+			name: "variants",
+			files: map[string]string{
+				testFreightServiceConfigFile: serviceConfig,
+				variantFile("staging"):       stagingServiceConfig,
+				variantFile("eu_west"):       serviceConfig,
+				variantFile("Invalid"):       serviceConfig,
+			},
+			expected: `
+// ServiceConfigEuWest is the service config for all services in the package in the eu_west environment.
+// Source: freight_grpc_service_config.eu_west.json.
+const ServiceConfigEuWest = ` + "`" + serviceConfig + "`" + `
+
+// ServiceConfigStaging is the service config for all services in the package in the staging environment.
+// Source: freight_grpc_service_config.staging.json.
+const ServiceConfigStaging = ` + "`" + stagingServiceConfig + "`" + `
+
+// ServiceConfigFor returns the service config for all services in the package in the environment.
+// The empty environment returns ServiceConfig.
+func ServiceConfigFor(environment string) (string, error) {
+	switch environment {
+	case "":
+		return ServiceConfig, nil
+	case "eu_west":
+		return ServiceConfigEuWest, nil
+	case "staging":
+		return ServiceConfigStaging, nil
+	}
+	return "", fmt.Errorf("no service config for environment %q", environment)
+}
+`,
+		},
+		{
+			name: "colliding constants",
+			files: map[string]string{
+				testFreightServiceConfigFile: serviceConfig,
+				variantFile("a1b"):           serviceConfig,
+				variantFile("a_1b"):          serviceConfig,
+			},
+			err: "environments a1b and a_1b both generate the constant ServiceConfigA1b",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTestFiles(t, tt.files)
+			p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+			err := p.generateFromJSON()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			files := p.gen.Response().GetFile()
+			if len(files) != 1 {
+				t.Fatalf("expected 1 generated file, got %d", len(files))
+			}
+			content := files[0].GetContent()
+			constant := "const ServiceConfig = `" + serviceConfig + "`\n"
+			i := strings.Index(content, constant)
+			if i == -1 {
+				t.Fatalf("expected the ServiceConfig constant in:\n%s", content)
+			}
+			if actual := content[i+len(constant):]; actual != tt.expected {
+				t.Errorf("expected after the ServiceConfig constant:\n%s\ngot:\n%s", tt.expected, actual)
+			}
+		})
+	}
+}
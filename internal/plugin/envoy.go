@@ -0,0 +1,69 @@
+package plugin
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// generateEnvoyRoutes writes Envoy routes with the timeouts and retry policies of the resolved service config of
+// every package to the directory, as <dir>/<package path>/envoy_routes.yaml, so that edge proxies mirror the retry
+// policies of clients. The routes are a list to paste into the routes of an Envoy virtual host, and send calls to the
+// cluster, which defaults to the package name.
+func (p *plugin) generateEnvoyRoutes(dir, cluster string) error {
+	serviceConfigs, err := p.packageServiceConfigs()
+	if err != nil {
+		return err
+	}
+	for _, serviceConfig := range serviceConfigs {
+		packageCluster := cluster
+		if packageCluster == "" {
+			packageCluster = string(serviceConfig.pkg)
+		}
+		routeConfiguration, err := xdsRouteConfigurationFor(serviceConfig, packageCluster)
+		if err != nil {
+			return fmt.Errorf("emit %s Envoy routes: %w", serviceConfig.pkg, err)
+		}
+		g := p.gen.NewGeneratedFile(path.Join(dir, packagePath(serviceConfig.pkg), "envoy_routes.yaml"), "")
+		g.P("# Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.")
+		g.P("# Source: ", serviceConfig.source)
+		g.P("# Envoy routes of package ", serviceConfig.pkg, ", to paste into the routes of a virtual host.")
+		for _, virtualHost := range routeConfiguration.VirtualHosts {
+			for _, route := range virtualHost.Routes {
+				writeEnvoyRoute(g, route)
+			}
+		}
+	}
+	return nil
+}
+
+// writeEnvoyRoute writes a route in the Envoy YAML format. gRPC deadlines span all attempts of a call, so the
+// timeout of each attempt is the timeout of the call, which Envoy would otherwise only apply to the whole call.
+func writeEnvoyRoute(g *protogen.GeneratedFile, route xdsRoute) {
+	g.P("- name: ", strconv.Quote(route.Name))
+	g.P("  match:")
+	g.P("    path: ", strconv.Quote(route.Match.Path))
+	g.P("  route:")
+	g.P("    cluster: ", strconv.Quote(route.Route.Cluster))
+	if route.Route.Timeout != "" {
+		g.P("    timeout: ", route.Route.Timeout)
+	}
+	if route.Route.RetryPolicy == nil {
+		return
+	}
+	g.P("    retry_policy:")
+	g.P("      retry_on: ", strconv.Quote(route.Route.RetryPolicy.RetryOn))
+	g.P("      num_retries: ", route.Route.RetryPolicy.NumRetries)
+	if route.Route.Timeout != "" {
+		g.P("      per_try_timeout: ", route.Route.Timeout)
+	}
+	if backOff := route.Route.RetryPolicy.RetryBackOff; backOff != nil {
+		g.P("      retry_back_off:")
+		g.P("        base_interval: ", backOff.BaseInterval)
+		if backOff.MaxInterval != "" {
+			g.P("        max_interval: ", backOff.MaxInterval)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package plugin
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateEnvoyRoutes(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{testFreightServiceConfigFile: testRouteServiceConfig})
+	p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testRouteServiceFile))
+	if err := p.generateEnvoyRoutes("envoy", ""); err != nil {
+		t.Fatal(err)
+	}
+	files := p.gen.Response().GetFile()
+	if len(files) != 1 {
+		t.Fatalf("expected 1 generated file, got %d", len(files))
+	}
+	const expectedName = "envoy/einride/example/freight/v1/envoy_routes.yaml"
+	if actual := files[0].GetName(); actual != expectedName {
+		t.Errorf("expected name %s, got %s", expectedName, actual)
+	}
+	expected := `# Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.
+# Source: ` + filepath.Join(dir, testFreightServiceConfigFile) + `
+# Envoy routes of package einride.example.freight.v1, to paste into the routes of a virtual host.
+- name: "/einride.example.freight.v1.FreightService/GetShipper"
+  match:
+    path: "/einride.example.freight.v1.FreightService/GetShipper"
+  route:
+    cluster: "einride.example.freight.v1"
+    timeout: 1s
+    retry_policy:
+      retry_on: "unavailable"
+      num_retries: 2
+      per_try_timeout: 1s
+      retry_back_off:
+        base_interval: 0.1s
+- name: "/einride.example.freight.v1.FreightService/CreateShipper"
+  match:
+    path: "/einride.example.freight.v1.FreightService/CreateShipper"
+  route:
+    cluster: "einride.example.freight.v1"
+    timeout: 10s
+`
+	if actual := files[0].GetContent(); actual != expected {
+		t.Errorf("expected content:\n%s\ngot:\n%s", expected, actual)
+	}
+}
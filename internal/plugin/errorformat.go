@@ -0,0 +1,109 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// errorFormat is the format diagnostics are written to stderr in.
+type errorFormat string
+
+const (
+	// errorFormatText writes warnings as lines of text, and errors as a single error listing every error.
+	errorFormatText errorFormat = "text"
+	// errorFormatGitHub writes diagnostics as GitHub Actions workflow commands, which annotate files and lines.
+	errorFormatGitHub errorFormat = "github"
+	// errorFormatJSON writes diagnostics as JSON objects, one per line.
+	errorFormatJSON errorFormat = "json"
+)
+
+// validate returns an error if the error format is not supported.
+func (f errorFormat) validate() error {
+	switch f {
+	case "", errorFormatText, errorFormatGitHub, errorFormatJSON:
+		return nil
+	}
+	return fmt.Errorf(
+		"unsupported error format %q (expected %q, %q or %q)",
+		f,
+		errorFormatText,
+		errorFormatGitHub,
+		errorFormatJSON,
+	)
+}
+
+// writeDiagnostics writes the diagnostics to w in the error format, and returns an error counting the errors.
+// The text format writes warnings only, and lists the errors in the returned error instead.
+func writeDiagnostics(w io.Writer, format errorFormat, diagnostics []diagnostic) error {
+	if format == "" || format == errorFormatText {
+		return report(w, diagnostics)
+	}
+	var errs int
+	enc := json.NewEncoder(w)
+	for _, diagnostic := range diagnostics {
+		if diagnostic.severity == severityError {
+			errs++
+		}
+		var err error
+		switch format {
+		case errorFormatGitHub:
+			_, err = io.WriteString(w, githubWorkflowCommand(diagnostic)+"\n")
+		case errorFormatJSON:
+			err = enc.Encode(jsonReportDiagnostic{
+				Rule:     string(diagnostic.rule),
+				Severity: diagnostic.severity.String(),
+				File:     diagnostic.location.file,
+				Line:     diagnostic.location.line,
+				Column:   diagnostic.location.column,
+				JSONPath: diagnostic.location.jsonPath,
+				Message:  diagnostic.message,
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if errs > 0 {
+		return fmt.Errorf("validate: %d problem(s) found", errs)
+	}
+	return nil
+}
+
+// githubWorkflowCommand returns the diagnostic as a GitHub Actions workflow command, such as
+// `::error file=a.json,line=1,col=2,title=RULE::message`.
+// See: https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions.
+func githubWorkflowCommand(diagnostic diagnostic) string {
+	command := "notice"
+	switch diagnostic.severity {
+	case severityWarning:
+		command = "warning"
+	case severityError:
+		command = "error"
+	}
+	properties := []string{"file=" + escapeGitHubProperty(diagnostic.location.file)}
+	if diagnostic.location.line > 0 {
+		properties = append(properties, "line="+strconv.Itoa(diagnostic.location.line))
+		if diagnostic.location.column > 0 {
+			properties = append(properties, "col="+strconv.Itoa(diagnostic.location.column))
+		}
+	}
+	properties = append(properties, "title="+escapeGitHubProperty(string(diagnostic.rule)))
+	message := diagnostic.message
+	if diagnostic.location.line == 0 && diagnostic.location.jsonPath != "" {
+		message = diagnostic.location.jsonPath + ": " + message
+	}
+	return "::" + command + " " + strings.Join(properties, ",") + "::" + escapeGitHubData(message)
+}
+
+// escapeGitHubData escapes the message of a GitHub Actions workflow command.
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeGitHubProperty escapes a property value of a GitHub Actions workflow command.
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,69 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteDiagnostics(t *testing.T) {
+	diagnostics := []diagnostic{
+		{
+			rule:     ruleDanglingName,
+			severity: severityWarning,
+			location: location{file: "a,b.json", line: 3, column: 4, jsonPath: "methodConfig[0].name[0]"},
+			message:  "50% dangling\nname",
+		},
+		{
+			rule:     ruleMissingServiceConfig,
+			severity: severityError,
+			location: location{file: "a.proto", jsonPath: "methodConfig"},
+			message:  "missing",
+		},
+	}
+	for _, tt := range []struct {
+		name          string
+		format        errorFormat
+		expected      string
+		expectedError string
+	}{
+		{
+			name:   "github",
+			format: errorFormatGitHub,
+			expected: "::warning file=a%2Cb.json,line=3,col=4,title=DANGLING_NAME::50%25 dangling%0Aname\n" +
+				"::error file=a.proto,title=MISSING_SERVICE_CONFIG::methodConfig: missing\n",
+			expectedError: "validate: 1 problem(s) found",
+		},
+		{
+			name:   "json",
+			format: errorFormatJSON,
+			expected: `{"rule":"DANGLING_NAME","severity":"warning","file":"a,b.json","line":3,"column":4,` +
+				`"jsonPath":"methodConfig[0].name[0]","message":"50% dangling\nname"}` + "\n" +
+				`{"rule":"MISSING_SERVICE_CONFIG","severity":"error","file":"a.proto","jsonPath":"methodConfig",` +
+				`"message":"missing"}` + "\n",
+			expectedError: "validate: 1 problem(s) found",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			err := writeDiagnostics(&b, tt.format, diagnostics)
+			if err == nil || err.Error() != tt.expectedError {
+				t.Errorf("expected error %q, got %v", tt.expectedError, err)
+			}
+			if b.String() != tt.expected {
+				t.Errorf("expected output:\n%s\ngot:\n%s", tt.expected, b.String())
+			}
+		})
+	}
+}
+
+func TestErrorFormatValidate(t *testing.T) {
+	for _, format := range []errorFormat{"", errorFormatText, errorFormatGitHub, errorFormatJSON} {
+		if err := format.validate(); err != nil {
+			t.Errorf("%q: %v", format, err)
+		}
+	}
+	if err := errorFormat("xml").validate(); err == nil {
+		t.Error("expected error for unsupported error format")
+	}
+}
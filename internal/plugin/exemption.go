@@ -0,0 +1,144 @@
+package plugin
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// lintExemptionExtension is the full name of the method option exempting a method from lint rules.
+const lintExemptionExtension protoreflect.FullName = "einride.serviceconfig.v1.lint_exemption"
+
+// lintExemption exempts a method from lint rules.
+type lintExemption struct {
+	// rules are the exempted lint rules. When empty, all lint rules are exempted.
+	rules []rule
+	// reason is the reason for the exemption.
+	reason string
+}
+
+// exempts returns true if the exemption applies to the rule.
+func (e *lintExemption) exempts(rule rule) bool {
+	if e == nil {
+		return false
+	}
+	if len(e.rules) == 0 {
+		return true
+	}
+	for _, exempted := range e.rules {
+		if exempted == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// methodLintExemption returns the lint exemption of a method, or nil when the method has no lint exemption.
+func (p *plugin) methodLintExemption(method protoreflect.MethodDescriptor) (*lintExemption, error) {
+	value, ok, err := p.dynamicExtension(method.Options(), lintExemptionExtension)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", method.FullName(), err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	exemption := value.Message()
+	var result lintExemption
+	fields := exemption.Descriptor().Fields()
+	if field := fields.ByName("rules"); field != nil {
+		list := exemption.Get(field).List()
+		for i := 0; i < list.Len(); i++ {
+			rule := rule(list.Get(i).String())
+			if !isLintRule(rule) {
+				return nil, fmt.Errorf("%s: %s: unknown lint rule %q", method.FullName(), lintExemptionExtension, rule)
+			}
+			result.rules = append(result.rules, rule)
+		}
+	}
+	if field := fields.ByName("reason"); field != nil {
+		result.reason = exemption.Get(field).String()
+	}
+	return &result, nil
+}
+
+// methodLintExemptions returns the lint exemptions of the methods of a service.
+func (p *plugin) methodLintExemptions(service *protogen.Service) (map[protoreflect.FullName]*lintExemption, error) {
+	result := map[protoreflect.FullName]*lintExemption{}
+	for _, method := range service.Methods {
+		exemption, err := p.methodLintExemption(method.Desc)
+		if err != nil {
+			return nil, err
+		}
+		if exemption != nil {
+			result[method.Desc.FullName()] = exemption
+		}
+	}
+	return result, nil
+}
+
+// serviceExemptExtension is the full name of the service option exempting a service from requiring a service config.
+const serviceExemptExtension protoreflect.FullName = "einride.serviceconfig.v1.exempt"
+
+// serviceExemptReasonExtension is the full name of the service option documenting why a service is exempt.
+const serviceExemptReasonExtension protoreflect.FullName = "einride.serviceconfig.v1.exempt_reason"
+
+// serviceExemption returns true when the service is exempt from requiring a service config, and the documented reason.
+func (p *plugin) serviceExemption(service protoreflect.ServiceDescriptor) (bool, string, error) {
+	exempt, ok, err := p.dynamicExtension(service.Options(), serviceExemptExtension)
+	if err != nil {
+		return false, "", fmt.Errorf("%s: %w", service.FullName(), err)
+	}
+	if !ok || !exempt.Bool() {
+		return false, "", nil
+	}
+	reason, ok, err := p.dynamicExtension(service.Options(), serviceExemptReasonExtension)
+	if err != nil {
+		return false, "", fmt.Errorf("%s: %w", service.FullName(), err)
+	}
+	if !ok {
+		return true, "", nil
+	}
+	return true, reason.String(), nil
+}
+
+// dynamicExtension returns the value of an extension on the options.
+// The extension is resolved from the files in the request rather than from linked Go types, so that annotations are
+// available without a dependency on their generated Go code.
+func (p *plugin) dynamicExtension(
+	options proto.Message,
+	name protoreflect.FullName,
+) (protoreflect.Value, bool, error) {
+	if options == nil {
+		return protoreflect.Value{}, false, nil
+	}
+	descriptor, err := p.registry().FindDescriptorByName(name)
+	if err != nil {
+		// The extension can only be set when its file is imported.
+		return protoreflect.Value{}, false, nil
+	}
+	extensionDescriptor, ok := descriptor.(protoreflect.ExtensionDescriptor)
+	if !ok {
+		return protoreflect.Value{}, false, fmt.Errorf("%s is not an extension", name)
+	}
+	extensionType := dynamicpb.NewExtensionType(extensionDescriptor)
+	var types protoregistry.Types
+	if err := types.RegisterExtension(extensionType); err != nil {
+		return protoreflect.Value{}, false, err
+	}
+	data, err := proto.Marshal(options)
+	if err != nil {
+		return protoreflect.Value{}, false, err
+	}
+	resolved := options.ProtoReflect().New().Interface()
+	if err := (proto.UnmarshalOptions{Resolver: &types}).Unmarshal(data, resolved); err != nil {
+		return protoreflect.Value{}, false, err
+	}
+	if !resolved.ProtoReflect().Has(extensionType.TypeDescriptor()) {
+		return protoreflect.Value{}, false, nil
+	}
+	return resolved.ProtoReflect().Get(extensionType.TypeDescriptor()), true, nil
+}
@@ -0,0 +1,253 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// testExemptionFile is a proto file declaring the exemption options of the annotations file.
+const testExemptionFile = `
+name: "einride/serviceconfig/v1/exemption.proto"
+package: "einride.serviceconfig.v1"
+dependency: "google/protobuf/descriptor.proto"
+options { go_package: "example.com/serviceconfig/v1;serviceconfigv1" }
+message_type {
+  name: "LintExemption"
+  field { name: "rules" number: 1 label: LABEL_REPEATED type: TYPE_STRING json_name: "rules" }
+  field { name: "reason" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "reason" }
+}
+extension {
+  name: "lint_exemption"
+  number: 215242484
+  label: LABEL_OPTIONAL
+  type: TYPE_MESSAGE
+  type_name: ".einride.serviceconfig.v1.LintExemption"
+  extendee: ".google.protobuf.MethodOptions"
+}
+extension {
+  name: "exempt"
+  number: 13812296
+  label: LABEL_OPTIONAL
+  type: TYPE_BOOL
+  extendee: ".google.protobuf.ServiceOptions"
+}
+extension {
+  name: "exempt_reason"
+  number: 101957618
+  label: LABEL_OPTIONAL
+  type: TYPE_STRING
+  extendee: ".google.protobuf.ServiceOptions"
+}
+`
+
+// setTestLintExemption sets the lint_exemption option of a method in the file, exempting it from the rules.
+func setTestLintExemption(t testing.TB, file *descriptorpb.FileDescriptorProto, method string, rules []string) {
+	t.Helper()
+	extensionType := testExemptionExtension(t, "lint_exemption")
+	exemption := dynamicpb.NewMessage(extensionType.TypeDescriptor().Message())
+	list := exemption.Mutable(exemption.Descriptor().Fields().ByName("rules")).List()
+	for _, rule := range rules {
+		list.Append(protoreflect.ValueOfString(rule))
+	}
+	exemption.Set(exemption.Descriptor().Fields().ByName("reason"), protoreflect.ValueOfString("test"))
+	for _, service := range file.GetService() {
+		for _, m := range service.GetMethod() {
+			if m.GetName() != method {
+				continue
+			}
+			if m.Options == nil {
+				m.Options = &descriptorpb.MethodOptions{}
+			}
+			m.Options.ProtoReflect().Set(extensionType.TypeDescriptor(), protoreflect.ValueOfMessage(exemption))
+			return
+		}
+	}
+	t.Fatalf("no method %s", method)
+}
+
+// setTestServiceExemption sets the exempt and exempt_reason options of the services in the file.
+func setTestServiceExemption(t testing.TB, file *descriptorpb.FileDescriptorProto, reason string) {
+	t.Helper()
+	exempt := testExemptionExtension(t, "exempt")
+	exemptReason := testExemptionExtension(t, "exempt_reason")
+	for _, service := range file.GetService() {
+		if service.Options == nil {
+			service.Options = &descriptorpb.ServiceOptions{}
+		}
+		service.Options.ProtoReflect().Set(exempt.TypeDescriptor(), protoreflect.ValueOfBool(true))
+		if reason != "" {
+			service.Options.ProtoReflect().Set(exemptReason.TypeDescriptor(), protoreflect.ValueOfString(reason))
+		}
+	}
+}
+
+// testExemptionExtension returns the type of an extension declared in the exemption file.
+func testExemptionExtension(t testing.TB, name protoreflect.Name) protoreflect.ExtensionType {
+	t.Helper()
+	exemptionFile, err := protodesc.NewFile(testFile(t, testExemptionFile), protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dynamicpb.NewExtensionType(exemptionFile.Extensions().ByName(name))
+}
+
+func TestMethodLintExemption(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		// rules are the rules of the exemption of GetShipper, or nil for no exemption.
+		rules []string
+		// imported is true when the file declaring the lint_exemption option is in the request.
+		imported bool
+		exempts  []rule
+		err      string
+	}{
+		{
+			name:     "no exemption",
+			imported: true,
+		},
+		{
+			name:     "exempted rules",
+			rules:    []string{"TIMEOUT_RANGE", "REQUIRE_TIMEOUT"},
+			imported: true,
+			exempts:  []rule{ruleTimeoutRange, ruleRequireTimeout},
+		},
+		{
+			name:     "all rules",
+			rules:    []string{},
+			imported: true,
+			exempts:  lintRules,
+		},
+		{
+			name:  "option not imported",
+			rules: []string{"TIMEOUT_RANGE"},
+		},
+		{
+			name:     "unknown rule",
+			rules:    []string{"TIMEOUT"},
+			imported: true,
+			err: "einride.example.freight.v1.FreightService.GetShipper: einride.serviceconfig.v1.lint_exemption:" +
+				` unknown lint rule "TIMEOUT"`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			file := testFile(t, testFreightServiceFile)
+			if tt.rules != nil {
+				setTestLintExemption(t, file, "GetShipper", tt.rules)
+			}
+			files := []*descriptorpb.FileDescriptorProto{file}
+			if tt.imported {
+				files = append(files, testFile(t, testExemptionFile))
+			}
+			p := newTestPlugin(t, pluginOptions{conflict: conflictPreferJSON}, files...)
+			exemptions, err := p.methodLintExemptions(p.gen.FilesByPath[file.GetName()].Services[0])
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			exemption := exemptions["einride.example.freight.v1.FreightService.GetShipper"]
+			if len(tt.exempts) == 0 && exemption != nil {
+				t.Fatalf("expected no exemption, got %v", exemption)
+			}
+			for _, rule := range lintRules {
+				expected := false
+				for _, exempted := range tt.exempts {
+					expected = expected || exempted == rule
+				}
+				if exemption.exempts(rule) != expected {
+					t.Errorf("expected exemption of %s to be %v", rule, expected)
+				}
+			}
+			if exemption != nil && exemption.reason != "test" {
+				t.Errorf("expected reason %q, got %q", "test", exemption.reason)
+			}
+			if len(exemptions) > 1 {
+				t.Errorf("expected only GetShipper to be exempted, got %v", exemptions)
+			}
+		})
+	}
+}
+
+func TestServiceExemption(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		exempt   bool
+		reason   string
+		imported bool
+		// err is a substring of the validation error with the required option, or empty for no error.
+		err string
+	}{
+		{
+			name:     "not exempt",
+			imported: true,
+			err:      "error: missing service config for einride.example.freight.v1.FreightService",
+		},
+		{
+			name:     "exempt with a reason",
+			exempt:   true,
+			reason:   "Only called by browsers.",
+			imported: true,
+		},
+		{
+			name:     "exempt without a reason",
+			exempt:   true,
+			imported: true,
+			err: "error: service einride.example.freight.v1.FreightService is exempt from requiring a service config," +
+				" but has no einride.serviceconfig.v1.exempt_reason (EXEMPTION_REASON)",
+		},
+		{
+			name:   "options not imported",
+			exempt: true,
+			reason: "Only called by browsers.",
+			err:    "error: missing service config for einride.example.freight.v1.FreightService",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			file := testFile(t, testFreightServiceFile)
+			if tt.exempt {
+				setTestServiceExemption(t, file, tt.reason)
+			}
+			files := []*descriptorpb.FileDescriptorProto{file}
+			if tt.imported {
+				files = append(files, testFile(t, testExemptionFile))
+			}
+			p := newTestPlugin(t, pluginOptions{path: t.TempDir(), conflict: conflictPreferJSON}, files...)
+			exempt, reason, err := p.serviceExemption(p.gen.FilesByPath[file.GetName()].Services[0].Desc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectedExempt, expectedReason := tt.exempt && tt.imported, tt.reason
+			if !tt.imported {
+				expectedReason = ""
+			}
+			if exempt != expectedExempt || reason != expectedReason {
+				t.Errorf("expected exemption (%v, %q), got (%v, %q)", expectedExempt, expectedReason, exempt, reason)
+			}
+			var validateErr error
+			captureStderr(t, func() {
+				validateErr = p.validate(validateOptions{required: true, reportFormat: reportFormatJSON})
+			})
+			if tt.err == "" {
+				if validateErr != nil {
+					t.Errorf("unexpected error: %v", validateErr)
+				}
+				return
+			}
+			if validateErr == nil || !strings.Contains(validateErr.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got %v", tt.err, validateErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// defaultMaxResponseMessageBytes is the default maximum size of a message received by a grpc-go client.
+// grpc-go does not limit the size of messages sent by clients by default.
+const defaultMaxResponseMessageBytes = 4 << 20
+
+// explainServiceConfigJSON is the subset of the service config JSON format described by explanations.
+type explainServiceConfigJSON struct {
+	MethodConfigs []explainMethodConfigJSON `json:"methodConfig"`
+}
+
+// explainMethodConfigJSON is a method config in the service config JSON format, with the fields that change the
+// behavior of calls.
+type explainMethodConfigJSON struct {
+	WaitForReady            *bool       `json:"waitForReady"`
+	Timeout                 *string     `json:"timeout"`
+	MaxRequestMessageBytes  json.Number `json:"maxRequestMessageBytes"`
+	MaxResponseMessageBytes json.Number `json:"maxResponseMessageBytes"`
+	RetryPolicy             *struct {
+		MaxAttempts          json.Number       `json:"maxAttempts"`
+		InitialBackoff       string            `json:"initialBackoff"`
+		MaxBackoff           string            `json:"maxBackoff"`
+		BackoffMultiplier    json.Number       `json:"backoffMultiplier"`
+		RetryableStatusCodes []json.RawMessage `json:"retryableStatusCodes"`
+	} `json:"retryPolicy"`
+	HedgingPolicy *struct {
+		MaxAttempts         json.Number       `json:"maxAttempts"`
+		HedgingDelay        string            `json:"hedgingDelay"`
+		NonFatalStatusCodes []json.RawMessage `json:"nonFatalStatusCodes"`
+	} `json:"hedgingPolicy"`
+}
+
+// writeExplanation writes a table with the effective method config of every method of the services to generate, or
+// of the services with the full names when not empty.
+func (p *plugin) writeExplanation(w io.Writer, services []string) error {
+	included := map[string]struct{}{}
+	for _, service := range services {
+		included[service] = struct{}{}
+	}
+	for _, file := range p.gen.Files {
+		if !file.Generate {
+			continue
+		}
+		for _, service := range file.Services {
+			if _, ok := included[string(service.Desc.FullName())]; len(included) > 0 && !ok {
+				continue
+			}
+			delete(included, string(service.Desc.FullName()))
+			if err := p.explainService(w, service.Desc); err != nil {
+				return fmt.Errorf("explain %s: %w", service.Desc.FullName(), err)
+			}
+		}
+	}
+	for _, service := range services {
+		if _, ok := included[service]; ok {
+			return fmt.Errorf("explain: service %s not found", service)
+		}
+	}
+	return nil
+}
+
+// explainService writes the effective method config of every method of the service.
+func (p *plugin) explainService(w io.Writer, service protoreflect.ServiceDescriptor) error {
+	serviceConfig, ok, err := p.resolveServiceConfig(service)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		_, err := fmt.Fprintf(w, "%s: no service config\n\n", service.FullName())
+		return err
+	}
+	if _, err := fmt.Fprintf(
+		w,
+		"%s: %s (%s)\n",
+		service.FullName(),
+		serviceConfig.source,
+		serviceConfig.kind(),
+	); err != nil {
+		return err
+	}
+	embedded, err := p.embeddedServiceConfig(serviceConfig)
+	if err != nil {
+		return err
+	}
+	var content serviceConfigJSON
+	if err := json.Unmarshal([]byte(embedded), &content); err != nil {
+		return err
+	}
+	var explainContent explainServiceConfigJSON
+	if err := json.Unmarshal([]byte(embedded), &explainContent); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "METHOD\tMETHOD CONFIG\tTIMEOUT\tWAIT FOR READY\tRETRY\tHEDGING\tMAX REQUEST\tMAX RESPONSE")
+	for i := 0; i < service.Methods().Len(); i++ {
+		method := service.Methods().Get(i)
+		name, matched, methodConfig := string(method.Name()), "none", explainMethodConfigJSON{}
+		if j, ok := content.methodConfigFor(method); ok && j < len(explainContent.MethodConfigs) {
+			matched = fmt.Sprintf("methodConfig[%d] (%s)", j, content.MethodConfigs[j].match(method))
+			methodConfig = explainContent.MethodConfigs[j]
+		}
+		explained, err := explainMethodConfig(methodConfig)
+		if err != nil {
+			return fmt.Errorf("%s: %w", matched, err)
+		}
+		row := append([]string{name, matched}, explained...)
+		if method.IsStreamingClient() || method.IsStreamingServer() {
+			row[0] += " (streaming)"
+		}
+		_, _ = fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w)
+	return err
+}
+
+// explainMethodConfig returns human-readable descriptions of the timeout, wait for ready, retry, hedging, and
+// message size limit columns of a method config, with the grpc-go defaults for missing fields.
+func explainMethodConfig(methodConfig explainMethodConfigJSON) ([]string, error) {
+	result := []string{"none", "false", "none", "none", "no limit", formatBytes(defaultMaxResponseMessageBytes)}
+	if methodConfig.Timeout != nil {
+		timeout, err := configjson.ParseDuration(*methodConfig.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("timeout: %w", err)
+		}
+		result[0] = configjson.FormatDuration(timeout)
+	}
+	if methodConfig.WaitForReady != nil {
+		result[1] = strconv.FormatBool(*methodConfig.WaitForReady)
+	}
+	if policy := methodConfig.RetryPolicy; policy != nil {
+		maxAttempts, attempts, err := explainMaxAttempts(policy.MaxAttempts)
+		if err != nil {
+			return nil, fmt.Errorf("retryPolicy.maxAttempts: %w", err)
+		}
+		initialBackoff, err := configjson.ParseDuration(policy.InitialBackoff)
+		if err != nil {
+			return nil, fmt.Errorf("retryPolicy.initialBackoff: %w", err)
+		}
+		maxBackoff, err := configjson.ParseDuration(policy.MaxBackoff)
+		if err != nil {
+			return nil, fmt.Errorf("retryPolicy.maxBackoff: %w", err)
+		}
+		multiplier, err := policy.BackoffMultiplier.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("retryPolicy.backoffMultiplier: %w", err)
+		}
+		codes, err := explainStatusCodes(policy.RetryableStatusCodes)
+		if err != nil {
+			return nil, fmt.Errorf("retryPolicy.retryableStatusCodes: %w", err)
+		}
+		result[2] = fmt.Sprintf(
+			"%s on %s, backoff %s",
+			attempts,
+			codes,
+			explainRetryBackoffs(maxAttempts, initialBackoff, maxBackoff, multiplier),
+		)
+	}
+	if policy := methodConfig.HedgingPolicy; policy != nil {
+		_, attempts, err := explainMaxAttempts(policy.MaxAttempts)
+		if err != nil {
+			return nil, fmt.Errorf("hedgingPolicy.maxAttempts: %w", err)
+		}
+		var delay time.Duration
+		if policy.HedgingDelay != "" {
+			if delay, err = configjson.ParseDuration(policy.HedgingDelay); err != nil {
+				return nil, fmt.Errorf("hedgingPolicy.hedgingDelay: %w", err)
+			}
+		}
+		schedule := "all at once"
+		if delay > 0 {
+			schedule = "every " + configjson.FormatDuration(delay)
+		}
+		result[3] = attempts + " " + schedule
+		if len(policy.NonFatalStatusCodes) > 0 {
+			codes, err := explainStatusCodes(policy.NonFatalStatusCodes)
+			if err != nil {
+				return nil, fmt.Errorf("hedgingPolicy.nonFatalStatusCodes: %w", err)
+			}
+			result[3] += ", continue on " + codes
+		}
+	}
+	if methodConfig.MaxRequestMessageBytes != "" {
+		n, err := methodConfig.MaxRequestMessageBytes.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("maxRequestMessageBytes: %w", err)
+		}
+		result[4] = formatBytes(n)
+	}
+	if methodConfig.MaxResponseMessageBytes != "" {
+		n, err := methodConfig.MaxResponseMessageBytes.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("maxResponseMessageBytes: %w", err)
+		}
+		result[5] = formatBytes(n)
+	}
+	return result, nil
+}
+
+// explainMaxAttempts returns the maximum number of attempts after applying the gRPC limit, and its description.
+func explainMaxAttempts(value json.Number) (int64, string, error) {
+	maxAttempts, err := value.Int64()
+	if err != nil {
+		return 0, "", err
+	}
+	if maxAttempts > maxAttemptsLimit {
+		return maxAttemptsLimit, fmt.Sprintf("%d attempts (limited from %d)", maxAttemptsLimit, maxAttempts), nil
+	}
+	return maxAttempts, fmt.Sprintf("%d attempts", maxAttempts), nil
+}
+
+// explainStatusCodes returns the names of status codes, separated by "|".
+func explainStatusCodes(values []json.RawMessage) (string, error) {
+	names := make([]string, 0, len(values))
+	for _, value := range values {
+		code, err := statusCodeJSON{value: value}.parse()
+		if err != nil {
+			return "", err
+		}
+		names = append(names, configjson.StatusCodeName(code))
+	}
+	return strings.Join(names, "|"), nil
+}
+
+// explainRetryBackoffs returns the longest backoff before each retry, such as "≤0.1s, ≤0.2s, ≤0.4s".
+// gRPC waits a random backoff up to min(initialBackoff*backoffMultiplier^(n-1), maxBackoff) before retry n.
+func explainRetryBackoffs(maxAttempts int64, initialBackoff, maxBackoff time.Duration, multiplier float64) string {
+	var schedule []string
+	for n := int64(1); n < maxAttempts; n++ {
+		backoff := time.Duration(math.Min(
+			float64(initialBackoff)*math.Pow(multiplier, float64(n-1)),
+			float64(maxBackoff),
+		))
+		schedule = append(schedule, "≤"+configjson.FormatDuration(backoff))
+	}
+	return strings.Join(schedule, ", ")
+}
+
+// formatBytes formats a number of bytes, in MiB or KiB when evenly divisible.
+func formatBytes(n int64) string {
+	switch {
+	case n > 0 && n%(1<<20) == 0:
+		return fmt.Sprintf("%d MiB", n>>20)
+	case n > 0 && n%(1<<10) == 0:
+		return fmt.Sprintf("%d KiB", n>>10)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
@@ -0,0 +1,127 @@
+package plugin
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWriteExplanation(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{testFreightServiceConfigFile: testRetryServiceConfig})
+	p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+	var output strings.Builder
+	if err := p.writeExplanation(&output, []string{"einride.example.freight.v1.FreightService"}); err != nil {
+		t.Fatal(err)
+	}
+	// Columns are aligned with at least two spaces, so rows are compared with columns separated by "|".
+	columns := regexp.MustCompile(` {2,}`)
+	var actual []string
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		actual = append(actual, columns.ReplaceAllString(strings.TrimSpace(line), "|"))
+	}
+	const defaultRow = "methodConfig[0] (default name)|10s|false|none|none|no limit|4 MiB"
+	expected := []string{
+		"einride.example.freight.v1.FreightService: " + filepath.Join(dir, testFreightServiceConfigFile) + " (JSON file)",
+		"METHOD|METHOD CONFIG|TIMEOUT|WAIT FOR READY|RETRY|HEDGING|MAX REQUEST|MAX RESPONSE",
+		"GetShipper|methodConfig[1] (method name)|1s|false|3 attempts on UNAVAILABLE, backoff ≤0.1s, ≤0.2s|none|" +
+			"no limit|4 MiB",
+		"UpdateShipper|" + defaultRow,
+		"CreateShipper|" + defaultRow,
+		"ImportShippers|" + defaultRow,
+		"ListShippers|" + defaultRow,
+		"WatchShippers (streaming)|" + defaultRow,
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+	if err := p.writeExplanation(&output, []string{"einride.example.freight.v1.Unknown"}); err == nil ||
+		err.Error() != "explain: service einride.example.freight.v1.Unknown not found" {
+		t.Errorf("expected unknown service error, got %v", err)
+	}
+}
+
+func TestExplainMethodConfig(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		methodConfig string
+		expected     []string
+		err          string
+	}{
+		{
+			name:         "defaults",
+			methodConfig: `{}`,
+			expected:     []string{"none", "false", "none", "none", "no limit", "4 MiB"},
+		},
+		{
+			name: "limited retry attempts",
+			methodConfig: `{
+  "waitForReady": true,
+  "retryPolicy": {
+    "maxAttempts": 7,
+    "initialBackoff": "1s",
+    "maxBackoff": "3s",
+    "backoffMultiplier": 2,
+    "retryableStatusCodes": ["UNAVAILABLE", 4]
+  }
+}`,
+			expected: []string{
+				"none",
+				"true",
+				"5 attempts (limited from 7) on UNAVAILABLE|DEADLINE_EXCEEDED, backoff ≤1s, ≤2s, ≤3s, ≤3s",
+				"none",
+				"no limit",
+				"4 MiB",
+			},
+		},
+		{
+			name: "hedging and message limits",
+			methodConfig: `{
+  "timeout": "0.5s",
+  "hedgingPolicy": {"maxAttempts": 3, "hedgingDelay": "0.1s", "nonFatalStatusCodes": ["UNAVAILABLE"]},
+  "maxRequestMessageBytes": 2048,
+  "maxResponseMessageBytes": 1000
+}`,
+			expected: []string{
+				"0.5s",
+				"false",
+				"none",
+				"3 attempts every 0.1s, continue on UNAVAILABLE",
+				"2 KiB",
+				"1000 bytes",
+			},
+		},
+		{
+			name:         "hedging without delay",
+			methodConfig: `{"hedgingPolicy": {"maxAttempts": 2}}`,
+			expected:     []string{"none", "false", "none", "2 attempts all at once", "no limit", "4 MiB"},
+		},
+		{
+			name:         "invalid timeout",
+			methodConfig: `{"timeout": "forever"}`,
+			err:          "timeout: ",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var methodConfig explainMethodConfigJSON
+			if err := json.Unmarshal([]byte(tt.methodConfig), &methodConfig); err != nil {
+				t.Fatal(err)
+			}
+			actual, err := explainMethodConfig(methodConfig)
+			if tt.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Fatalf("expected error starting with %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(actual, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// packageServiceConfig is the resolved service config of a proto package with services to generate, the way it is
+// embedded in generated code. Exporters write it in formats for consumers other than Go.
+type packageServiceConfig struct {
+	// pkg is the proto package.
+	pkg protoreflect.FullName
+	// source is the path of the JSON file or proto file the service config was resolved from.
+	source string
+	// json is the service config JSON, the way it is embedded in generated code.
+	json string
+	// services are the services of the package, in the order of their files.
+	services []protoreflect.ServiceDescriptor
+	// positions are the positions of values in the service config JSON file, only known for service configs resolved
+	// from JSON files.
+	positions jsonPositions
+}
+
+// packageServiceConfigs returns the resolved service configs of the proto packages with services to generate, in the
+// order of their files. Packages without a service config are skipped.
+func (p *plugin) packageServiceConfigs() ([]*packageServiceConfig, error) {
+	var result []*packageServiceConfig
+	byPackage := map[protoreflect.FullName]*packageServiceConfig{}
+	for _, file := range p.gen.Files {
+		if !file.Generate {
+			continue
+		}
+		for _, service := range file.Services {
+			serviceConfig, ok, err := p.resolveServiceConfig(service.Desc)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			pkg := file.Desc.Package()
+			if existing, ok := byPackage[pkg]; ok {
+				if existing.source != serviceConfig.source {
+					return nil, fmt.Errorf(
+						"export %s service config: services of the package resolve different service configs (%s and %s)",
+						pkg,
+						existing.source,
+						serviceConfig.source,
+					)
+				}
+				existing.services = append(existing.services, service.Desc)
+				continue
+			}
+			embedded, err := p.embeddedServiceConfig(serviceConfig)
+			if err != nil {
+				return nil, fmt.Errorf("export %s service config: %w", pkg, err)
+			}
+			packageServiceConfig := &packageServiceConfig{
+				pkg:      pkg,
+				source:   serviceConfig.source,
+				json:     embedded,
+				services: []protoreflect.ServiceDescriptor{service.Desc},
+			}
+			if !serviceConfig.annotation && !serviceConfig.merged {
+				// Positions only improve exports, so failing to parse them is not an error.
+				packageServiceConfig.positions, _ = parseJSONPositions(serviceConfig.source, []byte(serviceConfig.json))
+			}
+			byPackage[pkg] = packageServiceConfig
+			result = append(result, packageServiceConfig)
+		}
+	}
+	return result, nil
+}
+
+// packagePath returns the path of a proto package, such as einride/example/freight/v1.
+func packagePath(pkg protoreflect.FullName) string {
+	return strings.ReplaceAll(string(pkg), ".", "/")
+}
+
+// generateJSONArtifacts writes the resolved service config of every package in canonical form to the directory, as
+// <dir>/<package path>/service_config.json, so that consumers other than Go use the exact service configs embedded
+// in generated code.
+func (p *plugin) generateJSONArtifacts(dir string) error {
+	serviceConfigs, err := p.packageServiceConfigs()
+	if err != nil {
+		return err
+	}
+	for _, serviceConfig := range serviceConfigs {
+		formatted, err := formatServiceConfig([]byte(serviceConfig.json))
+		if err != nil {
+			return fmt.Errorf("emit %s service config JSON: %w", serviceConfig.pkg, err)
+		}
+		g := p.gen.NewGeneratedFile(path.Join(dir, packagePath(serviceConfig.pkg), "service_config.json"), "")
+		if _, err := g.Write(formatted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configMapKey is the key of the service config JSON in generated ConfigMaps.
+const configMapKey = "service_config.json"
+
+// configMapName returns the name of the generated ConfigMap of a proto package, such as
+// einride-example-freight-v1-grpc-service-config, which is a valid Kubernetes resource name.
+func configMapName(pkg protoreflect.FullName) string {
+	name := strings.NewReplacer(".", "-", "_", "-").Replace(strings.ToLower(string(pkg)))
+	return name + "-grpc-service-config"
+}
+
+// generateConfigMaps writes a Kubernetes ConfigMap manifest with the resolved service config of every package to the
+// directory, as <dir>/<package path>/configmap.yaml, for deployments that mount or inject the service config instead
+// of compiling it in. The service config JSON is stored in canonical form under the service_config.json key.
+func (p *plugin) generateConfigMaps(dir string) error {
+	serviceConfigs, err := p.packageServiceConfigs()
+	if err != nil {
+		return err
+	}
+	for _, serviceConfig := range serviceConfigs {
+		formatted, err := formatServiceConfig([]byte(serviceConfig.json))
+		if err != nil {
+			return fmt.Errorf("emit %s service config ConfigMap: %w", serviceConfig.pkg, err)
+		}
+		g := p.gen.NewGeneratedFile(path.Join(dir, packagePath(serviceConfig.pkg), "configmap.yaml"), "")
+		g.P("# Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.")
+		g.P("# Source: ", serviceConfig.source)
+		g.P("apiVersion: v1")
+		g.P("kind: ConfigMap")
+		g.P("metadata:")
+		g.P("  name: ", configMapName(serviceConfig.pkg))
+		g.P("  labels:")
+		g.P("    app.kubernetes.io/managed-by: protoc-gen-go-grpc-service-config")
+		g.P("  annotations:")
+		g.P("    grpc-service-config.einride.tech/package: ", serviceConfig.pkg)
+		g.P("data:")
+		g.P("  ", configMapKey, ": |")
+		for _, line := range strings.Split(strings.TrimSuffix(string(formatted), "\n"), "\n") {
+			g.P("    ", line)
+		}
+	}
+	return nil
+}
+
+// dnsTXTCharacterStringMaxBytes is the maximum size of a character-string in a DNS TXT record. Longer records are
+// split into several character-strings, which gRPC concatenates.
+const dnsTXTCharacterStringMaxBytes = 255
+
+// dnsTXTRecord returns the payload of the _grpc_config DNS TXT record of a service config: a grpc_config attribute
+// with a list of one service config choice, optionally limited to a percentage of clients for canary rollouts.
+func dnsTXTRecord(serviceConfig string, percentage int) (string, error) {
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, []byte(serviceConfig)); err != nil {
+		return "", err
+	}
+	choice := map[string]interface{}{"serviceConfig": json.RawMessage(compacted.Bytes())}
+	if percentage >= 0 {
+		choice["percentage"] = percentage
+	}
+	var result bytes.Buffer
+	enc := json.NewEncoder(&result)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode([]interface{}{choice}); err != nil {
+		return "", err
+	}
+	return "grpc_config=" + strings.TrimSuffix(result.String(), "\n"), nil
+}
+
+// dnsTXTRData returns a DNS TXT record payload as the quoted character-strings of a zone file, split to the maximum
+// size of a character-string.
+func dnsTXTRData(payload string) string {
+	var result strings.Builder
+	for len(payload) > 0 {
+		n := dnsTXTCharacterStringMaxBytes
+		if n > len(payload) {
+			n = len(payload)
+		}
+		if result.Len() > 0 {
+			result.WriteString(" ")
+		}
+		result.WriteString(`"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(payload[:n]) + `"`)
+		payload = payload[n:]
+	}
+	return result.String()
+}
+
+// generateDNSTXTRecords writes the _grpc_config DNS TXT record of the resolved service config of every package to
+// the directory, as <dir>/<package path>/grpc_config.txt with the record payload, and grpc_config.rdata with the
+// quoted character-strings of a zone file. A percentage of at least 0 limits the service config to that percentage
+// of clients. Records larger than a DNS TXT record are an error.
+func (p *plugin) generateDNSTXTRecords(dir string, percentage int) error {
+	if percentage > 100 {
+		return fmt.Errorf("emit DNS TXT records: percentage %d is larger than 100", percentage)
+	}
+	serviceConfigs, err := p.packageServiceConfigs()
+	if err != nil {
+		return err
+	}
+	for _, serviceConfig := range serviceConfigs {
+		payload, err := dnsTXTRecord(serviceConfig.json, percentage)
+		if err != nil {
+			return fmt.Errorf("emit %s service config DNS TXT record: %w", serviceConfig.pkg, err)
+		}
+		if len(payload) > dnsTXTRecordMaxBytes {
+			return fmt.Errorf(
+				"emit %s service config DNS TXT record: the record is %d bytes, larger than the %d bytes of a DNS TXT record",
+				serviceConfig.pkg,
+				len(payload),
+				dnsTXTRecordMaxBytes,
+			)
+		}
+		dir := path.Join(dir, packagePath(serviceConfig.pkg))
+		txt := p.gen.NewGeneratedFile(path.Join(dir, "grpc_config.txt"), "")
+		if _, err := txt.Write([]byte(payload + "\n")); err != nil {
+			return err
+		}
+		rdata := p.gen.NewGeneratedFile(path.Join(dir, "grpc_config.rdata"), "")
+		if _, err := rdata.Write([]byte(dnsTXTRData(payload) + "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
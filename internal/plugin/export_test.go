@@ -0,0 +1,174 @@
+package plugin
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateJSONArtifacts(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		testFreightServiceConfigFile: `{"methodConfig": [{"name": [{}], "timeout": "1.500s"}]}`,
+	})
+	p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+	if err := p.generateJSONArtifacts("service_configs"); err != nil {
+		t.Fatal(err)
+	}
+	files := p.gen.Response().GetFile()
+	if len(files) != 1 {
+		t.Fatalf("expected 1 generated file, got %d", len(files))
+	}
+	const expectedName = "service_configs/einride/example/freight/v1/service_config.json"
+	if actual := files[0].GetName(); actual != expectedName {
+		t.Errorf("expected name %s, got %s", expectedName, actual)
+	}
+	const expected = `{
+  "methodConfig": [
+    {
+      "name": [
+        {}
+      ],
+      "timeout": "1.5s"
+    }
+  ]
+}
+`
+	if actual := files[0].GetContent(); actual != expected {
+		t.Errorf("expected content:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestGenerateConfigMaps(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		testFreightServiceConfigFile: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+	})
+	p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+	if err := p.generateConfigMaps("manifests"); err != nil {
+		t.Fatal(err)
+	}
+	files := p.gen.Response().GetFile()
+	if len(files) != 1 {
+		t.Fatalf("expected 1 generated file, got %d", len(files))
+	}
+	const expectedName = "manifests/einride/example/freight/v1/configmap.yaml"
+	if actual := files[0].GetName(); actual != expectedName {
+		t.Errorf("expected name %s, got %s", expectedName, actual)
+	}
+	expected := `# Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.
+# Source: ` + filepath.Join(dir, testFreightServiceConfigFile) + `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: einride-example-freight-v1-grpc-service-config
+  labels:
+    app.kubernetes.io/managed-by: protoc-gen-go-grpc-service-config
+  annotations:
+    grpc-service-config.einride.tech/package: einride.example.freight.v1
+data:
+  service_config.json: |
+    {
+      "methodConfig": [
+        {
+          "name": [
+            {}
+          ],
+          "timeout": "10s"
+        }
+      ]
+    }
+`
+	if actual := files[0].GetContent(); actual != expected {
+		t.Errorf("expected content:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestDNSTXTRecord(t *testing.T) {
+	const serviceConfig = `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`
+	for _, tt := range []struct {
+		name       string
+		percentage int
+		expected   string
+	}{
+		{
+			name:       "all clients",
+			percentage: -1,
+			expected:   `grpc_config=[{"serviceConfig":{"methodConfig":[{"name":[{}],"timeout":"10s"}]}}]`,
+		},
+		{
+			name:       "percentage",
+			percentage: 25,
+			expected:   `grpc_config=[{"percentage":25,"serviceConfig":{"methodConfig":[{"name":[{}],"timeout":"10s"}]}}]`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := dnsTXTRecord(serviceConfig, tt.percentage)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDNSTXTRData(t *testing.T) {
+	long := strings.Repeat("a", dnsTXTCharacterStringMaxBytes)
+	for _, tt := range []struct {
+		name     string
+		payload  string
+		expected string
+	}{
+		{
+			name:     "escaped",
+			payload:  `grpc_config=[{"a":"\\"}]`,
+			expected: `"grpc_config=[{\"a\":\"\\\\\"}]"`,
+		},
+		{
+			name:     "split",
+			payload:  long + "b",
+			expected: `"` + long + `" "b"`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := dnsTXTRData(tt.payload); actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGenerateDNSTXTRecords(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		testFreightServiceConfigFile: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+	})
+	t.Run("records", func(t *testing.T) {
+		p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+		if err := p.generateDNSTXTRecords("dns", 10); err != nil {
+			t.Fatal(err)
+		}
+		const record = `grpc_config=[{"percentage":10,"serviceConfig":{"methodConfig":[{"name":[{}],"timeout":"10s"}]}}]`
+		expected := map[string]string{
+			"dns/einride/example/freight/v1/grpc_config.txt": record + "\n",
+			"dns/einride/example/freight/v1/grpc_config.rdata": `"grpc_config=[{\"percentage\":10,` +
+				`\"serviceConfig\":{\"methodConfig\":[{\"name\":[{}],\"timeout\":\"10s\"}]}}]"` + "\n",
+		}
+		actual := map[string]string{}
+		for _, file := range p.gen.Response().GetFile() {
+			actual[file.GetName()] = file.GetContent()
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected files %v, got %v", expected, actual)
+		}
+	})
+	t.Run("invalid percentage", func(t *testing.T) {
+		p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+		const expected = "emit DNS TXT records: percentage 101 is larger than 100"
+		if err := p.generateDNSTXTRecords("dns", 101); err == nil || err.Error() != expected {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	})
+}
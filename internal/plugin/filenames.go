@@ -0,0 +1,77 @@
+package plugin
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// filenameScheme decides how files generated for a package are named.
+type filenameScheme string
+
+const (
+	// filenamesParent names generated files after the parent package, such as "freight" for
+	// einride.example.freight.v1. The zero value names generated files the same way.
+	filenamesParent filenameScheme = "parent"
+	// filenamesUnique names generated files after the parent package, and after the full package for packages without
+	// a parent and for packages generated to the same directory as another package with the same parent name, so that
+	// the generated files of different packages never collide.
+	filenamesUnique filenameScheme = "unique"
+	// filenamesFullPackage names generated files after the full package, with dots replaced by underscores, such as
+	// "einride_example_freight_v1".
+	filenamesFullPackage filenameScheme = "full_package"
+)
+
+// validate returns an error if the filename scheme is not supported.
+func (s filenameScheme) validate() error {
+	switch s {
+	case "", filenamesParent, filenamesUnique, filenamesFullPackage:
+		return nil
+	}
+	return fmt.Errorf(
+		"unsupported filenames %q (expected %q, %q or %q)",
+		s,
+		filenamesParent,
+		filenamesUnique,
+		filenamesFullPackage,
+	)
+}
+
+// generatedFilenamePrefix returns the prefix of the names of the files generated for the package of a file.
+func (p *plugin) generatedFilenamePrefix(file *protogen.File) string {
+	pkg := file.Desc.Package()
+	fullPackageName := strings.ReplaceAll(string(pkg), ".", "_")
+	switch p.filenames {
+	case filenamesFullPackage:
+		return fullPackageName
+	case filenamesUnique:
+		if pkg.Parent() == "" {
+			return fullPackageName
+		}
+		dir := path.Dir(file.GeneratedFilenamePrefix)
+		for _, other := range p.gen.Files {
+			if other.Generate &&
+				other.Desc.Package() != pkg &&
+				other.Desc.Package().Parent().Name() == pkg.Parent().Name() &&
+				path.Dir(other.GeneratedFilenamePrefix) == dir {
+				return fullPackageName
+			}
+		}
+	}
+	return string(pkg.Parent().Name())
+}
+
+// generatedFromProtoFilename returns the name of the file generated from a default_service_config file annotation.
+func (p *plugin) generatedFromProtoFilename(file *protogen.File) string {
+	return path.Join(path.Dir(file.GeneratedFilenamePrefix), p.generatedFilenamePrefix(file)+"_grpc_service_config.pb.go")
+}
+
+// generatedFromJSONFilename returns the name of the file generated from the service config JSON file of a file.
+func (p *plugin) generatedFromJSONFilename(file *protogen.File) string {
+	return path.Join(
+		path.Dir(file.GeneratedFilenamePrefix),
+		p.generatedFilenamePrefix(file)+"_grpc_service_config.json.go",
+	)
+}
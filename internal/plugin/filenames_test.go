@@ -0,0 +1,161 @@
+package plugin
+
+import (
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestGeneratedFilenames(t *testing.T) {
+	const (
+		// acmeServiceFile has the same parent package name as the freight service, and is generated to the same
+		// directory.
+		acmeServiceFile = `
+name: "acme/freight/v1/acme_service.proto"
+package: "acme.freight.v1"
+options { go_package: "example.com/freight/v1;freightv1" }
+service { name: "AcmeService" }
+`
+		rootServiceFile = `
+name: "root_service.proto"
+package: "root"
+options { go_package: "example.com/root;root" }
+service { name: "RootService" }
+`
+	)
+	for _, tt := range []struct {
+		name      string
+		files     []string
+		filenames filenameScheme
+		expected  map[string][2]string
+	}{
+		{
+			name:  "default",
+			files: []string{testFreightServiceFile, acmeServiceFile, rootServiceFile},
+			expected: map[string][2]string{
+				"einride/example/freight/v1/freight_service.proto": {
+					"example.com/freight/v1/freight_grpc_service_config.pb.go",
+					"example.com/freight/v1/freight_grpc_service_config.json.go",
+				},
+				"acme/freight/v1/acme_service.proto": {
+					"example.com/freight/v1/freight_grpc_service_config.pb.go",
+					"example.com/freight/v1/freight_grpc_service_config.json.go",
+				},
+				"root_service.proto": {
+					"example.com/root/_grpc_service_config.pb.go",
+					"example.com/root/_grpc_service_config.json.go",
+				},
+			},
+		},
+		{
+			name:      "parent",
+			files:     []string{testFreightServiceFile},
+			filenames: filenamesParent,
+			expected: map[string][2]string{
+				"einride/example/freight/v1/freight_service.proto": {
+					"example.com/freight/v1/freight_grpc_service_config.pb.go",
+					"example.com/freight/v1/freight_grpc_service_config.json.go",
+				},
+			},
+		},
+		{
+			name:      "full package",
+			files:     []string{testFreightServiceFile},
+			filenames: filenamesFullPackage,
+			expected: map[string][2]string{
+				"einride/example/freight/v1/freight_service.proto": {
+					"example.com/freight/v1/einride_example_freight_v1_grpc_service_config.pb.go",
+					"example.com/freight/v1/einride_example_freight_v1_grpc_service_config.json.go",
+				},
+			},
+		},
+		{
+			name:      "unique",
+			files:     []string{testFreightServiceFile},
+			filenames: filenamesUnique,
+			expected: map[string][2]string{
+				"einride/example/freight/v1/freight_service.proto": {
+					"example.com/freight/v1/freight_grpc_service_config.pb.go",
+					"example.com/freight/v1/freight_grpc_service_config.json.go",
+				},
+			},
+		},
+		{
+			name:      "unique colliding parent packages",
+			files:     []string{testFreightServiceFile, acmeServiceFile},
+			filenames: filenamesUnique,
+			expected: map[string][2]string{
+				"einride/example/freight/v1/freight_service.proto": {
+					"example.com/freight/v1/einride_example_freight_v1_grpc_service_config.pb.go",
+					"example.com/freight/v1/einride_example_freight_v1_grpc_service_config.json.go",
+				},
+				"acme/freight/v1/acme_service.proto": {
+					"example.com/freight/v1/acme_freight_v1_grpc_service_config.pb.go",
+					"example.com/freight/v1/acme_freight_v1_grpc_service_config.json.go",
+				},
+			},
+		},
+		{
+			name:      "unique no parent package",
+			files:     []string{rootServiceFile},
+			filenames: filenamesUnique,
+			expected: map[string][2]string{
+				"root_service.proto": {
+					"example.com/root/root_grpc_service_config.pb.go",
+					"example.com/root/root_grpc_service_config.json.go",
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			files := make([]*descriptorpb.FileDescriptorProto, 0, len(tt.files))
+			for _, file := range tt.files {
+				files = append(files, testFile(t, file))
+			}
+			p := newTestPlugin(t, pluginOptions{conflict: conflictPreferJSON, filenames: tt.filenames}, files...)
+			for path, expected := range tt.expected {
+				file := p.gen.FilesByPath[path]
+				actual := [2]string{p.generatedFromProtoFilename(file), p.generatedFromJSONFilename(file)}
+				if actual != expected {
+					t.Errorf("%s: expected %v, got %v", path, expected, actual)
+				}
+			}
+		})
+	}
+	const expected = `unsupported filenames "short" (expected "parent", "unique" or "full_package")`
+	if err := filenameScheme("short").validate(); err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
+
+func TestGenerateFromJSONCollision(t *testing.T) {
+	const (
+		acmeServiceFile = `
+name: "acme/freight/v1/acme_service.proto"
+package: "acme.freight.v1"
+options { go_package: "example.com/freight/v1;freightv1" }
+service { name: "AcmeService" }
+`
+		serviceConfig = `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`
+	)
+	dir := writeTestFiles(t, map[string]string{
+		testFreightServiceConfigFile:                       serviceConfig,
+		"acme/freight/v1/freight_grpc_service_config.json": serviceConfig,
+	})
+	files := []*descriptorpb.FileDescriptorProto{testFile(t, testFreightServiceFile), testFile(t, acmeServiceFile)}
+	p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, files...)
+	expected := "run: generated file example.com/freight/v1/freight_grpc_service_config.json.go collides: " +
+		"it would be generated from both " + filepath.Join(dir, testFreightServiceConfigFile) +
+		" and " + filepath.Join(dir, "acme/freight/v1/freight_grpc_service_config.json")
+	if err := p.generateFromJSON(); err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+	p = newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON, filenames: filenamesUnique}, files...)
+	if err := p.generateFromJSON(); err != nil {
+		t.Fatal(err)
+	}
+	if generated := len(p.gen.Response().GetFile()); generated != 2 {
+		t.Errorf("expected 2 generated files, got %d", generated)
+	}
+}
@@ -0,0 +1,59 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+)
+
+// durationFields are the duration fields of method configs, which are normalized by formatting.
+var durationFields = map[string]struct{}{
+	"methodConfig[*].timeout":                    {},
+	"methodConfig[*].retryPolicy.initialBackoff": {},
+	"methodConfig[*].retryPolicy.maxBackoff":     {},
+	"methodConfig[*].hedgingPolicy.hedgingDelay": {},
+}
+
+// formatServiceConfig returns the service config JSON in canonical form, with sorted keys, the shortest durations
+// and 2-space indentation.
+func formatServiceConfig(data []byte) ([]byte, error) {
+	value, err := decodeJSONValue(data)
+	if err != nil {
+		return nil, err
+	}
+	var walk func(pattern string, value interface{})
+	walk = func(pattern string, value interface{}) {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			for key, field := range value {
+				fieldPattern := snakeToLowerCamel(key)
+				if pattern != "" {
+					fieldPattern = pattern + "." + fieldPattern
+				}
+				if s, ok := field.(string); ok {
+					if _, ok := durationFields[fieldPattern]; ok {
+						if d, err := configjson.ParseDuration(s); err == nil {
+							value[key] = configjson.FormatDuration(d)
+						}
+					}
+					continue
+				}
+				walk(fieldPattern, field)
+			}
+		case []interface{}:
+			for _, item := range value {
+				walk(pattern+"[*]", item)
+			}
+		}
+	}
+	walk("", value)
+	var result bytes.Buffer
+	enc := json.NewEncoder(&result)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	return result.Bytes(), nil
+}
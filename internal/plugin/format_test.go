@@ -0,0 +1,78 @@
+package plugin
+
+import (
+	"testing"
+)
+
+func TestFormatServiceConfig(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:  "sorted keys and indentation",
+			input: `{"methodConfig":[{"timeout":"10s","name":[{"service":"a.B","method":"C"}]}]}`,
+			expected: `{
+  "methodConfig": [
+    {
+      "name": [
+        {
+          "method": "C",
+          "service": "a.B"
+        }
+      ],
+      "timeout": "10s"
+    }
+  ]
+}
+`,
+		},
+		{
+			name: "durations",
+			input: `{"method_config": [{"timeout": "1.500s", "retryPolicy": {"initialBackoff": "0.10s", "maxBackoff": "2.0s"},` +
+				` "hedgingPolicy": {"hedgingDelay": "0.000000001s"}}]}`,
+			expected: `{
+  "method_config": [
+    {
+      "hedgingPolicy": {
+        "hedgingDelay": "0.000000001s"
+      },
+      "retryPolicy": {
+        "initialBackoff": "0.1s",
+        "maxBackoff": "2s"
+      },
+      "timeout": "1.5s"
+    }
+  ]
+}
+`,
+		},
+		{
+			name:  "unknown fields and numbers are preserved",
+			input: `{"unknown": "1.50s", "retryThrottling": {"maxTokens": 10, "tokenRatio": 0.10}}`,
+			expected: `{
+  "retryThrottling": {
+    "maxTokens": 10,
+    "tokenRatio": 0.10
+  },
+  "unknown": "1.50s"
+}
+`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := formatServiceConfig([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, actual)
+			}
+		})
+	}
+	if _, err := formatServiceConfig([]byte(`{"methodConfig": [}`)); err == nil {
+		t.Error("expected invalid JSON to fail")
+	}
+}
@@ -0,0 +1,135 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+	"gopkg.in/yaml.v3"
+)
+
+// gapicConfig is the subset of a GAPIC YAML configuration (config_schema_version 1.0.0) with retry settings.
+type gapicConfig struct {
+	Interfaces []gapicInterface `yaml:"interfaces"`
+}
+
+// gapicInterface is the configuration of a service in a GAPIC YAML configuration.
+type gapicInterface struct {
+	Name           string                `yaml:"name"`
+	RetryCodesDefs []gapicRetryCodesDef  `yaml:"retry_codes_def"`
+	RetryParamsDef []gapicRetryParamsDef `yaml:"retry_params_def"`
+	Methods        []gapicMethod         `yaml:"methods"`
+}
+
+// gapicRetryCodesDef is a named set of retryable status codes.
+type gapicRetryCodesDef struct {
+	Name       string   `yaml:"name"`
+	RetryCodes []string `yaml:"retry_codes"`
+}
+
+// gapicRetryParamsDef is a named set of retry parameters.
+type gapicRetryParamsDef struct {
+	Name                    string  `yaml:"name"`
+	InitialRetryDelayMillis int64   `yaml:"initial_retry_delay_millis"`
+	RetryDelayMultiplier    float64 `yaml:"retry_delay_multiplier"`
+	MaxRetryDelayMillis     int64   `yaml:"max_retry_delay_millis"`
+	TotalTimeoutMillis      int64   `yaml:"total_timeout_millis"`
+}
+
+// gapicMethod is the configuration of a method in a GAPIC YAML configuration.
+type gapicMethod struct {
+	Name            string `yaml:"name"`
+	RetryCodesName  string `yaml:"retry_codes_name"`
+	RetryParamsName string `yaml:"retry_params_name"`
+	TimeoutMillis   int64  `yaml:"timeout_millis"`
+}
+
+// gapicToServiceConfig converts the retry settings of a GAPIC YAML configuration to service config JSON.
+// GAPIC retries until a total timeout, which becomes the timeout of the method, with the most attempts gRPC allows.
+func gapicToServiceConfig(data []byte) ([]byte, error) {
+	var config gapicConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid GAPIC configuration: %w", err)
+	}
+	var methodConfigs []map[string]interface{}
+	// methodConfigsBySettings maps the JSON of method config settings to their method config, for grouping.
+	methodConfigsBySettings := map[string]map[string]interface{}{}
+	for _, service := range config.Interfaces {
+		retryCodes := map[string][]string{}
+		for _, def := range service.RetryCodesDefs {
+			retryCodes[def.Name] = def.RetryCodes
+		}
+		retryParams := map[string]gapicRetryParamsDef{}
+		for _, def := range service.RetryParamsDef {
+			retryParams[def.Name] = def
+		}
+		for _, method := range service.Methods {
+			settings := map[string]interface{}{}
+			params, hasParams := retryParams[method.RetryParamsName]
+			if method.RetryParamsName != "" && !hasParams {
+				return nil, fmt.Errorf(
+					"%s.%s: unknown retry_params_name %s",
+					service.Name,
+					method.Name,
+					method.RetryParamsName,
+				)
+			}
+			codes, hasCodes := retryCodes[method.RetryCodesName]
+			if method.RetryCodesName != "" && !hasCodes {
+				return nil, fmt.Errorf(
+					"%s.%s: unknown retry_codes_name %s",
+					service.Name,
+					method.Name,
+					method.RetryCodesName,
+				)
+			}
+			timeoutMillis := method.TimeoutMillis
+			if len(codes) > 0 && hasParams {
+				if params.TotalTimeoutMillis > 0 {
+					timeoutMillis = params.TotalTimeoutMillis
+				}
+				multiplier := params.RetryDelayMultiplier
+				if multiplier == 0 {
+					// GAPIC treats a missing multiplier as constant backoff, while gRPC requires one.
+					multiplier = 1
+				}
+				initialBackoff := time.Duration(params.InitialRetryDelayMillis) * time.Millisecond
+				maxBackoff := time.Duration(params.MaxRetryDelayMillis) * time.Millisecond
+				settings["retryPolicy"] = map[string]interface{}{
+					"maxAttempts":          maxAttemptsLimit,
+					"initialBackoff":       configjson.FormatDuration(initialBackoff),
+					"maxBackoff":           configjson.FormatDuration(maxBackoff),
+					"backoffMultiplier":    multiplier,
+					"retryableStatusCodes": codes,
+				}
+			}
+			if timeoutMillis > 0 {
+				settings["timeout"] = configjson.FormatDuration(time.Duration(timeoutMillis) * time.Millisecond)
+			}
+			if len(settings) == 0 {
+				continue
+			}
+			key, err := json.Marshal(settings)
+			if err != nil {
+				return nil, err
+			}
+			name := map[string]interface{}{"service": service.Name, "method": method.Name}
+			if methodConfig, ok := methodConfigsBySettings[string(key)]; ok {
+				methodConfig["name"] = append(methodConfig["name"].([]interface{}), name)
+				continue
+			}
+			settings["name"] = []interface{}{name}
+			methodConfigsBySettings[string(key)] = settings
+			methodConfigs = append(methodConfigs, settings)
+		}
+	}
+	if len(methodConfigs) == 0 {
+		return nil, fmt.Errorf("no retry or timeout settings found in GAPIC configuration")
+	}
+	serviceConfig, err := json.Marshal(map[string]interface{}{"methodConfig": methodConfigs})
+	if err != nil {
+		return nil, err
+	}
+	return formatServiceConfig(serviceConfig)
+}
@@ -0,0 +1,152 @@
+package plugin
+
+import "testing"
+
+func TestGAPICToServiceConfig(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		gapic    string
+		expected string
+		err      string
+	}{
+		{
+			name: "retries and timeouts",
+			gapic: `type: com.google.api.codegen.ConfigProto
+config_schema_version: 1.0.0
+interfaces:
+- name: einride.example.freight.v1.FreightService
+  retry_codes_def:
+  - name: idempotent
+    retry_codes: [UNAVAILABLE, DEADLINE_EXCEEDED]
+  - name: non_idempotent
+    retry_codes: []
+  retry_params_def:
+  - name: default
+    initial_retry_delay_millis: 100
+    retry_delay_multiplier: 1.3
+    max_retry_delay_millis: 60000
+    total_timeout_millis: 600000
+  methods:
+  - name: GetShipper
+    retry_codes_name: idempotent
+    retry_params_name: default
+    timeout_millis: 60000
+  - name: ListShippers
+    retry_codes_name: idempotent
+    retry_params_name: default
+  - name: CreateShipper
+    retry_codes_name: non_idempotent
+    retry_params_name: default
+    timeout_millis: 30000
+  - name: DeleteShipper
+`,
+			expected: `{
+  "methodConfig": [
+    {
+      "name": [
+        {
+          "method": "GetShipper",
+          "service": "einride.example.freight.v1.FreightService"
+        },
+        {
+          "method": "ListShippers",
+          "service": "einride.example.freight.v1.FreightService"
+        }
+      ],
+      "retryPolicy": {
+        "backoffMultiplier": 1.3,
+        "initialBackoff": "0.1s",
+        "maxAttempts": 5,
+        "maxBackoff": "60s",
+        "retryableStatusCodes": [
+          "UNAVAILABLE",
+          "DEADLINE_EXCEEDED"
+        ]
+      },
+      "timeout": "600s"
+    },
+    {
+      "name": [
+        {
+          "method": "CreateShipper",
+          "service": "einride.example.freight.v1.FreightService"
+        }
+      ],
+      "timeout": "30s"
+    }
+  ]
+}
+`,
+		},
+		{
+			name: "constant backoff",
+			gapic: `interfaces:
+- name: a.B
+  retry_codes_def: [{name: retry, retry_codes: [UNAVAILABLE]}]
+  retry_params_def: [{name: constant, initial_retry_delay_millis: 500, max_retry_delay_millis: 500}]
+  methods: [{name: C, retry_codes_name: retry, retry_params_name: constant, timeout_millis: 1000}]
+`,
+			expected: `{
+  "methodConfig": [
+    {
+      "name": [
+        {
+          "method": "C",
+          "service": "a.B"
+        }
+      ],
+      "retryPolicy": {
+        "backoffMultiplier": 1,
+        "initialBackoff": "0.5s",
+        "maxAttempts": 5,
+        "maxBackoff": "0.5s",
+        "retryableStatusCodes": [
+          "UNAVAILABLE"
+        ]
+      },
+      "timeout": "1s"
+    }
+  ]
+}
+`,
+		},
+		{
+			name: "unknown retry params",
+			gapic: `interfaces:
+- name: a.B
+  methods: [{name: C, retry_params_name: default}]
+`,
+			err: "a.B.C: unknown retry_params_name default",
+		},
+		{
+			name: "unknown retry codes",
+			gapic: `interfaces:
+- name: a.B
+  methods: [{name: C, retry_codes_name: idempotent}]
+`,
+			err: "a.B.C: unknown retry_codes_name idempotent",
+		},
+		{
+			name:  "no settings",
+			gapic: "interfaces: [{name: a.B, methods: [{name: C}]}]\n",
+			err:   "no retry or timeout settings found in GAPIC configuration",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := gapicToServiceConfig([]byte(tt.gapic))
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, actual)
+			}
+		})
+	}
+}
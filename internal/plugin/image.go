@@ -0,0 +1,162 @@
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+const (
+	// imageFileExtensionFieldNumber is the field number of buf_extension in the ImageFile message of Buf images.
+	imageFileExtensionFieldNumber protowire.Number = 8042
+	// imageFileIsImportFieldNumber is the field number of is_import in the ImageFileExtension message of Buf images.
+	imageFileIsImportFieldNumber protowire.Number = 1
+)
+
+// imageGoImportPath is the Go import path prefix of files without a go_package option, which protogen requires even
+// though nothing is generated.
+const imageGoImportPath = "grpc-service-config.invalid/"
+
+// readDescriptorSet reads a FileDescriptorSet, or a Buf image, in the binary or JSON format, from a file or from
+// stdin when the filename is "-". Returns the paths of files that a binary Buf image marks as imports, which are not
+// part of the module the image was built from.
+func readDescriptorSet(filename string) (*descriptorpb.FileDescriptorSet, map[string]struct{}, error) {
+	var data []byte
+	var err error
+	if filename == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("load descriptor set: %w", err)
+	}
+	var descriptorSet descriptorpb.FileDescriptorSet
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, &descriptorSet); err != nil {
+			return nil, nil, fmt.Errorf("load descriptor set %s: %w", filename, err)
+		}
+		return &descriptorSet, nil, nil
+	}
+	if err := (proto.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, &descriptorSet); err != nil {
+		return nil, nil, fmt.Errorf("load descriptor set %s: %w", filename, err)
+	}
+	imports, err := imageImports(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load descriptor set %s: %w", filename, err)
+	}
+	return &descriptorSet, imports, nil
+}
+
+// imageImports returns the paths of the files marked as imports in a binary Buf image.
+// The image is parsed on the wire level, since the Buf image proto is not a dependency.
+func imageImports(data []byte) (map[string]struct{}, error) {
+	result := map[string]struct{}{}
+	err := rangeFields(data, func(num protowire.Number, typ protowire.Type, file []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		var name string
+		var isImport bool
+		if err := rangeFields(file, func(num protowire.Number, typ protowire.Type, value []byte) error {
+			switch {
+			case num == 1 && typ == protowire.BytesType:
+				name = string(value)
+			case num == imageFileExtensionFieldNumber && typ == protowire.BytesType:
+				return rangeFields(value, func(num protowire.Number, typ protowire.Type, value []byte) error {
+					if num == imageFileIsImportFieldNumber && typ == protowire.VarintType {
+						v, _ := protowire.ConsumeVarint(value)
+						isImport = v != 0
+					}
+					return nil
+				})
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if isImport {
+			result[name] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// rangeFields calls f with the number, type and raw value of every field in a wire-format message.
+// Values of length-delimited fields are passed without their length prefix.
+func rangeFields(data []byte, f func(protowire.Number, protowire.Type, []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		m := protowire.ConsumeFieldValue(num, typ, data)
+		if m < 0 {
+			return protowire.ParseError(m)
+		}
+		value := data[:m]
+		if typ == protowire.BytesType {
+			value, _ = protowire.ConsumeBytes(value)
+		}
+		if err := f(num, typ, value); err != nil {
+			return err
+		}
+		data = data[m:]
+	}
+	return nil
+}
+
+// newPluginFromImage returns a plugin for the services in a Buf image or FileDescriptorSet, read from a file or from
+// stdin when the filename is "-", as if protoc generated every file in the image except imports.
+func newPluginFromImage(filename string, opts pluginOptions) (*plugin, error) {
+	descriptorSet, imports, err := readDescriptorSet(filename)
+	if err != nil {
+		return nil, err
+	}
+	p, err := newPluginFromFiles(descriptorSet.GetFile(), imports, opts)
+	if err != nil {
+		return nil, fmt.Errorf("load image %s: %w", filename, err)
+	}
+	return p, nil
+}
+
+// newPluginFromFiles returns a plugin for the services in the files, in topological order, as if protoc generated
+// every file except the imports.
+func newPluginFromFiles(
+	files []*descriptorpb.FileDescriptorProto,
+	imports map[string]struct{},
+	opts pluginOptions,
+) (*plugin, error) {
+	request := &pluginpb.CodeGeneratorRequest{ProtoFile: files}
+	var parameters []string
+	for _, file := range files {
+		if _, ok := imports[file.GetName()]; !ok {
+			request.FileToGenerate = append(request.FileToGenerate, file.GetName())
+		}
+		if file.GetOptions().GetGoPackage() == "" {
+			importPath := imageGoImportPath + path.Dir(file.GetName())
+			parameters = append(parameters, "M"+file.GetName()+"="+importPath)
+		}
+	}
+	request.Parameter = proto.String(strings.Join(parameters, ","))
+	gen, err := protogen.Options{}.New(request)
+	if err != nil {
+		return nil, err
+	}
+	return newPlugin(gen, opts)
+}
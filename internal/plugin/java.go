@@ -0,0 +1,100 @@
+package plugin
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// defaultJavaResourcePath is the default path pattern of generated grpc-java resources, which places the service
+// config of a service next to its generated Java classes.
+const defaultJavaResourcePath = "{java_package_path}/{service_name}.service_config.json"
+
+// javaResourcePathPlaceholder matches the placeholders of grpc-java resource path patterns.
+var javaResourcePathPlaceholder = regexp.MustCompile(`\{[^{}]*\}`)
+
+// javaResourcePath returns the path of the grpc-java resource of a service, with the placeholders of the pattern
+// replaced:
+//
+// {package} is the proto package, such as einride.example.freight.v1.
+// {package_path} is the path of the proto package, such as einride/example/freight/v1.
+// {java_package_path} is the path of the Java package, such as tech/einride/example/freight/v1.
+// {service} is the full name of the service, such as einride.example.freight.v1.FreightService.
+// {service_name} is the name of the service, such as FreightService.
+func javaResourcePath(pattern string, service protoreflect.ServiceDescriptor) (string, error) {
+	var err error
+	result := javaResourcePathPlaceholder.ReplaceAllStringFunc(pattern, func(placeholder string) string {
+		switch placeholder {
+		case "{package}":
+			return string(service.ParentFile().Package())
+		case "{package_path}":
+			return packagePath(service.ParentFile().Package())
+		case "{java_package_path}":
+			return strings.ReplaceAll(javaPackage(service.ParentFile()), ".", "/")
+		case "{service}":
+			return string(service.FullName())
+		case "{service_name}":
+			return string(service.Name())
+		}
+		if err == nil {
+			err = fmt.Errorf("unknown placeholder %s in Java resource path %q", placeholder, pattern)
+		}
+		return placeholder
+	})
+	if err != nil {
+		return "", err
+	}
+	return path.Clean(result), nil
+}
+
+// javaPackage returns the Java package of a proto file, which is the java_package option, or the proto package.
+func javaPackage(file protoreflect.FileDescriptor) string {
+	if options, ok := file.Options().(*descriptorpb.FileOptions); ok && options.GetJavaPackage() != "" {
+		return options.GetJavaPackage()
+	}
+	return string(file.Package())
+}
+
+// generateJavaResources writes the resolved service config of every service to the directory, as a JSON resource at
+// the path of the pattern, which grpc-java clients load from the classpath for ManagedChannelBuilder
+// defaultServiceConfig. Services of a package share the service config of the package.
+func (p *plugin) generateJavaResources(dir, pattern string) error {
+	if pattern == "" {
+		pattern = defaultJavaResourcePath
+	}
+	serviceConfigs, err := p.packageServiceConfigs()
+	if err != nil {
+		return err
+	}
+	services := map[string]protoreflect.FullName{}
+	for _, serviceConfig := range serviceConfigs {
+		formatted, err := formatServiceConfig([]byte(serviceConfig.json))
+		if err != nil {
+			return fmt.Errorf("emit %s Java resources: %w", serviceConfig.pkg, err)
+		}
+		for _, service := range serviceConfig.services {
+			resourcePath, err := javaResourcePath(pattern, service)
+			if err != nil {
+				return fmt.Errorf("emit %s Java resource: %w", service.FullName(), err)
+			}
+			if previous, ok := services[resourcePath]; ok {
+				return fmt.Errorf(
+					"emit %s Java resource: %s is also the Java resource of %s",
+					service.FullName(),
+					resourcePath,
+					previous,
+				)
+			}
+			services[resourcePath] = service.FullName()
+			g := p.gen.NewGeneratedFile(path.Join(dir, resourcePath), "")
+			if _, err := g.Write(formatted); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateJavaResources(t *testing.T) {
+	const serviceConfig = `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`
+	javaFile := strings.Replace(
+		testRouteServiceFile,
+		`options { go_package: "example.com/freight/v1;freightv1" }`,
+		`options { go_package: "example.com/freight/v1;freightv1" java_package: "tech.einride.example.freight.v1" }`,
+		1,
+	)
+	for _, tt := range []struct {
+		name         string
+		file         string
+		pattern      string
+		expectedName string
+		err          string
+	}{
+		{
+			name:         "default pattern",
+			file:         testRouteServiceFile,
+			expectedName: "java/einride/example/freight/v1/FreightService.service_config.json",
+		},
+		{
+			name:         "java package",
+			file:         javaFile,
+			pattern:      defaultJavaResourcePath,
+			expectedName: "java/tech/einride/example/freight/v1/FreightService.service_config.json",
+		},
+		{
+			name:         "package placeholders",
+			file:         javaFile,
+			pattern:      "{package_path}/{service}.json",
+			expectedName: "java/einride/example/freight/v1/einride.example.freight.v1.FreightService.json",
+		},
+		{
+			name:         "package name",
+			file:         testRouteServiceFile,
+			pattern:      "{package}/{service_name}.json",
+			expectedName: "java/einride.example.freight.v1/FreightService.json",
+		},
+		{
+			name:    "unknown placeholder",
+			file:    testRouteServiceFile,
+			pattern: "{service_path}.json",
+			err: "emit einride.example.freight.v1.FreightService Java resource: " +
+				`unknown placeholder {service_path} in Java resource path "{service_path}.json"`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTestFiles(t, map[string]string{testFreightServiceConfigFile: serviceConfig})
+			p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, tt.file))
+			err := p.generateJavaResources("java", tt.pattern)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			files := p.gen.Response().GetFile()
+			if len(files) != 1 {
+				t.Fatalf("expected 1 generated file, got %d", len(files))
+			}
+			if actual := files[0].GetName(); actual != tt.expectedName {
+				t.Errorf("expected name %s, got %s", tt.expectedName, actual)
+			}
+			const expected = `{
+  "methodConfig": [
+    {
+      "name": [
+        {}
+      ],
+      "timeout": "10s"
+    }
+  ]
+}
+`
+			if actual := files[0].GetContent(); actual != expected {
+				t.Errorf("expected content:\n%s\ngot:\n%s", expected, actual)
+			}
+		})
+	}
+}
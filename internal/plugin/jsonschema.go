@@ -0,0 +1,315 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.buf.build/protocolbuffers/go/grpc/grpc/grpc/service_config"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// jsonSchemaDraft is the JSON Schema draft of generated schemas.
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchema is the subset of JSON Schema used by the service config schema.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Defs                 map[string]*jsonSchema `json:"$defs,omitempty"`
+	Type                 []string               `json:"type,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	AnyOf                []*jsonSchema          `json:"anyOf,omitempty"`
+	// Not is used to express the `false` schema, which matches nothing.
+	Not *jsonSchema `json:"not,omitempty"`
+}
+
+// jsonSchemaFalse returns a schema matching no value.
+func jsonSchemaFalse() *jsonSchema {
+	return &jsonSchema{Not: &jsonSchema{}}
+}
+
+// durationPattern matches durations in the protobuf JSON format.
+const durationPattern = `^-?[0-9]+(\.[0-9]{1,9})?s$`
+
+// serviceConfigJSONSchema returns the JSON Schema of the service config JSON format.
+// The schema is generated from the service config proto, and accepts both JSON and original proto field names.
+func serviceConfigJSONSchema() *jsonSchema {
+	return messageJSONSchema("gRPC service config", (&service_config.ServiceConfig{}).ProtoReflect().Descriptor())
+}
+
+// messageJSONSchema returns the JSON Schema of the protobuf JSON format of the message.
+func messageJSONSchema(title string, descriptor protoreflect.MessageDescriptor) *jsonSchema {
+	schema := &jsonSchema{
+		Schema: jsonSchemaDraft,
+		Title:  title,
+		Ref:    jsonSchemaRef(descriptor),
+		Defs:   map[string]*jsonSchema{},
+	}
+	addJSONSchemaDef(schema.Defs, descriptor)
+	return schema
+}
+
+func jsonSchemaRef(message protoreflect.MessageDescriptor) string {
+	return "#/$defs/" + string(message.FullName())
+}
+
+func addJSONSchemaDef(defs map[string]*jsonSchema, message protoreflect.MessageDescriptor) {
+	if _, ok := defs[string(message.FullName())]; ok {
+		return
+	}
+	if schema, ok := wellKnownJSONSchema(message); ok {
+		defs[string(message.FullName())] = schema
+		return
+	}
+	schema := &jsonSchema{
+		Type:                 []string{"object"},
+		Properties:           map[string]*jsonSchema{},
+		AdditionalProperties: jsonSchemaFalse(),
+	}
+	defs[string(message.FullName())] = schema
+	for i := 0; i < message.Fields().Len(); i++ {
+		field := message.Fields().Get(i)
+		fieldSchema := jsonSchemaForField(defs, field)
+		schema.Properties[field.JSONName()] = fieldSchema
+		schema.Properties[string(field.Name())] = fieldSchema
+	}
+}
+
+func jsonSchemaForField(defs map[string]*jsonSchema, field protoreflect.FieldDescriptor) *jsonSchema {
+	switch {
+	case field.IsMap():
+		return &jsonSchema{
+			Type:                 []string{"object"},
+			AdditionalProperties: jsonSchemaForValue(defs, field.MapValue()),
+		}
+	case field.IsList():
+		return &jsonSchema{
+			Type:  []string{"array"},
+			Items: jsonSchemaForValue(defs, field),
+		}
+	}
+	return jsonSchemaForValue(defs, field)
+}
+
+func jsonSchemaForValue(defs map[string]*jsonSchema, field protoreflect.FieldDescriptor) *jsonSchema {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		addJSONSchemaDef(defs, field.Message())
+		return &jsonSchema{Ref: jsonSchemaRef(field.Message())}
+	case protoreflect.EnumKind:
+		values := field.Enum().Values()
+		names := make([]interface{}, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		return &jsonSchema{AnyOf: []*jsonSchema{
+			{Type: []string{"string"}, Enum: names},
+			{Type: []string{"integer"}},
+		}}
+	case protoreflect.BoolKind:
+		return &jsonSchema{Type: []string{"boolean"}}
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		return &jsonSchema{Type: []string{"string"}}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return &jsonSchema{Type: []string{"number", "string"}}
+	case protoreflect.Int64Kind,
+		protoreflect.Sint64Kind,
+		protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind,
+		protoreflect.Fixed64Kind:
+		// The protobuf JSON format encodes 64-bit integers as strings, but accepts numbers.
+		return &jsonSchema{Type: []string{"integer", "string"}}
+	}
+	return &jsonSchema{Type: []string{"integer"}}
+}
+
+func wellKnownJSONSchema(message protoreflect.MessageDescriptor) (*jsonSchema, bool) {
+	switch message.FullName() {
+	case "google.protobuf.Duration":
+		return &jsonSchema{Type: []string{"string"}, Pattern: durationPattern}, true
+	case "google.protobuf.BoolValue":
+		return &jsonSchema{Type: []string{"boolean"}}, true
+	case "google.protobuf.StringValue", "google.protobuf.BytesValue":
+		return &jsonSchema{Type: []string{"string"}}, true
+	case "google.protobuf.FloatValue", "google.protobuf.DoubleValue":
+		return &jsonSchema{Type: []string{"number", "string"}}, true
+	case "google.protobuf.Int32Value", "google.protobuf.UInt32Value":
+		return &jsonSchema{Type: []string{"integer"}}, true
+	case "google.protobuf.Int64Value", "google.protobuf.UInt64Value":
+		return &jsonSchema{Type: []string{"integer", "string"}}, true
+	case "google.protobuf.Struct":
+		return &jsonSchema{Type: []string{"object"}}, true
+	case "google.protobuf.ListValue":
+		return &jsonSchema{Type: []string{"array"}}, true
+	case "google.protobuf.Value":
+		return &jsonSchema{}, true
+	}
+	return nil, false
+}
+
+// validateJSONSchema validates a JSON document against the schema, and returns every violation found.
+// Violations of `additionalProperties` are reported separately, since they usually indicate misspelled fields.
+func validateJSONSchema(
+	schema *jsonSchema,
+	data []byte,
+) (unknownFields []jsonFinding, violations []jsonFinding, err error) {
+	var value interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return nil, nil, err
+	}
+	v := jsonSchemaValidator{root: schema, patterns: map[string]*regexp.Regexp{}}
+	v.validate(schema, value, "")
+	return v.unknownFields, v.violations, nil
+}
+
+type jsonSchemaValidator struct {
+	root          *jsonSchema
+	patterns      map[string]*regexp.Regexp
+	unknownFields []jsonFinding
+	violations    []jsonFinding
+}
+
+func (v *jsonSchemaValidator) resolve(schema *jsonSchema) *jsonSchema {
+	for schema.Ref != "" {
+		schema = v.root.Defs[strings.TrimPrefix(schema.Ref, "#/$defs/")]
+	}
+	return schema
+}
+
+func (v *jsonSchemaValidator) matches(schema *jsonSchema, value interface{}) bool {
+	nested := jsonSchemaValidator{root: v.root, patterns: v.patterns}
+	nested.validate(schema, value, "")
+	return len(nested.unknownFields) == 0 && len(nested.violations) == 0
+}
+
+func (v *jsonSchemaValidator) validate(schema *jsonSchema, value interface{}, path string) {
+	if value == nil {
+		// The protobuf JSON format accepts null for every field, and treats it as the default value.
+		return
+	}
+	schema = v.resolve(schema)
+	if schema.Not != nil {
+		if v.matches(schema.Not, value) {
+			v.violations = append(v.violations, jsonFinding{path: path, message: "value is not allowed"})
+		}
+		return
+	}
+	if len(schema.AnyOf) > 0 {
+		for _, alternative := range schema.AnyOf {
+			if v.matches(alternative, value) {
+				return
+			}
+		}
+		v.violations = append(v.violations, jsonFinding{path: path, message: fmt.Sprintf("invalid value %v", value)})
+		return
+	}
+	if len(schema.Type) > 0 && !jsonSchemaTypeMatches(schema.Type, value) {
+		v.violations = append(v.violations, jsonFinding{
+			path:    path,
+			message: fmt.Sprintf("expected %s", strings.Join(schema.Type, " or ")),
+		})
+		return
+	}
+	if len(schema.Enum) > 0 && !jsonSchemaEnumContains(schema.Enum, value) {
+		v.violations = append(v.violations, jsonFinding{path: path, message: fmt.Sprintf("invalid value %v", value)})
+		return
+	}
+	if s, ok := value.(string); ok && schema.Pattern != "" {
+		pattern, ok := v.patterns[schema.Pattern]
+		if !ok {
+			pattern = regexp.MustCompile(schema.Pattern)
+			v.patterns[schema.Pattern] = pattern
+		}
+		if !pattern.MatchString(s) {
+			v.violations = append(v.violations, jsonFinding{
+				path:    path,
+				message: fmt.Sprintf("%q does not match pattern %s", s, schema.Pattern),
+			})
+		}
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			if fieldSchema, ok := schema.Properties[key]; ok {
+				v.validate(fieldSchema, value[key], fieldPath)
+				continue
+			}
+			if schema.AdditionalProperties != nil {
+				if additional := v.resolve(schema.AdditionalProperties); additional.Not != nil {
+					v.unknownFields = append(v.unknownFields, jsonFinding{
+						path:    fieldPath,
+						message: fmt.Sprintf("unknown field %q", key),
+					})
+					continue
+				}
+				v.validate(schema.AdditionalProperties, value[key], fieldPath)
+			}
+		}
+	case []interface{}:
+		if schema.Items != nil {
+			for i, item := range value {
+				v.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+}
+
+func jsonSchemaTypeMatches(types []string, value interface{}) bool {
+	for _, t := range types {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case json.Number:
+			if t == "number" {
+				return true
+			}
+			if _, err := value.Int64(); err == nil && t == "integer" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func jsonSchemaEnumContains(enum []interface{}, value interface{}) bool {
+	for _, candidate := range enum {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package plugin
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	_ "google.golang.org/protobuf/types/known/durationpb" // registers google/protobuf/duration.proto
+)
+
+// testPolicyFile is a proto file with a message using the field types of the service config proto.
+const testPolicyFile = `
+name: "test/policy.proto"
+package: "test"
+dependency: "google/protobuf/duration.proto"
+message_type {
+  name: "Policy"
+  field { name: "max_attempts" number: 1 label: LABEL_OPTIONAL type: TYPE_UINT32 json_name: "maxAttempts" }
+  field {
+    name: "timeout" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Duration"
+    json_name: "timeout"
+  }
+  field { name: "codes" number: 3 label: LABEL_REPEATED type: TYPE_ENUM type_name: ".test.Code" json_name: "codes" }
+}
+enum_type {
+  name: "Code"
+  value { name: "OK" number: 0 }
+  value { name: "UNAVAILABLE" number: 14 }
+}
+`
+
+// testPolicyDescriptor returns the descriptor of the message in testPolicyFile.
+func testPolicyDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	file, err := protodesc.NewFile(testFile(t, testPolicyFile), protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file.Messages().ByName("Policy")
+}
+
+func TestMessageJSONSchema(t *testing.T) {
+	data, err := json.MarshalIndent(messageJSONSchema("Policy", testPolicyDescriptor(t)), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Policy",
+  "$ref": "#/$defs/test.Policy",
+  "$defs": {
+    "google.protobuf.Duration": {
+      "type": [
+        "string"
+      ],
+      "pattern": "^-?[0-9]+(\\.[0-9]{1,9})?s$"
+    },
+    "test.Policy": {
+      "type": [
+        "object"
+      ],
+      "properties": {
+        "codes": {
+          "type": [
+            "array"
+          ],
+          "items": {
+            "anyOf": [
+              {
+                "type": [
+                  "string"
+                ],
+                "enum": [
+                  "OK",
+                  "UNAVAILABLE"
+                ]
+              },
+              {
+                "type": [
+                  "integer"
+                ]
+              }
+            ]
+          }
+        },
+        "maxAttempts": {
+          "type": [
+            "integer"
+          ]
+        },
+        "max_attempts": {
+          "type": [
+            "integer"
+          ]
+        },
+        "timeout": {
+          "$ref": "#/$defs/google.protobuf.Duration"
+        }
+      },
+      "additionalProperties": {
+        "not": {}
+      }
+    }
+  }
+}`
+	if string(data) != expected {
+		t.Errorf("expected schema:\n%s\ngot:\n%s", expected, data)
+	}
+}
+
+func TestValidateJSONSchema(t *testing.T) {
+	schema := messageJSONSchema("Policy", testPolicyDescriptor(t))
+	for _, tt := range []struct {
+		name          string
+		data          string
+		unknownFields []string
+		violations    []string
+	}{
+		{
+			name: "valid",
+			data: `{"maxAttempts": 3, "timeout": "0.1s", "codes": ["UNAVAILABLE", 14]}`,
+		},
+		{
+			name: "proto field names",
+			data: `{"max_attempts": 3}`,
+		},
+		{
+			name: "null",
+			data: `{"timeout": null}`,
+		},
+		{
+			name:          "unknown field",
+			data:          `{"maxAttempt": 3}`,
+			unknownFields: []string{`maxAttempt: unknown field "maxAttempt"`},
+		},
+		{
+			name: "invalid values",
+			data: `{"maxAttempts": 1.5, "timeout": "1m", "codes": ["UNAVAILABLE", "UNKNOWN"]}`,
+			violations: []string{
+				"codes[1]: invalid value UNKNOWN",
+				"maxAttempts: expected integer",
+				`timeout: "1m" does not match pattern ^-?[0-9]+(\.[0-9]{1,9})?s$`,
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			unknownFields, violations, err := validateJSONSchema(schema, []byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := jsonFindingStrings(unknownFields); actual != strings.Join(tt.unknownFields, "\n") {
+				t.Errorf("expected unknown fields:\n%s\ngot:\n%s", strings.Join(tt.unknownFields, "\n"), actual)
+			}
+			if actual := jsonFindingStrings(violations); actual != strings.Join(tt.violations, "\n") {
+				t.Errorf("expected violations:\n%s\ngot:\n%s", strings.Join(tt.violations, "\n"), actual)
+			}
+		})
+	}
+}
+
+// jsonFindingStrings returns the findings as lines of paths and messages.
+func jsonFindingStrings(findings []jsonFinding) string {
+	lines := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		lines = append(lines, finding.path+": "+finding.message)
+	}
+	return strings.Join(lines, "\n")
+}
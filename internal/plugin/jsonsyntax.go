@@ -0,0 +1,52 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// describeJSONError describes a JSON decoding error, with a suggestion for common syntax mistakes.
+func describeJSONError(data []byte, err error) string {
+	if hint := jsonSyntaxHint(data, err); hint != "" {
+		return fmt.Sprintf("%v (%s)", err, hint)
+	}
+	return err.Error()
+}
+
+// jsonSyntaxHint returns a suggestion for fixing a JSON syntax error caused by a common mistake, such as a trailing
+// comma, a single-quoted string, an unquoted key or a comment. Returns an empty string when no suggestion applies.
+func jsonSyntaxHint(data []byte, err error) string {
+	var syntaxError *json.SyntaxError
+	if !errors.As(err, &syntaxError) || syntaxError.Offset <= 0 || syntaxError.Offset > int64(len(data)) {
+		return ""
+	}
+	// The offset of a syntax error is just past the offending byte.
+	offset := syntaxError.Offset - 1
+	previous := bytes.TrimRight(data[:offset], " \t\r\n")
+	switch c := data[offset]; {
+	case (c == '}' || c == ']') && bytes.HasSuffix(previous, []byte(",")):
+		line := offsetLocation("", data, int64(len(previous)-1)).line
+		return fmt.Sprintf("remove the trailing comma on line %d, JSON does not allow trailing commas", line)
+	case c == '\'':
+		return "use double quotes instead of single quotes, JSON does not allow single-quoted strings"
+	case c == '/' && offset+1 < int64(len(data)) && (data[offset+1] == '/' || data[offset+1] == '*'):
+		return "remove the comment, JSON does not allow comments"
+	case isJSONIdentifierStart(c) && (bytes.HasSuffix(previous, []byte("{")) || bytes.HasSuffix(previous, []byte(","))):
+		end := offset
+		for end < int64(len(data)) && isJSONIdentifierPart(data[end]) {
+			end++
+		}
+		return fmt.Sprintf("quote the key as %q, JSON requires object keys to be strings", data[offset:end])
+	}
+	return ""
+}
+
+func isJSONIdentifierStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isJSONIdentifierPart(c byte) bool {
+	return isJSONIdentifierStart(c) || c >= '0' && c <= '9'
+}
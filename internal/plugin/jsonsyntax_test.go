@@ -0,0 +1,61 @@
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDescribeJSONError(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			name: "trailing comma",
+			data: "{\n  \"methodConfig\": [],\n}",
+			expected: "invalid character '}' looking for beginning of object key string" +
+				" (remove the trailing comma on line 2, JSON does not allow trailing commas)",
+		},
+		{
+			name: "trailing comma in array",
+			data: `{"methodConfig": [{"name": [{}]},]}`,
+			expected: "invalid character ']' looking for beginning of value" +
+				" (remove the trailing comma on line 1, JSON does not allow trailing commas)",
+		},
+		{
+			name: "single quotes",
+			data: `{'methodConfig': []}`,
+			expected: "invalid character '\\'' looking for beginning of object key string" +
+				" (use double quotes instead of single quotes, JSON does not allow single-quoted strings)",
+		},
+		{
+			name: "comment",
+			data: "{\n  // retries\n  \"methodConfig\": []\n}",
+			expected: "invalid character '/' looking for beginning of object key string" +
+				" (remove the comment, JSON does not allow comments)",
+		},
+		{
+			name: "unquoted key",
+			data: `{"methodConfig": [], retryThrottling: {}}`,
+			expected: "invalid character 'r' looking for beginning of object key string" +
+				` (quote the key as "retryThrottling", JSON requires object keys to be strings)`,
+		},
+		{
+			name:     "other syntax error",
+			data:     `{"methodConfig" []}`,
+			expected: "invalid character '[' after object key",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := json.Unmarshal([]byte(tt.data), &serviceConfigJSON{})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if actual := describeJSONError([]byte(tt.data), err); actual != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, actual)
+			}
+		})
+	}
+}
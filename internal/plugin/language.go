@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"fmt"
+	"path"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// language is the language of the modules generated with the service configs.
+type language string
+
+const (
+	// languageGo generates Go files with service config constants.
+	languageGo language = "go"
+	// languageTypeScript generates TypeScript modules for @grpc/grpc-js clients.
+	languageTypeScript language = "ts"
+	// languagePython generates Python modules for grpcio clients.
+	languagePython language = "python"
+	// languageCSharp generates C# classes for Grpc.Net.Client clients.
+	languageCSharp language = "csharp"
+)
+
+// validate returns an error if the language is not supported.
+func (l language) validate() error {
+	switch l {
+	case languageGo, languageTypeScript, languagePython, languageCSharp:
+		return nil
+	}
+	return fmt.Errorf(
+		"unsupported lang %q (expected %q, %q, %q or %q)",
+		l,
+		languageGo,
+		languageTypeScript,
+		languagePython,
+		languageCSharp,
+	)
+}
+
+// moduleFilename returns the name of the module generated for a package in a language other than Go, named like the
+// generated Go files, such as einride/example/freight/v1/freight_grpc_service_config.ts.
+func moduleFilename(pkg protoreflect.FullName, extension string) string {
+	return path.Join(packagePath(pkg), string(pkg.Parent().Name())+"_grpc_service_config"+extension)
+}
+
+// generateModules generates a module with the resolved service config of every package in a language other than Go.
+func (p *plugin) generateModules(lang language) error {
+	serviceConfigs, err := p.packageServiceConfigs()
+	if err != nil {
+		return err
+	}
+	for _, serviceConfig := range serviceConfigs {
+		var err error
+		switch lang {
+		case languageTypeScript:
+			err = p.generateTypeScriptModule(serviceConfig)
+		case languagePython:
+			err = p.generatePythonModule(serviceConfig)
+		case languageCSharp:
+			err = p.generateCSharpModule(serviceConfig)
+		}
+		if err != nil {
+			return fmt.Errorf("generate %s %s module: %w", serviceConfig.pkg, lang, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+)
+
+// loadBalancingConfigValidator validates load balancing configs in a service config.
+// Invalid nested load balancing configs are only reported as generic errors when dialing, so they are validated
+// separately to give actionable diagnostics.
+type loadBalancingConfigValidator struct {
+	diagnostics   *diagnostics
+	serviceConfig resolvedServiceConfig
+}
+
+func (v *loadBalancingConfigValidator) errorf(path string, format string, args ...interface{}) {
+	v.diagnostics.errorf(
+		ruleInvalidLoadBalancingConfig,
+		v.serviceConfig.locate(path),
+		"%s: %s",
+		path,
+		fmt.Sprintf(format, args...),
+	)
+}
+
+func (v *loadBalancingConfigValidator) warnf(path string, format string, args ...interface{}) {
+	v.diagnostics.warnf(
+		ruleInvalidLoadBalancingConfig,
+		v.serviceConfig.locate(path),
+		"%s: %s",
+		path,
+		fmt.Sprintf(format, args...),
+	)
+}
+
+// validateList validates a list of load balancing configs, such as the top-level loadBalancingConfig or the child
+// policy of a parent policy. Each entry must contain exactly one policy.
+func (v *loadBalancingConfigValidator) validateList(path string, configs []map[string]json.RawMessage) {
+	for i, config := range configs {
+		entryPath := fmt.Sprintf("%s[%d]", path, i)
+		if len(config) != 1 {
+			v.errorf(entryPath, "must contain exactly one load balancing policy, but contains %d", len(config))
+		}
+		names := make([]string, 0, len(config))
+		for name := range config {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			v.validatePolicy(entryPath+"."+name, name, config[name])
+		}
+	}
+}
+
+// validatePolicy validates the config of a load balancing policy.
+func (v *loadBalancingConfigValidator) validatePolicy(path string, name string, config json.RawMessage) {
+	switch name {
+	case "outlier_detection", "outlier_detection_experimental":
+		v.validateOutlierDetection(path, config)
+	case "ring_hash", "ring_hash_experimental":
+		v.validateRingHash(path, config)
+	case "xds_cluster_manager", "xds_cluster_manager_experimental":
+		v.validateXDSClusterManager(path, config)
+	case "weighted_target", "weighted_target_experimental":
+		v.validateWeightedTarget(path, config)
+	case "pick_first":
+		v.validatePickFirst(path, config)
+	}
+}
+
+// validateChildPolicy validates the required child policy of a parent policy.
+func (v *loadBalancingConfigValidator) validateChildPolicy(path string, childPolicy []map[string]json.RawMessage) {
+	if len(childPolicy) == 0 {
+		v.errorf(path, "child policy is required")
+		return
+	}
+	v.validateList(path, childPolicy)
+}
+
+// validateDuration validates an optional non-negative duration.
+func (v *loadBalancingConfigValidator) validateDuration(path string, s *string) {
+	if s == nil {
+		return
+	}
+	d, err := configjson.ParseDuration(*s)
+	if err != nil {
+		v.errorf(path, "%v", err)
+		return
+	}
+	if d < 0 {
+		v.errorf(path, "duration %s must not be negative", *s)
+	}
+}
+
+// validatePercentage validates an optional percentage.
+func (v *loadBalancingConfigValidator) validatePercentage(path string, percentage *uint32) {
+	if percentage != nil && *percentage > 100 {
+		v.errorf(path, "percentage %d must not exceed 100", *percentage)
+	}
+}
+
+// outlierDetectionConfigJSON is an outlier detection load balancing config in the service config JSON format.
+type outlierDetectionConfigJSON struct {
+	Interval            *string `json:"interval"`
+	BaseEjectionTime    *string `json:"baseEjectionTime"`
+	MaxEjectionTime     *string `json:"maxEjectionTime"`
+	MaxEjectionPercent  *uint32 `json:"maxEjectionPercent"`
+	SuccessRateEjection *struct {
+		StdevFactor           *uint32 `json:"stdevFactor"`
+		EnforcementPercentage *uint32 `json:"enforcementPercentage"`
+		MinimumHosts          *uint32 `json:"minimumHosts"`
+		RequestVolume         *uint32 `json:"requestVolume"`
+	} `json:"successRateEjection"`
+	FailurePercentageEjection *struct {
+		Threshold             *uint32 `json:"threshold"`
+		EnforcementPercentage *uint32 `json:"enforcementPercentage"`
+		MinimumHosts          *uint32 `json:"minimumHosts"`
+		RequestVolume         *uint32 `json:"requestVolume"`
+	} `json:"failurePercentageEjection"`
+	ChildPolicy []map[string]json.RawMessage `json:"childPolicy"`
+}
+
+func (v *loadBalancingConfigValidator) validateOutlierDetection(path string, data json.RawMessage) {
+	var config outlierDetectionConfigJSON
+	if err := json.Unmarshal(data, &config); err != nil {
+		v.errorf(path, "invalid outlier detection config: %v", err)
+		return
+	}
+	v.validateDuration(path+".interval", config.Interval)
+	v.validateDuration(path+".baseEjectionTime", config.BaseEjectionTime)
+	v.validateDuration(path+".maxEjectionTime", config.MaxEjectionTime)
+	v.validatePercentage(path+".maxEjectionPercent", config.MaxEjectionPercent)
+	if successRate := config.SuccessRateEjection; successRate != nil {
+		v.validatePercentage(
+			path+".successRateEjection.enforcementPercentage",
+			successRate.EnforcementPercentage,
+		)
+	}
+	if failurePercentage := config.FailurePercentageEjection; failurePercentage != nil {
+		v.validatePercentage(path+".failurePercentageEjection.threshold", failurePercentage.Threshold)
+		v.validatePercentage(
+			path+".failurePercentageEjection.enforcementPercentage",
+			failurePercentage.EnforcementPercentage,
+		)
+	}
+	v.validateChildPolicy(path+".childPolicy", config.ChildPolicy)
+}
+
+const (
+	// ringHashMaxRingSize is the maximum ring size accepted by gRPC.
+	ringHashMaxRingSize = 8 * 1024 * 1024
+	// ringHashLargeRingSize is the ring size above which rings use a significant amount of client memory, since
+	// every channel keeps a ring per cluster.
+	ringHashLargeRingSize = 1024 * 1024
+)
+
+// ringHashConfigJSON is a ring hash load balancing config in the service config JSON format.
+type ringHashConfigJSON struct {
+	MinRingSize *json.Number `json:"minRingSize"`
+	MaxRingSize *json.Number `json:"maxRingSize"`
+}
+
+func (v *loadBalancingConfigValidator) validateRingHash(path string, data json.RawMessage) {
+	var config ringHashConfigJSON
+	if err := json.Unmarshal(data, &config); err != nil {
+		v.errorf(path, "invalid ring hash config: %v", err)
+		return
+	}
+	minRingSize, minOK := v.validateRingSize(path+".minRingSize", config.MinRingSize)
+	maxRingSize, maxOK := v.validateRingSize(path+".maxRingSize", config.MaxRingSize)
+	if minOK && maxOK && minRingSize > maxRingSize {
+		v.errorf(path, "minRingSize %d must not exceed maxRingSize %d", minRingSize, maxRingSize)
+	}
+}
+
+// validateRingSize validates an optional ring size, and returns it when set and valid.
+func (v *loadBalancingConfigValidator) validateRingSize(path string, size *json.Number) (uint64, bool) {
+	if size == nil {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(size.String(), 10, 64)
+	if err != nil {
+		v.errorf(path, "invalid ring size %s", size)
+		return 0, false
+	}
+	switch {
+	case n == 0:
+		// Zero is the same as the default ring size.
+		return 0, false
+	case n > ringHashMaxRingSize:
+		v.errorf(path, "ring size %d must not exceed %d", n, ringHashMaxRingSize)
+		return 0, false
+	case n > ringHashLargeRingSize:
+		v.warnf(path, "ring size %d exceeds %d, and will use a large amount of client memory", n, ringHashLargeRingSize)
+	}
+	return n, true
+}
+
+// xdsClusterManagerConfigJSON is an xDS cluster manager load balancing config in the service config JSON format.
+type xdsClusterManagerConfigJSON struct {
+	Children map[string]struct {
+		ChildPolicy []map[string]json.RawMessage `json:"childPolicy"`
+	} `json:"children"`
+}
+
+func (v *loadBalancingConfigValidator) validateXDSClusterManager(path string, data json.RawMessage) {
+	var config xdsClusterManagerConfigJSON
+	if err := json.Unmarshal(data, &config); err != nil {
+		v.errorf(path, "invalid xDS cluster manager config: %v", err)
+		return
+	}
+	if len(config.Children) == 0 {
+		v.errorf(path, "at least one child is required")
+		return
+	}
+	names := make([]string, 0, len(config.Children))
+	for name := range config.Children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v.validateChildPolicy(path+".children."+name+".childPolicy", config.Children[name].ChildPolicy)
+	}
+}
+
+// weightedTargetConfigJSON is a weighted target load balancing config in the service config JSON format.
+type weightedTargetConfigJSON struct {
+	Targets map[string]struct {
+		Weight      *uint32                      `json:"weight"`
+		ChildPolicy []map[string]json.RawMessage `json:"childPolicy"`
+	} `json:"targets"`
+}
+
+func (v *loadBalancingConfigValidator) validateWeightedTarget(path string, data json.RawMessage) {
+	var config weightedTargetConfigJSON
+	if err := json.Unmarshal(data, &config); err != nil {
+		v.errorf(path, "invalid weighted target config: %v", err)
+		return
+	}
+	if len(config.Targets) == 0 {
+		v.errorf(path, "at least one target is required")
+		return
+	}
+	names := make([]string, 0, len(config.Targets))
+	for name := range config.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		target := config.Targets[name]
+		targetPath := path + ".targets." + name
+		if target.Weight == nil || *target.Weight == 0 {
+			v.errorf(targetPath+".weight", "weight must be greater than 0")
+		}
+		v.validateChildPolicy(targetPath+".childPolicy", target.ChildPolicy)
+	}
+}
+
+// pickFirstConfigJSON is a pick first load balancing config in the service config JSON format.
+type pickFirstConfigJSON struct {
+	ShuffleAddressList json.RawMessage `json:"shuffleAddressList"`
+}
+
+func (v *loadBalancingConfigValidator) validatePickFirst(path string, data json.RawMessage) {
+	var config pickFirstConfigJSON
+	if err := json.Unmarshal(data, &config); err != nil {
+		v.errorf(path, "invalid pick first config: %v", err)
+		return
+	}
+	if config.ShuffleAddressList != nil {
+		var shuffleAddressList bool
+		if err := json.Unmarshal(config.ShuffleAddressList, &shuffleAddressList); err != nil {
+			v.errorf(path+".shuffleAddressList", "must be a boolean, but is %s", config.ShuffleAddressList)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLoadBalancingConfigValidator(t *testing.T) {
+	for _, tt := range []struct {
+		name                string
+		loadBalancingConfig string
+		diagnostics         []string
+	}{
+		{
+			name:                "round robin",
+			loadBalancingConfig: `[{"round_robin": {}}]`,
+		},
+		{
+			name: "valid outlier detection",
+			loadBalancingConfig: `[{"outlier_detection_experimental": {
+  "interval": "10s",
+  "maxEjectionPercent": 10,
+  "failurePercentageEjection": {"threshold": 85},
+  "childPolicy": [{"round_robin": {}}]
+}}]`,
+		},
+		{
+			name:                "more than one policy",
+			loadBalancingConfig: `[{"round_robin": {}, "pick_first": {}}]`,
+			diagnostics: []string{
+				"a.json: error: loadBalancingConfig[0]: must contain exactly one load balancing policy," +
+					" but contains 2 (INVALID_LOAD_BALANCING_CONFIG)",
+			},
+		},
+		{
+			name: "invalid outlier detection",
+			loadBalancingConfig: `[{"outlier_detection": {
+  "interval": "-1s",
+  "baseEjectionTime": "30",
+  "maxEjectionPercent": 101,
+  "successRateEjection": {"enforcementPercentage": 200},
+  "failurePercentageEjection": {"threshold": 101, "enforcementPercentage": 100}
+}}]`,
+			diagnostics: []string{
+				"a.json: error: loadBalancingConfig[0].outlier_detection.interval:" +
+					" duration -1s must not be negative (INVALID_LOAD_BALANCING_CONFIG)",
+				"a.json: error: loadBalancingConfig[0].outlier_detection.baseEjectionTime:" +
+					` malformed duration "30" (INVALID_LOAD_BALANCING_CONFIG)`,
+				"a.json: error: loadBalancingConfig[0].outlier_detection.maxEjectionPercent:" +
+					" percentage 101 must not exceed 100 (INVALID_LOAD_BALANCING_CONFIG)",
+				"a.json: error: loadBalancingConfig[0].outlier_detection.successRateEjection.enforcementPercentage:" +
+					" percentage 200 must not exceed 100 (INVALID_LOAD_BALANCING_CONFIG)",
+				"a.json: error: loadBalancingConfig[0].outlier_detection.failurePercentageEjection.threshold:" +
+					" percentage 101 must not exceed 100 (INVALID_LOAD_BALANCING_CONFIG)",
+				"a.json: error: loadBalancingConfig[0].outlier_detection.childPolicy:" +
+					" child policy is required (INVALID_LOAD_BALANCING_CONFIG)",
+			},
+		},
+		{
+			name: "invalid child policy",
+			loadBalancingConfig: `[{"outlier_detection": {
+  "childPolicy": [{"outlier_detection": {"interval": "1m", "childPolicy": [{"round_robin": {}}]}}]
+}}]`,
+			diagnostics: []string{
+				"a.json: error: loadBalancingConfig[0].outlier_detection.childPolicy[0].outlier_detection.interval:" +
+					` malformed duration "1m" (INVALID_LOAD_BALANCING_CONFIG)`,
+			},
+		},
+		{
+			name:                "valid ring hash",
+			loadBalancingConfig: `[{"ring_hash_experimental": {"minRingSize": 1024, "maxRingSize": 4096}}]`,
+		},
+		{
+			name:                "default ring size",
+			loadBalancingConfig: `[{"ring_hash": {"minRingSize": 0, "maxRingSize": 16}}]`,
+		},
+		{
+			name:                "invalid ring hash",
+			loadBalancingConfig: `[{"ring_hash": {"minRingSize": 4096, "maxRingSize": 1024}}]`,
+			diagnostics: []string{
+				"a.json: error: loadBalancingConfig[0].ring_hash: minRingSize 4096 must not exceed maxRingSize 1024" +
+					" (INVALID_LOAD_BALANCING_CONFIG)",
+			},
+		},
+		{
+			name:                "ring sizes",
+			loadBalancingConfig: `[{"ring_hash": {"minRingSize": -1, "maxRingSize": 8388609}}]`,
+			diagnostics: []string{
+				"a.json: error: loadBalancingConfig[0].ring_hash.minRingSize: invalid ring size -1" +
+					" (INVALID_LOAD_BALANCING_CONFIG)",
+				"a.json: error: loadBalancingConfig[0].ring_hash.maxRingSize: ring size 8388609 must not exceed" +
+					" 8388608 (INVALID_LOAD_BALANCING_CONFIG)",
+			},
+		},
+		{
+			name:                "large ring size",
+			loadBalancingConfig: `[{"ring_hash": {"maxRingSize": 2097152}}]`,
+			diagnostics: []string{
+				"a.json: warning: loadBalancingConfig[0].ring_hash.maxRingSize: ring size 2097152 exceeds 1048576," +
+					" and will use a large amount of client memory (INVALID_LOAD_BALANCING_CONFIG)",
+			},
+		},
+		{
+			name: "valid xDS cluster manager",
+			loadBalancingConfig: `[{"xds_cluster_manager_experimental": {"children": {
+  "a": {"childPolicy": [{"round_robin": {}}]},
+  "b": {"childPolicy": [{"pick_first": {}}]}
+}}}]`,
+		},
+		{
+			name:                "xDS cluster manager without children",
+			loadBalancingConfig: `[{"xds_cluster_manager": {}}]`,
+			diagnostics: []string{
+				"a.json: error: loadBalancingConfig[0].xds_cluster_manager: at least one child is required" +
+					" (INVALID_LOAD_BALANCING_CONFIG)",
+			},
+		},
+		{
+			name: "invalid xDS cluster manager children",
+			loadBalancingConfig: `[{"xds_cluster_manager": {"children": {
+  "b": {"childPolicy": [{"ring_hash": {"minRingSize": -1}}]},
+  "a": {}
+}}}]`,
+			diagnostics: []string{
+				"a.json: error: loadBalancingConfig[0].xds_cluster_manager.children.a.childPolicy:" +
+					" child policy is required (INVALID_LOAD_BALANCING_CONFIG)",
+				"a.json: error: loadBalancingConfig[0].xds_cluster_manager.children.b.childPolicy[0].ring_hash" +
+					".minRingSize: invalid ring size -1 (INVALID_LOAD_BALANCING_CONFIG)",
+			},
+		},
+		{
+			name: "valid weighted target",
+			loadBalancingConfig: `[{"weighted_target_experimental": {"targets": {
+  "a": {"weight": 1, "childPolicy": [{"round_robin": {}}]},
+  "b": {"weight": 3, "childPolicy": [{"round_robin": {}}]}
+}}}]`,
+		},
+		{
+			name:                "weighted target without targets",
+			loadBalancingConfig: `[{"weighted_target": {"targets": {}}}]`,
+			diagnostics: []string{
+				"a.json: error: loadBalancingConfig[0].weighted_target: at least one target is required" +
+					" (INVALID_LOAD_BALANCING_CONFIG)",
+			},
+		},
+		{
+			name: "invalid weighted target",
+			loadBalancingConfig: `[{"weighted_target": {"targets": {
+  "b": {"weight": 0, "childPolicy": [{"round_robin": {}}]},
+  "a": {}
+}}}]`,
+			diagnostics: []string{
+				"a.json: error: loadBalancingConfig[0].weighted_target.targets.a.weight: weight must be greater than 0" +
+					" (INVALID_LOAD_BALANCING_CONFIG)",
+				"a.json: error: loadBalancingConfig[0].weighted_target.targets.a.childPolicy:" +
+					" child policy is required (INVALID_LOAD_BALANCING_CONFIG)",
+				"a.json: error: loadBalancingConfig[0].weighted_target.targets.b.weight: weight must be greater than 0" +
+					" (INVALID_LOAD_BALANCING_CONFIG)",
+			},
+		},
+		{
+			name:                "pick first",
+			loadBalancingConfig: `[{"pick_first": {"shuffleAddressList": true}}]`,
+		},
+		{
+			name:                "invalid pick first shuffleAddressList",
+			loadBalancingConfig: `[{"pick_first": {"shuffleAddressList": "yes"}}]`,
+			diagnostics: []string{
+				"a.json: error: loadBalancingConfig[0].pick_first.shuffleAddressList: must be a boolean," +
+					` but is "yes" (INVALID_LOAD_BALANCING_CONFIG)`,
+			},
+		},
+		{
+			name:                "malformed outlier detection",
+			loadBalancingConfig: `[{"outlier_detection": {"interval": 10}}]`,
+			diagnostics: []string{
+				"a.json: error: loadBalancingConfig[0].outlier_detection: invalid outlier detection config:" +
+					" json: cannot unmarshal number into Go struct field" +
+					" outlierDetectionConfigJSON.interval of type string (INVALID_LOAD_BALANCING_CONFIG)",
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var loadBalancingConfig []map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.loadBalancingConfig), &loadBalancingConfig); err != nil {
+				t.Fatal(err)
+			}
+			var found diagnostics
+			v := loadBalancingConfigValidator{diagnostics: &found, serviceConfig: resolvedServiceConfig{source: "a.json"}}
+			v.validateList("loadBalancingConfig", loadBalancingConfig)
+			if len(found.list) != len(tt.diagnostics) {
+				t.Fatalf("expected %d diagnostics, got %v", len(tt.diagnostics), found.list)
+			}
+			for i, diagnostic := range found.list {
+				if diagnostic.String() != tt.diagnostics[i] {
+					t.Errorf("expected diagnostic %q, got %q", tt.diagnostics[i], diagnostic)
+				}
+			}
+		})
+	}
+}
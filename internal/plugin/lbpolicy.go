@@ -0,0 +1,56 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// deprecatedLoadBalancingPolicyFields are the names of the deprecated loadBalancingPolicy field.
+var deprecatedLoadBalancingPolicyFields = []string{"loadBalancingPolicy", "load_balancing_policy"}
+
+// loadBalancingPolicyReplacement returns the loadBalancingConfig replacing a deprecated loadBalancingPolicy.
+func loadBalancingPolicyReplacement(policy string) string {
+	return fmt.Sprintf(`[{%q: {}}]`, strings.ToLower(policy))
+}
+
+// fixDeprecatedLoadBalancingPolicy rewrites the deprecated loadBalancingPolicy field of a service config to the
+// equivalent loadBalancingConfig. When the service config already has a loadBalancingConfig, which takes precedence,
+// the deprecated field is removed. Returns false when the service config does not use the deprecated field.
+func fixDeprecatedLoadBalancingPolicy(serviceConfig string) (string, bool, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(serviceConfig), &fields); err != nil {
+		return "", false, fmt.Errorf("fix loadBalancingPolicy: %w", err)
+	}
+	var fixed bool
+	for _, field := range deprecatedLoadBalancingPolicyFields {
+		value, ok := fields[field]
+		if !ok {
+			continue
+		}
+		delete(fields, field)
+		fixed = true
+		var policy string
+		if err := json.Unmarshal(value, &policy); err != nil {
+			return "", false, fmt.Errorf("fix loadBalancingPolicy: %s: %w", field, err)
+		}
+		if policy == "" {
+			continue
+		}
+		if _, ok := fields["loadBalancingConfig"]; ok {
+			continue
+		}
+		if _, ok := fields["load_balancing_config"]; ok {
+			continue
+		}
+		fields["loadBalancingConfig"] = json.RawMessage(loadBalancingPolicyReplacement(policy))
+	}
+	if !fixed {
+		return serviceConfig, false, nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return "", false, fmt.Errorf("fix loadBalancingPolicy: %w", err)
+	}
+	return string(data), true, nil
+}
@@ -0,0 +1,100 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+func TestFixDeprecatedLoadBalancingPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		serviceConfig string
+		expected      string
+		fixed         bool
+		err           string
+	}{
+		{
+			name:          "no deprecated field",
+			serviceConfig: `{"loadBalancingConfig": [{"round_robin": {}}]}`,
+			expected:      `{"loadBalancingConfig": [{"round_robin": {}}]}`,
+		},
+		{
+			name:          "deprecated field",
+			serviceConfig: `{"loadBalancingPolicy": "ROUND_ROBIN", "methodConfig": [{"name": [{}]}]}`,
+			expected:      `{"loadBalancingConfig":[{"round_robin":{}}],"methodConfig":[{"name":[{}]}]}`,
+			fixed:         true,
+		},
+		{
+			name:          "proto field name",
+			serviceConfig: `{"load_balancing_policy": "pick_first"}`,
+			expected:      `{"loadBalancingConfig":[{"pick_first":{}}]}`,
+			fixed:         true,
+		},
+		{
+			name:          "loadBalancingConfig takes precedence",
+			serviceConfig: `{"loadBalancingPolicy": "ROUND_ROBIN", "loadBalancingConfig": [{"pick_first": {}}]}`,
+			expected:      `{"loadBalancingConfig":[{"pick_first":{}}]}`,
+			fixed:         true,
+		},
+		{
+			name:          "empty policy",
+			serviceConfig: `{"loadBalancingPolicy": ""}`,
+			expected:      `{}`,
+			fixed:         true,
+		},
+		{
+			name:          "invalid policy",
+			serviceConfig: `{"loadBalancingPolicy": 1}`,
+			err:           "fix loadBalancingPolicy: loadBalancingPolicy: json: cannot unmarshal number",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, fixed, err := fixDeprecatedLoadBalancingPolicy(tt.serviceConfig)
+			if tt.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Fatalf("expected error starting with %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != tt.expected || fixed != tt.fixed {
+				t.Errorf("expected %s (fixed %v), got %s (fixed %v)", tt.expected, tt.fixed, actual, fixed)
+			}
+		})
+	}
+}
+
+func TestGenerateFromJSONFix(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{testFreightServiceConfigFile: `{"loadBalancingPolicy": "ROUND_ROBIN"}`})
+	for _, tt := range []struct {
+		fix      bool
+		expected string
+	}{
+		{fix: false, expected: "const ServiceConfig = `{\"loadBalancingPolicy\": \"ROUND_ROBIN\"}`"},
+		{fix: true, expected: "const ServiceConfig = `{\"loadBalancingConfig\":[{\"round_robin\":{}}]}`"},
+	} {
+		gen, err := protogen.Options{}.New(testRequest(t, "", testFile(t, testFreightServiceFile)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := newPlugin(gen, pluginOptions{path: dir, conflict: conflictPreferJSON, fix: tt.fix})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.generateFromJSON(); err != nil {
+			t.Fatal(err)
+		}
+		response := gen.Response()
+		if len(response.GetFile()) != 1 {
+			t.Fatalf("expected 1 generated file, got %d", len(response.GetFile()))
+		}
+		if content := response.GetFile()[0].GetContent(); !strings.Contains(content, tt.expected) {
+			t.Errorf("fix=%v: expected generated file containing %s, got:\n%s", tt.fix, tt.expected, content)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package plugin
+
+import (
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// The exported identifiers in this file back the public serviceconfigcheck package, so that other tools reuse the exact
+// resolution and validation logic of the plugin. They are not a stable API, use the serviceconfigcheck package instead.
+
+// Source is a service config resolved for a service, or read from a file.
+type Source struct {
+	// Path is the path of the JSON file or proto file the service config was resolved from.
+	Path string
+	// JSON is the service config JSON.
+	JSON string
+	// Annotation is true when the service config was resolved from a default_service_config file annotation.
+	Annotation bool
+	// Merged is true when the service config was merged from a JSON file and a default_service_config annotation.
+	Merged bool
+}
+
+func newSource(serviceConfig resolvedServiceConfig) Source {
+	return Source{
+		Path:       serviceConfig.source,
+		JSON:       serviceConfig.json,
+		Annotation: serviceConfig.annotation,
+		Merged:     serviceConfig.merged,
+	}
+}
+
+func (s Source) resolved() resolvedServiceConfig {
+	return resolvedServiceConfig{source: s.Path, json: s.JSON, annotation: s.Annotation, merged: s.Merged}
+}
+
+// Resolve resolves the service config of a service, from a service config JSON file in path or a
+// default_service_config annotation in files, with the conflict policy of the conflict plugin option.
+func Resolve(
+	files *protoregistry.Files,
+	path string,
+	conflict string,
+	service protoreflect.ServiceDescriptor,
+) (Source, bool, error) {
+	policy := conflictPolicy(conflict)
+	if err := policy.validate(); err != nil {
+		return Source{}, false, err
+	}
+	p := &plugin{files: files, pluginOptions: pluginOptions{path: path, conflict: policy}}
+	serviceConfig, ok, err := p.resolveServiceConfig(service)
+	if err != nil || !ok {
+		return Source{}, false, err
+	}
+	return newSource(serviceConfig), true, nil
+}
+
+// ReadSource reads a service config JSON or YAML file, the way the plugin and the standalone CLI read them.
+func ReadSource(filename string) (Source, error) {
+	serviceConfig, err := readServiceConfigSource(filename)
+	if err != nil {
+		return Source{}, err
+	}
+	return newSource(serviceConfig), nil
+}
+
+// CheckOptions configures Check.
+type CheckOptions struct {
+	// TargetGRPCGoVersion is the oldest grpc-go release service configs must be compatible with, or empty.
+	TargetGRPCGoVersion string
+	// RequireLossless requires service config JSON files to survive a round trip through the service config proto.
+	RequireLossless bool
+	// MaxConfigBytes is the maximum size of a compacted service config, or 0 for no limit.
+	MaxConfigBytes int
+	// Fix accepts deprecated fields that the fix plugin option rewrites in generated code.
+	Fix bool
+}
+
+// Finding is a problem found by Check.
+type Finding struct {
+	// Rule is the ID of the rule that found the problem.
+	Rule string
+	// Severity is the severity of the problem: info, warning or error.
+	Severity string
+	// File is the path of the file the problem was found in.
+	File string
+	// Line is the 1-based line number, or 0 when unknown.
+	Line int
+	// Column is the 1-based column number, or 0 when unknown.
+	Column int
+	// JSONPath is the JSON path of the problematic value, or empty when unknown.
+	JSONPath string
+	// Message describes the problem.
+	Message string
+}
+
+// Check validates the content of service configs, independent of the services they apply to, and returns every
+// problem found. Names referencing services and methods are checked against files, when not nil.
+func Check(files *protoregistry.Files, sources []Source, opts CheckOptions) ([]Finding, error) {
+	validateOpts := validateOptions{
+		requireLossless: opts.RequireLossless,
+		maxConfigBytes:  opts.MaxConfigBytes,
+	}
+	if opts.TargetGRPCGoVersion != "" {
+		version, err := parseGRPCGoVersion(opts.TargetGRPCGoVersion)
+		if err != nil {
+			return nil, err
+		}
+		validateOpts.targetGRPCGoVersion = &version
+	}
+	p := &plugin{files: files, pluginOptions: pluginOptions{fix: opts.Fix}}
+	serviceConfigs := make([]resolvedServiceConfig, 0, len(sources))
+	for _, source := range sources {
+		serviceConfigs = append(serviceConfigs, source.resolved())
+	}
+	var diagnostics diagnostics
+	if err := p.checkServiceConfigs(&diagnostics, serviceConfigs, validateOpts); err != nil {
+		return nil, err
+	}
+	result := make([]Finding, 0, len(diagnostics.list))
+	for _, diagnostic := range diagnostics.list {
+		result = append(result, Finding{
+			Rule:     string(diagnostic.rule),
+			Severity: diagnostic.severity.String(),
+			File:     diagnostic.location.file,
+			Line:     diagnostic.location.line,
+			Column:   diagnostic.location.column,
+			JSONPath: diagnostic.location.jsonPath,
+			Message:  diagnostic.message,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,395 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// lintLevel is the level of a lint rule.
+type lintLevel string
+
+const (
+	// lintLevelOff disables a lint rule.
+	lintLevelOff lintLevel = "off"
+	// lintLevelWarn reports lint rule findings as warnings.
+	lintLevelWarn lintLevel = "warn"
+	// lintLevelError reports lint rule findings as errors.
+	lintLevelError lintLevel = "error"
+)
+
+// validate returns an error if the lint level is not supported.
+func (l lintLevel) validate() error {
+	switch l {
+	case lintLevelOff, lintLevelWarn, lintLevelError:
+		return nil
+	}
+	return fmt.Errorf("unsupported lint level %q (expected %q, %q or %q)", l, lintLevelOff, lintLevelWarn, lintLevelError)
+}
+
+// report adds a diagnostic with the severity of the lint level.
+func (l lintLevel) report(
+	diagnostics *diagnostics,
+	rule rule,
+	location location,
+	format string,
+	args ...interface{},
+) {
+	switch l {
+	case lintLevelWarn:
+		diagnostics.warnf(rule, location, format, args...)
+	case lintLevelError:
+		diagnostics.errorf(rule, location, format, args...)
+	}
+}
+
+// lintRules are the rules configurable with lint levels.
+var lintRules = []rule{
+	ruleRequireTimeout,
+	ruleRequireDefaultMethodConfig,
+	ruleTimeoutRange,
+	ruleGatewayTimeout,
+	ruleStreamingRetry,
+	ruleNonIdempotentRetry,
+	ruleHedgingSideEffects,
+	ruleRetryUnsafeStatusCode,
+	ruleWaitForReady,
+}
+
+// lintOptions configures the lint rules applied during validation.
+type lintOptions struct {
+	// levels are the levels of lint rules configured by plugin options.
+	// Rules without a level are off.
+	levels map[rule]lintLevel
+	// config is the lint configuration file, or nil when there is none.
+	config *lintConfig
+	// minTimeout is the shortest allowed method timeout, or 0 for no minimum.
+	minTimeout time.Duration
+	// maxTimeout is the longest allowed method timeout, or 0 for no maximum.
+	maxTimeout time.Duration
+	// gatewayTimeout is the timeout of the HTTP gateway serving methods with google.api.http annotations, or 0 for
+	// no gateway timeout.
+	gatewayTimeout time.Duration
+}
+
+// validate returns an error if the lint options are invalid.
+func (o lintOptions) validate() error {
+	for _, level := range o.levels {
+		if err := level.validate(); err != nil {
+			return err
+		}
+	}
+	if o.minTimeout < 0 || o.maxTimeout < 0 || o.gatewayTimeout < 0 {
+		return fmt.Errorf("lint timeout thresholds must not be negative")
+	}
+	if o.minTimeout > 0 && o.maxTimeout > 0 && o.minTimeout > o.maxTimeout {
+		return fmt.Errorf("lint minimum timeout %s is longer than the maximum timeout %s", o.minTimeout, o.maxTimeout)
+	}
+	return nil
+}
+
+// level returns the level of the rule for services in the package.
+// Levels in the lint configuration file take precedence over levels configured by plugin options.
+func (o lintOptions) level(rule rule, pkg protoreflect.FullName) lintLevel {
+	if o.config != nil {
+		if level, ok := o.config.level(rule, pkg); ok {
+			return level
+		}
+	}
+	if level, ok := o.levels[rule]; ok {
+		return level
+	}
+	return lintLevelOff
+}
+
+// lintServiceConfig applies the enabled lint rules to a service config for services in the package.
+func lintServiceConfig(
+	diagnostics *diagnostics,
+	opts lintOptions,
+	pkg protoreflect.FullName,
+	serviceConfig resolvedServiceConfig,
+	serviceConfigContent serviceConfigJSON,
+) {
+	if !serviceConfigContent.hasDefaultMethodConfig() {
+		opts.level(ruleRequireDefaultMethodConfig, pkg).report(
+			diagnostics,
+			ruleRequireDefaultMethodConfig,
+			serviceConfig.locate("methodConfig"),
+			"no method config has an empty name ({}), so methods added later will not be covered by any method config",
+		)
+	}
+	for i, methodConfig := range serviceConfigContent.MethodConfigs {
+		if methodConfig.WaitForReady == nil || !*methodConfig.WaitForReady {
+			continue
+		}
+		path := fmt.Sprintf("methodConfig[%d].waitForReady", i)
+		scope := "methods"
+		if methodConfig.isDefault() {
+			scope = "all methods"
+		}
+		opts.level(ruleWaitForReady, pkg).report(
+			diagnostics,
+			ruleWaitForReady,
+			serviceConfig.locate(path),
+			"%s: waitForReady is enabled for %s, which can hide outages by queueing calls instead of failing them",
+			path,
+			scope,
+		)
+	}
+	for _, statusCode := range serviceConfigContent.statusCodes() {
+		if code, err := statusCode.parse(); err == nil && isUnsafeToRetry(code) {
+			opts.level(ruleRetryUnsafeStatusCode, pkg).report(
+				diagnostics,
+				ruleRetryUnsafeStatusCode,
+				serviceConfig.locate(statusCode.path),
+				"%s: %s may be returned after a request has been processed, and is unsafe to retry",
+				statusCode.path,
+				configjson.StatusCodeName(code),
+			)
+		}
+	}
+}
+
+// lintService applies the enabled lint rules to the methods of a service.
+// Methods are not linted by rules they are exempted from.
+func lintService(
+	diagnostics *diagnostics,
+	opts lintOptions,
+	service *protogen.Service,
+	serviceConfig resolvedServiceConfig,
+	serviceConfigContent serviceConfigJSON,
+	exemptions map[protoreflect.FullName]*lintExemption,
+) {
+	pkg := service.Desc.ParentFile().Package()
+	for _, method := range service.Methods {
+		exemption := exemptions[method.Desc.FullName()]
+		level := func(rule rule) lintLevel {
+			if exemption.exempts(rule) {
+				return lintLevelOff
+			}
+			return opts.level(rule, pkg)
+		}
+		index, ok := serviceConfigContent.methodConfigFor(method.Desc)
+		path := fmt.Sprintf("methodConfig[%d]", index)
+		if !method.Desc.IsStreamingClient() && !method.Desc.IsStreamingServer() {
+			if !ok {
+				level(ruleRequireTimeout).report(
+					diagnostics,
+					ruleRequireTimeout,
+					serviceConfig.locate(""),
+					"unary method %s has no method config and therefore no timeout",
+					method.Desc.FullName(),
+				)
+			} else if serviceConfigContent.MethodConfigs[index].Timeout == nil {
+				level(ruleRequireTimeout).report(
+					diagnostics,
+					ruleRequireTimeout,
+					serviceConfig.locate(path),
+					"unary method %s has no timeout in %s",
+					method.Desc.FullName(),
+					path,
+				)
+			}
+		}
+		if ok && (method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer()) {
+			methodConfig := serviceConfigContent.MethodConfigs[index]
+			if methodConfig.RetryPolicy != nil {
+				level(ruleStreamingRetry).report(
+					diagnostics,
+					ruleStreamingRetry,
+					serviceConfig.locate(path+".retryPolicy"),
+					"streaming method %s has a retry policy in %s",
+					method.Desc.FullName(),
+					path,
+				)
+			}
+			if methodConfig.HedgingPolicy != nil {
+				level(ruleStreamingRetry).report(
+					diagnostics,
+					ruleStreamingRetry,
+					serviceConfig.locate(path+".hedgingPolicy"),
+					"streaming method %s has a hedging policy in %s",
+					method.Desc.FullName(),
+					path,
+				)
+			}
+		}
+		if ok && serviceConfigContent.MethodConfigs[index].RetryPolicy != nil {
+			if nonIdempotent, reason := isNonIdempotent(method.Desc); nonIdempotent {
+				level(ruleNonIdempotentRetry).report(
+					diagnostics,
+					ruleNonIdempotentRetry,
+					serviceConfig.locate(path+".retryPolicy"),
+					"non-idempotent method %s (%s) has a retry policy in %s",
+					method.Desc.FullName(),
+					reason,
+					path,
+				)
+			}
+		}
+		if ok && serviceConfigContent.MethodConfigs[index].Timeout != nil {
+			lintTimeoutRange(
+				diagnostics,
+				opts,
+				level(ruleTimeoutRange),
+				method,
+				serviceConfig,
+				path+".timeout",
+				*serviceConfigContent.MethodConfigs[index].Timeout,
+			)
+		}
+		if ok && serviceConfigContent.MethodConfigs[index].Timeout != nil && opts.gatewayTimeout > 0 {
+			if timeout, err := configjson.ParseDuration(*serviceConfigContent.MethodConfigs[index].Timeout); err == nil &&
+				timeout > opts.gatewayTimeout && hasHTTPRule(method.Desc) {
+				level(ruleGatewayTimeout).report(
+					diagnostics,
+					ruleGatewayTimeout,
+					serviceConfig.locate(path+".timeout"),
+					"method %s has a google.api.http annotation and a timeout of %s in %s, which exceeds the "+
+						"gateway timeout %s, so calls can outlive the HTTP requests they serve",
+					method.Desc.FullName(),
+					timeout,
+					path,
+					opts.gatewayTimeout,
+				)
+			}
+		}
+		if ok && serviceConfigContent.MethodConfigs[index].HedgingPolicy != nil {
+			if sideEffects, reason := mayHaveSideEffects(method.Desc); sideEffects {
+				level(ruleHedgingSideEffects).report(
+					diagnostics,
+					ruleHedgingSideEffects,
+					serviceConfig.locate(path+".hedgingPolicy"),
+					"method %s may have side effects (%s) and has a hedging policy in %s, "+
+						"which sends duplicate requests",
+					method.Desc.FullName(),
+					reason,
+					path,
+				)
+			}
+		}
+	}
+}
+
+// lintTimeoutRange reports a method timeout outside of the allowed timeout range.
+func lintTimeoutRange(
+	diagnostics *diagnostics,
+	opts lintOptions,
+	level lintLevel,
+	method *protogen.Method,
+	serviceConfig resolvedServiceConfig,
+	path string,
+	value string,
+) {
+	timeout, err := configjson.ParseDuration(value)
+	if err != nil {
+		return
+	}
+	if opts.minTimeout > 0 && timeout < opts.minTimeout {
+		level.report(
+			diagnostics,
+			ruleTimeoutRange,
+			serviceConfig.locate(path),
+			"%s: method %s has a timeout of %s, which is shorter than the minimum timeout %s",
+			path,
+			method.Desc.FullName(),
+			timeout,
+			opts.minTimeout,
+		)
+	}
+	if opts.maxTimeout > 0 && timeout > opts.maxTimeout {
+		level.report(
+			diagnostics,
+			ruleTimeoutRange,
+			serviceConfig.locate(path),
+			"%s: method %s has a timeout of %s, which is longer than the maximum timeout %s",
+			path,
+			method.Desc.FullName(),
+			timeout,
+			opts.maxTimeout,
+		)
+	}
+}
+
+// readOnlyMethodPrefixes are prefixes of method names that are conventionally free of side effects.
+var readOnlyMethodPrefixes = []string{"Get", "List", "BatchGet", "Search"}
+
+// mayHaveSideEffects returns true unless the method is known to be idempotent or free of side effects, and the reason
+// why. An idempotency_level option is authoritative: the method is known to be safe when it is IDEMPOTENT or
+// NO_SIDE_EFFECTS. Without the option, the method is known to be safe when its google.api.http annotation maps it to
+// HTTP GET, or when its name starts with Get, List, BatchGet or Search.
+func mayHaveSideEffects(method protoreflect.MethodDescriptor) (bool, string) {
+	options, _ := method.Options().(*descriptorpb.MethodOptions)
+	if options != nil && options.IdempotencyLevel != nil {
+		switch level := options.GetIdempotencyLevel(); level {
+		case descriptorpb.MethodOptions_IDEMPOTENT, descriptorpb.MethodOptions_NO_SIDE_EFFECTS:
+			return false, ""
+		default:
+			return true, "idempotency_level " + level.String()
+		}
+	}
+	if options != nil {
+		if httpRule, ok := proto.GetExtension(options, annotations.E_Http).(*annotations.HttpRule); ok && httpRule != nil {
+			if httpRule.GetGet() != "" {
+				return false, ""
+			}
+		}
+	}
+	for _, prefix := range readOnlyMethodPrefixes {
+		if strings.HasPrefix(string(method.Name()), prefix) {
+			return false, ""
+		}
+	}
+	return true, "no idempotency_level option"
+}
+
+// hasHTTPRule returns true if the method has a google.api.http annotation.
+func hasHTTPRule(method protoreflect.MethodDescriptor) bool {
+	options, ok := method.Options().(*descriptorpb.MethodOptions)
+	if !ok || options == nil {
+		return false
+	}
+	httpRule, ok := proto.GetExtension(options, annotations.E_Http).(*annotations.HttpRule)
+	return ok && httpRule != nil
+}
+
+// isNonIdempotent returns true unless the idempotency_level option of the method is IDEMPOTENT or NO_SIDE_EFFECTS,
+// and the reason why. The option is authoritative: a google.api.http annotation does not make a method idempotent,
+// and the HTTP method it maps to is only included in the reason.
+func isNonIdempotent(method protoreflect.MethodDescriptor) (bool, string) {
+	options, _ := method.Options().(*descriptorpb.MethodOptions)
+	if options != nil && options.IdempotencyLevel != nil {
+		switch level := options.GetIdempotencyLevel(); level {
+		case descriptorpb.MethodOptions_IDEMPOTENT, descriptorpb.MethodOptions_NO_SIDE_EFFECTS:
+			return false, ""
+		default:
+			return true, "idempotency_level " + level.String()
+		}
+	}
+	if options != nil {
+		if httpRule, ok := proto.GetExtension(options, annotations.E_Http).(*annotations.HttpRule); ok && httpRule != nil {
+			for _, verb := range []struct {
+				name    string
+				pattern string
+			}{
+				{name: "GET", pattern: httpRule.GetGet()},
+				{name: "PUT", pattern: httpRule.GetPut()},
+				{name: "POST", pattern: httpRule.GetPost()},
+				{name: "DELETE", pattern: httpRule.GetDelete()},
+				{name: "PATCH", pattern: httpRule.GetPatch()},
+			} {
+				if verb.pattern != "" {
+					return true, "no idempotency_level option, HTTP " + verb.name
+				}
+			}
+		}
+	}
+	return true, "no idempotency_level option"
+}
@@ -0,0 +1,524 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestLintService(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		lint          lintOptions
+		serviceConfig string
+		// exemptions are the lint rules methods are exempted from, by method name.
+		exemptions map[string][]string
+		// diagnostics are substrings of the lint diagnostics, in order.
+		diagnostics []string
+	}{
+		{
+			name:          "timeouts not required",
+			serviceConfig: `{"methodConfig": [{"name": [{}]}]}`,
+		},
+		{
+			name:          "default timeout",
+			lint:          lintOptions{levels: map[rule]lintLevel{ruleRequireTimeout: lintLevelError}},
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+		},
+		{
+			name: "missing timeout",
+			lint: lintOptions{levels: map[rule]lintLevel{ruleRequireTimeout: lintLevelError}},
+			serviceConfig: `{"methodConfig": [
+  {"name": [{}], "timeout": "10s"},
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "UpdateShipper"}]}
+]}`,
+			diagnostics: []string{
+				"error: unary method einride.example.freight.v1.FreightService.UpdateShipper" +
+					" has no timeout in methodConfig[1] (REQUIRE_TIMEOUT)",
+			},
+		},
+		{
+			name: "missing method config",
+			lint: lintOptions{levels: map[rule]lintLevel{ruleRequireTimeout: lintLevelError}},
+			serviceConfig: `{"methodConfig": [
+  {"name": [
+    {"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"},
+    {"service": "einride.example.freight.v1.FreightService", "method": "CreateShipper"},
+    {"service": "einride.example.freight.v1.FreightService", "method": "ImportShippers"},
+    {"service": "einride.example.freight.v1.FreightService", "method": "ListShippers"}
+  ], "timeout": "1s"}
+]}`,
+			diagnostics: []string{
+				"error: unary method einride.example.freight.v1.FreightService.UpdateShipper" +
+					" has no method config and therefore no timeout (REQUIRE_TIMEOUT)",
+			},
+		},
+		{
+			name: "streaming methods are ignored",
+			lint: lintOptions{levels: map[rule]lintLevel{ruleRequireTimeout: lintLevelError}},
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}], "timeout": "1s"},
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "WatchShippers"}]}
+]}`,
+		},
+		{
+			name: "retries of streaming methods",
+			lint: lintOptions{levels: map[rule]lintLevel{ruleStreamingRetry: lintLevelWarn}},
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}], "retryPolicy": {"maxAttempts": 2}},
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "WatchShippers"}],
+   "retryPolicy": {"maxAttempts": 2}, "hedgingPolicy": {"maxAttempts": 2}}
+]}`,
+			diagnostics: []string{
+				":4:19: warning: streaming method einride.example.freight.v1.FreightService.WatchShippers" +
+					" has a retry policy in methodConfig[1] (STREAMING_RETRY)",
+				":4:56: warning: streaming method einride.example.freight.v1.FreightService.WatchShippers" +
+					" has a hedging policy in methodConfig[1] (STREAMING_RETRY)",
+			},
+		},
+		{
+			name: "retries of streaming methods as errors",
+			lint: lintOptions{levels: map[rule]lintLevel{ruleStreamingRetry: lintLevelError}},
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}], "retryPolicy": {"maxAttempts": 2}}
+]}`,
+			diagnostics: []string{
+				"error: streaming method einride.example.freight.v1.FreightService.WatchShippers" +
+					" has a retry policy in methodConfig[0] (STREAMING_RETRY)",
+			},
+		},
+		{
+			name: "retries of non-idempotent methods",
+			lint: lintOptions{levels: map[rule]lintLevel{ruleNonIdempotentRetry: lintLevelWarn}},
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}], "retryPolicy": {"maxAttempts": 2}}
+]}`,
+			diagnostics: []string{
+				"warning: non-idempotent method einride.example.freight.v1.FreightService.CreateShipper" +
+					" (no idempotency_level option) has a retry policy in methodConfig[0] (NON_IDEMPOTENT_RETRY)",
+				"warning: non-idempotent method einride.example.freight.v1.FreightService.ImportShippers" +
+					" (no idempotency_level option, HTTP POST) has a retry policy in methodConfig[0] (NON_IDEMPOTENT_RETRY)",
+				"warning: non-idempotent method einride.example.freight.v1.FreightService.ListShippers" +
+					" (no idempotency_level option, HTTP GET) has a retry policy in methodConfig[0] (NON_IDEMPOTENT_RETRY)",
+				"warning: non-idempotent method einride.example.freight.v1.FreightService.WatchShippers" +
+					" (no idempotency_level option) has a retry policy in methodConfig[0] (NON_IDEMPOTENT_RETRY)",
+			},
+		},
+		{
+			name: "retries of non-idempotent methods off",
+			lint: lintOptions{levels: map[rule]lintLevel{ruleNonIdempotentRetry: lintLevelOff}},
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}], "retryPolicy": {"maxAttempts": 2}}
+]}`,
+		},
+		{
+			name: "hedging of methods with side effects",
+			lint: lintOptions{levels: map[rule]lintLevel{ruleHedgingSideEffects: lintLevelWarn}},
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}], "hedgingPolicy": {"maxAttempts": 2}}
+]}`,
+			diagnostics: []string{
+				"warning: method einride.example.freight.v1.FreightService.CreateShipper may have side effects" +
+					" (no idempotency_level option) and has a hedging policy in methodConfig[0]," +
+					" which sends duplicate requests (HEDGING_SIDE_EFFECTS)",
+				"warning: method einride.example.freight.v1.FreightService.ImportShippers may have side effects",
+				"warning: method einride.example.freight.v1.FreightService.WatchShippers may have side effects",
+			},
+		},
+		{
+			name: "hedging of methods with side effects off",
+			lint: lintOptions{levels: map[rule]lintLevel{ruleHedgingSideEffects: lintLevelOff}},
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}], "hedgingPolicy": {"maxAttempts": 2}}
+]}`,
+		},
+		{
+			name: "timeout range",
+			lint: lintOptions{
+				levels:     map[rule]lintLevel{ruleTimeoutRange: lintLevelWarn},
+				minTimeout: time.Second,
+				maxTimeout: time.Minute,
+			},
+			serviceConfig: `{"methodConfig": [
+  {"name": [{}], "timeout": "10s"},
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}], "timeout": "0.1s"},
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "ImportShippers"}], "timeout": "600s"}
+]}`,
+			diagnostics: []string{
+				":3:107: warning: methodConfig[1].timeout: method einride.example.freight.v1.FreightService.GetShipper" +
+					" has a timeout of 100ms, which is shorter than the minimum timeout 1s (TIMEOUT_RANGE)",
+				":4:111: warning: methodConfig[2].timeout: method" +
+					" einride.example.freight.v1.FreightService.ImportShippers has a timeout of 10m0s, which is longer" +
+					" than the maximum timeout 1m0s (TIMEOUT_RANGE)",
+			},
+		},
+		{
+			name: "timeout range exemptions",
+			lint: lintOptions{
+				levels:     map[rule]lintLevel{ruleTimeoutRange: lintLevelWarn, ruleRequireTimeout: lintLevelError},
+				maxTimeout: time.Minute,
+			},
+			serviceConfig: `{"methodConfig": [
+  {"name": [{}], "timeout": "600s"},
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "UpdateShipper"}]}
+]}`,
+			exemptions: map[string][]string{
+				"GetShipper":     {"TIMEOUT_RANGE"},
+				"CreateShipper":  {"TIMEOUT_RANGE", "REQUIRE_TIMEOUT"},
+				"ImportShippers": {},
+				"ListShippers":   {"REQUIRE_TIMEOUT"},
+				"UpdateShipper":  {},
+			},
+			diagnostics: []string{
+				"warning: methodConfig[0].timeout: method einride.example.freight.v1.FreightService.ListShippers" +
+					" has a timeout of 10m0s",
+				"warning: methodConfig[0].timeout: method einride.example.freight.v1.FreightService.WatchShippers" +
+					" has a timeout of 10m0s",
+			},
+		},
+		{
+			name: "gateway timeout",
+			lint: lintOptions{
+				levels:         map[rule]lintLevel{ruleGatewayTimeout: lintLevelWarn},
+				gatewayTimeout: 30 * time.Second,
+			},
+			serviceConfig: `{"methodConfig": [
+  {"name": [{}], "timeout": "60s"},
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}], "timeout": "30s"}
+]}`,
+			diagnostics: []string{
+				"warning: method einride.example.freight.v1.FreightService.UpdateShipper has a google.api.http" +
+					" annotation and a timeout of 1m0s in methodConfig[0], which exceeds the gateway timeout 30s," +
+					" so calls can outlive the HTTP requests they serve (GATEWAY_TIMEOUT)",
+				"warning: method einride.example.freight.v1.FreightService.ImportShippers has a google.api.http",
+				"warning: method einride.example.freight.v1.FreightService.ListShippers has a google.api.http",
+			},
+		},
+		{
+			name:          "gateway timeout not configured",
+			lint:          lintOptions{levels: map[rule]lintLevel{ruleGatewayTimeout: lintLevelWarn}},
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "60s"}]}`,
+		},
+		{
+			name: "retries of streaming methods off",
+			lint: lintOptions{levels: map[rule]lintLevel{ruleStreamingRetry: lintLevelOff}},
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}], "retryPolicy": {"maxAttempts": 2}}
+]}`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTestFiles(t, map[string]string{testFreightServiceConfigFile: tt.serviceConfig})
+			file := testFile(t, testFreightServiceFile)
+			for method, rules := range tt.exemptions {
+				setTestLintExemption(t, file, method, rules)
+			}
+			p := newTestPlugin(
+				t,
+				pluginOptions{path: dir, conflict: conflictPreferJSON},
+				testFile(t, testExemptionFile),
+				file,
+			)
+			var found diagnostics
+			for _, file := range p.gen.Files {
+				for _, service := range file.Services {
+					serviceConfig, _, err := p.resolveServiceConfig(service.Desc)
+					if err != nil {
+						t.Fatal(err)
+					}
+					serviceConfigContent, err := parseTestServiceConfig(serviceConfig)
+					if err != nil {
+						t.Fatal(err)
+					}
+					serviceConfig.positions, err = parseJSONPositions(serviceConfig.source, []byte(serviceConfig.json))
+					if err != nil {
+						t.Fatal(err)
+					}
+					exemptions, err := p.methodLintExemptions(service)
+					if err != nil {
+						t.Fatal(err)
+					}
+					lintService(&found, tt.lint, service, serviceConfig, serviceConfigContent, exemptions)
+				}
+			}
+			if len(found.list) != len(tt.diagnostics) {
+				t.Fatalf("expected %d diagnostics, got %v", len(tt.diagnostics), found.list)
+			}
+			for i, diagnostic := range found.list {
+				if !strings.Contains(diagnostic.String(), tt.diagnostics[i]) {
+					t.Errorf("expected diagnostic containing %q, got %v", tt.diagnostics[i], diagnostic)
+				}
+			}
+		})
+	}
+}
+
+func TestLintOptionsValidate(t *testing.T) {
+	for _, level := range []lintLevel{lintLevelOff, lintLevelWarn, lintLevelError} {
+		opts := lintOptions{levels: map[rule]lintLevel{ruleStreamingRetry: level, ruleNonIdempotentRetry: level}}
+		if err := opts.validate(); err != nil {
+			t.Errorf("expected %s to be valid, got %v", level, err)
+		}
+	}
+	opts := lintOptions{levels: map[rule]lintLevel{ruleStreamingRetry: lintLevelWarn, ruleNonIdempotentRetry: "fatal"}}
+	if err := opts.validate(); err == nil {
+		t.Error("expected fatal to be invalid")
+	}
+	for _, tt := range []struct {
+		minTimeout     time.Duration
+		maxTimeout     time.Duration
+		gatewayTimeout time.Duration
+		err            string
+	}{
+		{minTimeout: time.Second},
+		{maxTimeout: time.Second},
+		{minTimeout: time.Second, maxTimeout: time.Second},
+		{minTimeout: -time.Second, err: "lint timeout thresholds must not be negative"},
+		{gatewayTimeout: -time.Second, err: "lint timeout thresholds must not be negative"},
+		{
+			minTimeout: time.Minute,
+			maxTimeout: time.Second,
+			err:        "lint minimum timeout 1m0s is longer than the maximum timeout 1s",
+		},
+	} {
+		err := lintOptions{
+			minTimeout:     tt.minTimeout,
+			maxTimeout:     tt.maxTimeout,
+			gatewayTimeout: tt.gatewayTimeout,
+		}.validate()
+		if tt.err == "" && err != nil {
+			t.Errorf("timeouts %s to %s: unexpected error: %v", tt.minTimeout, tt.maxTimeout, err)
+		}
+		if tt.err != "" && (err == nil || err.Error() != tt.err) {
+			t.Errorf("timeouts %s to %s: expected error %q, got %v", tt.minTimeout, tt.maxTimeout, tt.err, err)
+		}
+	}
+}
+
+func TestLintServiceConfig(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		levels        map[rule]lintLevel
+		serviceConfig string
+		diagnostics   []string
+	}{
+		{
+			name:   "unsafe status codes",
+			levels: map[rule]lintLevel{ruleRetryUnsafeStatusCode: lintLevelWarn},
+			serviceConfig: `{"methodConfig": [
+  {"retryPolicy": {"retryableStatusCodes": ["UNAVAILABLE", "INTERNAL"]}},
+  {"hedgingPolicy": {"nonFatalStatusCodes": [2]}}
+]}`,
+			diagnostics: []string{
+				"a.json: warning: methodConfig[0].retryPolicy.retryableStatusCodes[1]: INTERNAL may be returned" +
+					" after a request has been processed, and is unsafe to retry (RETRY_UNSAFE_STATUS_CODE)",
+				"a.json: warning: methodConfig[1].hedgingPolicy.nonFatalStatusCodes[0]: UNKNOWN may be returned" +
+					" after a request has been processed, and is unsafe to retry (RETRY_UNSAFE_STATUS_CODE)",
+			},
+		},
+		{
+			name:          "unsafe status codes off",
+			levels:        map[rule]lintLevel{ruleRetryUnsafeStatusCode: lintLevelOff},
+			serviceConfig: `{"methodConfig": [{"retryPolicy": {"retryableStatusCodes": ["INTERNAL"]}}]}`,
+		},
+		{
+			name:   "wait for ready",
+			levels: map[rule]lintLevel{ruleWaitForReady: lintLevelError},
+			serviceConfig: `{"methodConfig": [
+  {"name": [{}], "waitForReady": true},
+  {"name": [{"service": "a.B"}], "waitForReady": false},
+  {"name": [{"service": "a.C"}], "waitForReady": true}
+]}`,
+			diagnostics: []string{
+				"a.json: error: methodConfig[0].waitForReady: waitForReady is enabled for all methods," +
+					" which can hide outages by queueing calls instead of failing them (WAIT_FOR_READY)",
+				"a.json: error: methodConfig[2].waitForReady: waitForReady is enabled for methods," +
+					" which can hide outages by queueing calls instead of failing them (WAIT_FOR_READY)",
+			},
+		},
+		{
+			name:   "require default method config",
+			levels: map[rule]lintLevel{ruleRequireDefaultMethodConfig: lintLevelError},
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "a.B"}], "timeout": "1s"},
+  {"name": [{"service": "a.C", "method": "D"}], "timeout": "1s"}
+]}`,
+			diagnostics: []string{
+				"a.json: error: no method config has an empty name ({}), so methods added later will not be" +
+					" covered by any method config (REQUIRE_DEFAULT_METHOD_CONFIG)",
+			},
+		},
+		{
+			name:   "default method config",
+			levels: map[rule]lintLevel{ruleRequireDefaultMethodConfig: lintLevelError},
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "a.B"}], "timeout": "1s"},
+  {"name": [{"service": "a.C"}, {}], "timeout": "1s"}
+]}`,
+		},
+		{
+			name:          "require default method config off",
+			serviceConfig: `{"methodConfig": [{"name": [{"service": "a.B"}], "timeout": "1s"}]}`,
+		},
+		{
+			name:          "wait for ready off",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "waitForReady": true}]}`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			serviceConfig := resolvedServiceConfig{source: "a.json", json: tt.serviceConfig}
+			serviceConfigContent, err := parseTestServiceConfig(serviceConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var found diagnostics
+			opts := lintOptions{levels: tt.levels}
+			lintServiceConfig(&found, opts, "einride.example.freight.v1", serviceConfig, serviceConfigContent)
+			if len(found.list) != len(tt.diagnostics) {
+				t.Fatalf("expected %d diagnostics, got %v", len(tt.diagnostics), found.list)
+			}
+			for i, diagnostic := range found.list {
+				if diagnostic.String() != tt.diagnostics[i] {
+					t.Errorf("expected diagnostic %q, got %q", tt.diagnostics[i], diagnostic)
+				}
+			}
+		})
+	}
+}
+
+// testIdempotencyServiceFile is a service with methods covering idempotency_level options and HTTP methods.
+const testIdempotencyServiceFile = `
+name: "einride/example/idempotency/v1/idempotency_service.proto"
+package: "einride.example.idempotency.v1"
+dependency: "google/api/annotations.proto"
+options { go_package: "example.com/idempotency/v1;idempotencyv1" }
+message_type { name: "Request" }
+service {
+  name: "IdempotencyService"
+  method {
+    name: "UnknownGet"
+    input_type: ".einride.example.idempotency.v1.Request"
+    output_type: ".einride.example.idempotency.v1.Request"
+    options {
+      idempotency_level: IDEMPOTENCY_UNKNOWN
+      [google.api.http] { get: "/v1/requests" }
+    }
+  }
+  method {
+    name: "IdempotentPost"
+    input_type: ".einride.example.idempotency.v1.Request"
+    output_type: ".einride.example.idempotency.v1.Request"
+    options {
+      idempotency_level: IDEMPOTENT
+      [google.api.http] { post: "/v1/requests" body: "*" }
+    }
+  }
+  method {
+    name: "NoSideEffects"
+    input_type: ".einride.example.idempotency.v1.Request"
+    output_type: ".einride.example.idempotency.v1.Request"
+    options { idempotency_level: NO_SIDE_EFFECTS }
+  }
+  method {
+    name: "Get"
+    input_type: ".einride.example.idempotency.v1.Request"
+    output_type: ".einride.example.idempotency.v1.Request"
+    options { [google.api.http] { get: "/v1/requests" } }
+  }
+  method {
+    name: "Put"
+    input_type: ".einride.example.idempotency.v1.Request"
+    output_type: ".einride.example.idempotency.v1.Request"
+    options { [google.api.http] { put: "/v1/requests" body: "*" } }
+  }
+  method {
+    name: "Delete"
+    input_type: ".einride.example.idempotency.v1.Request"
+    output_type: ".einride.example.idempotency.v1.Request"
+    options { [google.api.http] { delete: "/v1/requests" } }
+  }
+  method {
+    name: "Post"
+    input_type: ".einride.example.idempotency.v1.Request"
+    output_type: ".einride.example.idempotency.v1.Request"
+    options { [google.api.http] { post: "/v1/requests" body: "*" } }
+  }
+  method {
+    name: "Patch"
+    input_type: ".einride.example.idempotency.v1.Request"
+    output_type: ".einride.example.idempotency.v1.Request"
+    options { [google.api.http] { patch: "/v1/requests" body: "*" } }
+  }
+  method {
+    name: "GetUnknown"
+    input_type: ".einride.example.idempotency.v1.Request"
+    output_type: ".einride.example.idempotency.v1.Request"
+    options { idempotency_level: IDEMPOTENCY_UNKNOWN }
+  }
+  method {
+    name: "ListRequests"
+    input_type: ".einride.example.idempotency.v1.Request"
+    output_type: ".einride.example.idempotency.v1.Request"
+  }
+  method {
+    name: "NoOptions"
+    input_type: ".einride.example.idempotency.v1.Request"
+    output_type: ".einride.example.idempotency.v1.Request"
+  }
+}
+`
+
+func TestIsNonIdempotent(t *testing.T) {
+	p := newTestPlugin(t, pluginOptions{conflict: conflictPreferJSON}, testFile(t, testIdempotencyServiceFile))
+	methods := p.gen.Files[len(p.gen.Files)-1].Services[0].Desc.Methods()
+	for _, tt := range []struct {
+		method        string
+		nonIdempotent bool
+		reason        string
+	}{
+		{method: "UnknownGet", nonIdempotent: true, reason: "idempotency_level IDEMPOTENCY_UNKNOWN"},
+		{method: "IdempotentPost"},
+		{method: "NoSideEffects"},
+		{method: "Get", nonIdempotent: true, reason: "no idempotency_level option, HTTP GET"},
+		{method: "Put", nonIdempotent: true, reason: "no idempotency_level option, HTTP PUT"},
+		{method: "Delete", nonIdempotent: true, reason: "no idempotency_level option, HTTP DELETE"},
+		{method: "Post", nonIdempotent: true, reason: "no idempotency_level option, HTTP POST"},
+		{method: "Patch", nonIdempotent: true, reason: "no idempotency_level option, HTTP PATCH"},
+		{method: "GetUnknown", nonIdempotent: true, reason: "idempotency_level IDEMPOTENCY_UNKNOWN"},
+		{method: "ListRequests", nonIdempotent: true, reason: "no idempotency_level option"},
+		{method: "NoOptions", nonIdempotent: true, reason: "no idempotency_level option"},
+	} {
+		nonIdempotent, reason := isNonIdempotent(methods.ByName(protoreflect.Name(tt.method)))
+		if nonIdempotent != tt.nonIdempotent || reason != tt.reason {
+			t.Errorf("%s: expected %v (%q), got %v (%q)", tt.method, tt.nonIdempotent, tt.reason, nonIdempotent, reason)
+		}
+	}
+}
+
+func TestMayHaveSideEffects(t *testing.T) {
+	p := newTestPlugin(t, pluginOptions{conflict: conflictPreferJSON}, testFile(t, testIdempotencyServiceFile))
+	methods := p.gen.Files[len(p.gen.Files)-1].Services[0].Desc.Methods()
+	for _, tt := range []struct {
+		method      string
+		sideEffects bool
+		reason      string
+	}{
+		{method: "UnknownGet", sideEffects: true, reason: "idempotency_level IDEMPOTENCY_UNKNOWN"},
+		{method: "IdempotentPost"},
+		{method: "NoSideEffects"},
+		{method: "Get"},
+		{method: "Put", sideEffects: true, reason: "no idempotency_level option"},
+		{method: "GetUnknown", sideEffects: true, reason: "idempotency_level IDEMPOTENCY_UNKNOWN"},
+		{method: "ListRequests"},
+		{method: "NoOptions", sideEffects: true, reason: "no idempotency_level option"},
+	} {
+		sideEffects, reason := mayHaveSideEffects(methods.ByName(protoreflect.Name(tt.method)))
+		if sideEffects != tt.sideEffects || reason != tt.reason {
+			t.Errorf("%s: expected %v (%q), got %v (%q)", tt.method, tt.sideEffects, tt.reason, sideEffects, reason)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+)
+
+// lintConfigFileName is the name of the lint configuration file discovered in the service config path.
+const lintConfigFileName = "serviceconfig_lint.yaml"
+
+// lintConfig is a lint configuration file.
+//
+// Example:
+//
+//	rules:
+//	  - rule: REQUIRE_TIMEOUT
+//	    level: error
+//	  - rule: REQUIRE_TIMEOUT
+//	    level: warn
+//	    packages:
+//	      - einride.legacy.*
+//	ignore_only:
+//	  DANGLING_NAME:
+//	    - einride/legacy
+type lintConfig struct {
+	// Rules configure the levels of lint rules.
+	// When more than one entry applies to a rule and package, the last entry takes precedence.
+	Rules []lintConfigRule `yaml:"rules"`
+	// IgnoreOnly maps rule IDs to the files and directories their problems are ignored in, like the ignore_only lint
+	// option of buf.yaml. Paths are relative to the service config path.
+	IgnoreOnly map[rule][]string `yaml:"ignore_only"`
+}
+
+// lintConfigRule configures the level of a lint rule.
+type lintConfigRule struct {
+	// Rule is the ID of the lint rule.
+	Rule rule `yaml:"rule"`
+	// Level is the level of the lint rule.
+	Level lintLevel `yaml:"level"`
+	// Packages are glob patterns of the proto packages the entry applies to.
+	// When empty, the entry applies to all packages.
+	Packages []string `yaml:"packages"`
+}
+
+// loadLintConfig loads the lint configuration file.
+// When file is empty, the lint configuration file is discovered in the service config path.
+// Returns nil when there is no lint configuration file.
+func loadLintConfig(file string, serviceConfigPath string) (*lintConfig, error) {
+	if file == "" {
+		discovered := filepath.Join(serviceConfigPath, lintConfigFileName)
+		if _, err := os.Stat(discovered); err != nil {
+			return nil, nil
+		}
+		file = discovered
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("load lint config: %w", err)
+	}
+	var config lintConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("load lint config %s: %w", file, err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("load lint config %s: %w", file, err)
+	}
+	return &config, nil
+}
+
+// validate returns an error if the lint configuration is invalid.
+func (c *lintConfig) validate() error {
+	for i, entry := range c.Rules {
+		if !isLintRule(entry.Rule) {
+			return fmt.Errorf("rules[%d]: unknown lint rule %q", i, entry.Rule)
+		}
+		if err := entry.Level.validate(); err != nil {
+			return fmt.Errorf("rules[%d]: %w", i, err)
+		}
+		for _, pattern := range entry.Packages {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("rules[%d]: invalid package pattern %q: %w", i, pattern, err)
+			}
+		}
+	}
+	for rule := range c.IgnoreOnly {
+		if !isRule(rule) {
+			return fmt.Errorf("ignore_only: unknown rule %q", rule)
+		}
+	}
+	return nil
+}
+
+// unignored returns the diagnostics not ignored by the ignore_only entries.
+func (c *lintConfig) unignored(diagnostics []diagnostic, serviceConfigPath string) []diagnostic {
+	result := diagnostics[:0]
+	for _, diagnostic := range diagnostics {
+		if !c.ignores(diagnostic, serviceConfigPath) {
+			result = append(result, diagnostic)
+		}
+	}
+	return result
+}
+
+// ignores returns true if an ignore_only entry applies to the diagnostic.
+// Diagnostics of service config files are matched relative to the service config path.
+func (c *lintConfig) ignores(diagnostic diagnostic, serviceConfigPath string) bool {
+	file := filepath.ToSlash(diagnostic.location.file)
+	rel, err := filepath.Rel(serviceConfigPath, diagnostic.location.file)
+	if err == nil && !strings.HasPrefix(rel, "..") {
+		file = filepath.ToSlash(rel)
+	}
+	for _, ignored := range c.IgnoreOnly[diagnostic.rule] {
+		ignored = strings.TrimSuffix(path.Clean(filepath.ToSlash(ignored)), "/")
+		if file == ignored || strings.HasPrefix(file, ignored+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// level returns the level of the rule for services in the package, if configured.
+func (c *lintConfig) level(rule rule, pkg protoreflect.FullName) (lintLevel, bool) {
+	var result lintLevel
+	var ok bool
+	for _, entry := range c.Rules {
+		if entry.Rule == rule && entry.appliesTo(pkg) {
+			result, ok = entry.Level, true
+		}
+	}
+	return result, ok
+}
+
+// appliesTo returns true if the entry applies to services in the package.
+func (r lintConfigRule) appliesTo(pkg protoreflect.FullName) bool {
+	if len(r.Packages) == 0 {
+		return true
+	}
+	for _, pattern := range r.Packages {
+		if ok, _ := path.Match(pattern, string(pkg)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isRule returns true if the rule is a validation rule.
+func isRule(rule rule) bool {
+	for _, r := range rules {
+		if rule == r.rule {
+			return true
+		}
+	}
+	return false
+}
+
+// isLintRule returns true if the rule is configurable with lint levels.
+func isLintRule(rule rule) bool {
+	for _, lintRule := range lintRules {
+		if rule == lintRule {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package plugin
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestLoadLintConfig(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		files map[string]string
+		// file is the lint configuration file option, relative to the service config path.
+		file string
+		// rules is the number of rules of the loaded lint configuration, or -1 when none is loaded.
+		rules int
+		err   string
+	}{
+		{
+			name:  "no lint config",
+			rules: -1,
+		},
+		{
+			name: "discovered",
+			files: map[string]string{
+				lintConfigFileName: "rules:\n  - rule: REQUIRE_TIMEOUT\n    level: error\n",
+			},
+			rules: 1,
+		},
+		{
+			name: "explicit",
+			files: map[string]string{
+				"lint.yaml": "rules:\n  - rule: REQUIRE_TIMEOUT\n    level: warn\n    packages: [einride.*]\n",
+			},
+			file:  "lint.yaml",
+			rules: 1,
+		},
+		{
+			name:  "empty",
+			files: map[string]string{lintConfigFileName: ""},
+			rules: 0,
+		},
+		{
+			name: "missing explicit file",
+			file: "lint.yaml",
+			err:  "load lint config",
+		},
+		{
+			name: "unknown rule",
+			files: map[string]string{
+				lintConfigFileName: "rules:\n  - rule: DUPLICATE_NAME\n    level: error\n",
+			},
+			err: `rules[0]: unknown lint rule "DUPLICATE_NAME"`,
+		},
+		{
+			name: "invalid level",
+			files: map[string]string{
+				lintConfigFileName: "rules:\n  - rule: REQUIRE_TIMEOUT\n    level: fatal\n",
+			},
+			err: `rules[0]: unsupported lint level "fatal"`,
+		},
+		{
+			name: "invalid package pattern",
+			files: map[string]string{
+				lintConfigFileName: "rules:\n  - rule: REQUIRE_TIMEOUT\n    level: warn\n    packages: ['[']\n",
+			},
+			err: `rules[0]: invalid package pattern "["`,
+		},
+		{
+			name: "unknown ignored rule",
+			files: map[string]string{
+				lintConfigFileName: "ignore_only:\n  NO_SUCH_RULE:\n    - einride/legacy\n",
+			},
+			err: `ignore_only: unknown rule "NO_SUCH_RULE"`,
+		},
+		{
+			name: "unknown field",
+			files: map[string]string{
+				lintConfigFileName: "rules:\n  - rule: REQUIRE_TIMEOUT\n    severity: warn\n",
+			},
+			err: "field severity not found",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTestFiles(t, tt.files)
+			file := tt.file
+			if file != "" {
+				file = filepath.Join(dir, file)
+			}
+			config, err := loadLintConfig(file, dir)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.rules == -1 {
+				if config != nil {
+					t.Errorf("expected no lint config, got %v", config)
+				}
+				return
+			}
+			if config == nil || len(config.Rules) != tt.rules {
+				t.Errorf("expected %d rules, got %v", tt.rules, config)
+			}
+		})
+	}
+}
+
+func TestLintConfigUnignored(t *testing.T) {
+	config := &lintConfig{
+		IgnoreOnly: map[rule][]string{
+			ruleDanglingName:   {"einride/legacy/"},
+			ruleRequireTimeout: {"einride/example/v1/example_grpc_service_config.json"},
+		},
+	}
+	path := filepath.Join("proto", "configs")
+	diagnostics := []diagnostic{
+		{rule: ruleDanglingName, location: location{file: filepath.Join(path, "einride/legacy/v1/a.json")}},
+		{rule: ruleDanglingName, location: location{file: filepath.Join(path, "einride/legacy2/v1/a.json")}},
+		{rule: ruleRequireTimeout, location: location{file: filepath.Join(path, "einride/legacy/v1/a.json")}},
+		{rule: ruleRequireTimeout, location: location{
+			file: filepath.Join(path, "einride/example/v1/example_grpc_service_config.json"),
+		}},
+		{rule: ruleDanglingName, location: location{file: "einride/legacy/v1/service.proto"}},
+	}
+	var actual []string
+	for _, diagnostic := range config.unignored(diagnostics, path) {
+		actual = append(actual, string(diagnostic.rule)+" "+filepath.ToSlash(diagnostic.location.file))
+	}
+	expected := []string{
+		"DANGLING_NAME proto/configs/einride/legacy2/v1/a.json",
+		"REQUIRE_TIMEOUT proto/configs/einride/legacy/v1/a.json",
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestLintOptionsLevel(t *testing.T) {
+	opts := lintOptions{
+		levels: map[rule]lintLevel{ruleRequireTimeout: lintLevelError, ruleStreamingRetry: lintLevelWarn},
+		config: &lintConfig{
+			Rules: []lintConfigRule{
+				{Rule: ruleRequireTimeout, Level: lintLevelOff, Packages: []string{"einride.legacy.*"}},
+				{Rule: ruleRequireTimeout, Level: lintLevelWarn, Packages: []string{"einride.legacy.v1"}},
+				{Rule: ruleStreamingRetry, Level: lintLevelError},
+			},
+		},
+	}
+	for _, tt := range []struct {
+		rule     rule
+		pkg      protoreflect.FullName
+		expected lintLevel
+	}{
+		{rule: ruleRequireTimeout, pkg: "einride.example.v1", expected: lintLevelError},
+		{rule: ruleRequireTimeout, pkg: "einride.legacy.v2", expected: lintLevelOff},
+		{rule: ruleRequireTimeout, pkg: "einride.legacy.v1", expected: lintLevelWarn},
+		{rule: ruleStreamingRetry, pkg: "einride.example.v1", expected: lintLevelError},
+		{rule: ruleNonIdempotentRetry, pkg: "einride.example.v1", expected: lintLevelOff},
+	} {
+		if actual := opts.level(tt.rule, tt.pkg); actual != tt.expected {
+			t.Errorf("expected %s in %s to be %s, got %s", tt.rule, tt.pkg, tt.expected, actual)
+		}
+	}
+}
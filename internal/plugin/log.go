@@ -0,0 +1,79 @@
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// logLevel is the level of the structured logs written by the plugin.
+type logLevel string
+
+const (
+	// logLevelOff disables logging.
+	logLevelOff logLevel = "off"
+	// logLevelDebug logs the files scanned, the service config sources found and missing, and validation timings.
+	logLevelDebug logLevel = "debug"
+)
+
+// validate returns an error if the log level is not supported.
+func (l logLevel) validate() error {
+	switch l {
+	case logLevelOff, logLevelDebug:
+		return nil
+	}
+	return fmt.Errorf("unsupported log level %q (expected %q or %q)", l, logLevelOff, logLevelDebug)
+}
+
+// logger writes structured logs in the logfmt format, one event per line.
+// A nil logger discards all logs, so that logging is optional wherever a plugin is created.
+type logger struct {
+	w   io.Writer
+	now func() time.Time
+}
+
+// newLogger returns a logger writing to w at the level, or nil when logging is off.
+func newLogger(w io.Writer, level logLevel) *logger {
+	if level != logLevelDebug {
+		return nil
+	}
+	return &logger{w: w, now: time.Now}
+}
+
+// debug logs an event with key-value pairs.
+func (l *logger) debug(msg string, keyvals ...interface{}) {
+	if l == nil {
+		return
+	}
+	var b strings.Builder
+	b.WriteString("time=")
+	b.WriteString(l.now().UTC().Format(time.RFC3339Nano))
+	b.WriteString(" level=debug msg=")
+	b.WriteString(logfmtValue(msg))
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		b.WriteString(" ")
+		b.WriteString(fmt.Sprint(keyvals[i]))
+		b.WriteString("=")
+		b.WriteString(logfmtValue(fmt.Sprint(keyvals[i+1])))
+	}
+	b.WriteString("\n")
+	_, _ = io.WriteString(l.w, b.String())
+}
+
+// timed logs an event with the duration since start, and key-value pairs.
+func (l *logger) timed(msg string, start time.Time, keyvals ...interface{}) {
+	if l == nil {
+		return
+	}
+	l.debug(msg, append([]interface{}{"duration", l.now().Sub(start)}, keyvals...)...)
+}
+
+// logfmtValue returns the value quoted when needed in the logfmt format.
+func logfmtValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\t\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,72 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger(t *testing.T) {
+	var output strings.Builder
+	l := newLogger(&output, logLevelDebug)
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	l.debug("scan file", "file", "einride/example/freight/v1/freight_service.proto", "generate", true)
+	l.debug("look up service config file", "file", "my dir/service config.json", "found", false)
+	l.timed("validate service configs", now.Add(-1500*time.Millisecond), "diagnostics", 2)
+	expected := `time=2021-01-02T03:04:05Z level=debug msg="scan file" ` +
+		`file=einride/example/freight/v1/freight_service.proto generate=true
+time=2021-01-02T03:04:05Z level=debug msg="look up service config file" file="my dir/service config.json" found=false
+time=2021-01-02T03:04:05Z level=debug msg="validate service configs" duration=1.5s diagnostics=2
+`
+	if output.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+}
+
+func TestLoggerOff(t *testing.T) {
+	var output strings.Builder
+	l := newLogger(&output, logLevelOff)
+	if l != nil {
+		t.Fatalf("expected no logger, got %v", l)
+	}
+	l.debug("scan file", "file", "a.proto")
+	l.timed("validate service configs", time.Now())
+	if output.Len() != 0 {
+		t.Errorf("expected no output, got %q", output.String())
+	}
+}
+
+func TestLogLevelValidate(t *testing.T) {
+	for _, level := range []logLevel{logLevelOff, logLevelDebug} {
+		if err := level.validate(); err != nil {
+			t.Errorf("expected %q to be valid, got %v", level, err)
+		}
+	}
+	if err := logLevel("trace").validate(); err == nil || !strings.Contains(err.Error(), `unsupported log level "trace"`) {
+		t.Errorf("expected unsupported log level error, got %v", err)
+	}
+}
+
+func TestPluginDebugLogs(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{testFreightServiceConfigFile: testRetryServiceConfig})
+	var output strings.Builder
+	p := newTestPlugin(
+		t,
+		pluginOptions{path: dir, conflict: conflictPreferJSON, log: newLogger(&output, logLevelDebug)},
+		testFile(t, testFreightServiceFile),
+	)
+	if _, _, err := p.resolveServiceConfig(p.gen.Files[len(p.gen.Files)-1].Services[0].Desc); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`msg="scan file" file=einride/example/freight/v1/freight_service.proto package=einride.example.freight.v1 ` +
+			"generate=true services=1",
+		`msg="look up service config file" service=einride.example.freight.v1.FreightService`,
+		"found=true",
+	} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("expected logs containing %q, got:\n%s", expected, output.String())
+		}
+	}
+}
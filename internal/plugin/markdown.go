@@ -0,0 +1,100 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// markdownCell escapes the pipes in the content of a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// sourceLink returns the relative link from a Markdown file in the directory to the source of a service config,
+// assuming that the output directory is the root of the proto files, where the path option points.
+func (p *plugin) sourceLink(dir, source string) string {
+	if p.path != "" {
+		if rel, err := filepath.Rel(p.path, source); err == nil && !strings.HasPrefix(rel, "..") {
+			source = rel
+		}
+	}
+	if filepath.IsAbs(source) {
+		return filepath.ToSlash(source)
+	}
+	link, err := filepath.Rel(filepath.FromSlash(dir), source)
+	if err != nil {
+		return filepath.ToSlash(source)
+	}
+	return filepath.ToSlash(link)
+}
+
+// generateMarkdownDocs writes a SERVICECONFIG.md file per package to the directory, as
+// <dir>/<package path>/SERVICECONFIG.md, with a table per service of the effective timeout, retry schedule, hedging,
+// and message size limits of every method, linking to the method configs in the source of the service config.
+func (p *plugin) generateMarkdownDocs(dir string) error {
+	serviceConfigs, err := p.packageServiceConfigs()
+	if err != nil {
+		return err
+	}
+	for _, serviceConfig := range serviceConfigs {
+		if err := p.generateMarkdownDoc(dir, serviceConfig); err != nil {
+			return fmt.Errorf("emit %s Markdown documentation: %w", serviceConfig.pkg, err)
+		}
+	}
+	return nil
+}
+
+func (p *plugin) generateMarkdownDoc(dir string, serviceConfig *packageServiceConfig) error {
+	var content serviceConfigJSON
+	if err := json.Unmarshal([]byte(serviceConfig.json), &content); err != nil {
+		return err
+	}
+	var explainContent explainServiceConfigJSON
+	if err := json.Unmarshal([]byte(serviceConfig.json), &explainContent); err != nil {
+		return err
+	}
+	docDir := path.Join(dir, packagePath(serviceConfig.pkg))
+	link := p.sourceLink(docDir, serviceConfig.source)
+	g := p.gen.NewGeneratedFile(path.Join(docDir, "SERVICECONFIG.md"), "")
+	g.P("<!-- Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT. -->")
+	g.P()
+	g.P("# Service config of ", serviceConfig.pkg)
+	g.P()
+	g.P("Source: [", filepath.Base(serviceConfig.source), "](", link, ")")
+	for _, service := range serviceConfig.services {
+		g.P()
+		g.P("## ", service.FullName())
+		g.P()
+		g.P("| Method | Method config | Timeout | Wait for ready | Retry | Hedging | Max request | Max response |")
+		g.P("| --- | --- | --- | --- | --- | --- | --- | --- |")
+		for i := 0; i < service.Methods().Len(); i++ {
+			method := service.Methods().Get(i)
+			name, matched, methodConfig := "`"+string(method.Name())+"`", "none", explainMethodConfigJSON{}
+			if j, ok := content.methodConfigFor(method); ok && j < len(explainContent.MethodConfigs) {
+				jsonPath := fmt.Sprintf("methodConfig[%d]", j)
+				anchor := ""
+				if position, ok := serviceConfig.positions[jsonPath]; ok {
+					anchor = fmt.Sprintf("#L%d", position.line)
+				}
+				matched = fmt.Sprintf("[%s](%s%s) (%s)", jsonPath, link, anchor, content.MethodConfigs[j].match(method))
+				methodConfig = explainContent.MethodConfigs[j]
+			}
+			explained, err := explainMethodConfig(methodConfig)
+			if err != nil {
+				return fmt.Errorf("%s: %w", method.FullName(), err)
+			}
+			if method.IsStreamingClient() || method.IsStreamingServer() {
+				name += " (streaming)"
+			}
+			row := append([]string{name, matched}, explained...)
+			for k := range row {
+				row[k] = markdownCell(row[k])
+			}
+			g.P("| ", strings.Join(row, " | "), " |")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownCell(t *testing.T) {
+	const expected = "`a` \\| `b`"
+	if actual := markdownCell("`a` | `b`"); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestGenerateMarkdownDocs(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		testFreightServiceConfigFile: `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s", "maxRequestMessageBytes": 1024},
+    {
+      "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+      "timeout": "1s",
+      "retryPolicy": {
+        "maxAttempts": 3,
+        "initialBackoff": "0.1s",
+        "maxBackoff": "1s",
+        "backoffMultiplier": 2,
+        "retryableStatusCodes": ["UNAVAILABLE"]
+      }
+    }
+  ]
+}`,
+	})
+	p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testRouteServiceFile))
+	if err := p.generateMarkdownDocs("docs"); err != nil {
+		t.Fatal(err)
+	}
+	files := p.gen.Response().GetFile()
+	if len(files) != 1 {
+		t.Fatalf("expected 1 generated file, got %d", len(files))
+	}
+	const expectedName = "docs/einride/example/freight/v1/SERVICECONFIG.md"
+	if actual := files[0].GetName(); actual != expectedName {
+		t.Errorf("expected name %s, got %s", expectedName, actual)
+	}
+	const source = "../../../../../einride/example/freight/v1/freight_grpc_service_config.json"
+	expected := strings.Join([]string{
+		"<!-- Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT. -->",
+		"",
+		"# Service config of einride.example.freight.v1",
+		"",
+		"Source: [freight_grpc_service_config.json](" + source + ")",
+		"",
+		"## einride.example.freight.v1.FreightService",
+		"",
+		"| Method | Method config | Timeout | Wait for ready | Retry | Hedging | Max request | Max response |",
+		"| --- | --- | --- | --- | --- | --- | --- | --- |",
+		"| `GetShipper` | [methodConfig[1]](" + source + "#L4) (method name) | 1s | false | " +
+			"3 attempts on UNAVAILABLE, backoff ≤0.1s, ≤0.2s | none | no limit | 4 MiB |",
+		"| `CreateShipper` | [methodConfig[0]](" + source + "#L3) (default name) | 10s | false | " +
+			"none | none | 1 KiB | 4 MiB |",
+		"",
+	}, "\n")
+	if actual := files[0].GetContent(); actual != expected {
+		t.Errorf("expected content:\n%s\ngot:\n%s", expected, actual)
+	}
+}
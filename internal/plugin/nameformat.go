@@ -0,0 +1,113 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// nameFormatFindings returns the names in the service config JSON whose format is not accepted by every gRPC
+// implementation. Service configs are shared across languages, so names accepted by grpc-go, but rejected or
+// interpreted differently by grpc-java or grpc-node, are problems.
+func nameFormatFindings(data []byte) ([]jsonFinding, error) {
+	var serviceConfig map[string]json.RawMessage
+	if err := json.Unmarshal(data, &serviceConfig); err != nil {
+		return nil, err
+	}
+	var result []jsonFinding
+	for _, field := range []string{"methodConfig", "method_config"} {
+		value, ok := serviceConfig[field]
+		if !ok {
+			continue
+		}
+		var methodConfigs []map[string]json.RawMessage
+		if err := json.Unmarshal(value, &methodConfigs); err != nil {
+			// Invalid method configs are reported by the JSON Schema.
+			continue
+		}
+		for i, methodConfig := range methodConfigs {
+			var names []map[string]json.RawMessage
+			if err := json.Unmarshal(methodConfig["name"], &names); err != nil {
+				continue
+			}
+			for j, name := range names {
+				path := fmt.Sprintf("methodConfig[%d].name[%d]", i, j)
+				result = append(result, nameFormatFindingsOf(path, name)...)
+			}
+		}
+	}
+	return result, nil
+}
+
+func nameFormatFindingsOf(path string, name map[string]json.RawMessage) []jsonFinding {
+	var result []jsonFinding
+	var service, method string
+	keys := make([]string, 0, len(name))
+	for key := range name {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := name[key]
+		var s string
+		switch {
+		case key == "service":
+			_ = json.Unmarshal(value, &s)
+			service = s
+		case key == "method":
+			_ = json.Unmarshal(value, &s)
+			method = s
+		case strings.EqualFold(key, "service") || strings.EqualFold(key, "method"):
+			result = append(result, jsonFinding{
+				path: path + "." + key,
+				message: fmt.Sprintf(
+					"field %q is matched case-insensitively by grpc-go, but ignored by grpc-java and grpc-node, use %q",
+					key,
+					strings.ToLower(key),
+				),
+			})
+		}
+	}
+	switch {
+	case service == "" && method != "":
+		result = append(result, jsonFinding{
+			path:    path,
+			message: fmt.Sprintf("method %q has no service, which is rejected by gRPC", method),
+		})
+	case strings.HasPrefix(service, "/") || strings.HasPrefix(service, "."):
+		result = append(result, jsonFinding{
+			path:    path + ".service",
+			message: fmt.Sprintf("service %q must not have a leading %q", service, service[:1]),
+		})
+	case strings.Contains(service, "/"):
+		result = append(result, jsonFinding{
+			path:    path + ".service",
+			message: fmt.Sprintf("service %q must not contain a method, use the method field", service),
+		})
+	case service != "" && !strings.Contains(service, "."):
+		result = append(result, jsonFinding{
+			path:    path + ".service",
+			message: fmt.Sprintf("service %q is not package-qualified", service),
+		})
+	}
+	switch {
+	case strings.HasPrefix(method, "/"):
+		result = append(result, jsonFinding{
+			path:    path + ".method",
+			message: fmt.Sprintf("method %q must not have a leading %q", method, "/"),
+		})
+	case strings.ContainsAny(method, "/."):
+		result = append(result, jsonFinding{
+			path:    path + ".method",
+			message: fmt.Sprintf("method %q must not be prefixed with its service", method),
+		})
+	}
+	if strings.TrimSpace(service) != service || strings.TrimSpace(method) != method {
+		result = append(result, jsonFinding{
+			path:    path,
+			message: "service and method must not have leading or trailing whitespace",
+		})
+	}
+	return result
+}
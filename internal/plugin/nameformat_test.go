@@ -0,0 +1,95 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNameFormatFindings(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		serviceConfig string
+		findings      []string
+	}{
+		{
+			name: "valid names",
+			serviceConfig: `{"methodConfig": [{"name": [
+  {},
+  {"service": "einride.example.freight.v1.FreightService"},
+  {"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}
+]}]}`,
+		},
+		{
+			name:          "case-insensitive field",
+			serviceConfig: `{"methodConfig": [{"name": [{"Service": "einride.example.freight.v1.FreightService"}]}]}`,
+			findings: []string{
+				`methodConfig[0].name[0].Service: field "Service" is matched case-insensitively by grpc-go,` +
+					` but ignored by grpc-java and grpc-node, use "service"`,
+			},
+		},
+		{
+			name:          "method without service",
+			serviceConfig: `{"methodConfig": [{"name": [{"method": "GetShipper"}]}]}`,
+			findings: []string{
+				`methodConfig[0].name[0]: method "GetShipper" has no service, which is rejected by gRPC`,
+			},
+		},
+		{
+			name:          "leading slash",
+			serviceConfig: `{"methodConfig": [{"name": [{"service": "/einride.example.freight.v1.FreightService"}]}]}`,
+			findings: []string{
+				`methodConfig[0].name[0].service: service "/einride.example.freight.v1.FreightService"` +
+					` must not have a leading "/"`,
+			},
+		},
+		{
+			name: "service with method",
+			serviceConfig: `{"methodConfig": [{"name": [
+  {"service": "einride.example.freight.v1.FreightService/GetShipper"}
+]}]}`,
+			findings: []string{
+				`methodConfig[0].name[0].service: service "einride.example.freight.v1.FreightService/GetShipper"` +
+					` must not contain a method, use the method field`,
+			},
+		},
+		{
+			name:          "unqualified service",
+			serviceConfig: `{"method_config": [{"name": [{"service": "FreightService"}]}]}`,
+			findings: []string{
+				`methodConfig[0].name[0].service: service "FreightService" is not package-qualified`,
+			},
+		},
+		{
+			name: "qualified method",
+			serviceConfig: `{"methodConfig": [{"name": [
+  {"service": "einride.example.freight.v1.FreightService", "method": "FreightService.GetShipper"},
+  {"service": "einride.example.freight.v1.FreightService", "method": "/GetShipper"}
+]}]}`,
+			findings: []string{
+				`methodConfig[0].name[0].method: method "FreightService.GetShipper" must not be prefixed with its service`,
+				`methodConfig[0].name[1].method: method "/GetShipper" must not have a leading "/"`,
+			},
+		},
+		{
+			name: "whitespace",
+			serviceConfig: `{"methodConfig": [{"name": [
+  {"service": "einride.example.freight.v1.FreightService", "method": "GetShipper "}
+]}]}`,
+			findings: []string{
+				`methodConfig[0].name[0]: service and method must not have leading or trailing whitespace`,
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := nameFormatFindings([]byte(tt.serviceConfig))
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := jsonFindingStrings(findings)
+			if expected := strings.Join(tt.findings, "\n"); actual != expected {
+				t.Errorf("expected findings:\n%s\ngot:\n%s", expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// numericFields are the numeric service config fields grpc-go parses as JSON numbers.
+// The protobuf JSON format also accepts numeric strings for these fields, but grpc-go rejects them.
+var numericFields = map[string]struct{}{
+	"methodConfig[*].retryPolicy.maxAttempts":       {},
+	"methodConfig[*].retryPolicy.backoffMultiplier": {},
+	"methodConfig[*].hedgingPolicy.maxAttempts":     {},
+	"methodConfig[*].maxRequestMessageBytes":        {},
+	"methodConfig[*].maxResponseMessageBytes":       {},
+	"retryThrottling.maxTokens":                     {},
+	"retryThrottling.tokenRatio":                    {},
+}
+
+// normalizeNumericStrings replaces numeric strings in numeric fields of the service config JSON with numbers.
+// Only the numeric strings are rewritten, so the rest of the service config keeps its formatting and field order.
+// Returns the normalized service config and the normalized values, or the unchanged service config when there is
+// nothing to normalize.
+func normalizeNumericStrings(serviceConfig string) (string, []jsonFinding, error) {
+	data := []byte(serviceConfig)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// numericString is a numeric string at a byte range of the service config.
+	type numericString struct {
+		start, end int64
+		value      string
+	}
+	var numericStrings []numericString
+	var findings []jsonFinding
+	var walk func(path, pattern, key string) error
+	walk = func(path, pattern, key string) error {
+		start := skipJSONSeparators(data, dec.InputOffset())
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case json.Delim:
+			if token == '{' {
+				for dec.More() {
+					fieldKey, err := dec.Token()
+					if err != nil {
+						return err
+					}
+					key := fmt.Sprint(fieldKey)
+					fieldPath, fieldPattern := key, snakeToLowerCamel(key)
+					if path != "" {
+						fieldPath, fieldPattern = path+"."+fieldPath, pattern+"."+fieldPattern
+					}
+					if err := walk(fieldPath, fieldPattern, key); err != nil {
+						return err
+					}
+				}
+			} else {
+				for i := 0; dec.More(); i++ {
+					if err := walk(fmt.Sprintf("%s[%d]", path, i), pattern+"[*]", key); err != nil {
+						return err
+					}
+				}
+			}
+			_, err = dec.Token()
+			return err
+		case string:
+			if _, ok := numericFields[pattern]; !ok {
+				return nil
+			}
+			if _, err := json.Number(token).Float64(); err == nil && json.Valid([]byte(token)) {
+				numericStrings = append(numericStrings, numericString{start: start, end: dec.InputOffset(), value: token})
+				findings = append(findings, jsonFinding{
+					path:    path,
+					message: fmt.Sprintf("%s is the string %q, which grpc-go rejects, use the number %s", key, token, token),
+				})
+			}
+		}
+		return nil
+	}
+	if err := walk("", "", ""); err != nil {
+		return "", nil, err
+	}
+	if len(numericStrings) == 0 {
+		return serviceConfig, nil, nil
+	}
+	var result strings.Builder
+	var offset int64
+	for _, numericString := range numericStrings {
+		result.WriteString(serviceConfig[offset:numericString.start])
+		result.WriteString(numericString.value)
+		offset = numericString.end
+	}
+	result.WriteString(serviceConfig[offset:])
+	return result.String(), findings, nil
+}
@@ -0,0 +1,58 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeNumericStrings(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		serviceConfig string
+		expected      string
+		findings      []string
+	}{
+		{
+			name:          "numbers",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "retryPolicy": {"maxAttempts": 3}}]}`,
+			expected:      `{"methodConfig": [{"name": [{}], "retryPolicy": {"maxAttempts": 3}}]}`,
+		},
+		{
+			name: "numeric strings",
+			serviceConfig: `{
+  "methodConfig": [{"name": [{}], "retryPolicy": {"maxAttempts": "3", "backoffMultiplier": "1.5"}}],
+  "retry_throttling": {"max_tokens": "10", "token_ratio": 0.1}
+}`,
+			expected: `{
+  "methodConfig": [{"name": [{}], "retryPolicy": {"maxAttempts": 3, "backoffMultiplier": 1.5}}],
+  "retry_throttling": {"max_tokens": 10, "token_ratio": 0.1}
+}`,
+			findings: []string{
+				`methodConfig[0].retryPolicy.maxAttempts: maxAttempts is the string "3", which grpc-go rejects,` +
+					` use the number 3`,
+				`methodConfig[0].retryPolicy.backoffMultiplier: backoffMultiplier is the string "1.5", which grpc-go` +
+					` rejects, use the number 1.5`,
+				`retry_throttling.max_tokens: max_tokens is the string "10", which grpc-go rejects, use the number 10`,
+			},
+		},
+		{
+			name:          "non-numeric strings",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "retryPolicy": {"maxAttempts": "three"}, "timeout": "1"}]}`,
+			expected:      `{"methodConfig": [{"name": [{}], "retryPolicy": {"maxAttempts": "three"}, "timeout": "1"}]}`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, findings, err := normalizeNumericStrings(tt.serviceConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, actual)
+			}
+			if expected, actual := strings.Join(tt.findings, "\n"), jsonFindingStrings(findings); actual != expected {
+				t.Errorf("expected findings:\n%s\ngot:\n%s", expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/yamljson"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIExtension is the name of the OpenAPI vendor extension with the service config of an operation.
+const openAPIExtension = "x-grpc-service-config"
+
+// openAPIExtensionFields are the method config fields included in OpenAPI vendor extensions.
+var openAPIExtensionFields = []string{"timeout", "waitForReady", "retryPolicy", "hedgingPolicy"}
+
+// httpPathVariablePattern matches the patterns of variables in google.api.http path templates, such as
+// "=shippers/*" in "/v1/{name=shippers/*}".
+var httpPathVariablePattern = regexp.MustCompile(`=[^}]*}`)
+
+// openAPIOperation is an HTTP binding of a method, which is an operation in an OpenAPI document.
+type openAPIOperation struct {
+	// path is the OpenAPI path, such as "/v1/{name}".
+	path string
+	// method is the lower case HTTP method, such as "get".
+	method string
+}
+
+// openAPIOperations returns the operations of the google.api.http annotation of a method, including additional
+// bindings. The patterns of path variables are removed, like OpenAPI generators for grpc-gateway do.
+func openAPIOperations(method protoreflect.MethodDescriptor) []openAPIOperation {
+	options, ok := method.Options().(*descriptorpb.MethodOptions)
+	if !ok || options == nil {
+		return nil
+	}
+	httpRule, ok := proto.GetExtension(options, annotations.E_Http).(*annotations.HttpRule)
+	if !ok || httpRule == nil {
+		return nil
+	}
+	var result []openAPIOperation
+	for _, rule := range append([]*annotations.HttpRule{httpRule}, httpRule.GetAdditionalBindings()...) {
+		var operation openAPIOperation
+		switch pattern := rule.GetPattern().(type) {
+		case *annotations.HttpRule_Get:
+			operation = openAPIOperation{path: pattern.Get, method: "get"}
+		case *annotations.HttpRule_Put:
+			operation = openAPIOperation{path: pattern.Put, method: "put"}
+		case *annotations.HttpRule_Post:
+			operation = openAPIOperation{path: pattern.Post, method: "post"}
+		case *annotations.HttpRule_Delete:
+			operation = openAPIOperation{path: pattern.Delete, method: "delete"}
+		case *annotations.HttpRule_Patch:
+			operation = openAPIOperation{path: pattern.Patch, method: "patch"}
+		case *annotations.HttpRule_Custom:
+			operation = openAPIOperation{path: pattern.Custom.GetPath(), method: strings.ToLower(pattern.Custom.GetKind())}
+		default:
+			continue
+		}
+		operation.path = httpPathVariablePattern.ReplaceAllString(operation.path, "}")
+		result = append(result, operation)
+	}
+	return result
+}
+
+// openAPIExtensions returns the OpenAPI paths of the methods with google.api.http annotations of the services to
+// generate, with the vendor extension of every operation: the timeout, waitForReady, and retry and hedging policies
+// of the method config that applies to the method.
+func (p *plugin) openAPIExtensions() (map[string]map[string]map[string]json.RawMessage, error) {
+	serviceConfigs, err := p.packageServiceConfigs()
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]map[string]map[string]json.RawMessage{}
+	for _, serviceConfig := range serviceConfigs {
+		var content serviceConfigJSON
+		if err := json.Unmarshal([]byte(serviceConfig.json), &content); err != nil {
+			return nil, fmt.Errorf("%s: %w", serviceConfig.source, err)
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(serviceConfig.json), &fields); err != nil {
+			return nil, fmt.Errorf("%s: %w", serviceConfig.source, err)
+		}
+		var methodConfigs []map[string]json.RawMessage
+		if data := configjson.MethodConfigsField(fields); len(data) > 0 {
+			if err := json.Unmarshal(data, &methodConfigs); err != nil {
+				return nil, fmt.Errorf("%s: methodConfig: %w", serviceConfig.source, err)
+			}
+		}
+		for _, service := range serviceConfig.services {
+			for i := 0; i < service.Methods().Len(); i++ {
+				method := service.Methods().Get(i)
+				operations := openAPIOperations(method)
+				if len(operations) == 0 {
+					continue
+				}
+				index, ok := content.methodConfigFor(method)
+				if !ok || index >= len(methodConfigs) {
+					continue
+				}
+				extension := map[string]json.RawMessage{}
+				for _, field := range openAPIExtensionFields {
+					if value, ok := methodConfigs[index][field]; ok {
+						extension[field] = value
+					}
+				}
+				if len(extension) == 0 {
+					continue
+				}
+				for _, operation := range operations {
+					if result[operation.path] == nil {
+						result[operation.path] = map[string]map[string]json.RawMessage{}
+					}
+					result[operation.path][operation.method] = extension
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// readOpenAPIDocument reads an OpenAPI document in the JSON or YAML format.
+func readOpenAPIDocument(filename string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if yamljson.IsYAMLFile(filename) {
+		if data, err = yamljson.ToJSON(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return document, nil
+}
+
+// generateOpenAPI writes the x-grpc-service-config vendor extensions of the operations of methods with
+// google.api.http annotations to a file, so that REST consumers see the effective deadline and retry policies.
+// With an OpenAPI document, the document is written with the extensions added to its operations, and operations
+// missing from the document are skipped. Without one, a JSON Merge Patch (RFC 7386) for the document is written.
+// Files with a YAML file extension are written in the YAML format, and other files in the JSON format.
+func (p *plugin) generateOpenAPI(filename, documentFilename string) error {
+	extensions, err := p.openAPIExtensions()
+	if err != nil {
+		return fmt.Errorf("emit OpenAPI extensions: %w", err)
+	}
+	var result map[string]interface{}
+	if documentFilename != "" {
+		if result, err = readOpenAPIDocument(documentFilename); err != nil {
+			return fmt.Errorf("emit OpenAPI extensions: %w", err)
+		}
+		paths, _ := result["paths"].(map[string]interface{})
+		for path, operations := range extensions {
+			pathItem, _ := paths[path].(map[string]interface{})
+			for method, extension := range operations {
+				if operation, ok := pathItem[method].(map[string]interface{}); ok {
+					operation[openAPIExtension] = extension
+				}
+			}
+		}
+	} else {
+		paths := make(map[string]interface{}, len(extensions))
+		for path, operations := range extensions {
+			pathItem := make(map[string]interface{}, len(operations))
+			for method, extension := range operations {
+				pathItem[method] = map[string]interface{}{openAPIExtension: extension}
+			}
+			paths[path] = pathItem
+		}
+		result = map[string]interface{}{"paths": paths}
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("emit OpenAPI extensions: %w", err)
+	}
+	data = append(data, '\n')
+	if yamljson.IsYAMLFile(filename) {
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("emit OpenAPI extensions: %w", err)
+		}
+		var encoded bytes.Buffer
+		enc := yaml.NewEncoder(&encoded)
+		enc.SetIndent(2)
+		if err := enc.Encode(value); err != nil {
+			return fmt.Errorf("emit OpenAPI extensions: %w", err)
+		}
+		data = encoded.Bytes()
+	}
+	g := p.gen.NewGeneratedFile(filename, "")
+	_, err = g.Write(data)
+	return err
+}
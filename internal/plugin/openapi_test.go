@@ -0,0 +1,143 @@
+package plugin
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateOpenAPI(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		testFreightServiceConfigFile: `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s", "maxRequestMessageBytes": 1024},
+    {
+      "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+      "timeout": "1s",
+      "waitForReady": true
+    }
+  ]
+}`,
+		"openapi.yaml": `openapi: 3.0.0
+paths:
+  /v1/{name}:
+    get:
+      operationId: GetShipper
+  /v1/other:
+    get:
+      operationId: Other
+`,
+	})
+	for _, tt := range []struct {
+		name     string
+		filename string
+		document string
+		expected string
+	}{
+		{
+			name:     "merge patch",
+			filename: "openapi_patch.json",
+			expected: `{
+  "paths": {
+    "/v1/shippers": {
+      "get": {
+        "x-grpc-service-config": {
+          "timeout": "10s"
+        }
+      }
+    },
+    "/v1/shippers:import": {
+      "post": {
+        "x-grpc-service-config": {
+          "timeout": "10s"
+        }
+      }
+    },
+    "/v1/{name}": {
+      "get": {
+        "x-grpc-service-config": {
+          "timeout": "1s",
+          "waitForReady": true
+        }
+      },
+      "patch": {
+        "x-grpc-service-config": {
+          "timeout": "10s"
+        }
+      }
+    }
+  }
+}
+`,
+		},
+		{
+			name:     "YAML merge patch",
+			filename: "openapi_patch.yaml",
+			expected: `paths:
+  /v1/{name}:
+    get:
+      x-grpc-service-config:
+        timeout: 1s
+        waitForReady: true
+    patch:
+      x-grpc-service-config:
+        timeout: 10s
+  /v1/shippers:
+    get:
+      x-grpc-service-config:
+        timeout: 10s
+  /v1/shippers:import:
+    post:
+      x-grpc-service-config:
+        timeout: 10s
+`,
+		},
+		{
+			name:     "document",
+			filename: "openapi.json",
+			document: "openapi.yaml",
+			expected: `{
+  "openapi": "3.0.0",
+  "paths": {
+    "/v1/other": {
+      "get": {
+        "operationId": "Other"
+      }
+    },
+    "/v1/{name}": {
+      "get": {
+        "operationId": "GetShipper",
+        "x-grpc-service-config": {
+          "timeout": "1s",
+          "waitForReady": true
+        }
+      }
+    }
+  }
+}
+`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			opts := pluginOptions{path: dir, conflict: conflictPreferJSON}
+			p := newTestPlugin(t, opts, testFile(t, testFreightServiceFile))
+			var document string
+			if tt.document != "" {
+				document = filepath.Join(dir, tt.document)
+			}
+			if err := p.generateOpenAPI(tt.filename, document); err != nil {
+				t.Fatal(err)
+			}
+			files := p.gen.Response().GetFile()
+			if len(files) != 1 {
+				t.Fatalf("expected 1 generated file, got %d", len(files))
+			}
+			if actual := files[0].GetName(); actual != tt.filename {
+				t.Errorf("expected name %s, got %s", tt.filename, actual)
+			}
+			if actual := files[0].GetContent(); actual != tt.expected {
+				t.Errorf("expected content:\n%s\ngot:\n%s", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package plugin
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+)
+
+// runOptions are the plugin options of a run, parsed from the parameter of the request.
+type runOptions struct {
+	// generalSet, validationSet and outputSet are the flag sets of the groups of options.
+	generalSet, validationSet, outputSet flag.FlagSet
+
+	path      string
+	lang      string
+	conflict  string
+	fix       bool
+	dedupe    bool
+	filenames string
+	dryRun    bool
+	logLevel  string
+	cacheDir  string
+	profile   string
+	validate  bool
+
+	validation validationFlags
+	lint       lintFlags
+	outputs    outputFlags
+}
+
+// validationFlags are the options of validation, which require validate=true.
+type validationFlags struct {
+	required         bool
+	strict           bool
+	targetGRPCGo     string
+	requireLossless  bool
+	maxConfigBytes   int
+	jobs             int
+	failFast         bool
+	breakingBaseline string
+	breakingTimeout  float64
+	reportFile       string
+	reportFormat     string
+	errorFormat      string
+}
+
+// lintFlags are the options of the opt-in lint rules, which require validate=true.
+type lintFlags struct {
+	configFile            string
+	requireTimeout        bool
+	requireDefault        bool
+	streamingRetry        string
+	nonIdempotentRetry    string
+	hedgingSideEffects    string
+	retryUnsafeStatusCode string
+	minTimeout            time.Duration
+	maxTimeout            time.Duration
+	timeoutRange          string
+	gatewayTimeout        time.Duration
+	gatewayTimeoutLevel   string
+	waitForReady          string
+}
+
+// outputFlags are the options of outputs in addition to the generated code.
+type outputFlags struct {
+	jsonSchema       string
+	json             string
+	configMap        string
+	dnsTXT           string
+	dnsTXTPercentage int
+	xds              string
+	xdsCluster       string
+	envoy            string
+	endpoints        string
+	terraform        string
+	markdown         string
+	csv              string
+	openAPI          string
+	openAPIDocument  string
+	java             string
+	javaResourcePath string
+}
+
+// newRunOptions returns the plugin options of a run, with their defaults.
+func newRunOptions() *runOptions {
+	o := &runOptions{}
+	general := &o.generalSet
+	general.StringVar(&o.path, "path", "", "input path of service config JSON files")
+	general.StringVar(&o.lang, "lang", string(languageGo), "language of generated modules (go, ts, python or csharp)")
+	general.StringVar(
+		&o.conflict,
+		"conflict",
+		string(conflictPreferJSON),
+		"how to resolve a service config JSON file and an annotation in the same package "+
+			"(error, prefer_json, prefer_annotation or merge)",
+	)
+	general.BoolVar(&o.fix, "fix", false, "rewrite deprecated service config fields in generated code")
+	general.BoolVar(&o.dedupe, "dedupe_service_configs", false, "embed identical service configs in one constant")
+	general.StringVar(&o.filenames, "filenames", string(filenamesParent), "naming of generated files")
+	general.BoolVar(&o.dryRun, "dry_run", false, "print resolved service configs instead of generating files")
+	general.StringVar(&o.logLevel, "log_level", string(logLevelOff), "level of structured logs to stderr (off or debug)")
+	general.StringVar(&o.cacheDir, "cache_dir", "", "directory of cached responses of runs with unchanged inputs")
+	general.StringVar(&o.profile, "profile", string(profileOff), "kind of profile to write of the run (cpu, mem or trace)")
+	general.BoolVar(&o.validate, "validate", false, "validate service configs")
+
+	validation := &o.validationSet
+	v := &o.validation
+	validation.BoolVar(&v.required, "required", false, "require every service to have a service config")
+	validation.BoolVar(&v.strict, "strict", false, "treat validation warnings as errors")
+	validation.StringVar(&v.targetGRPCGo, "target_grpc_go_version", "", "oldest grpc-go release to validate against")
+	validation.BoolVar(
+		&v.requireLossless, "require_lossless", false, "require service configs to survive a proto round trip",
+	)
+	validation.IntVar(&v.maxConfigBytes, "max_config_bytes", 0, "maximum size of a compacted service config, or 0")
+	validation.IntVar(&v.jobs, "jobs", 0, "maximum number of service configs validated concurrently")
+	validation.BoolVar(&v.failFast, "fail_fast", false, "stop validation at the first service that fails validation")
+	validation.StringVar(
+		&v.breakingBaseline, "breaking_baseline", "", "directory of previously generated files to compare against",
+	)
+	validation.Float64Var(
+		&v.breakingTimeout,
+		"breaking_timeout_ratio",
+		0.5,
+		"smallest allowed ratio between a new and a previous timeout (0 to allow any timeout)",
+	)
+	validation.StringVar(&v.reportFile, "report", "", "output path of a machine-readable validation report")
+	validation.StringVar(
+		&v.reportFormat, "report_format", string(reportFormatJSON), "validation report format (json or sarif)",
+	)
+	validation.StringVar(
+		&v.errorFormat,
+		"error_format",
+		string(errorFormatText),
+		"format of diagnostics written to stderr (text, github or json)",
+	)
+
+	l := &o.lint
+	validation.StringVar(&l.configFile, "lint_config", "", "path of a lint configuration file")
+	validation.BoolVar(
+		&l.requireTimeout, "lint_require_timeout", false, "require every unary method to have a timeout",
+	)
+	validation.BoolVar(
+		&l.requireDefault,
+		"require_default_method_config",
+		false,
+		"require every service config to have a default method config",
+	)
+	validation.StringVar(
+		&l.streamingRetry,
+		"lint_streaming_retry",
+		string(lintLevelWarn),
+		"level of the lint against retries on streaming methods (off, warn or error)",
+	)
+	validation.StringVar(
+		&l.nonIdempotentRetry,
+		"lint_non_idempotent_retry",
+		string(lintLevelOff),
+		"level of the lint against retries on non-idempotent methods (off, warn or error)",
+	)
+	validation.StringVar(
+		&l.hedgingSideEffects,
+		"lint_hedging_side_effects",
+		string(lintLevelWarn),
+		"level of the lint against hedging methods that may have side effects (off, warn or error)",
+	)
+	validation.StringVar(
+		&l.retryUnsafeStatusCode,
+		"lint_retry_unsafe_status_code",
+		string(lintLevelWarn),
+		"level of the lint against retrying INTERNAL and UNKNOWN (off, warn or error)",
+	)
+	validation.DurationVar(
+		&l.minTimeout, "lint_min_timeout", 0, "shortest allowed method timeout (0 for no minimum)",
+	)
+	validation.DurationVar(
+		&l.maxTimeout, "lint_max_timeout", 0, "longest allowed method timeout (0 for no maximum)",
+	)
+	validation.StringVar(
+		&l.timeoutRange,
+		"lint_timeout_range",
+		string(lintLevelWarn),
+		"level of the lint against timeouts outside of lint_min_timeout and lint_max_timeout (off, warn or error)",
+	)
+	validation.DurationVar(
+		&l.gatewayTimeout,
+		"lint_gateway_timeout",
+		0,
+		"timeout of the HTTP gateway serving methods with google.api.http annotations (0 for none)",
+	)
+	validation.StringVar(
+		&l.gatewayTimeoutLevel,
+		"lint_gateway_timeout_level",
+		string(lintLevelWarn),
+		"level of the lint against timeouts exceeding lint_gateway_timeout (off, warn or error)",
+	)
+	validation.StringVar(
+		&l.waitForReady,
+		"lint_wait_for_ready",
+		string(lintLevelOff),
+		"level of the lint against enabling waitForReady (off, warn or error)",
+	)
+
+	outputs := &o.outputSet
+	out := &o.outputs
+	outputs.StringVar(&out.jsonSchema, "json_schema_out", "", "output path of the service config JSON Schema")
+	outputs.StringVar(&out.json, "emit_json", "", "output directory of resolved service config JSON files")
+	outputs.StringVar(&out.configMap, "emit_configmap", "", "output directory of Kubernetes ConfigMap manifests")
+	outputs.StringVar(&out.dnsTXT, "emit_dns_txt", "", "output directory of _grpc_config DNS TXT records")
+	outputs.IntVar(
+		&out.dnsTXTPercentage,
+		"dns_txt_percentage",
+		-1,
+		"percentage of clients DNS TXT records apply to, for canary rollouts (-1 for all clients)",
+	)
+	outputs.StringVar(&out.xds, "emit_xds", "", "output directory of xDS RouteConfiguration resources")
+	outputs.StringVar(&out.xdsCluster, "xds_cluster", "", "cluster of xDS and Envoy routes (default the package)")
+	outputs.StringVar(&out.envoy, "emit_envoy", "", "output directory of Envoy routes")
+	outputs.StringVar(&out.endpoints, "emit_endpoints", "", "output directory of google.api.Service backend rules")
+	outputs.StringVar(&out.terraform, "emit_terraform", "", "output directory of a Terraform file with service configs")
+	outputs.StringVar(&out.markdown, "emit_markdown", "", "output directory of SERVICECONFIG.md files")
+	outputs.StringVar(&out.csv, "emit_csv", "", "output path of a CSV or TSV summary of effective policies")
+	outputs.StringVar(&out.openAPI, "emit_openapi", "", "output path of OpenAPI x-grpc-service-config extensions")
+	outputs.StringVar(
+		&out.openAPIDocument, "openapi_document", "", "input path of an OpenAPI document to add extensions to",
+	)
+	outputs.StringVar(&out.java, "emit_java", "", "output directory of grpc-java service config resources")
+	outputs.StringVar(
+		&out.javaResourcePath, "java_resource_path", defaultJavaResourcePath, "path pattern of grpc-java resources",
+	)
+	return o
+}
+
+// set sets a plugin option, like flag.FlagSet.Set.
+func (o *runOptions) set(name, value string) error {
+	for _, flags := range []*flag.FlagSet{&o.validationSet, &o.outputSet} {
+		if flags.Lookup(name) != nil {
+			return flags.Set(name, value)
+		}
+	}
+	return o.generalSet.Set(name, value)
+}
+
+// check returns an error if an option is invalid, or conflicts with other options.
+func (o *runOptions) check() error {
+	if err := language(o.lang).validate(); err != nil {
+		return err
+	}
+	if err := conflictPolicy(o.conflict).validate(); err != nil {
+		return err
+	}
+	if err := filenameScheme(o.filenames).validate(); err != nil {
+		return err
+	}
+	if err := logLevel(o.logLevel).validate(); err != nil {
+		return err
+	}
+	if err := profileKind(o.profile).validate(); err != nil {
+		return err
+	}
+	if o.validate {
+		opts, err := o.validateOptions(nil, nil)
+		if err != nil {
+			return err
+		}
+		if err := opts.check(); err != nil {
+			return err
+		}
+	} else if name, ok := firstSet(&o.validationSet); ok {
+		return fmt.Errorf("plugin option %s requires validate=true", name)
+	}
+	if o.dryRun {
+		if o.validate {
+			return fmt.Errorf("plugin option dry_run can not be combined with validate")
+		}
+		if name, ok := firstSet(&o.outputSet); ok {
+			return fmt.Errorf("plugin option dry_run can not be combined with %s", name)
+		}
+	}
+	for _, dependency := range []struct {
+		name, requires string
+		ok             bool
+	}{
+		{name: "dns_txt_percentage", requires: "emit_dns_txt", ok: o.outputs.dnsTXT != ""},
+		{name: "xds_cluster", requires: "emit_xds or emit_envoy", ok: o.outputs.xds != "" || o.outputs.envoy != ""},
+		{name: "openapi_document", requires: "emit_openapi", ok: o.outputs.openAPI != ""},
+		{name: "java_resource_path", requires: "emit_java", ok: o.outputs.java != ""},
+		{name: "breaking_timeout_ratio", requires: "breaking_baseline", ok: o.validation.breakingBaseline != ""},
+	} {
+		if o.isSet(dependency.name) && !dependency.ok {
+			return fmt.Errorf("plugin option %s requires %s", dependency.name, dependency.requires)
+		}
+	}
+	return nil
+}
+
+// cacheable returns true if runs with the options can be replayed from a generation cache.
+func (o *runOptions) cacheable() bool {
+	// Dry runs only write to stderr, breaking change detection compares against previously generated files, which are
+	// outputs rather than inputs of runs, and profiles and report files are written outside of the response and stderr,
+	// which are all that is replayed.
+	return o.cacheDir != "" && !o.dryRun && o.validation.breakingBaseline == "" && o.profile == "" &&
+		(o.validation.reportFile == "" || o.validation.reportFile == "-")
+}
+
+// pluginOptions returns the options of the plugin.
+func (o *runOptions) pluginOptions(stderr io.Writer) pluginOptions {
+	return pluginOptions{
+		path:      o.path,
+		conflict:  conflictPolicy(o.conflict),
+		fix:       o.fix,
+		dedupe:    o.dedupe,
+		filenames: filenameScheme(o.filenames),
+		log:       newLogger(stderr, logLevel(o.logLevel)),
+	}
+}
+
+// validateOptions returns the options of validation, with the lint configuration file.
+func (o *runOptions) validateOptions(lintConfig *lintConfig, stderr io.Writer) (validateOptions, error) {
+	levels := map[rule]lintLevel{
+		ruleStreamingRetry:        lintLevel(o.lint.streamingRetry),
+		ruleNonIdempotentRetry:    lintLevel(o.lint.nonIdempotentRetry),
+		ruleHedgingSideEffects:    lintLevel(o.lint.hedgingSideEffects),
+		ruleRetryUnsafeStatusCode: lintLevel(o.lint.retryUnsafeStatusCode),
+		ruleWaitForReady:          lintLevel(o.lint.waitForReady),
+		ruleTimeoutRange:          lintLevel(o.lint.timeoutRange),
+		ruleGatewayTimeout:        lintLevel(o.lint.gatewayTimeoutLevel),
+	}
+	if o.lint.requireTimeout {
+		levels[ruleRequireTimeout] = lintLevelError
+	}
+	if o.lint.requireDefault {
+		levels[ruleRequireDefaultMethodConfig] = lintLevelError
+	}
+	var targetGRPCGoVersion *grpcGoVersion
+	if o.validation.targetGRPCGo != "" {
+		version, err := parseGRPCGoVersion(o.validation.targetGRPCGo)
+		if err != nil {
+			return validateOptions{}, err
+		}
+		targetGRPCGoVersion = &version
+	}
+	return validateOptions{
+		required:            o.validation.required,
+		strict:              o.validation.strict,
+		stderr:              stderr,
+		reportFile:          o.validation.reportFile,
+		reportFormat:        reportFormat(o.validation.reportFormat),
+		errorFormat:         errorFormat(o.validation.errorFormat),
+		requireLossless:     o.validation.requireLossless,
+		maxConfigBytes:      o.validation.maxConfigBytes,
+		targetGRPCGoVersion: targetGRPCGoVersion,
+		jobs:                o.validation.jobs,
+		failFast:            o.validation.failFast,
+		breaking: breakingOptions{
+			baseline:     o.validation.breakingBaseline,
+			timeoutRatio: o.validation.breakingTimeout,
+		},
+		lint: lintOptions{
+			levels:         levels,
+			config:         lintConfig,
+			minTimeout:     o.lint.minTimeout,
+			maxTimeout:     o.lint.maxTimeout,
+			gatewayTimeout: o.lint.gatewayTimeout,
+		},
+	}, nil
+}
+
+// firstSet returns the name of the first option of the flag set that is set, in lexicographical order.
+func firstSet(flags *flag.FlagSet) (string, bool) {
+	var name string
+	flags.Visit(func(f *flag.Flag) {
+		if name == "" {
+			name = f.Name
+		}
+	})
+	return name, name != ""
+}
+
+// isSet returns true if the option is set by the parameter.
+func (o *runOptions) isSet(name string) bool {
+	var result bool
+	for _, flags := range []*flag.FlagSet{&o.generalSet, &o.validationSet, &o.outputSet} {
+		flags.Visit(func(f *flag.Flag) {
+			result = result || f.Name == name
+		})
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunOptionsCheck(t *testing.T) {
+	for _, tt := range []struct {
+		parameter string
+		err       string
+	}{
+		{parameter: ""},
+		{parameter: "validate=true,strict=true,lint_min_timeout=1s,report=report.json"},
+		{parameter: "emit_xds=out,xds_cluster=cluster"},
+		{parameter: "emit_envoy=out,xds_cluster=cluster"},
+		{parameter: "validate=true,breaking_baseline=old,breaking_timeout_ratio=0.2"},
+		{parameter: "lang=java", err: `unsupported lang "java"`},
+		{parameter: "strict=true", err: "plugin option strict requires validate=true"},
+		{parameter: "lint_streaming_retry=error", err: "plugin option lint_streaming_retry requires validate=true"},
+		{parameter: "validate=true,report_format=xml", err: `unsupported report format "xml"`},
+		{parameter: "validate=true,jobs=-1", err: "jobs must not be negative"},
+		{parameter: "validate=true,lint_min_timeout=2s,lint_max_timeout=1s", err: "lint minimum timeout 2s"},
+		{parameter: "validate=true,target_grpc_go_version=latest", err: "latest"},
+		{parameter: "dry_run=true,validate=true", err: "plugin option dry_run can not be combined with validate"},
+		{parameter: "dry_run=true,emit_json=out", err: "plugin option dry_run can not be combined with emit_json"},
+		{parameter: "dns_txt_percentage=10", err: "plugin option dns_txt_percentage requires emit_dns_txt"},
+		{parameter: "xds_cluster=cluster", err: "plugin option xds_cluster requires emit_xds or emit_envoy"},
+		{parameter: "openapi_document=openapi.yaml", err: "plugin option openapi_document requires emit_openapi"},
+		{parameter: "java_resource_path=x", err: "plugin option java_resource_path requires emit_java"},
+		{
+			parameter: "validate=true,breaking_timeout_ratio=0.2",
+			err:       "plugin option breaking_timeout_ratio requires breaking_baseline",
+		},
+	} {
+		o := newRunOptions()
+		if tt.parameter != "" {
+			for _, param := range strings.Split(tt.parameter, ",") {
+				name, value := param, ""
+				if i := strings.IndexByte(param, '='); i >= 0 {
+					name, value = param[:i], param[i+1:]
+				}
+				if err := o.set(name, value); err != nil {
+					t.Fatalf("%q: %v", tt.parameter, err)
+				}
+			}
+		}
+		err := o.check()
+		if tt.err == "" && err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.parameter, err)
+		}
+		if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%q: expected error containing %q, got %v", tt.parameter, tt.err, err)
+		}
+	}
+}
+
+func TestRunOptionsSetUnknown(t *testing.T) {
+	if err := newRunOptions().set("unknown", "true"); err == nil {
+		t.Error("expected an error for an unknown plugin option")
+	}
+}
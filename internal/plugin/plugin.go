@@ -0,0 +1,610 @@
+// Package plugin implements the protoc-gen-go-grpc-service-config protoc plugin.
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+
+	serviceconfigv1 "go.buf.build/protocolbuffers/go/einride/grpc-service-config/einride/serviceconfig/v1"
+	"go.buf.build/protocolbuffers/go/grpc/grpc/grpc/service_config"
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const docURL = "https://github.com/grpc/grpc/blob/master/doc/service_config.md"
+
+// Main runs the protoc plugin.
+func Main() {
+	if len(os.Args) == 2 && os.Args[1] == "--version" {
+		fmt.Printf("%s %s\n", filepath.Base(os.Args[0]), VersionInfo())
+		os.Exit(0)
+	}
+	run(newRun())
+}
+
+// newRun returns the options of a plugin run, with the plugin options parsed from the parameter of the request, the
+// function returning the generation cache entry of the run, and the function generating files.
+func newRun() (
+	protogen.Options,
+	func(gen *protogen.Plugin) (*generationCache, error),
+	func(gen *protogen.Plugin, stderr io.Writer) error,
+) {
+	o := newRunOptions()
+	cache := func(gen *protogen.Plugin) (*generationCache, error) {
+		if !o.cacheable() {
+			return nil, nil
+		}
+		return newGenerationCache(o.cacheDir, gen, o.path, o.lint.configFile, o.outputs.openAPIDocument)
+	}
+	return protogen.Options{ParamFunc: o.set}, cache, func(gen *protogen.Plugin, stderr io.Writer) (err error) {
+		if err := o.check(); err != nil {
+			return err
+		}
+		stopProfile, err := startProfile(profileKind(o.profile))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if stopErr := stopProfile(); err == nil {
+				err = stopErr
+			}
+		}()
+		p, err := newPlugin(gen, o.pluginOptions(stderr))
+		if err != nil {
+			return err
+		}
+		if p.conflict == conflictError {
+			if err := p.checkConflicts(); err != nil {
+				return err
+			}
+		}
+		if o.dryRun {
+			return p.writeResolution(stderr)
+		}
+		if o.validate {
+			lintConfig, err := loadLintConfig(o.lint.configFile, o.path)
+			if err != nil {
+				return err
+			}
+			opts, err := o.validateOptions(lintConfig, stderr)
+			if err != nil {
+				return err
+			}
+			if err := p.validate(opts); err != nil {
+				return err
+			}
+		}
+		if language(o.lang) == languageGo {
+			if err := p.generateFromJSON(); err != nil {
+				return err
+			}
+			if err := p.generateFromProto(); err != nil {
+				return err
+			}
+		} else if err := p.generateModules(language(o.lang)); err != nil {
+			return err
+		}
+		if o.outputs.jsonSchema != "" {
+			if err := p.generateJSONSchema(o.outputs.jsonSchema); err != nil {
+				return err
+			}
+		}
+		if o.outputs.json != "" {
+			if err := p.generateJSONArtifacts(o.outputs.json); err != nil {
+				return err
+			}
+		}
+		if o.outputs.configMap != "" {
+			if err := p.generateConfigMaps(o.outputs.configMap); err != nil {
+				return err
+			}
+		}
+		if o.outputs.dnsTXT != "" {
+			if err := p.generateDNSTXTRecords(o.outputs.dnsTXT, o.outputs.dnsTXTPercentage); err != nil {
+				return err
+			}
+		}
+		if o.outputs.xds != "" {
+			if err := p.generateXDSRouteConfigurations(o.outputs.xds, o.outputs.xdsCluster); err != nil {
+				return err
+			}
+		}
+		if o.outputs.envoy != "" {
+			if err := p.generateEnvoyRoutes(o.outputs.envoy, o.outputs.xdsCluster); err != nil {
+				return err
+			}
+		}
+		if o.outputs.endpoints != "" {
+			if err := p.generateEndpointsBackendRules(o.outputs.endpoints); err != nil {
+				return err
+			}
+		}
+		if o.outputs.terraform != "" {
+			if err := p.generateTerraformLocals(o.outputs.terraform); err != nil {
+				return err
+			}
+		}
+		if o.outputs.java != "" {
+			if err := p.generateJavaResources(o.outputs.java, o.outputs.javaResourcePath); err != nil {
+				return err
+			}
+		}
+		if o.outputs.markdown != "" {
+			if err := p.generateMarkdownDocs(o.outputs.markdown); err != nil {
+				return err
+			}
+		}
+		if o.outputs.csv != "" {
+			if err := p.generateCSV(o.outputs.csv); err != nil {
+				return err
+			}
+		}
+		if o.outputs.openAPI != "" {
+			if err := p.generateOpenAPI(o.outputs.openAPI, o.outputs.openAPIDocument); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+type plugin struct {
+	pluginOptions
+	gen *protogen.Plugin
+	// files are the files of the descriptor set, or nil until built on first use when running as a protoc plugin.
+	files     *protoregistry.Files
+	filesOnce sync.Once
+	// filesByPackage are the files of the request by proto package, or nil until built on first use.
+	filesByPackage map[protoreflect.FullName][]protoreflect.FileDescriptor
+	schema         *jsonSchema
+	// serviceConfigFiles are the service config files read, by cleaned absolute path.
+	serviceConfigFiles map[string]*serviceConfigFile
+	// generatedSources are the sources of the generated Go files with service configs, by filename.
+	generatedSources map[string]string
+}
+
+// pluginOptions configures how service configs are resolved and generated.
+type pluginOptions struct {
+	// path is the input path of service config JSON files.
+	path string
+	// conflict decides which service config to resolve when a package has both a JSON file and an annotation.
+	conflict conflictPolicy
+	// fix rewrites deprecated service config fields in generated code.
+	fix bool
+	// dedupe embeds byte-identical service config JSON files in a single generated constant.
+	dedupe bool
+	// filenames decides how files generated for a package are named.
+	filenames filenameScheme
+	// log writes structured debug logs, or is nil to not log.
+	log *logger
+}
+
+func newPlugin(gen *protogen.Plugin, opts pluginOptions) (*plugin, error) {
+	if err := opts.conflict.validate(); err != nil {
+		return nil, err
+	}
+	if err := opts.filenames.validate(); err != nil {
+		return nil, err
+	}
+	for _, file := range gen.Files {
+		opts.log.debug(
+			"scan file",
+			"file", file.Desc.Path(),
+			"package", file.Desc.Package(),
+			"generate", file.Generate,
+			"services", len(file.Services),
+		)
+	}
+	return &plugin{
+		pluginOptions: opts,
+		gen:           gen,
+	}, nil
+}
+
+// registry returns the files of the descriptor set, or nil for service configs validated without one. When running
+// as a protoc plugin, the registry is built from the files of the request on first use, since it is only needed to
+// look up names, and building it for every file is wasted work for huge requests with service config JSON files.
+func (p *plugin) registry() *protoregistry.Files {
+	p.filesOnce.Do(func() {
+		if p.files != nil || p.gen == nil {
+			return
+		}
+		var files protoregistry.Files
+		for _, file := range p.gen.Files {
+			// protogen already registered the same files in a registry of its own, so registering them can not fail.
+			_ = files.RegisterFile(file.Desc)
+		}
+		p.files = &files
+	})
+	return p.files
+}
+
+// packageFiles returns the files of a proto package, in the order of the request.
+func (p *plugin) packageFiles(pkg protoreflect.FullName) []protoreflect.FileDescriptor {
+	if p.gen == nil {
+		var result []protoreflect.FileDescriptor
+		p.files.RangeFilesByPackage(pkg, func(file protoreflect.FileDescriptor) bool {
+			result = append(result, file)
+			return true
+		})
+		return result
+	}
+	if p.filesByPackage == nil {
+		p.filesByPackage = map[protoreflect.FullName][]protoreflect.FileDescriptor{}
+		for _, file := range p.gen.Files {
+			p.filesByPackage[file.Desc.Package()] = append(p.filesByPackage[file.Desc.Package()], file.Desc)
+		}
+	}
+	return p.filesByPackage[pkg]
+}
+
+// serviceConfigSchema returns the service config JSON Schema, generating it on first use.
+func (p *plugin) serviceConfigSchema() *jsonSchema {
+	if p.schema == nil {
+		p.schema = serviceConfigJSONSchema()
+	}
+	return p.schema
+}
+
+func (p *plugin) generateJSONSchema(filename string) error {
+	data, err := json.MarshalIndent(p.serviceConfigSchema(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("generate JSON Schema: %w", err)
+	}
+	g := p.gen.NewGeneratedFile(filename, "")
+	_, err = g.Write(append(data, '\n'))
+	return err
+}
+
+func (p *plugin) generateFromProto() error {
+	for _, file := range p.gen.Files {
+		if !file.Generate {
+			continue
+		}
+		defaultServiceConfig := proto.GetExtension(
+			file.Proto.GetOptions(),
+			serviceconfigv1.E_DefaultServiceConfig,
+		).(*service_config.ServiceConfig)
+		if defaultServiceConfig == nil {
+			continue
+		}
+		g, err := p.newGeneratedFile(p.generatedFromProtoFilename(file), file.Desc.Path(), file.GoImportPath)
+		if err != nil {
+			return err
+		}
+		generatedFileHeader(g)
+		g.P("package ", file.GoPackageName)
+		g.P()
+		g.P("// DefaultServiceConfig is the default service config for all services in the package.")
+		g.P("// Source: ", file.Desc.Path(), ".")
+		serviceConfig := protojson.MarshalOptions{}.Format(defaultServiceConfig)
+		if p.fix {
+			fixed, ok, err := fixDeprecatedLoadBalancingPolicy(serviceConfig)
+			if err != nil {
+				return fmt.Errorf("%s: %w", file.Desc.Path(), err)
+			}
+			if ok {
+				serviceConfig = fixed
+			}
+		}
+		writeServiceConfigConstant(g, "DefaultServiceConfig", serviceConfig)
+	}
+	return nil
+}
+
+func (p *plugin) generateFromJSON() error {
+	generatedServiceConfigFiles := map[string]struct{}{}
+	// generatedServiceConfigs are the constants generated for service config contents, used for deduplication.
+	generatedServiceConfigs := map[string]protogen.GoIdent{}
+	for _, file := range p.gen.Files {
+		if !file.Generate {
+			continue
+		}
+		for _, service := range file.Services {
+			serviceConfigFile := p.resolveServiceConfigJSONFile(service.Desc)
+			_, found, err := p.readServiceConfigJSONFile(serviceConfigFile)
+			if err != nil {
+				return err
+			}
+			if !found {
+				continue
+			}
+			if _, ok := generatedServiceConfigFiles[serviceConfigFile]; ok {
+				continue
+			}
+			generatedServiceConfigFiles[serviceConfigFile] = struct{}{}
+			serviceConfig, err := p.readGeneratedServiceConfig(serviceConfigFile)
+			if err != nil {
+				return err
+			}
+			g, err := p.newGeneratedFile(p.generatedFromJSONFilename(file), serviceConfigFile, file.GoImportPath)
+			if err != nil {
+				return err
+			}
+			generatedFileHeader(g)
+			g.P("package ", file.GoPackageName)
+			g.P()
+			g.P("// ServiceConfig is the service config for all services in the package.")
+			g.P("// Source: ", filepath.Base(serviceConfigFile), ".")
+			shared, ok := generatedServiceConfigs[serviceConfig]
+			if !ok {
+				generatedServiceConfigs[serviceConfig] = file.GoImportPath.Ident("ServiceConfig")
+			}
+			if ok && p.dedupe && shared.GoImportPath != file.GoImportPath {
+				g.P("const ServiceConfig = ", shared)
+			} else {
+				writeServiceConfigConstant(g, "ServiceConfig", serviceConfig)
+			}
+			if err := p.generateServiceConfigVariants(g, serviceConfigFile); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newGeneratedFile returns a new generated Go file with a service config from the source, which is a JSON or proto
+// file. Files of different sources that would be generated with the same name are an error, instead of files that
+// overwrite each other.
+func (p *plugin) newGeneratedFile(
+	filename string,
+	source string,
+	importPath protogen.GoImportPath,
+) (*protogen.GeneratedFile, error) {
+	if p.generatedSources == nil {
+		p.generatedSources = map[string]string{}
+	}
+	if existing, ok := p.generatedSources[filename]; ok {
+		return nil, fmt.Errorf(
+			"run: generated file %s collides: it would be generated from both %s and %s",
+			filename,
+			existing,
+			source,
+		)
+	}
+	p.generatedSources[filename] = source
+	return p.gen.NewGeneratedFile(filename, importPath), nil
+}
+
+// readGeneratedServiceConfig reads a service config JSON or YAML file, and returns the service config JSON to
+// generate, with deprecated fields fixed when enabled and numeric strings normalized.
+func (p *plugin) readGeneratedServiceConfig(serviceConfigFile string) (string, error) {
+	serviceConfig, found, err := p.readServiceConfigJSONFile(serviceConfigFile)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf("run: service config file %s does not exist", serviceConfigFile)
+	}
+	if err := json.Unmarshal([]byte(serviceConfig), &serviceConfigJSON{}); err != nil {
+		return "", fmt.Errorf(
+			"run: invalid service config file %s: %s",
+			jsonErrorLocation(serviceConfigFile, []byte(serviceConfig), err),
+			describeJSONError([]byte(serviceConfig), err),
+		)
+	}
+	if p.fix {
+		fixed, ok, err := fixDeprecatedLoadBalancingPolicy(serviceConfig)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", serviceConfigFile, err)
+		}
+		if ok {
+			serviceConfig = fixed
+		}
+	}
+	serviceConfig, _, err = normalizeNumericStrings(serviceConfig)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", serviceConfigFile, err)
+	}
+	return serviceConfig, nil
+}
+
+// serviceConfigChunkBytes is the maximum size of the raw string literals of generated service config constants.
+// Larger service configs are split into concatenated literals, so that generated files have no huge lines, which
+// editors, diff tools and code review handle poorly.
+const serviceConfigChunkBytes = 16 * 1024
+
+// writeServiceConfigConstant writes a constant with a service config as a raw string literal, or as concatenated raw
+// string literals of at most serviceConfigChunkBytes each for large service configs. Every literal is written with a
+// separate call, so that no copy of the service config is made for writing it.
+func writeServiceConfigConstant(g *protogen.GeneratedFile, name, serviceConfig string) {
+	if len(serviceConfig) <= serviceConfigChunkBytes {
+		g.P("const ", name, " = `", serviceConfig, "`")
+		return
+	}
+	g.P("const ", name, " =")
+	for len(serviceConfig) > 0 {
+		n := serviceConfigChunkBytes
+		if n >= len(serviceConfig) {
+			n = len(serviceConfig)
+		} else {
+			// Literals are split at rune boundaries, since each literal must be valid UTF-8.
+			for n > 0 && !utf8.RuneStart(serviceConfig[n]) {
+				n--
+			}
+		}
+		if n == len(serviceConfig) {
+			g.P("`", serviceConfig, "`")
+		} else {
+			g.P("`", serviceConfig[:n], "` +")
+		}
+		serviceConfig = serviceConfig[n:]
+	}
+}
+
+// serviceConfigDir returns the directory of the service config files of a proto file in the input path of service
+// config files. Proto file paths are slash-separated on every OS, so they are converted to the separators of the OS
+// before they are joined with the input path.
+func serviceConfigDir(serviceConfigPath string, file protoreflect.FileDescriptor) string {
+	return filepath.Join(serviceConfigPath, filepath.FromSlash(path.Dir(file.Path())))
+}
+
+func (p *plugin) resolveServiceConfigJSONFile(service protoreflect.ServiceDescriptor) string {
+	parentPackageName := string(service.ParentFile().Package().Parent().Name())
+	fileName := parentPackageName + "_grpc_service_config.json"
+	fullyQualifiedFileName := filepath.Join(serviceConfigDir(p.path, service.ParentFile()), fileName)
+	return fullyQualifiedFileName
+}
+
+// resolvedServiceConfig is a service config resolved for a service.
+type resolvedServiceConfig struct {
+	// source is the path of the JSON file or proto file the service config was resolved from.
+	source string
+	// json is the service config JSON.
+	json string
+	// annotation is true when the service config was resolved from a default_service_config file annotation.
+	annotation bool
+	// merged is true when the service config was merged from a JSON file and a default_service_config annotation.
+	merged bool
+	// positions are the positions of values in the service config JSON file.
+	// Positions are only known for service configs resolved from JSON files.
+	positions jsonPositions
+}
+
+// locate returns the location of the value at the JSON path in the service config source.
+// The location only includes a line and column for service configs resolved from JSON files.
+func (c resolvedServiceConfig) locate(path string) location {
+	if position, ok := c.positions[path]; ok {
+		return position
+	}
+	return location{file: c.source, jsonPath: path}
+}
+
+// describe returns a human-readable description of the JSON path, including its line and column when known.
+func (c resolvedServiceConfig) describe(path string) string {
+	if position, ok := c.positions[path]; ok {
+		return fmt.Sprintf("%s (line %d, column %d)", path, position.line, position.column)
+	}
+	return path
+}
+
+func (p *plugin) resolveServiceConfigFromJSONFile(
+	service protoreflect.ServiceDescriptor,
+) (resolvedServiceConfig, bool, error) {
+	serviceConfigJSONFile := p.resolveServiceConfigJSONFile(service)
+	serviceConfigJSON, found, err := p.readServiceConfigJSONFile(serviceConfigJSONFile)
+	p.log.debug(
+		"look up service config file",
+		"service", service.FullName(),
+		"file", serviceConfigJSONFile,
+		"found", found,
+	)
+	if err != nil {
+		return resolvedServiceConfig{}, false, fmt.Errorf("resolve %s service config: %w", service.FullName(), err)
+	}
+	if !found {
+		return resolvedServiceConfig{}, false, nil
+	}
+	return resolvedServiceConfig{source: serviceConfigJSONFile, json: serviceConfigJSON}, true, nil
+}
+
+// serviceConfigFile is the cached result of reading a service config file.
+type serviceConfigFile struct {
+	// found is false when the file does not exist.
+	found bool
+	// content is the content of the file as a string, so that resolved service configs share it instead of copying
+	// it, which matters for large service configs resolved by many services.
+	content string
+	err     error
+}
+
+// readServiceConfigJSONFile reads a service config file, and returns false when it does not exist. Every file is
+// read once per plugin run, since services resolve the same file repeatedly, and later reads return the cached
+// result. Files are cached by their cleaned absolute path, so different spellings of a path share a read.
+func (p *plugin) readServiceConfigJSONFile(filename string) (string, bool, error) {
+	key, err := filepath.Abs(filename)
+	if err != nil {
+		return "", false, err
+	}
+	if cached, ok := p.serviceConfigFiles[key]; ok {
+		return cached.content, cached.found, cached.err
+	}
+	var result serviceConfigFile
+	if _, err := os.Stat(filename); err == nil {
+		result.found = true
+		var data []byte
+		data, result.err = readServiceConfigFile(filename)
+		result.content = string(data)
+	}
+	if p.serviceConfigFiles == nil {
+		p.serviceConfigFiles = map[string]*serviceConfigFile{}
+	}
+	p.serviceConfigFiles[key] = &result
+	return result.content, result.found, result.err
+}
+
+func (p *plugin) resolveServiceConfigFromFileAnnotation(
+	service protoreflect.ServiceDescriptor,
+) (resolvedServiceConfig, bool, error) {
+	var serviceConfig *service_config.ServiceConfig
+	var source string
+	for _, file := range p.packageFiles(service.ParentFile().Package()) {
+		serviceConfig = proto.GetExtension(
+			file.Options(),
+			serviceconfigv1.E_DefaultServiceConfig,
+		).(*service_config.ServiceConfig)
+		source = file.Path()
+		p.log.debug(
+			"look up default_service_config annotation",
+			"service", service.FullName(),
+			"file", source,
+			"found", serviceConfig != nil,
+		)
+		if serviceConfig != nil {
+			break
+		}
+	}
+	if serviceConfig == nil {
+		return resolvedServiceConfig{}, false, nil
+	}
+	return resolvedServiceConfig{source: source, json: protojson.Format(serviceConfig), annotation: true}, true, nil
+}
+
+func (p *plugin) resolveServiceConfig(service protoreflect.ServiceDescriptor) (resolvedServiceConfig, bool, error) {
+	fromJSON, hasJSON, err := p.resolveServiceConfigFromJSONFile(service)
+	if err != nil {
+		return resolvedServiceConfig{}, false, err
+	}
+	fromAnnotation, hasAnnotation, err := p.resolveServiceConfigFromFileAnnotation(service)
+	if err != nil {
+		return resolvedServiceConfig{}, false, err
+	}
+	if !hasJSON || !hasAnnotation {
+		if hasJSON {
+			return fromJSON, true, nil
+		}
+		return fromAnnotation, hasAnnotation, nil
+	}
+	switch p.conflict {
+	case conflictError:
+		return resolvedServiceConfig{}, false, fmt.Errorf(
+			"resolve %s service config: both %s and a default_service_config annotation in %s exist "+
+				"(set conflict to %s, %s or %s to choose)",
+			service.FullName(),
+			fromJSON.source,
+			fromAnnotation.source,
+			conflictPreferJSON,
+			conflictPreferAnnotation,
+			conflictMerge,
+		)
+	case conflictPreferAnnotation:
+		return fromAnnotation, true, nil
+	case conflictMerge:
+		merged, err := configjson.Merge(fromAnnotation.json, fromJSON.json)
+		if err != nil {
+			return resolvedServiceConfig{}, false, fmt.Errorf("resolve %s service config: %w", service.FullName(), err)
+		}
+		return resolvedServiceConfig{source: fromJSON.source, json: merged, merged: true}, true, nil
+	}
+	return fromJSON, true, nil
+}
@@ -0,0 +1,671 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	_ "google.golang.org/genproto/googleapis/api/annotations" // registers the google.api.http extension
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// testFreightServiceFile is the proto file of the freight service used by tests, with unary and streaming methods,
+// idempotency levels and google.api.http annotations.
+const testFreightServiceFile = `
+name: "einride/example/freight/v1/freight_service.proto"
+package: "einride.example.freight.v1"
+dependency: "google/api/annotations.proto"
+options { go_package: "example.com/freight/v1;freightv1" }
+message_type { name: "Shipper" }
+service {
+  name: "FreightService"
+  method {
+    name: "GetShipper"
+    input_type: ".einride.example.freight.v1.Shipper"
+    output_type: ".einride.example.freight.v1.Shipper"
+    options {
+      idempotency_level: NO_SIDE_EFFECTS
+      [google.api.http] { get: "/v1/{name=shippers/*}" }
+    }
+  }
+  method {
+    name: "UpdateShipper"
+    input_type: ".einride.example.freight.v1.Shipper"
+    output_type: ".einride.example.freight.v1.Shipper"
+    options {
+      idempotency_level: IDEMPOTENT
+      [google.api.http] { patch: "/v1/{name=shippers/*}" body: "*" }
+    }
+  }
+  method {
+    name: "CreateShipper"
+    input_type: ".einride.example.freight.v1.Shipper"
+    output_type: ".einride.example.freight.v1.Shipper"
+  }
+  method {
+    name: "ImportShippers"
+    input_type: ".einride.example.freight.v1.Shipper"
+    output_type: ".einride.example.freight.v1.Shipper"
+    options {
+      [google.api.http] { post: "/v1/shippers:import" body: "*" }
+    }
+  }
+  method {
+    name: "ListShippers"
+    input_type: ".einride.example.freight.v1.Shipper"
+    output_type: ".einride.example.freight.v1.Shipper"
+    options {
+      [google.api.http] { get: "/v1/shippers" }
+    }
+  }
+  method {
+    name: "WatchShippers"
+    input_type: ".einride.example.freight.v1.Shipper"
+    output_type: ".einride.example.freight.v1.Shipper"
+    server_streaming: true
+  }
+}
+`
+
+// testFreightServiceConfigFile is the path of the service config JSON file of the freight service, relative to the
+// input path of service config JSON files.
+const testFreightServiceConfigFile = "einride/example/freight/v1/freight_grpc_service_config.json"
+
+// testRetryServiceConfig is a service config with a default method config, and a retry policy for a method of the
+// freight service.
+const testRetryServiceConfig = `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s"},
+    {
+      "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+      "timeout": "1s",
+      "retryPolicy": {
+        "maxAttempts": 3,
+        "initialBackoff": "0.1s",
+        "maxBackoff": "1s",
+        "backoffMultiplier": 2,
+        "retryableStatusCodes": ["UNAVAILABLE"]
+      }
+    }
+  ]
+}`
+
+// testManyServices returns n copies of the freight service in packages of their own, and writes a service config
+// JSON file for every package to a new temporary directory, which is returned as the input path of service configs.
+func testManyServices(tb testing.TB, n int) (string, []*descriptorpb.FileDescriptorProto) {
+	tb.Helper()
+	files := make([]*descriptorpb.FileDescriptorProto, 0, n)
+	serviceConfigs := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		replacer := strings.NewReplacer(
+			"/freight/", fmt.Sprintf("/freight%d/", i),
+			".freight.", fmt.Sprintf(".freight%d.", i),
+		)
+		files = append(files, testFile(tb, replacer.Replace(testFreightServiceFile)))
+		serviceConfigFile := fmt.Sprintf("einride/example/freight%d/v1/freight%d_grpc_service_config.json", i, i)
+		serviceConfigs[serviceConfigFile] = replacer.Replace(testRetryServiceConfig)
+	}
+	return writeTestFiles(tb, serviceConfigs), files
+}
+
+// testFile returns a file descriptor in the protobuf text format.
+func testFile(t testing.TB, text string) *descriptorpb.FileDescriptorProto {
+	t.Helper()
+	var file descriptorpb.FileDescriptorProto
+	if err := prototext.Unmarshal([]byte(text), &file); err != nil {
+		t.Fatal(err)
+	}
+	return &file
+}
+
+// testRequest returns a request to generate the files, with the parameter. The dependencies of the files are looked
+// up in the global registry, and added before the files that import them.
+func testRequest(
+	t testing.TB,
+	parameter string,
+	files ...*descriptorpb.FileDescriptorProto,
+) *pluginpb.CodeGeneratorRequest {
+	t.Helper()
+	request := &pluginpb.CodeGeneratorRequest{Parameter: proto.String(parameter)}
+	added := map[string]struct{}{}
+	var addDependency func(path string)
+	addDependency = func(path string) {
+		if _, ok := added[path]; ok {
+			return
+		}
+		added[path] = struct{}{}
+		file, err := protoregistry.GlobalFiles.FindFileByPath(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < file.Imports().Len(); i++ {
+			addDependency(file.Imports().Get(i).Path())
+		}
+		request.ProtoFile = append(request.ProtoFile, protodesc.ToFileDescriptorProto(file))
+	}
+	for _, file := range files {
+		for _, dependency := range file.GetDependency() {
+			addDependency(dependency)
+		}
+	}
+	for _, file := range files {
+		request.FileToGenerate = append(request.FileToGenerate, file.GetName())
+		request.ProtoFile = append(request.ProtoFile, file)
+	}
+	return request
+}
+
+// writeTestFiles writes files with their content to a new temporary directory, by path relative to the directory,
+// and returns the directory.
+func writeTestFiles(t testing.TB, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// runTestPlugin runs the plugin like protoc, with the plugin options in the parameter, and returns the response.
+// Diagnostics written to stderr are discarded.
+func runTestPlugin(
+	t testing.TB,
+	parameter string,
+	files ...*descriptorpb.FileDescriptorProto,
+) *pluginpb.CodeGeneratorResponse {
+	t.Helper()
+	opts, _, f := newRun()
+	gen, err := opts.New(testRequest(t, parameter, files...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f(gen, io.Discard); err != nil {
+		gen.Error(err)
+	}
+	return gen.Response()
+}
+
+// newTestPlugin returns a plugin generating the files, with the options.
+func newTestPlugin(t testing.TB, opts pluginOptions, files ...*descriptorpb.FileDescriptorProto) *plugin {
+	t.Helper()
+	gen, err := protogen.Options{}.New(testRequest(t, "", files...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := newPlugin(gen, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// responseFile returns the content of a generated file of a response, or false when the file was not generated.
+func responseFile(response *pluginpb.CodeGeneratorResponse, name string) (string, bool) {
+	for _, file := range response.GetFile() {
+		if file.GetName() == name {
+			return file.GetContent(), true
+		}
+	}
+	return "", false
+}
+
+// readTestReport reads the diagnostics of a JSON validation report.
+func readTestReport(t testing.TB, filename string) []jsonReportDiagnostic {
+	t.Helper()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report jsonReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	return report.Diagnostics
+}
+
+func TestGenerate(t *testing.T) {
+	// largeServiceConfig exceeds the size of a DNS TXT record.
+	largeServiceConfig := `{"healthCheckConfig": {"serviceName": "` + strings.Repeat("x", dnsTXTRecordMaxBytes) + `"}}`
+	for _, tt := range []struct {
+		name          string
+		parameter     string
+		serviceConfig string
+		// generated is the name of the generated file, or empty when no file is generated.
+		generated string
+		// contains are strings the generated file contains.
+		contains []string
+		// err is a substring of the error of the response, or empty for no error.
+		err string
+		// rules are the severities and rules of the validation report, in order.
+		rules []string
+	}{
+		{
+			name:          "service config",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+			generated:     "example.com/freight/v1/freight_grpc_service_config.json.go",
+			contains: []string{
+				"// Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.\n// versions:\n// \tgrpc-go v",
+				"package freightv1",
+				"// Source: freight_grpc_service_config.json.",
+				"const ServiceConfig = `{\"methodConfig\": [{\"name\": [{}], \"timeout\": \"10s\"}]}`",
+			},
+		},
+		{
+			name:          "full package filenames",
+			parameter:     "filenames=full_package",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+			generated:     "example.com/freight/v1/einride_example_freight_v1_grpc_service_config.json.go",
+		},
+		{
+			name: "numeric strings",
+			serviceConfig: `{
+  "methodConfig": [{
+    "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+    "timeout": "1s",
+    "maxRequestMessageBytes": "1024",
+    "retryPolicy": {
+      "maxAttempts": "3",
+      "initialBackoff": "0.1s",
+      "maxBackoff": "1s",
+      "backoffMultiplier": "2",
+      "retryableStatusCodes": ["UNAVAILABLE"]
+    }
+  }],
+  "retryThrottling": {"maxTokens": "10", "tokenRatio": "0.1"}
+}`,
+			generated: "example.com/freight/v1/freight_grpc_service_config.json.go",
+			contains: []string{
+				`"maxAttempts": 3`,
+				`"backoffMultiplier": 2`,
+				`"maxRequestMessageBytes": 1024`,
+				`"maxTokens": 10`,
+				`"tokenRatio": 0.1`,
+			},
+		},
+		{
+			name:      "numeric strings validated",
+			parameter: "validate=true",
+			serviceConfig: `{
+  "methodConfig": [{
+    "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+    "timeout": "1s",
+    "retryPolicy": {
+      "maxAttempts": "3",
+      "initialBackoff": "0.1s",
+      "maxBackoff": "1s",
+      "backoffMultiplier": 2,
+      "retryableStatusCodes": ["UNAVAILABLE"]
+    }
+  }]
+}`,
+			generated: "example.com/freight/v1/freight_grpc_service_config.json.go",
+			contains:  []string{`"maxAttempts": 3`},
+			rules:     []string{"warning NUMERIC_REPRESENTATION"},
+		},
+		{
+			name:          "invalid service config",
+			parameter:     "validate=true",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "forever"}]}`,
+			err:           "1 problem(s) found",
+			rules:         []string{"error INVALID_SERVICE_CONFIG"},
+		},
+		{
+			name:          "no size limit by default",
+			parameter:     "validate=true",
+			serviceConfig: largeServiceConfig,
+			generated:     "example.com/freight/v1/freight_grpc_service_config.json.go",
+			rules:         []string{},
+		},
+		{
+			name:          "size limit",
+			parameter:     "validate=true,max_config_bytes=32",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+			err:           "1 problem(s) found",
+			rules:         []string{"error CONFIG_SIZE"},
+		},
+		{
+			name:          "invalid JSON",
+			serviceConfig: `{"methodConfig": [}`,
+			err:           "invalid service config file",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTestFiles(t, map[string]string{testFreightServiceConfigFile: tt.serviceConfig})
+			reportFile := filepath.Join(t.TempDir(), "report.json")
+			parameter := "path=" + dir
+			if tt.parameter != "" {
+				parameter += "," + tt.parameter
+			}
+			if tt.rules != nil {
+				parameter += ",report=" + reportFile
+			}
+			response := runTestPlugin(t, parameter, testFile(t, testFreightServiceFile))
+			if tt.err == "" && response.GetError() != "" {
+				t.Fatalf("unexpected error: %s", response.GetError())
+			}
+			if !strings.Contains(response.GetError(), tt.err) {
+				t.Fatalf("expected error containing %q, got %q", tt.err, response.GetError())
+			}
+			if tt.generated != "" {
+				content, ok := responseFile(response, tt.generated)
+				if !ok {
+					t.Fatalf("expected %s to be generated", tt.generated)
+				}
+				for _, s := range tt.contains {
+					if !strings.Contains(content, s) {
+						t.Errorf("expected %s to contain %q, got:\n%s", tt.generated, s, content)
+					}
+				}
+			}
+			if tt.rules == nil {
+				return
+			}
+			var rules []string
+			for _, diagnostic := range readTestReport(t, reportFile) {
+				rules = append(rules, diagnostic.Severity+" "+diagnostic.Rule)
+			}
+			if strings.Join(rules, "\n") != strings.Join(tt.rules, "\n") {
+				t.Errorf("expected diagnostics %q, got %q", tt.rules, rules)
+			}
+		})
+	}
+}
+
+func TestGenerateFromJSONDedupe(t *testing.T) {
+	const serviceConfig = `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`
+	dir := writeTestFiles(t, map[string]string{
+		testFreightServiceConfigFile:                                  serviceConfig,
+		"einride/example/shipper/v1/shipper_grpc_service_config.json": serviceConfig,
+	})
+	shipperServiceFile := `
+name: "einride/example/shipper/v1/shipper_service.proto"
+package: "einride.example.shipper.v1"
+options { go_package: "example.com/shipper/v1;shipperv1" }
+service { name: "ShipperService" }
+`
+	for _, tt := range []struct {
+		name   string
+		dedupe bool
+		// expected are the generated constants, by generated file.
+		expected map[string]string
+	}{
+		{
+			name: "identical service configs",
+			expected: map[string]string{
+				"example.com/freight/v1/freight_grpc_service_config.json.go": "const ServiceConfig = `" + serviceConfig + "`",
+				"example.com/shipper/v1/shipper_grpc_service_config.json.go": "const ServiceConfig = `" + serviceConfig + "`",
+			},
+		},
+		{
+			name:   "deduplicated service configs",
+			dedupe: true,
+			expected: map[string]string{
+				"example.com/freight/v1/freight_grpc_service_config.json.go": "const ServiceConfig = `" + serviceConfig + "`",
+				"example.com/shipper/v1/shipper_grpc_service_config.json.go": "import (\n\tv1 \"example.com/freight/v1\"\n)" +
+					"\n\n// ServiceConfig is the service config for all services in the package.\n" +
+					"// Source: shipper_grpc_service_config.json.\nconst ServiceConfig = v1.ServiceConfig",
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPlugin(
+				t,
+				pluginOptions{path: dir, conflict: conflictPreferJSON, dedupe: tt.dedupe},
+				testFile(t, testFreightServiceFile),
+				testFile(t, shipperServiceFile),
+			)
+			if err := p.generateFromJSON(); err != nil {
+				t.Fatal(err)
+			}
+			response := p.gen.Response()
+			if len(response.GetFile()) != len(tt.expected) {
+				t.Fatalf("expected %d generated files, got %d", len(tt.expected), len(response.GetFile()))
+			}
+			for _, file := range response.GetFile() {
+				if !strings.Contains(file.GetContent(), tt.expected[file.GetName()]) {
+					t.Errorf("expected %s to contain:\n%s\ngot:\n%s", file.GetName(), tt.expected[file.GetName()], file.GetContent())
+				}
+			}
+		})
+	}
+	p := newTestPlugin(
+		t,
+		pluginOptions{path: dir, conflict: conflictPreferJSON},
+		testFile(t, testFreightServiceFile),
+		testFile(t, shipperServiceFile),
+	)
+	var err error
+	output := captureStderr(t, func() {
+		err = p.validate(validateOptions{reportFormat: reportFormatJSON})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := filepath.Join(dir, "einride/example/shipper/v1/shipper_grpc_service_config.json") +
+		":1:1: info: service config is identical to the service config in " +
+		filepath.Join(dir, testFreightServiceConfigFile) + ", consider consolidating them (DUPLICATE_CONFIG)"
+	if !strings.Contains(output, expected) {
+		t.Errorf("expected output containing %q, got %q", expected, output)
+	}
+}
+
+func TestReadServiceConfigJSONFile(t *testing.T) {
+	const serviceConfig = `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`
+	dir := writeTestFiles(t, map[string]string{testFreightServiceConfigFile: serviceConfig})
+	p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+	filename := filepath.Join(dir, testFreightServiceConfigFile)
+	missing := filepath.Join(dir, "missing.json")
+	for _, name := range []string{filename, missing} {
+		if _, _, err := p.readServiceConfigJSONFile(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Later reads return the cached results, also for other spellings of the paths.
+	if err := os.WriteFile(filename, []byte(`{}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(missing, []byte(`{}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	content, found, err := p.readServiceConfigJSONFile(filepath.Join(dir, ".", testFreightServiceConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found || content != serviceConfig {
+		t.Errorf("expected the cached service config %s, got %s (found %v)", serviceConfig, content, found)
+	}
+	if _, found, err := p.readServiceConfigJSONFile(missing); err != nil || found {
+		t.Errorf("expected the cached missing file, got found %v and error %v", found, err)
+	}
+}
+
+func TestWriteServiceConfigConstant(t *testing.T) {
+	// The multi-byte runes straddle the chunk boundaries, so literals are split before them.
+	large := strings.Repeat("ö", serviceConfigChunkBytes) + "x"
+	for _, tt := range []struct {
+		name          string
+		serviceConfig string
+		literals      int
+	}{
+		{name: "small", serviceConfig: `{"methodConfig": []}`, literals: 1},
+		{name: "chunk", serviceConfig: strings.Repeat("x", serviceConfigChunkBytes), literals: 1},
+		{name: "large", serviceConfig: large, literals: 3},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			gen, err := protogen.Options{}.New(testRequest(t, ""))
+			if err != nil {
+				t.Fatal(err)
+			}
+			g := gen.NewGeneratedFile("constant.go", "")
+			g.P("package constant")
+			writeServiceConfigConstant(g, "ServiceConfig", tt.serviceConfig)
+			content, err := g.Content()
+			if err != nil {
+				t.Fatal(err)
+			}
+			file, err := parser.ParseFile(token.NewFileSet(), "constant.go", content, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var literals []string
+			ast.Inspect(file, func(node ast.Node) bool {
+				if literal, ok := node.(*ast.BasicLit); ok {
+					value, err := strconv.Unquote(literal.Value)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if len(value) > serviceConfigChunkBytes || !utf8.ValidString(value) {
+						t.Errorf("expected literals of at most %d bytes of valid UTF-8", serviceConfigChunkBytes)
+					}
+					literals = append(literals, value)
+				}
+				return true
+			})
+			if len(literals) != tt.literals {
+				t.Errorf("expected %d literals, got %d", tt.literals, len(literals))
+			}
+			if actual := strings.Join(literals, ""); actual != tt.serviceConfig {
+				t.Errorf("expected the literals to concatenate to the service config of %d bytes, got %d bytes",
+					len(tt.serviceConfig), len(actual))
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	p := newTestPlugin(t, pluginOptions{conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+	if p.files != nil {
+		t.Fatal("expected the registry to be built on first use")
+	}
+	const name = "einride.example.freight.v1.FreightService"
+	descriptor, err := p.registry().FindDescriptorByName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if descriptor.FullName() != name {
+		t.Errorf("expected %s, got %s", name, descriptor.FullName())
+	}
+	if p.registry() != p.files {
+		t.Error("expected the registry to be built once")
+	}
+	files := p.packageFiles("einride.example.freight.v1")
+	if len(files) != 1 || files[0].Path() != "einride/example/freight/v1/freight_service.proto" {
+		t.Errorf("expected the freight service file, got %v", files)
+	}
+	if files := p.packageFiles("einride.example.missing.v1"); len(files) != 0 {
+		t.Errorf("expected no files, got %v", files)
+	}
+}
+
+func TestServiceConfigDir(t *testing.T) {
+	for _, tt := range []struct {
+		name              string
+		serviceConfigPath string
+		file              string
+		expected          string
+	}{
+		{
+			name:              "nested proto file",
+			serviceConfigPath: "proto",
+			file:              "einride/example/freight/v1/freight_service.proto",
+			expected:          filepath.Join("proto", "einride", "example", "freight", "v1"),
+		},
+		{
+			name:              "nested path",
+			serviceConfigPath: filepath.Join("build", "proto"),
+			file:              "einride/example/freight/v1/freight_service.proto",
+			expected:          filepath.Join("build", "proto", "einride", "example", "freight", "v1"),
+		},
+		{
+			name:              "absolute path",
+			serviceConfigPath: filepath.Join(string(filepath.Separator), "src", "proto"),
+			file:              "einride/example/freight/v1/freight_service.proto",
+			expected: filepath.Join(
+				string(filepath.Separator), "src", "proto", "einride", "example", "freight", "v1",
+			),
+		},
+		{
+			name:              "proto file in the root",
+			serviceConfigPath: filepath.Join("build", "proto"),
+			file:              "freight_service.proto",
+			expected:          filepath.Join("build", "proto"),
+		},
+		{
+			name:              "current directory",
+			serviceConfigPath: ".",
+			file:              "einride/example/freight/v1/freight_service.proto",
+			expected:          filepath.Join("einride", "example", "freight", "v1"),
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{Name: proto.String(tt.file)}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := serviceConfigDir(tt.serviceConfigPath, file); actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func BenchmarkResolveServiceConfig(b *testing.B) {
+	dir, files := testManyServices(b, 100)
+	p := newTestPlugin(b, pluginOptions{path: dir, conflict: conflictPreferJSON}, files...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Service config files are read once per plugin run, so every iteration is a new run.
+		p.serviceConfigFiles = nil
+		for _, file := range p.gen.Files {
+			if !file.Generate {
+				continue
+			}
+			for _, service := range file.Services {
+				if _, found, err := p.resolveServiceConfig(service.Desc); err != nil || !found {
+					b.Fatalf("resolve %s: found %v, error %v", service.Desc.FullName(), found, err)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkGenerateFromJSON(b *testing.B) {
+	dir, files := testManyServices(b, 100)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Every iteration is a new plugin run.
+		p := newTestPlugin(b, pluginOptions{path: dir, conflict: conflictPreferJSON}, files...)
+		if err := p.generateFromJSON(); err != nil {
+			b.Fatal(err)
+		}
+		if response := p.gen.Response(); len(response.GetFile()) != len(files) {
+			b.Fatalf("expected %d generated files, got %d", len(files), len(response.GetFile()))
+		}
+	}
+}
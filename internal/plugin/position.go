@@ -0,0 +1,118 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// location is a location in a file.
+type location struct {
+	// file is the path of the file.
+	file string
+	// line is the 1-based line number, or 0 when unknown.
+	line int
+	// column is the 1-based column number, or 0 when unknown.
+	column int
+	// jsonPath is the JSON path of the value in the file, such as `methodConfig[0].name[1]`, or empty when unknown.
+	jsonPath string
+}
+
+// String implements fmt.Stringer.
+func (l location) String() string {
+	if l.line == 0 {
+		return l.file
+	}
+	return l.file + ":" + strconv.Itoa(l.line) + ":" + strconv.Itoa(l.column)
+}
+
+// jsonPositions maps JSON paths, such as `methodConfig[0].name[1]`, to the location of their values in a JSON document.
+type jsonPositions map[string]location
+
+// parseJSONPositions returns the positions of every value in the JSON document in the file.
+func parseJSONPositions(file string, data []byte) (jsonPositions, error) {
+	positions := jsonPositions{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var walk func(path string) error
+	walk = func(path string) error {
+		start := skipJSONSeparators(data, dec.InputOffset())
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		position := offsetLocation(file, data, start)
+		position.jsonPath = path
+		positions[path] = position
+		switch token {
+		case json.Delim('{'):
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if path == "" {
+					err = walk(fmt.Sprint(key))
+				} else {
+					err = walk(fmt.Sprintf("%s.%s", path, key))
+				}
+				if err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+			return err
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+			return err
+		}
+		return nil
+	}
+	if err := walk(""); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// skipJSONSeparators returns the offset of the first byte at or after offset that is not whitespace or a separator.
+func skipJSONSeparators(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) {
+		switch data[offset] {
+		case ' ', '\t', '\r', '\n', ':', ',':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// offsetLocation returns the location of the byte offset in the file data.
+func offsetLocation(file string, data []byte, offset int64) location {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	line := 1 + bytes.Count(data[:offset], []byte("\n"))
+	column := int(offset) - bytes.LastIndexByte(data[:offset], '\n')
+	return location{file: file, line: line, column: column}
+}
+
+// jsonErrorLocation returns the location of a JSON decoding error in the file data, when the error has an offset.
+func jsonErrorLocation(file string, data []byte, err error) location {
+	var syntaxError *json.SyntaxError
+	if errors.As(err, &syntaxError) && syntaxError.Offset > 0 {
+		// The offset of a syntax error is just past the offending byte.
+		return offsetLocation(file, data, syntaxError.Offset-1)
+	}
+	var unmarshalTypeError *json.UnmarshalTypeError
+	if errors.As(err, &unmarshalTypeError) {
+		return offsetLocation(file, data, unmarshalTypeError.Offset)
+	}
+	return location{file: file}
+}
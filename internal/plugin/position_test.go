@@ -0,0 +1,57 @@
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseJSONPositions(t *testing.T) {
+	data := []byte(`{
+  "methodConfig": [
+    {"name": [{}, {"service": "a.B"}], "timeout": "1s"}
+  ]
+}`)
+	positions, err := parseJSONPositions("a.json", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		path     string
+		expected string
+	}{
+		{path: "", expected: "a.json:1:1"},
+		{path: "methodConfig", expected: "a.json:2:19"},
+		{path: "methodConfig[0]", expected: "a.json:3:5"},
+		{path: "methodConfig[0].name[0]", expected: "a.json:3:15"},
+		{path: "methodConfig[0].name[1]", expected: "a.json:3:19"},
+		{path: "methodConfig[0].name[1].service", expected: "a.json:3:31"},
+		{path: "methodConfig[0].timeout", expected: "a.json:3:51"},
+	} {
+		if actual := positions[tt.path].String(); actual != tt.expected {
+			t.Errorf("expected %s at %q, got %s", tt.expected, tt.path, actual)
+		}
+	}
+}
+
+func TestJSONErrorLocation(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{name: "syntax error", data: "{\n  \"methodConfig\": [}\n}", expected: "a.json:2:20"},
+		{name: "type error", data: "{\n  \"methodConfig\": {}\n}", expected: "a.json:2:20"},
+		{name: "unexpected end", data: "{", expected: "a.json:1:1"},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := json.Unmarshal([]byte(tt.data), &serviceConfigJSON{})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if actual := jsonErrorLocation("a.json", []byte(tt.data), err).String(); actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// profileKind is the kind of profile written of a plugin run, to measure the performance of resolving, validating and
+// generating service configs.
+type profileKind string
+
+const (
+	// profileOff writes no profile.
+	profileOff profileKind = ""
+	// profileCPU writes a pprof CPU profile.
+	profileCPU profileKind = "cpu"
+	// profileMem writes a pprof profile of the memory allocated during the run.
+	profileMem profileKind = "mem"
+	// profileTrace writes an execution trace, for go tool trace.
+	profileTrace profileKind = "trace"
+)
+
+// validate returns an error if the profile kind is not supported.
+func (k profileKind) validate() error {
+	switch k {
+	case profileOff, profileCPU, profileMem, profileTrace:
+		return nil
+	}
+	return fmt.Errorf("unsupported profile %q (expected %q, %q or %q)", k, profileCPU, profileMem, profileTrace)
+}
+
+// filename returns the name of the profile file, which is written to the working directory of the plugin.
+func (k profileKind) filename() string {
+	if k == profileTrace {
+		return "protoc-gen-go-grpc-service-config.trace"
+	}
+	return "protoc-gen-go-grpc-service-config." + string(k) + ".pprof"
+}
+
+// startProfile starts profiling a plugin run, and returns a function that stops profiling and writes the profile.
+func startProfile(kind profileKind) (func() error, error) {
+	if kind == profileOff {
+		return func() error { return nil }, nil
+	}
+	f, err := os.Create(kind.filename())
+	if err != nil {
+		return nil, fmt.Errorf("profile: %w", err)
+	}
+	var stop func() error
+	switch kind {
+	case profileCPU:
+		err = pprof.StartCPUProfile(f)
+		stop = func() error {
+			pprof.StopCPUProfile()
+			return nil
+		}
+	case profileMem:
+		stop = func() error {
+			// A garbage collection updates the profile with the latest allocations.
+			runtime.GC()
+			return pprof.Lookup("allocs").WriteTo(f, 0)
+		}
+	case profileTrace:
+		err = trace.Start(f)
+		stop = func() error {
+			trace.Stop()
+			return nil
+		}
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("profile: %w", err)
+	}
+	return func() error {
+		err := stop()
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("profile: %w", err)
+		}
+		return nil
+	}, nil
+}
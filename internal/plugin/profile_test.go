@@ -0,0 +1,68 @@
+package plugin
+
+import (
+	"os"
+	"testing"
+)
+
+func TestProfileKindValidate(t *testing.T) {
+	for _, kind := range []profileKind{profileOff, profileCPU, profileMem, profileTrace} {
+		if err := kind.validate(); err != nil {
+			t.Errorf("%q: expected no error, got %v", kind, err)
+		}
+	}
+	const expected = `unsupported profile "block" (expected "cpu", "mem" or "trace")`
+	if err := profileKind("block").validate(); err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
+
+func TestStartProfile(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Profiles are written to the working directory.
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	for _, tt := range []struct {
+		kind     profileKind
+		filename string
+	}{
+		{kind: profileOff},
+		{kind: profileCPU, filename: "protoc-gen-go-grpc-service-config.cpu.pprof"},
+		{kind: profileMem, filename: "protoc-gen-go-grpc-service-config.mem.pprof"},
+		{kind: profileTrace, filename: "protoc-gen-go-grpc-service-config.trace"},
+	} {
+		stop, err := startProfile(tt.kind)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.kind, err)
+		}
+		if err := stop(); err != nil {
+			t.Fatalf("%q: %v", tt.kind, err)
+		}
+		if tt.filename == "" {
+			continue
+		}
+		info, err := os.Stat(tt.filename)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.kind, err)
+		}
+		if info.Size() == 0 {
+			t.Errorf("%q: expected a non-empty profile", tt.kind)
+		}
+	}
+	files, err := os.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Errorf("expected 3 profiles, got %d", len(files))
+	}
+}
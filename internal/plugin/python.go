@@ -0,0 +1,36 @@
+package plugin
+
+import (
+	"strconv"
+	"strings"
+)
+
+// generatePythonModule generates a Python module with the service config JSON of a package, and the channel options
+// that apply it to clients. Go quoted strings are valid Python string literals.
+func (p *plugin) generatePythonModule(serviceConfig *packageServiceConfig) error {
+	formatted, err := formatServiceConfig([]byte(serviceConfig.json))
+	if err != nil {
+		return err
+	}
+	g := p.gen.NewGeneratedFile(moduleFilename(serviceConfig.pkg, ".py"), "")
+	g.P("# Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.")
+	if version := Version(); version != develVersion {
+		g.P("# versions:")
+		g.P("# \tprotoc-gen-go-grpc-service-config ", version)
+	}
+	g.P("# Source: ", serviceConfig.source)
+	g.P(`"""Service config for all services in the `, serviceConfig.pkg, ` package."""`)
+	g.P()
+	g.P("# The service config JSON for all services in the package.")
+	g.P("SERVICE_CONFIG = (")
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(string(formatted), "\n"), "\n") {
+		g.P("    ", strconv.Quote(line))
+	}
+	g.P(")")
+	g.P()
+	g.P()
+	g.P("def channel_options():")
+	g.P(`    """Returns the channel options that apply the service config to clients of services in the package."""`)
+	g.P(`    return [("grpc.service_config", SERVICE_CONFIG)]`)
+	return nil
+}
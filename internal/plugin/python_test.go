@@ -0,0 +1,50 @@
+package plugin
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGeneratePythonModule(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		testFreightServiceConfigFile: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+	})
+	p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+	if err := p.generateModules(languagePython); err != nil {
+		t.Fatal(err)
+	}
+	files := p.gen.Response().GetFile()
+	if len(files) != 1 {
+		t.Fatalf("expected 1 generated file, got %d", len(files))
+	}
+	const expectedName = "einride/example/freight/v1/freight_grpc_service_config.py"
+	if actual := files[0].GetName(); actual != expectedName {
+		t.Errorf("expected name %s, got %s", expectedName, actual)
+	}
+	expected := `# Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.
+# Source: ` + filepath.Join(dir, testFreightServiceConfigFile) + `
+"""Service config for all services in the einride.example.freight.v1 package."""
+
+# The service config JSON for all services in the package.
+SERVICE_CONFIG = (
+    "{\n"
+    "  \"methodConfig\": [\n"
+    "    {\n"
+    "      \"name\": [\n"
+    "        {}\n"
+    "      ],\n"
+    "      \"timeout\": \"10s\"\n"
+    "    }\n"
+    "  ]\n"
+    "}"
+)
+
+
+def channel_options():
+    """Returns the channel options that apply the service config to clients of services in the package."""
+    return [("grpc.service_config", SERVICE_CONFIG)]
+`
+	if actual := files[0].GetContent(); actual != expected {
+		t.Errorf("expected content:\n%s\ngot:\n%s", expected, actual)
+	}
+}
@@ -0,0 +1,198 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// reportFormat is the format of a machine-readable validation report.
+type reportFormat string
+
+const (
+	// reportFormatJSON is a JSON validation report.
+	reportFormatJSON reportFormat = "json"
+	// reportFormatSARIF is a SARIF 2.1.0 validation report.
+	reportFormatSARIF reportFormat = "sarif"
+)
+
+// validate returns an error if the report format is not supported.
+func (f reportFormat) validate() error {
+	switch f {
+	case reportFormatJSON, reportFormatSARIF:
+		return nil
+	}
+	return fmt.Errorf("unsupported report format %q (expected %q or %q)", f, reportFormatJSON, reportFormatSARIF)
+}
+
+// writeReportFile writes a validation report of the diagnostics to the file.
+// The special file name "-" writes the report to stderr.
+func writeReportFile(stderr io.Writer, file string, format reportFormat, diagnostics []diagnostic) error {
+	var report interface{}
+	switch format {
+	case reportFormatSARIF:
+		report = newSARIFReport(diagnostics)
+	default:
+		report = newJSONReport(diagnostics)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	data = append(data, '\n')
+	if file == "-" {
+		_, err = stderr.Write(data)
+	} else {
+		err = os.WriteFile(file, data, 0o600)
+	}
+	if err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	return nil
+}
+
+// jsonReport is a validation report in JSON format.
+type jsonReport struct {
+	Diagnostics []jsonReportDiagnostic `json:"diagnostics"`
+}
+
+// jsonReportDiagnostic is a diagnostic in a JSON validation report.
+type jsonReportDiagnostic struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	JSONPath string `json:"jsonPath,omitempty"`
+	Message  string `json:"message"`
+}
+
+func newJSONReport(diagnostics []diagnostic) jsonReport {
+	report := jsonReport{Diagnostics: []jsonReportDiagnostic{}}
+	for _, diagnostic := range diagnostics {
+		report.Diagnostics = append(report.Diagnostics, jsonReportDiagnostic{
+			Rule:     string(diagnostic.rule),
+			Severity: diagnostic.severity.String(),
+			File:     diagnostic.location.file,
+			Line:     diagnostic.location.line,
+			Column:   diagnostic.location.column,
+			JSONPath: diagnostic.location.jsonPath,
+			Message:  diagnostic.message,
+		})
+	}
+	return report
+}
+
+// sarifReport is a validation report in SARIF 2.1.0 format.
+// See: https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+type sarifReport struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// sarifLevel returns the SARIF level of the severity.
+func (s severity) sarifLevel() string {
+	if s == severityInfo {
+		return "note"
+	}
+	return s.String()
+}
+
+func newSARIFReport(diagnostics []diagnostic) sarifReport {
+	driver := sarifDriver{
+		Name:           "protoc-gen-go-grpc-service-config",
+		InformationURI: "https://github.com/einride/protoc-gen-go-grpc-service-config",
+	}
+	for _, rule := range rules {
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:               string(rule.rule),
+			ShortDescription: sarifMessage{Text: rule.description},
+		})
+	}
+	run := sarifRun{Tool: sarifTool{Driver: driver}, Results: []sarifResult{}}
+	for _, diagnostic := range diagnostics {
+		location := sarifLocation{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: diagnostic.location.file},
+			},
+		}
+		if diagnostic.location.line != 0 {
+			location.PhysicalLocation.Region = &sarifRegion{
+				StartLine:   diagnostic.location.line,
+				StartColumn: diagnostic.location.column,
+			}
+		}
+		if diagnostic.location.jsonPath != "" {
+			location.LogicalLocations = []sarifLogicalLocation{
+				{FullyQualifiedName: diagnostic.location.jsonPath},
+			}
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    string(diagnostic.rule),
+			Level:     diagnostic.severity.sarifLevel(),
+			Message:   sarifMessage{Text: diagnostic.message},
+			Locations: []sarifLocation{location},
+		})
+	}
+	return sarifReport{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+}
@@ -0,0 +1,194 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteReportFile(t *testing.T) {
+	diagnostics := []diagnostic{
+		{
+			rule:     ruleDanglingName,
+			severity: severityWarning,
+			location: location{file: "a.json", line: 3, column: 4, jsonPath: "methodConfig[0].name[0]"},
+			message:  "dangling",
+		},
+		{
+			rule:     ruleMissingServiceConfig,
+			severity: severityError,
+			location: location{file: "a.proto"},
+			message:  "missing",
+		},
+	}
+	for _, tt := range []struct {
+		name     string
+		format   reportFormat
+		expected string
+	}{
+		{
+			name:   "json",
+			format: reportFormatJSON,
+			expected: `{
+  "diagnostics": [
+    {
+      "rule": "DANGLING_NAME",
+      "severity": "warning",
+      "file": "a.json",
+      "line": 3,
+      "column": 4,
+      "jsonPath": "methodConfig[0].name[0]",
+      "message": "dangling"
+    },
+    {
+      "rule": "MISSING_SERVICE_CONFIG",
+      "severity": "error",
+      "file": "a.proto",
+      "message": "missing"
+    }
+  ]
+}
+`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "report")
+			if err := writeReportFile(os.Stderr, file, tt.format, diagnostics); err != nil {
+				t.Fatal(err)
+			}
+			actual, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != tt.expected {
+				t.Errorf("expected report:\n%s\ngot:\n%s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNewSARIFReport(t *testing.T) {
+	report := newSARIFReport([]diagnostic{
+		{
+			rule:     ruleDanglingName,
+			severity: severityWarning,
+			location: location{file: "a.json", line: 3, column: 4, jsonPath: "methodConfig[0].name[0]"},
+			message:  "dangling",
+		},
+		{
+			rule:     ruleMissingServiceConfig,
+			severity: severityError,
+			location: location{file: "a.proto"},
+			message:  "missing",
+		},
+		{
+			rule:     ruleDuplicateConfig,
+			severity: severityInfo,
+			location: location{file: "b.json"},
+			message:  "identical",
+		},
+	})
+	if len(report.Runs) != 1 {
+		t.Fatalf("expected 1 run, got %d", len(report.Runs))
+	}
+	driver := &report.Runs[0].Tool.Driver
+	if len(driver.Rules) != len(rules) {
+		t.Errorf("expected %d rules, got %d", len(rules), len(driver.Rules))
+	}
+	// The rules grow with every new validation rule, so only their number is checked.
+	driver.Rules = nil
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "protoc-gen-go-grpc-service-config",
+          "informationUri": "https://github.com/einride/protoc-gen-go-grpc-service-config",
+          "rules": null
+        }
+      },
+      "results": [
+        {
+          "ruleId": "DANGLING_NAME",
+          "level": "warning",
+          "message": {
+            "text": "dangling"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "a.json"
+                },
+                "region": {
+                  "startLine": 3,
+                  "startColumn": 4
+                }
+              },
+              "logicalLocations": [
+                {
+                  "fullyQualifiedName": "methodConfig[0].name[0]"
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "ruleId": "MISSING_SERVICE_CONFIG",
+          "level": "error",
+          "message": {
+            "text": "missing"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "a.proto"
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "DUPLICATE_CONFIG",
+          "level": "note",
+          "message": {
+            "text": "identical"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "b.json"
+                }
+              }
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}`
+	if string(data) != expected {
+		t.Errorf("expected report:\n%s\ngot:\n%s", expected, data)
+	}
+}
+
+func TestReportFormatValidate(t *testing.T) {
+	for _, format := range []reportFormat{reportFormatJSON, reportFormatSARIF} {
+		if err := format.validate(); err != nil {
+			t.Errorf("expected %s to be valid, got %v", format, err)
+		}
+	}
+	if err := reportFormat("xml").validate(); err == nil {
+		t.Error("expected xml to be invalid")
+	}
+}
@@ -0,0 +1,95 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// writeResolution writes where the service config of every service to generate was resolved from, and the service
+// config JSON as it is embedded in generated code. Shared service configs are only written once.
+func (p *plugin) writeResolution(w io.Writer) error {
+	firstServiceBySource := map[string]protoreflect.FullName{}
+	for _, file := range p.gen.Files {
+		if !file.Generate {
+			continue
+		}
+		for _, service := range file.Services {
+			serviceConfig, ok, err := p.resolveServiceConfig(service.Desc)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				if _, err := fmt.Fprintf(w, "%s: no service config\n\n", service.Desc.FullName()); err != nil {
+					return err
+				}
+				continue
+			}
+			if _, err := fmt.Fprintf(
+				w,
+				"%s: %s (%s)\n",
+				service.Desc.FullName(),
+				serviceConfig.source,
+				serviceConfig.kind(),
+			); err != nil {
+				return err
+			}
+			if firstService, ok := firstServiceBySource[serviceConfig.source]; ok {
+				if _, err := fmt.Fprintf(w, "same service config as %s\n\n", firstService); err != nil {
+					return err
+				}
+				continue
+			}
+			firstServiceBySource[serviceConfig.source] = service.Desc.FullName()
+			embedded, err := p.embeddedServiceConfig(serviceConfig)
+			if err != nil {
+				return fmt.Errorf("resolve %s service config: %w", service.Desc.FullName(), err)
+			}
+			var indented bytes.Buffer
+			if err := json.Indent(&indented, []byte(embedded), "", "  "); err != nil {
+				return fmt.Errorf("resolve %s service config: %w", service.Desc.FullName(), err)
+			}
+			if _, err := fmt.Fprintf(w, "%s\n\n", bytes.TrimSpace(indented.Bytes())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// kind returns a description of the kind of source the service config was resolved from.
+func (c resolvedServiceConfig) kind() string {
+	switch {
+	case c.annotation:
+		return "default_service_config annotation"
+	case c.merged:
+		return "JSON file merged with default_service_config annotation"
+	}
+	return "JSON file"
+}
+
+// embeddedServiceConfig returns the resolved service config the way it is embedded in generated code, with
+// deprecated fields fixed when the fix option is enabled, and numeric strings normalized in JSON files.
+func (p *plugin) embeddedServiceConfig(serviceConfig resolvedServiceConfig) (string, error) {
+	result := serviceConfig.json
+	if p.fix {
+		fixed, ok, err := fixDeprecatedLoadBalancingPolicy(result)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			result = fixed
+		}
+	}
+	if serviceConfig.annotation {
+		return result, nil
+	}
+	result, _, err := normalizeNumericStrings(result)
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package plugin
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteResolution(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		testFreightServiceConfigFile: `{"methodConfig": [{"name": [{}], "retryPolicy": {"maxAttempts": "3"}}]}`,
+	})
+	shipperServiceFile := `
+name: "einride/example/shipper/v1/shipper_service.proto"
+package: "einride.example.shipper.v1"
+options { go_package: "example.com/shipper/v1;shipperv1" }
+service { name: "ShipperService" }
+`
+	p := newTestPlugin(
+		t,
+		pluginOptions{path: dir, conflict: conflictPreferJSON},
+		testFile(t, testFreightServiceFile),
+		testFile(t, shipperServiceFile),
+	)
+	var output strings.Builder
+	if err := p.writeResolution(&output); err != nil {
+		t.Fatal(err)
+	}
+	expected := "einride.example.freight.v1.FreightService: " + filepath.Join(dir, testFreightServiceConfigFile) +
+		" (JSON file)\n" + `{
+  "methodConfig": [
+    {
+      "name": [
+        {}
+      ],
+      "retryPolicy": {
+        "maxAttempts": 3
+      }
+    }
+  ]
+}
+
+einride.example.shipper.v1.ShipperService: no service config
+
+`
+	if output.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+}
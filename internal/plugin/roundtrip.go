@@ -0,0 +1,194 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.buf.build/protocolbuffers/go/grpc/grpc/grpc/service_config"
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// lostValues returns every value in the service config JSON that is dropped when parsing it into the service config
+// proto and serializing it back to JSON. Unknown and misplaced fields are dropped, which means that the embedded
+// service config does not behave the way it was written.
+func lostValues(data []byte) ([]jsonFinding, error) {
+	var serviceConfig service_config.ServiceConfig
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, &serviceConfig); err != nil {
+		return nil, err
+	}
+	roundTripped, err := protojson.Marshal(&serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+	original, err := decodeJSONValue(data)
+	if err != nil {
+		return nil, err
+	}
+	result, err := decodeJSONValue(roundTripped)
+	if err != nil {
+		return nil, err
+	}
+	var findings []jsonFinding
+	compareRoundTrip(&findings, "", original, result)
+	return findings, nil
+}
+
+// decodeJSONValue decodes a JSON document, preserving numbers as json.Number.
+func decodeJSONValue(data []byte) (interface{}, error) {
+	var value interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func compareRoundTrip(findings *[]jsonFinding, path string, original, roundTripped interface{}) {
+	switch original := original.(type) {
+	case map[string]interface{}:
+		roundTrippedObject, _ := roundTripped.(map[string]interface{})
+		keys := make([]string, 0, len(original))
+		for key := range original {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			roundTrippedValue, ok := lookupJSONField(roundTrippedObject, key)
+			if !ok {
+				if !isZeroJSONValue(original[key]) {
+					*findings = append(*findings, jsonFinding{
+						path:    fieldPath,
+						message: fmt.Sprintf("field %q is dropped when parsed as a service config", key),
+					})
+				}
+				continue
+			}
+			compareRoundTrip(findings, fieldPath, original[key], roundTrippedValue)
+		}
+	case []interface{}:
+		roundTrippedArray, _ := roundTripped.([]interface{})
+		for i, item := range original {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			if i >= len(roundTrippedArray) {
+				*findings = append(*findings, jsonFinding{
+					path:    itemPath,
+					message: "value is dropped when parsed as a service config",
+				})
+				continue
+			}
+			compareRoundTrip(findings, itemPath, item, roundTrippedArray[i])
+		}
+	default:
+		if !equalJSONScalars(original, roundTripped) {
+			*findings = append(*findings, jsonFinding{
+				path:    path,
+				message: fmt.Sprintf("value %v is changed to %v when parsed as a service config", original, roundTripped),
+			})
+		}
+	}
+}
+
+// lookupJSONField looks up a field in a JSON object, by either its JSON name or its original proto name.
+func lookupJSONField(object map[string]interface{}, key string) (interface{}, bool) {
+	for _, candidate := range []string{key, snakeToLowerCamel(key), lowerCamelToSnake(key)} {
+		if value, ok := object[candidate]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// isZeroJSONValue returns true if the value is the default value of a proto field, which is omitted when serialized.
+func isZeroJSONValue(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !value
+	case string:
+		return value == ""
+	case json.Number:
+		f, err := value.Float64()
+		return err == nil && f == 0
+	case []interface{}:
+		return len(value) == 0
+	}
+	return false
+}
+
+// equalJSONScalars returns true if the scalars are semantically equal service config values, such as a number and a
+// numeric string, two representations of the same duration, or an enum number and an enum name.
+func equalJSONScalars(a, b interface{}) bool {
+	if a == b {
+		return true
+	}
+	aString, aIsString := jsonScalarString(a)
+	bString, bIsString := jsonScalarString(b)
+	if !aIsString || !bIsString {
+		return false
+	}
+	if aNumber, err := json.Number(aString).Float64(); err == nil {
+		if bNumber, err := json.Number(bString).Float64(); err == nil {
+			return aNumber == bNumber
+		}
+		// Enum numbers are serialized as enum names.
+		_, bIsName := b.(string)
+		return bIsName
+	}
+	if aDuration, err := configjson.ParseDuration(aString); err == nil {
+		if bDuration, err := configjson.ParseDuration(bString); err == nil {
+			return aDuration == bDuration
+		}
+	}
+	return false
+}
+
+func jsonScalarString(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case json.Number:
+		return value.String(), true
+	}
+	return "", false
+}
+
+func snakeToLowerCamel(s string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range s {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func lowerCamelToSnake(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+			b.WriteRune(r - 'A' + 'a')
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareRoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		original     string
+		roundTripped string
+		findings     []string
+	}{
+		{
+			name:         "lossless",
+			original:     `{"methodConfig": [{"name": [{"service": "a.B"}], "timeout": "1.000s"}]}`,
+			roundTripped: `{"methodConfig": [{"name": [{"service": "a.B"}], "timeout": "1s"}]}`,
+		},
+		{
+			name:         "original proto field names",
+			original:     `{"method_config": [{"wait_for_ready": true}]}`,
+			roundTripped: `{"methodConfig": [{"waitForReady": true}]}`,
+		},
+		{
+			name:         "numbers, numeric strings and enum numbers",
+			original:     `{"maxTokens": "10", "tokenRatio": 0.5, "codes": [14]}`,
+			roundTripped: `{"maxTokens": 10, "tokenRatio": 0.5, "codes": ["UNAVAILABLE"]}`,
+		},
+		{
+			name:         "default values are omitted",
+			original:     `{"waitForReady": false, "timeout": "", "name": [], "maxTokens": 0, "policy": null}`,
+			roundTripped: `{}`,
+		},
+		{
+			name:         "dropped fields",
+			original:     `{"methodConfig": [{"timeout": "1s", "retryPolicy": {"maxAttempts": 3}, "timout": "2s"}]}`,
+			roundTripped: `{"methodConfig": [{"timeout": "1s"}]}`,
+			findings: []string{
+				`methodConfig[0].retryPolicy: field "retryPolicy" is dropped when parsed as a service config`,
+				`methodConfig[0].timout: field "timout" is dropped when parsed as a service config`,
+			},
+		},
+		{
+			name:         "dropped and changed values",
+			original:     `{"name": [{"service": "a.B"}, {"service": "a.C"}], "timeout": "1s", "waitForReady": true}`,
+			roundTripped: `{"name": [{"service": "a.B"}], "timeout": "2s", "waitForReady": "yes"}`,
+			findings: []string{
+				"name[1]: value is dropped when parsed as a service config",
+				"timeout: value 1s is changed to 2s when parsed as a service config",
+				"waitForReady: value true is changed to yes when parsed as a service config",
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			original, err := decodeJSONValue([]byte(tt.original))
+			if err != nil {
+				t.Fatal(err)
+			}
+			roundTripped, err := decodeJSONValue([]byte(tt.roundTripped))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var findings []jsonFinding
+			compareRoundTrip(&findings, "", original, roundTripped)
+			if actual := jsonFindingStrings(findings); actual != strings.Join(tt.findings, "\n") {
+				t.Errorf("expected findings:\n%s\ngot:\n%s", strings.Join(tt.findings, "\n"), actual)
+			}
+		})
+	}
+}
+
+func TestJSONFieldNames(t *testing.T) {
+	for _, tt := range []struct {
+		snake string
+		camel string
+	}{
+		{snake: "timeout", camel: "timeout"},
+		{snake: "wait_for_ready", camel: "waitForReady"},
+		{snake: "max_request_message_bytes", camel: "maxRequestMessageBytes"},
+	} {
+		if actual := snakeToLowerCamel(tt.snake); actual != tt.camel {
+			t.Errorf("expected %q to be %q in lower camel case, got %q", tt.snake, tt.camel, actual)
+		}
+		if actual := lowerCamelToSnake(tt.camel); actual != tt.snake {
+			t.Errorf("expected %q to be %q in snake case, got %q", tt.camel, tt.snake, actual)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// scaffoldRetryPolicy is the retry policy of scaffolded method configs for methods free of side effects.
+// It retries transient unavailability a few times with exponential backoff, which is a safe starting point.
+var scaffoldRetryPolicy = map[string]interface{}{
+	"maxAttempts":          4,
+	"initialBackoff":       "0.1s",
+	"maxBackoff":           "1s",
+	"backoffMultiplier":    2,
+	"retryableStatusCodes": []string{"UNAVAILABLE"},
+}
+
+// scaffoldServiceConfig returns a starter service config for the services in a package, with timeouts, and retry
+// policies for unary methods free of side effects.
+func scaffoldServiceConfig(
+	files *protoregistry.Files,
+	packageName protoreflect.FullName,
+	timeout time.Duration,
+) ([]byte, error) {
+	var methodConfigs []interface{}
+	files.RangeFilesByPackage(packageName, func(file protoreflect.FileDescriptor) bool {
+		for i := 0; i < file.Services().Len(); i++ {
+			service := file.Services().Get(i)
+			methodConfigs = append(methodConfigs, map[string]interface{}{
+				"name":    []interface{}{map[string]interface{}{"service": string(service.FullName())}},
+				"timeout": configjson.FormatDuration(timeout),
+			})
+			var retryableNames []interface{}
+			for j := 0; j < service.Methods().Len(); j++ {
+				method := service.Methods().Get(j)
+				if method.IsStreamingClient() || method.IsStreamingServer() {
+					continue
+				}
+				if sideEffects, _ := mayHaveSideEffects(method); sideEffects {
+					continue
+				}
+				retryableNames = append(retryableNames, map[string]interface{}{
+					"service": string(service.FullName()),
+					"method":  string(method.Name()),
+				})
+			}
+			if len(retryableNames) > 0 {
+				methodConfigs = append(methodConfigs, map[string]interface{}{
+					"name":        retryableNames,
+					"timeout":     configjson.FormatDuration(timeout),
+					"retryPolicy": scaffoldRetryPolicy,
+				})
+			}
+		}
+		return true
+	})
+	if len(methodConfigs) == 0 {
+		return nil, fmt.Errorf("no services found in package %s", packageName)
+	}
+	data, err := json.Marshal(map[string]interface{}{"methodConfig": methodConfigs})
+	if err != nil {
+		return nil, err
+	}
+	return formatServiceConfig(data)
+}
@@ -0,0 +1,60 @@
+package plugin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScaffoldServiceConfig(t *testing.T) {
+	p := newTestPlugin(t, pluginOptions{conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+	actual, err := scaffoldServiceConfig(p.registry(), "einride.example.freight.v1", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected = `{
+  "methodConfig": [
+    {
+      "name": [
+        {
+          "service": "einride.example.freight.v1.FreightService"
+        }
+      ],
+      "timeout": "5s"
+    },
+    {
+      "name": [
+        {
+          "method": "GetShipper",
+          "service": "einride.example.freight.v1.FreightService"
+        },
+        {
+          "method": "UpdateShipper",
+          "service": "einride.example.freight.v1.FreightService"
+        },
+        {
+          "method": "ListShippers",
+          "service": "einride.example.freight.v1.FreightService"
+        }
+      ],
+      "retryPolicy": {
+        "backoffMultiplier": 2,
+        "initialBackoff": "0.1s",
+        "maxAttempts": 4,
+        "maxBackoff": "1s",
+        "retryableStatusCodes": [
+          "UNAVAILABLE"
+        ]
+      },
+      "timeout": "5s"
+    }
+  ]
+}
+`
+	if string(actual) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+	if _, err := scaffoldServiceConfig(p.registry(), "einride.example.shipper.v1", time.Second); err == nil ||
+		err.Error() != "no services found in package einride.example.shipper.v1" {
+		t.Errorf("expected an error for a package without services, got %v", err)
+	}
+}
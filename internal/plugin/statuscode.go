@@ -0,0 +1,55 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+)
+
+// statusCodeJSON is a retryable or non-fatal status code in a service config.
+type statusCodeJSON struct {
+	// path is the JSON path of the status code.
+	path string
+	// value is the status code name or number.
+	value json.RawMessage
+}
+
+// statusCodes returns every retryable and non-fatal status code in the service config.
+func (c serviceConfigJSON) statusCodes() []statusCodeJSON {
+	var result []statusCodeJSON
+	for i, methodConfig := range c.MethodConfigs {
+		if methodConfig.RetryPolicy != nil {
+			for j, value := range methodConfig.RetryPolicy.RetryableStatusCodes {
+				result = append(result, statusCodeJSON{
+					path:  fmt.Sprintf("methodConfig[%d].retryPolicy.retryableStatusCodes[%d]", i, j),
+					value: value,
+				})
+			}
+		}
+		if methodConfig.HedgingPolicy != nil {
+			for j, value := range methodConfig.HedgingPolicy.NonFatalStatusCodes {
+				result = append(result, statusCodeJSON{
+					path:  fmt.Sprintf("methodConfig[%d].hedgingPolicy.nonFatalStatusCodes[%d]", i, j),
+					value: value,
+				})
+			}
+		}
+	}
+	return result
+}
+
+// parse parses the status code name or number, with the same rules as gRPC.
+func (s statusCodeJSON) parse() (codes.Code, error) {
+	var code codes.Code
+	if err := code.UnmarshalJSON(s.value); err != nil {
+		return 0, err
+	}
+	return code, nil
+}
+
+// isUnsafeToRetry returns true if the status code may be returned after a server has processed a request, and is
+// therefore unsafe to retry without knowing that the method is idempotent.
+func isUnsafeToRetry(code codes.Code) bool {
+	return code == codes.Internal || code == codes.Unknown
+}
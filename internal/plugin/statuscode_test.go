@@ -0,0 +1,46 @@
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestStatusCodes(t *testing.T) {
+	var serviceConfig serviceConfigJSON
+	if err := json.Unmarshal([]byte(`{"methodConfig": [
+  {"retryPolicy": {"retryableStatusCodes": ["UNAVAILABLE", 4]}},
+  {"hedgingPolicy": {"nonFatalStatusCodes": ["internal", "UNAVAILBLE"]}}
+]}`), &serviceConfig); err != nil {
+		t.Fatal(err)
+	}
+	for i, tt := range []struct {
+		path string
+		code codes.Code
+		err  bool
+	}{
+		{path: "methodConfig[0].retryPolicy.retryableStatusCodes[0]", code: codes.Unavailable},
+		{path: "methodConfig[0].retryPolicy.retryableStatusCodes[1]", code: codes.DeadlineExceeded},
+		{path: "methodConfig[1].hedgingPolicy.nonFatalStatusCodes[0]", err: true},
+		{path: "methodConfig[1].hedgingPolicy.nonFatalStatusCodes[1]", err: true},
+	} {
+		statusCodes := serviceConfig.statusCodes()
+		if len(statusCodes) != 4 {
+			t.Fatalf("expected 4 status codes, got %d", len(statusCodes))
+		}
+		if statusCodes[i].path != tt.path {
+			t.Errorf("expected path %s, got %s", tt.path, statusCodes[i].path)
+		}
+		code, err := statusCodes[i].parse()
+		if tt.err {
+			if err == nil {
+				t.Errorf("expected %s to be invalid, got %v", statusCodes[i].value, code)
+			}
+			continue
+		}
+		if err != nil || code != tt.code {
+			t.Errorf("expected %s to be %v, got %v, %v", statusCodes[i].value, tt.code, code, err)
+		}
+	}
+}
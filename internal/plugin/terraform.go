@@ -0,0 +1,49 @@
+package plugin
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// terraformLocalName is the name of the Terraform local with the service configs in generated Terraform files.
+const terraformLocalName = "grpc_service_configs"
+
+// hclTemplateEscape escapes the template sequences of HCL strings and heredocs, so that they are used literally.
+func hclTemplateEscape(s string) string {
+	return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(s)
+}
+
+// generateTerraformLocals writes the resolved service config of every package to a Terraform file in the directory,
+// as <dir>/grpc_service_configs.tf, with a grpc_service_configs local mapping package names to service config JSON,
+// for infrastructure that provisions DNS TXT records or config stores with the service configs.
+func (p *plugin) generateTerraformLocals(dir string) error {
+	serviceConfigs, err := p.packageServiceConfigs()
+	if err != nil {
+		return err
+	}
+	if len(serviceConfigs) == 0 {
+		return nil
+	}
+	g := p.gen.NewGeneratedFile(path.Join(dir, terraformLocalName+".tf"), "")
+	g.P("# Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.")
+	g.P()
+	g.P("locals {")
+	g.P("  ", terraformLocalName, " = {")
+	for _, serviceConfig := range serviceConfigs {
+		formatted, err := formatServiceConfig([]byte(serviceConfig.json))
+		if err != nil {
+			return fmt.Errorf("emit %s Terraform local: %w", serviceConfig.pkg, err)
+		}
+		g.P("    # Source: ", serviceConfig.source)
+		g.P("    ", strconv.Quote(string(serviceConfig.pkg)), " = <<-EOT")
+		for _, line := range strings.Split(strings.TrimSuffix(string(formatted), "\n"), "\n") {
+			g.P("      ", hclTemplateEscape(line))
+		}
+		g.P("    EOT")
+	}
+	g.P("  }")
+	g.P("}")
+	return nil
+}
@@ -0,0 +1,75 @@
+package plugin
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestHCLTemplateEscape(t *testing.T) {
+	for _, tt := range []struct {
+		s        string
+		expected string
+	}{
+		{s: `{"timeout": "10s"}`, expected: `{"timeout": "10s"}`},
+		{s: `"${var}"`, expected: `"$${var}"`},
+		{s: `"%{if}"`, expected: `"%%{if}"`},
+		{s: `"$ and %"`, expected: `"$ and %"`},
+	} {
+		if actual := hclTemplateEscape(tt.s); actual != tt.expected {
+			t.Errorf("hclTemplateEscape(%q): expected %q, got %q", tt.s, tt.expected, actual)
+		}
+	}
+}
+
+func TestGenerateTerraformLocals(t *testing.T) {
+	t.Run("locals", func(t *testing.T) {
+		dir := writeTestFiles(t, map[string]string{
+			testFreightServiceConfigFile: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+		})
+		p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+		if err := p.generateTerraformLocals("terraform"); err != nil {
+			t.Fatal(err)
+		}
+		files := p.gen.Response().GetFile()
+		if len(files) != 1 {
+			t.Fatalf("expected 1 generated file, got %d", len(files))
+		}
+		const expectedName = "terraform/grpc_service_configs.tf"
+		if actual := files[0].GetName(); actual != expectedName {
+			t.Errorf("expected name %s, got %s", expectedName, actual)
+		}
+		expected := `# Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.
+
+locals {
+  grpc_service_configs = {
+    # Source: ` + filepath.Join(dir, testFreightServiceConfigFile) + `
+    "einride.example.freight.v1" = <<-EOT
+      {
+        "methodConfig": [
+          {
+            "name": [
+              {}
+            ],
+            "timeout": "10s"
+          }
+        ]
+      }
+    EOT
+  }
+}
+`
+		if actual := files[0].GetContent(); actual != expected {
+			t.Errorf("expected content:\n%s\ngot:\n%s", expected, actual)
+		}
+	})
+	t.Run("no service configs", func(t *testing.T) {
+		opts := pluginOptions{path: t.TempDir(), conflict: conflictPreferJSON}
+		p := newTestPlugin(t, opts, testFile(t, testFreightServiceFile))
+		if err := p.generateTerraformLocals("terraform"); err != nil {
+			t.Fatal(err)
+		}
+		if files := p.gen.Response().GetFile(); len(files) != 0 {
+			t.Errorf("expected no generated files, got %d", len(files))
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package plugin
+
+import "strings"
+
+// generateTypeScriptModule generates a TypeScript module with the service config of a package, typed against the
+// ServiceConfig interface of @grpc/grpc-js, and the channel options that apply it to clients.
+func (p *plugin) generateTypeScriptModule(serviceConfig *packageServiceConfig) error {
+	formatted, err := formatServiceConfig([]byte(serviceConfig.json))
+	if err != nil {
+		return err
+	}
+	g := p.gen.NewGeneratedFile(moduleFilename(serviceConfig.pkg, ".ts"), "")
+	g.P("// Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.")
+	if version := Version(); version != develVersion {
+		g.P("// versions:")
+		g.P("// \tprotoc-gen-go-grpc-service-config ", version)
+	}
+	g.P("// Source: ", serviceConfig.source)
+	g.P()
+	g.P(`import type { ServiceConfig } from "@grpc/grpc-js/build/src/service-config";`)
+	g.P()
+	g.P("/** The service config for all services in the package. */")
+	g.P("export const serviceConfig = ", strings.TrimSuffix(string(formatted), "\n"), " as ServiceConfig;")
+	g.P()
+	g.P("/** The service config JSON for all services in the package. */")
+	g.P("export const serviceConfigJSON: string = JSON.stringify(serviceConfig);")
+	g.P()
+	g.P("/** The channel options that apply the service config to clients of services in the package. */")
+	g.P(`export const channelOptions = { "grpc.service_config": serviceConfigJSON };`)
+	return nil
+}
@@ -0,0 +1,62 @@
+package plugin
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLanguageValidate(t *testing.T) {
+	for _, lang := range []language{languageGo, languageTypeScript, languagePython, languageCSharp} {
+		if err := lang.validate(); err != nil {
+			t.Errorf("%s: %v", lang, err)
+		}
+	}
+	const expected = `unsupported lang "java" (expected "go", "ts", "python" or "csharp")`
+	if err := language("java").validate(); err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
+
+func TestGenerateTypeScriptModule(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		testFreightServiceConfigFile: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+	})
+	p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+	if err := p.generateModules(languageTypeScript); err != nil {
+		t.Fatal(err)
+	}
+	files := p.gen.Response().GetFile()
+	if len(files) != 1 {
+		t.Fatalf("expected 1 generated file, got %d", len(files))
+	}
+	const expectedName = "einride/example/freight/v1/freight_grpc_service_config.ts"
+	if actual := files[0].GetName(); actual != expectedName {
+		t.Errorf("expected name %s, got %s", expectedName, actual)
+	}
+	expected := `// Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.
+// Source: ` + filepath.Join(dir, testFreightServiceConfigFile) + `
+
+import type { ServiceConfig } from "@grpc/grpc-js/build/src/service-config";
+
+/** The service config for all services in the package. */
+export const serviceConfig = {
+  "methodConfig": [
+    {
+      "name": [
+        {}
+      ],
+      "timeout": "10s"
+    }
+  ]
+} as ServiceConfig;
+
+/** The service config JSON for all services in the package. */
+export const serviceConfigJSON: string = JSON.stringify(serviceConfig);
+
+/** The channel options that apply the service config to clients of services in the package. */
+export const channelOptions = { "grpc.service_config": serviceConfigJSON };
+`
+	if actual := files[0].GetContent(); actual != expected {
+		t.Errorf("expected content:\n%s\ngot:\n%s", expected, actual)
+	}
+}
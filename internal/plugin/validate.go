@@ -0,0 +1,813 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// validateOptions configures service config validation.
+type validateOptions struct {
+	// required requires every service to have a service config.
+	required bool
+	// strict treats validation warnings as errors.
+	strict bool
+	// reportFile is the path of a file to write a machine-readable validation report to, or empty for no report.
+	reportFile string
+	// reportFormat is the format of the validation report.
+	reportFormat reportFormat
+	// errorFormat is the format diagnostics are written to stderr in.
+	errorFormat errorFormat
+	// stderr is where diagnostics, and reports to "-", are written, or nil for os.Stderr.
+	stderr io.Writer
+	// targetGRPCGoVersion is the oldest grpc-go release service configs must be compatible with, or nil.
+	targetGRPCGoVersion *grpcGoVersion
+	// requireLossless requires service config JSON files to survive a round trip through the service config proto.
+	requireLossless bool
+	// maxConfigBytes is the maximum size of a compacted service config, or 0 for no limit.
+	maxConfigBytes int
+	// breaking configures breaking change detection.
+	breaking breakingOptions
+	// lint configures the opt-in lint rules.
+	lint lintOptions
+	// jobs is the maximum number of service configs validated concurrently, or 0 for GOMAXPROCS.
+	jobs int
+	// failFast stops validation at the first service with a problem that fails validation.
+	failFast bool
+}
+
+// dnsTXTRecordMaxBytes is the maximum size of a DNS TXT record.
+// Service configs distributed via DNS must fit in a TXT record.
+const dnsTXTRecordMaxBytes = 65535
+
+// maxAttemptsLimit is the limit of retry and hedging attempts in gRPC.
+const maxAttemptsLimit = configjson.MaxAttemptsLimit
+
+// exceedsMaxAttemptsLimit returns true if maxAttempts is an integer larger than the gRPC limit. Values that are not
+// integers are reported by validation against grpc-go.
+func exceedsMaxAttemptsLimit(maxAttempts json.Number) bool {
+	n, err := maxAttempts.Int64()
+	return err == nil && n > maxAttemptsLimit
+}
+
+// check returns an error if the options are invalid.
+func (o validateOptions) check() error {
+	if err := o.reportFormat.validate(); err != nil {
+		return err
+	}
+	if err := o.errorFormat.validate(); err != nil {
+		return err
+	}
+	if o.maxConfigBytes < 0 {
+		return fmt.Errorf("max_config_bytes must not be negative: %d", o.maxConfigBytes)
+	}
+	if o.jobs < 0 {
+		return fmt.Errorf("jobs must not be negative: %d", o.jobs)
+	}
+	if err := o.lint.validate(); err != nil {
+		return err
+	}
+	return o.breaking.validate()
+}
+
+// validate validates the service configs of all services to generate, and reports every problem found.
+func (p *plugin) validate(opts validateOptions) error {
+	if err := opts.check(); err != nil {
+		return err
+	}
+	start := time.Now()
+	diagnostics, err := p.validationDiagnostics(opts)
+	if err != nil {
+		return err
+	}
+	p.log.timed("validate service configs", start, "diagnostics", len(diagnostics.list))
+	return finishValidation(diagnostics, opts)
+}
+
+// validationDiagnostics returns every problem found in the service configs of all services to generate, except
+// problems ignored by the lint configuration file.
+func (p *plugin) validationDiagnostics(opts validateOptions) (*diagnostics, error) {
+	if err := opts.lint.validate(); err != nil {
+		return nil, err
+	}
+	if err := opts.breaking.validate(); err != nil {
+		return nil, err
+	}
+	session, err := startValidationSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.close()
+	var diagnostics diagnostics
+	validatedSources := map[string]struct{}{}
+	// Services are resolved sequentially, and their service configs are validated concurrently by tasks.
+	var tasks []*validationTask
+	// scopes are the service configs and the services they apply to, in the order they were resolved.
+	var scopes []*serviceConfigScope
+	scopesBySource := map[string]*serviceConfigScope{}
+	for _, file := range p.gen.Files {
+		if !file.Generate {
+			continue
+		}
+		for _, service := range file.Services {
+			file, service := file, service
+			task := &validationTask{descriptor: service.Desc}
+			tasks = append(tasks, task)
+			exempt, exemptReason, err := p.serviceExemption(service.Desc)
+			if err != nil {
+				return nil, err
+			}
+			if exempt && strings.TrimSpace(exemptReason) == "" {
+				task.diagnostics.errorf(
+					ruleExemptionReason,
+					location{file: file.Desc.Path()},
+					"service %s is exempt from requiring a service config, but has no %s",
+					service.Desc.FullName(),
+					serviceExemptReasonExtension,
+				)
+			}
+			serviceConfig, ok, err := p.resolveServiceConfig(service.Desc)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				if opts.required && !exempt {
+					task.diagnostics.errorf(
+						ruleMissingServiceConfig,
+						location{file: file.Desc.Path()},
+						"missing service config for %s (see: %s)",
+						service.Desc.FullName(),
+						docURL,
+					)
+				}
+				continue
+			}
+			var serviceConfigContent serviceConfigJSON
+			if err := json.Unmarshal([]byte(serviceConfig.json), &serviceConfigContent); err != nil {
+				if _, ok := validatedSources[serviceConfig.source]; !ok {
+					validatedSources[serviceConfig.source] = struct{}{}
+					task.diagnostics.errorf(
+						ruleInvalidJSON,
+						jsonErrorLocation(serviceConfig.source, []byte(serviceConfig.json), err),
+						"invalid service config: %s",
+						describeJSONError([]byte(serviceConfig.json), err),
+					)
+				}
+				continue
+			}
+			if !serviceConfig.annotation && !serviceConfig.merged {
+				// Positions are only used to improve diagnostics, so failing to parse them is not an error.
+				serviceConfig.positions, _ = parseJSONPositions(serviceConfig.source, []byte(serviceConfig.json))
+			}
+			_, validated := validatedSources[serviceConfig.source]
+			validatedSources[serviceConfig.source] = struct{}{}
+			scope, ok := scopesBySource[serviceConfig.source]
+			if !ok {
+				scope = &serviceConfigScope{serviceConfig: serviceConfig, serviceConfigContent: serviceConfigContent}
+				scopesBySource[serviceConfig.source] = scope
+				scopes = append(scopes, scope)
+			}
+			scope.services = append(scope.services, service)
+			exemptions, err := p.methodLintExemptions(service)
+			if err != nil {
+				return nil, err
+			}
+			var baseline *resolvedServiceConfig
+			if opts.breaking.baseline != "" {
+				baselineServiceConfig, ok, err := p.resolveBaselineServiceConfig(opts.breaking.baseline, file, service)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					baseline = &baselineServiceConfig
+				}
+			}
+			task.run = func() error {
+				if !validated {
+					start := time.Now()
+					if err := p.validateServiceConfig(
+						&task.diagnostics,
+						session,
+						opts,
+						serviceConfig,
+						serviceConfigContent,
+					); err != nil {
+						return err
+					}
+					lintServiceConfig(&task.diagnostics, opts.lint, file.Desc.Package(), serviceConfig, serviceConfigContent)
+					p.log.timed("validate service config", start, "source", serviceConfig.source)
+				}
+				if opts.required && !exempt && !serviceConfigContent.hasService(service) {
+					task.diagnostics.errorf(
+						ruleMissingServiceConfig,
+						serviceConfig.locate(""),
+						"missing service config for %s (see: %s)",
+						service.Desc.FullName(),
+						docURL,
+					)
+				}
+				lintService(&task.diagnostics, opts.lint, service, serviceConfig, serviceConfigContent, exemptions)
+				if baseline != nil {
+					checkBreakingChanges(&task.diagnostics, opts.breaking, service, *baseline, serviceConfig, serviceConfigContent)
+				}
+				return nil
+			}
+		}
+	}
+	failedFast, err := p.runValidationTasks(&diagnostics, tasks, opts)
+	if err != nil {
+		return nil, err
+	}
+	// Checks across services are skipped when validation failed fast, since not every service was validated.
+	if !failedFast {
+		for _, scope := range scopes {
+			start := len(diagnostics.list)
+			for _, shadowed := range scope.serviceConfigContent.shadowedMethodConfigs(scope.services) {
+				diagnostics.warnf(
+					ruleShadowedMethodConfig,
+					scope.serviceConfig.locate(shadowed.path),
+					"%s: %s",
+					shadowed.path,
+					shadowed.message,
+				)
+			}
+			diagnostics.describe(start, scope.services[0].Desc)
+		}
+		firstSourceByContent := map[string]string{}
+		for _, scope := range scopes {
+			firstSource, ok := firstSourceByContent[scope.serviceConfig.json]
+			if !ok {
+				firstSourceByContent[scope.serviceConfig.json] = scope.serviceConfig.source
+				continue
+			}
+			diagnostics.infof(
+				ruleDuplicateConfig,
+				scope.serviceConfig.locate(""),
+				"service config is identical to the service config in %s, consider consolidating them",
+				firstSource,
+			)
+			diagnostics.describe(len(diagnostics.list)-1, scope.services[0].Desc)
+		}
+	}
+	if opts.lint.config != nil {
+		diagnostics.list = opts.lint.config.unignored(diagnostics.list, p.path)
+	}
+	return &diagnostics, nil
+}
+
+// finishValidation writes the validation report, if any, and reports the diagnostics.
+func finishValidation(diagnostics *diagnostics, opts validateOptions) error {
+	result := diagnostics.effective(opts.strict)
+	stderr := opts.stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	if opts.reportFile != "" {
+		if err := writeReportFile(stderr, opts.reportFile, opts.reportFormat, result); err != nil {
+			return err
+		}
+	}
+	return writeDiagnostics(stderr, opts.errorFormat, result)
+}
+
+// validationTask is the validation of a service, which runs concurrently with the validation of other services.
+type validationTask struct {
+	// diagnostics are the problems found by the task, reported in the order of the tasks.
+	diagnostics diagnostics
+	// descriptor is the proto descriptor the diagnostics of the task apply to, unless they have one of their own.
+	descriptor protoreflect.Descriptor
+	// run validates the service and adds the problems found to the diagnostics of the task, or is nil when there is
+	// nothing to validate concurrently.
+	run func() error
+}
+
+// runValidationTasks runs the tasks with at most jobs running concurrently, where 0 is GOMAXPROCS. The diagnostics
+// of the tasks are added in the order of the tasks, regardless of the order they finish in, so reports are
+// deterministic. When tasks fail, the error of the first failed task is returned.
+//
+// With fail fast, no more tasks are started once a task finds a problem that fails validation, and only the
+// diagnostics of the tasks up to the first task with such a problem are added. Tasks are started in order, so every
+// task before it has run, and the diagnostics are the same regardless of the number of jobs. Returns true when
+// validation failed fast.
+func (p *plugin) runValidationTasks(
+	diagnostics *diagnostics,
+	tasks []*validationTask,
+	opts validateOptions,
+) (bool, error) {
+	jobs := opts.jobs
+	if jobs < 0 {
+		return false, fmt.Errorf("jobs must not be negative: %d", jobs)
+	}
+	if jobs == 0 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+	if jobs > len(tasks) {
+		jobs = len(tasks)
+	}
+	// The JSON Schema is built lazily, so it is built before tasks read it concurrently.
+	p.serviceConfigSchema()
+	errs := make([]error, len(tasks))
+	indexes := make(chan int)
+	// failed is set to 1 when a task fails validation with fail fast.
+	var failed int32
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				task := tasks[index]
+				if task.run != nil {
+					errs[index] = task.run()
+				}
+				if opts.failFast && (errs[index] != nil || p.failsValidation(task.diagnostics.list, opts)) {
+					atomic.StoreInt32(&failed, 1)
+				}
+			}
+		}()
+	}
+	for i := range tasks {
+		if atomic.LoadInt32(&failed) != 0 {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	for i, task := range tasks {
+		if errs[i] != nil {
+			return false, errs[i]
+		}
+		start := len(diagnostics.list)
+		diagnostics.list = append(diagnostics.list, task.diagnostics.list...)
+		diagnostics.describe(start, task.descriptor)
+		if opts.failFast && p.failsValidation(task.diagnostics.list, opts) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// failsValidation returns true if any of the diagnostics fails validation: an error, or a warning in strict mode,
+// that is not ignored by the lint configuration file.
+func (p *plugin) failsValidation(diagnostics []diagnostic, opts validateOptions) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.severity != severityError && (!opts.strict || diagnostic.severity != severityWarning) {
+			continue
+		}
+		if opts.lint.config == nil || !opts.lint.config.ignores(diagnostic, p.path) {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceConfigScope is a service config and the services it applies to.
+type serviceConfigScope struct {
+	serviceConfig        resolvedServiceConfig
+	serviceConfigContent serviceConfigJSON
+	services             []*protogen.Service
+}
+
+// validateServiceConfig validates the content of a single service config, independent of the services it applies to.
+func (p *plugin) validateServiceConfig(
+	diagnostics *diagnostics,
+	session *validationSession,
+	opts validateOptions,
+	serviceConfig resolvedServiceConfig,
+	serviceConfigContent serviceConfigJSON,
+) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(serviceConfig.json), &fields); err == nil && len(fields) == 0 {
+		diagnostics.warnf(
+			ruleNoOpConfig,
+			serviceConfig.locate(""),
+			"service config is empty, so no timeouts, retries or other policies are configured",
+		)
+	}
+	if serviceConfigContent.MethodConfigs != nil && len(serviceConfigContent.MethodConfigs) == 0 {
+		diagnostics.warnf(
+			ruleNoOpConfig,
+			serviceConfig.locate("methodConfig"),
+			"methodConfig is empty, so no timeouts, retries or other method policies are configured",
+		)
+	}
+	for i, methodConfig := range serviceConfigContent.MethodConfigs {
+		if methodConfig.isNoOp() {
+			path := fmt.Sprintf("methodConfig[%d]", i)
+			diagnostics.warnf(
+				ruleNoOpConfig,
+				serviceConfig.locate(path),
+				"%s has no timeout, retry or other policy, so it only overrides more general method configs",
+				path,
+			)
+		}
+	}
+	for _, duplicate := range serviceConfigContent.duplicateNames() {
+		diagnostics.errorf(
+			ruleDuplicateName,
+			serviceConfig.locate(duplicate.path),
+			"duplicate name %s in %s, already matched by %s",
+			duplicate.name,
+			duplicate.path,
+			serviceConfig.describe(duplicate.firstPath),
+		)
+	}
+	if !serviceConfig.annotation {
+		lostPaths := map[string]struct{}{}
+		if opts.requireLossless {
+			lost, err := lostValues([]byte(serviceConfig.json))
+			if err != nil {
+				diagnostics.errorf(ruleLossless, serviceConfig.locate(""), "invalid service config: %v", err)
+			}
+			for _, value := range lost {
+				lostPaths[value.path] = struct{}{}
+				diagnostics.errorf(
+					ruleLossless,
+					serviceConfig.locate(value.path),
+					"%s: %s",
+					value.path,
+					value.message,
+				)
+			}
+		}
+		unknownFields, violations, err := validateJSONSchema(p.serviceConfigSchema(), []byte(serviceConfig.json))
+		if err != nil {
+			return err
+		}
+		for _, unknownField := range unknownFields {
+			if _, ok := lostPaths[unknownField.path]; ok {
+				continue
+			}
+			diagnostics.warnf(
+				ruleUnknownField,
+				serviceConfig.locate(unknownField.path),
+				"%s: %s",
+				unknownField.path,
+				unknownField.message,
+			)
+		}
+		for _, violation := range violations {
+			diagnostics.warnf(
+				ruleJSONSchema,
+				serviceConfig.locate(violation.path),
+				"%s: %s",
+				violation.path,
+				violation.message,
+			)
+		}
+	}
+	if opts.maxConfigBytes > 0 {
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, []byte(serviceConfig.json)); err != nil {
+			return err
+		}
+		if compacted.Len() > opts.maxConfigBytes {
+			diagnostics.errorf(
+				ruleConfigSize,
+				serviceConfig.locate(""),
+				"service config is %d bytes when compacted, which exceeds the budget of %d bytes",
+				compacted.Len(),
+				opts.maxConfigBytes,
+			)
+		}
+	}
+	if opts.targetGRPCGoVersion != nil {
+		for _, use := range serviceConfigContent.grpcGoFeatureUses() {
+			if use.capability.supportedBy(*opts.targetGRPCGoVersion) {
+				continue
+			}
+			if use.capability.since == nil {
+				diagnostics.errorf(
+					ruleGRPCGoCompatibility,
+					serviceConfig.locate(use.path),
+					"%s: %s is not supported by grpc-go",
+					use.path,
+					use.capability.feature,
+				)
+				continue
+			}
+			diagnostics.errorf(
+				ruleGRPCGoCompatibility,
+				serviceConfig.locate(use.path),
+				"%s: %s requires grpc-go %s or later, but the target is %s",
+				use.path,
+				use.capability.feature,
+				use.capability.since,
+				opts.targetGRPCGoVersion,
+			)
+		}
+	}
+	if policy := serviceConfigContent.LoadBalancingPolicy; policy != nil && *policy != "" && !p.fix {
+		diagnostics.errorf(
+			ruleDeprecatedLoadBalancingPolicy,
+			serviceConfig.locate("loadBalancingPolicy"),
+			"loadBalancingPolicy is deprecated: replace it with \"loadBalancingConfig\": %s, "+
+				"or enable the fix option to rewrite it in generated code",
+			loadBalancingPolicyReplacement(*policy),
+		)
+	}
+	normalized, numericStrings, err := normalizeNumericStrings(serviceConfig.json)
+	if err != nil {
+		return err
+	}
+	for _, numericString := range numericStrings {
+		diagnostics.warnf(
+			ruleNumericRepresentation,
+			serviceConfig.locate(numericString.path),
+			"%s: %s (normalized in generated code)",
+			numericString.path,
+			numericString.message,
+		)
+	}
+	deprecated, err := deprecatedFieldUses([]byte(serviceConfig.json))
+	if err != nil {
+		return err
+	}
+	for _, use := range deprecated {
+		diagnostics.warnf(ruleDeprecatedField, serviceConfig.locate(use.path), "%s: %s", use.path, use.message)
+	}
+	lbValidator := loadBalancingConfigValidator{diagnostics: diagnostics, serviceConfig: serviceConfig}
+	lbValidator.validateList("loadBalancingConfig", serviceConfigContent.LoadBalancingConfig)
+	for _, statusCode := range serviceConfigContent.statusCodes() {
+		code, err := statusCode.parse()
+		if err != nil {
+			diagnostics.errorf(
+				ruleInvalidStatusCode,
+				serviceConfig.locate(statusCode.path),
+				"%s: invalid status code %s",
+				statusCode.path,
+				statusCode.value,
+			)
+			continue
+		}
+		if code == codes.OK {
+			diagnostics.errorf(
+				ruleInvalidStatusCode,
+				serviceConfig.locate(statusCode.path),
+				"%s: OK is not a failure and can not be retried",
+				statusCode.path,
+			)
+		}
+	}
+	for i, methodConfig := range serviceConfigContent.MethodConfigs {
+		if methodConfig.RetryPolicy != nil && exceedsMaxAttemptsLimit(methodConfig.RetryPolicy.MaxAttempts) {
+			path := fmt.Sprintf("methodConfig[%d].retryPolicy.maxAttempts", i)
+			diagnostics.warnf(
+				ruleMaxAttempts,
+				serviceConfig.locate(path),
+				"%s: maxAttempts %s exceeds the gRPC limit and is reduced to %d",
+				path,
+				methodConfig.RetryPolicy.MaxAttempts,
+				maxAttemptsLimit,
+			)
+		}
+		if methodConfig.HedgingPolicy != nil && exceedsMaxAttemptsLimit(methodConfig.HedgingPolicy.MaxAttempts) {
+			path := fmt.Sprintf("methodConfig[%d].hedgingPolicy.maxAttempts", i)
+			diagnostics.warnf(
+				ruleMaxAttempts,
+				serviceConfig.locate(path),
+				"%s: maxAttempts %s exceeds the gRPC limit and is reduced to %d",
+				path,
+				methodConfig.HedgingPolicy.MaxAttempts,
+				maxAttemptsLimit,
+			)
+		}
+	}
+	nameFormats, err := nameFormatFindings([]byte(serviceConfig.json))
+	if err != nil {
+		return err
+	}
+	// Name formats are warnings, since grpc-go accepts them, and strict mode makes them errors.
+	for _, nameFormat := range nameFormats {
+		diagnostics.warnf(
+			ruleNameFormat,
+			serviceConfig.locate(nameFormat.path),
+			"%s: %s",
+			nameFormat.path,
+			nameFormat.message,
+		)
+	}
+	for _, dangling := range p.danglingNames(serviceConfigContent) {
+		diagnostics.warnf(
+			ruleDanglingName,
+			serviceConfig.locate(dangling.path),
+			"%s: %s",
+			dangling.path,
+			dangling.message,
+		)
+	}
+	for _, ungenerated := range p.ungeneratedNames(serviceConfigContent) {
+		diagnostics.warnf(
+			ruleUngeneratedService,
+			serviceConfig.locate(ungenerated.path),
+			"%s: %s",
+			ungenerated.path,
+			ungenerated.message,
+		)
+	}
+	// gRPC Go validates a service config when parsing it.
+	// The normalized service config is validated, since that is what is embedded in generated code.
+	if err := session.parse(normalized); err != nil {
+		diagnostics.errorf(ruleInvalidServiceConfig, serviceConfig.locate(""), "invalid service config: %v", err)
+	}
+	return nil
+}
+
+// jsonFinding is a problem found at a JSON path in a service config.
+type jsonFinding struct {
+	// path is the JSON path of the problematic value.
+	path string
+	// message describes the problem.
+	message string
+}
+
+// danglingNames returns every name in the service config that references a service or method not present in the
+// descriptor set. Dangling names are usually leftovers from renamed services and methods.
+func (p *plugin) danglingNames(serviceConfigContent serviceConfigJSON) []jsonFinding {
+	registry := p.registry()
+	if registry == nil {
+		// Service configs validated without a descriptor set can not reference known services.
+		return nil
+	}
+	var result []jsonFinding
+	for i, methodConfig := range serviceConfigContent.MethodConfigs {
+		for j, name := range methodConfig.Names {
+			if name.Service == "" {
+				continue
+			}
+			path := fmt.Sprintf("methodConfig[%d].name[%d]", i, j)
+			descriptor, err := registry.FindDescriptorByName(protoreflect.FullName(name.Service))
+			if err != nil {
+				result = append(result, jsonFinding{
+					path:    path,
+					message: fmt.Sprintf("references unknown service %s", name.Service),
+				})
+				continue
+			}
+			serviceDescriptor, ok := descriptor.(protoreflect.ServiceDescriptor)
+			if !ok {
+				result = append(result, jsonFinding{
+					path:    path,
+					message: fmt.Sprintf("references %s, which is not a service", name.Service),
+				})
+				continue
+			}
+			if name.Method != "" && serviceDescriptor.Methods().ByName(protoreflect.Name(name.Method)) == nil {
+				result = append(result, jsonFinding{
+					path:    path,
+					message: fmt.Sprintf("references unknown method %s/%s", name.Service, name.Method),
+				})
+			}
+		}
+	}
+	return result
+}
+
+// ungeneratedNames returns the names referencing services in files that are not generated, such as dependencies.
+// Method configs for those services are not embedded in the generated package of the services, and are effectively
+// dead.
+func (p *plugin) ungeneratedNames(serviceConfigContent serviceConfigJSON) []jsonFinding {
+	if p.gen == nil {
+		// Service configs validated independent of protoc are not generated.
+		return nil
+	}
+	var result []jsonFinding
+	for i, methodConfig := range serviceConfigContent.MethodConfigs {
+		for j, name := range methodConfig.Names {
+			if name.Service == "" {
+				continue
+			}
+			descriptor, err := p.registry().FindDescriptorByName(protoreflect.FullName(name.Service))
+			if err != nil {
+				continue
+			}
+			serviceDescriptor, ok := descriptor.(protoreflect.ServiceDescriptor)
+			if !ok {
+				continue
+			}
+			file, ok := p.gen.FilesByPath[serviceDescriptor.ParentFile().Path()]
+			if !ok || file.Generate {
+				continue
+			}
+			result = append(result, jsonFinding{
+				path: fmt.Sprintf("methodConfig[%d].name[%d]", i, j),
+				message: fmt.Sprintf(
+					"references service %s in %s, which is not generated, so no generated package embeds it",
+					name.Service,
+					file.Desc.Path(),
+				),
+			})
+		}
+	}
+	return result
+}
+
+const (
+	// validationSessionBufferBytes is the buffer size of the in-memory connection of validation sessions.
+	validationSessionBufferBytes = 1 << 20
+	// validationSessionDialTimeout bounds dialing the in-memory server of a validation session, so that a misbehaving
+	// environment fails the run with a timeout error instead of hanging it.
+	validationSessionDialTimeout = 10 * time.Second
+)
+
+// validationSession validates service configs with the service config parser of gRPC Go, through a single client
+// connection to an in-memory gRPC server, so validation is hermetic and needs no network. Every distinct service
+// config is parsed once, however many services and sources share it.
+type validationSession struct {
+	listener *bufconn.Listener
+	server   *grpc.Server
+	wg       sync.WaitGroup
+	resolver *manual.Resolver
+	conn     *grpc.ClientConn
+	mu       sync.Mutex
+	// results are the parse errors of the parsed service configs, by the SHA-256 hash of their content.
+	results map[[sha256.Size]byte]error
+}
+
+// startValidationSession starts a validation session, which must be closed.
+func startValidationSession() (*validationSession, error) {
+	s := &validationSession{
+		listener: bufconn.Listen(validationSessionBufferBytes),
+		server:   grpc.NewServer(),
+		resolver: manual.NewBuilderWithScheme("grpc-service-config-validation"),
+		results:  map[[sha256.Size]byte]error{},
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		_ = s.server.Serve(s.listener)
+	}()
+	s.resolver.InitialState(resolver.State{Addresses: []resolver.Address{{Addr: "bufconn"}}})
+	ctx, cancel := context.WithTimeout(context.Background(), validationSessionDialTimeout)
+	defer cancel()
+	// The dial does not block until the connection is ready, since parsing service configs does not need it.
+	conn, err := grpc.DialContext(
+		ctx,
+		s.resolver.Scheme()+":///bufconn",
+		grpc.WithResolvers(s.resolver),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return s.listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err == nil && s.resolver.CC == nil {
+		_ = conn.Close()
+		err = fmt.Errorf("the resolver of the client connection was not built")
+	}
+	if err != nil {
+		s.server.Stop()
+		s.wg.Wait()
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf(
+				"start validation session: dialing the in-memory server timed out after %s",
+				validationSessionDialTimeout,
+			)
+		}
+		return nil, fmt.Errorf("start validation session: %w", err)
+	}
+	s.conn = conn
+	return s, nil
+}
+
+// parse parses a service config with the parser the client connection applies to service configs from resolvers,
+// which is the parser of default service configs, and returns the parse error.
+func (s *validationSession) parse(serviceConfig string) error {
+	hash := sha256.Sum256([]byte(serviceConfig))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err, ok := s.results[hash]; ok {
+		return err
+	}
+	err := s.resolver.CC.ParseServiceConfig(serviceConfig).Err
+	s.results[hash] = err
+	return err
+}
+
+// close closes the client connection, stops the in-memory server, and waits for it to finish serving.
+func (s *validationSession) close() {
+	_ = s.conn.Close()
+	s.server.Stop()
+	s.wg.Wait()
+}
@@ -0,0 +1,620 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// captureStderr returns what the function writes to stderr.
+func captureStderr(t testing.TB, f func()) string {
+	t.Helper()
+	file, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = file
+	defer func() {
+		os.Stderr = stderr
+	}()
+	f()
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		opts          validateOptions
+		serviceConfig string
+		// err is a substring of the validation error, or empty for no error.
+		err string
+		// warning is a substring of the warnings written to stderr, or empty for no warnings.
+		warning string
+	}{
+		{
+			name: "unique names",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}], "timeout": "1s"},
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}], "timeout": "10s"}
+]}`,
+		},
+		{
+			name: "duplicate names",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}], "timeout": "1s"},
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}], "timeout": "2s"}
+]}`,
+			err: testFreightServiceConfigFile + ":3:13: error: duplicate name" +
+				" /einride.example.freight.v1.FreightService/GetShipper in methodConfig[1].name[0]," +
+				" already matched by methodConfig[0].name[0] (line 2, column 13)",
+		},
+		{
+			name: "duplicate names in a method config",
+			serviceConfig: `{"methodConfig": [{"name": [
+  {"service": "einride.example.freight.v1.FreightService"},
+  {"service": "einride.example.freight.v1.FreightService"}
+], "timeout": "1s"}]}`,
+			err: testFreightServiceConfigFile + ":3:3: error: duplicate name" +
+				" /einride.example.freight.v1.FreightService/ in methodConfig[0].name[1]," +
+				" already matched by methodConfig[0].name[0] (line 2, column 3)",
+		},
+		{
+			name: "every problem is reported",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}], "timeout": "1s"},
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}], "timeout": "2s"},
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}], "timeout": "forever"}
+]}`,
+			err: "validate: 2 problem(s) found",
+		},
+		{
+			name:          "within size budget",
+			opts:          validateOptions{maxConfigBytes: 48},
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+		},
+		{
+			name:          "size budget exceeded",
+			opts:          validateOptions{maxConfigBytes: 40},
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+			err: "error: service config is 48 bytes when compacted, which exceeds the budget of 40 bytes" +
+				" (CONFIG_SIZE)",
+		},
+		{
+			name: "supported by the target grpc-go version",
+			opts: validateOptions{targetGRPCGoVersion: &grpcGoVersion{major: 1, minor: 40}},
+			serviceConfig: `{"methodConfig": [{
+  "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+  "retryPolicy": {
+    "maxAttempts": 2,
+    "initialBackoff": "0.1s",
+    "maxBackoff": "1s",
+    "backoffMultiplier": 2,
+    "retryableStatusCodes": ["UNAVAILABLE"]
+  }
+}]}`,
+		},
+		{
+			name: "not supported by the target grpc-go version",
+			opts: validateOptions{targetGRPCGoVersion: &grpcGoVersion{major: 1, minor: 39, patch: 1}},
+			serviceConfig: `{"methodConfig": [{
+  "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+  "retryPolicy": {
+    "maxAttempts": 2,
+    "initialBackoff": "0.1s",
+    "maxBackoff": "1s",
+    "backoffMultiplier": 2,
+    "retryableStatusCodes": ["UNAVAILABLE"]
+  }
+}]}`,
+			err: ":3:18: error: methodConfig[0].retryPolicy: retryPolicy requires grpc-go v1.40.0 or later," +
+				" but the target is v1.39.1 (GRPC_GO_COMPATIBILITY)",
+		},
+		{
+			name: "max attempts",
+			serviceConfig: `{"methodConfig": [{
+  "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+  "retryPolicy": {
+    "maxAttempts": 6,
+    "initialBackoff": "0.1s",
+    "maxBackoff": "1s",
+    "backoffMultiplier": 2,
+    "retryableStatusCodes": ["UNAVAILABLE"]
+  }
+}]}`,
+			warning: ":4:20: warning: methodConfig[0].retryPolicy.maxAttempts: maxAttempts 6 exceeds the gRPC limit" +
+				" and is reduced to 5 (MAX_ATTEMPTS)",
+		},
+		{
+			name: "max attempts of hedging policies",
+			serviceConfig: `{"methodConfig": [{
+  "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+  "hedgingPolicy": {"maxAttempts": 10, "hedgingDelay": "0.1s"}
+}]}`,
+			warning: ":3:36: warning: methodConfig[0].hedgingPolicy.maxAttempts: maxAttempts 10 exceeds the gRPC limit" +
+				" and is reduced to 5 (MAX_ATTEMPTS)",
+		},
+		{
+			name: "max attempts within the limit",
+			serviceConfig: `{"methodConfig": [{
+  "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+  "hedgingPolicy": {"maxAttempts": 5, "hedgingDelay": "0.1s"}
+}]}`,
+		},
+		{
+			name:          "deprecated loadBalancingPolicy",
+			serviceConfig: `{"loadBalancingPolicy": "ROUND_ROBIN", "methodConfig": [{"name": [{}], "timeout": "1s"}]}`,
+			err: ":1:25: error: loadBalancingPolicy is deprecated: replace it with" +
+				` "loadBalancingConfig": [{"round_robin": {}}], or enable the fix option to rewrite it in generated code` +
+				" (DEPRECATED_LOAD_BALANCING_POLICY)",
+		},
+		{
+			name:          "empty service config",
+			serviceConfig: `{}`,
+			warning: testFreightServiceConfigFile + ":1:1: warning: service config is empty, so no timeouts, retries or" +
+				" other policies are configured (NO_OP_CONFIG)",
+		},
+		{
+			name:          "empty method configs",
+			serviceConfig: `{"methodConfig": []}`,
+			warning: ":1:18: warning: methodConfig is empty, so no timeouts, retries or other method policies are" +
+				" configured (NO_OP_CONFIG)",
+		},
+		{
+			name: "no-op method config",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{}], "timeout": "10s"},
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}]}
+]}`,
+			warning: ":3:3: warning: methodConfig[1] has no timeout, retry or other policy, so it only overrides more" +
+				" general method configs (NO_OP_CONFIG)",
+		},
+		{
+			name: "shadowed method config",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService"}], "timeout": "10s"},
+  {
+    "name": [
+      {"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"},
+      {"service": "einride.example.freight.v1.FreightService", "method": "UpdateShipper"},
+      {"service": "einride.example.freight.v1.FreightService", "method": "CreateShipper"},
+      {"service": "einride.example.freight.v1.FreightService", "method": "ImportShippers"},
+      {"service": "einride.example.freight.v1.FreightService", "method": "ListShippers"},
+      {"service": "einride.example.freight.v1.FreightService", "method": "WatchShippers"}
+    ],
+    "timeout": "1s"
+  }
+]}`,
+			warning: ":2:3: warning: methodConfig[0]: method config never applies, since every method matched by" +
+				" service einride.example.freight.v1.FreightService has a more specific name",
+		},
+		{
+			name: "invalid status code",
+			serviceConfig: `{"methodConfig": [{
+  "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+  "retryPolicy": {"retryableStatusCodes": ["UNAVAILBLE"]}
+}]}`,
+			err: ":3:44: error: methodConfig[0].retryPolicy.retryableStatusCodes[0]: invalid status code" +
+				` "UNAVAILBLE" (INVALID_STATUS_CODE)`,
+		},
+		{
+			name: "OK status code",
+			serviceConfig: `{"methodConfig": [{
+  "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+  "hedgingPolicy": {"nonFatalStatusCodes": [0]}
+}]}`,
+			err: ":3:45: error: methodConfig[0].hedgingPolicy.nonFatalStatusCodes[0]: OK is not a failure" +
+				" and can not be retried (INVALID_STATUS_CODE)",
+		},
+		{
+			name: "unknown service",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.ShipperService"}], "timeout": "1s"}
+]}`,
+			warning: testFreightServiceConfigFile + ":2:13: warning: methodConfig[0].name[0]:" +
+				" references unknown service einride.example.freight.v1.ShipperService",
+		},
+		{
+			name: "unknown method",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "DeleteShipper"}], "timeout": "1s"}
+]}`,
+			warning: "methodConfig[0].name[0]: references unknown method" +
+				" einride.example.freight.v1.FreightService/DeleteShipper",
+		},
+		{
+			name: "not a service",
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.Shipper"}], "timeout": "1s"}
+]}`,
+			warning: "methodConfig[0].name[0]: references einride.example.freight.v1.Shipper, which is not a service",
+		},
+		{
+			name: "unknown method in strict mode",
+			opts: validateOptions{strict: true},
+			serviceConfig: `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "DeleteShipper"}], "timeout": "1s"}
+]}`,
+			err: "methodConfig[0].name[0]: references unknown method" +
+				" einride.example.freight.v1.FreightService/DeleteShipper",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTestFiles(t, map[string]string{testFreightServiceConfigFile: tt.serviceConfig})
+			p := newTestPlugin(
+				t,
+				pluginOptions{path: dir, conflict: conflictPreferJSON},
+				testFile(t, testFreightServiceFile),
+			)
+			var err error
+			warnings := captureStderr(t, func() {
+				opts := tt.opts
+				opts.reportFormat = reportFormatJSON
+				err = p.validate(opts)
+			})
+			if tt.warning == "" && warnings != "" {
+				t.Errorf("unexpected warnings: %s", warnings)
+			}
+			if !strings.Contains(warnings, tt.warning) {
+				t.Errorf("expected warnings containing %q, got %q", tt.warning, warnings)
+			}
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error containing %q, got %v", tt.err, err)
+			}
+			if !strings.Contains(err.Error(), filepath.Join(dir, testFreightServiceConfigFile)) {
+				t.Errorf("expected error to name the service config file, got %v", err)
+			}
+		})
+	}
+}
+
+// diagnosticRules returns the severities and rules of diagnostics, such as "warning NO_OP_CONFIG", in order.
+func diagnosticRules(diagnostics []diagnostic) []string {
+	result := make([]string, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		result = append(result, diagnostic.severity.String()+" "+string(diagnostic.rule))
+	}
+	return result
+}
+
+func TestValidationDiagnostics(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		serviceConfig string
+		// lint are the levels of lint rules, in addition to the defaults.
+		lint map[rule]lintLevel
+		// rules are the severities and rules of the diagnostics, in order.
+		rules []string
+	}{
+		{
+			name:          "valid",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+		},
+		{
+			name: "default method config with more specific names",
+			serviceConfig: `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s"},
+    {"name": [{"service": "einride.example.freight.v1.FreightService"}], "timeout": "5s"}
+  ]
+}`,
+			lint: map[rule]lintLevel{ruleRequireDefaultMethodConfig: lintLevelError},
+		},
+		{
+			name: "shadowed service method config",
+			serviceConfig: `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s"},
+    {"name": [{"service": "einride.example.freight.v1.FreightService"}], "timeout": "5s"},
+    {
+      "name": [
+        {"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"},
+        {"service": "einride.example.freight.v1.FreightService", "method": "UpdateShipper"},
+        {"service": "einride.example.freight.v1.FreightService", "method": "CreateShipper"},
+        {"service": "einride.example.freight.v1.FreightService", "method": "ImportShippers"},
+        {"service": "einride.example.freight.v1.FreightService", "method": "ListShippers"},
+        {"service": "einride.example.freight.v1.FreightService", "method": "WatchShippers"}
+      ],
+      "timeout": "1s"
+    }
+  ]
+}`,
+			rules: []string{"warning SHADOWED_METHOD_CONFIG"},
+		},
+		{
+			name: "retries of non-idempotent methods",
+			serviceConfig: `{
+  "methodConfig": [{
+    "name": [
+      {"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"},
+      {"service": "einride.example.freight.v1.FreightService", "method": "UpdateShipper"},
+      {"service": "einride.example.freight.v1.FreightService", "method": "CreateShipper"},
+      {"service": "einride.example.freight.v1.FreightService", "method": "ImportShippers"},
+      {"service": "einride.example.freight.v1.FreightService", "method": "ListShippers"}
+    ],
+    "timeout": "1s",
+    "retryPolicy": {
+      "maxAttempts": 3,
+      "initialBackoff": "0.1s",
+      "maxBackoff": "1s",
+      "backoffMultiplier": 2,
+      "retryableStatusCodes": ["UNAVAILABLE"]
+    }
+  }]
+}`,
+			lint: map[rule]lintLevel{ruleNonIdempotentRetry: lintLevelWarn},
+			rules: []string{
+				"warning NON_IDEMPOTENT_RETRY",
+				"warning NON_IDEMPOTENT_RETRY",
+				"warning NON_IDEMPOTENT_RETRY",
+			},
+		},
+		{
+			name: "name formats",
+			serviceConfig: `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s"},
+    {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "/GetShipper"}], "timeout": "1s"}
+  ]
+}`,
+			rules: []string{"warning NAME_FORMAT", "warning DANGLING_NAME"},
+		},
+		{
+			name:          "invalid JSON",
+			serviceConfig: `{"methodConfig": [}`,
+			rules:         []string{"error INVALID_JSON"},
+		},
+		{
+			name:          "invalid service config",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "forever"}]}`,
+			rules:         []string{"error INVALID_SERVICE_CONFIG"},
+		},
+		{
+			name: "every problem is reported",
+			serviceConfig: `{
+  "methodConfig": [
+    {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}], "timeout": "1s"},
+    {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}]},
+    {"name": [{"service": "einride.example.freight.v1.Missing"}], "timeout": "1s"}
+  ]
+}`,
+			rules: []string{
+				"warning NO_OP_CONFIG",
+				"error DUPLICATE_NAME",
+				"warning DANGLING_NAME",
+				"error INVALID_SERVICE_CONFIG",
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTestFiles(t, map[string]string{testFreightServiceConfigFile: tt.serviceConfig})
+			p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+			levels := map[rule]lintLevel{
+				ruleStreamingRetry:        lintLevelWarn,
+				ruleNonIdempotentRetry:    lintLevelOff,
+				ruleHedgingSideEffects:    lintLevelWarn,
+				ruleRetryUnsafeStatusCode: lintLevelWarn,
+				ruleWaitForReady:          lintLevelOff,
+				ruleTimeoutRange:          lintLevelWarn,
+				ruleGatewayTimeout:        lintLevelWarn,
+			}
+			for rule, level := range tt.lint {
+				levels[rule] = level
+			}
+			diagnostics, err := p.validationDiagnostics(validateOptions{lint: lintOptions{levels: levels}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := diagnosticRules(diagnostics.list); strings.Join(got, "\n") != strings.Join(tt.rules, "\n") {
+				t.Errorf("expected diagnostics %q, got %q:\n%v", tt.rules, got, diagnostics.list)
+			}
+		})
+	}
+}
+
+func TestUngeneratedNames(t *testing.T) {
+	dependency := testFile(t, `
+name: "einride/example/shipper/v1/shipper_service.proto"
+package: "einride.example.shipper.v1"
+options { go_package: "example.com/shipper/v1;shipperv1" }
+service { name: "ShipperService" }
+`)
+	request := testRequest(t, "", dependency, testFile(t, testFreightServiceFile))
+	request.FileToGenerate = []string{"einride/example/freight/v1/freight_service.proto"}
+	gen, err := protogen.Options{}.New(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := newPlugin(gen, pluginOptions{conflict: conflictPreferJSON})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serviceConfig, err := parseTestServiceConfig(resolvedServiceConfig{json: `{"methodConfig": [
+  {"name": [{}, {"service": "einride.example.freight.v1.FreightService"}]},
+  {"name": [{"service": "einride.example.shipper.v1.ShipperService", "method": "GetShipper"}]},
+  {"name": [{"service": "einride.example.shipper.v1.Missing"}, {"service": "einride.example.freight.v1.Shipper"}]}
+]}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected = "methodConfig[1].name[0]: references service einride.example.shipper.v1.ShipperService in" +
+		" einride/example/shipper/v1/shipper_service.proto, which is not generated, so no generated package embeds it"
+	if actual := jsonFindingStrings(p.ungeneratedNames(serviceConfig)); actual != expected {
+		t.Errorf("expected findings:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestValidationSession(t *testing.T) {
+	session, err := startValidationSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.close()
+	const (
+		valid   = `{"methodConfig": [{"name": [{"service": "a.B"}], "timeout": "2s"}]}`
+		invalid = `{"loadBalancingConfig": [{"unknown": {}}]}`
+	)
+	if err := session.parse(valid); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	first := session.parse(invalid)
+	if first == nil {
+		t.Fatal("expected an error")
+	}
+	if second := session.parse(invalid); second != first {
+		t.Errorf("expected the cached error %v, got %v", first, second)
+	}
+	if len(session.results) != 2 {
+		t.Errorf("expected 2 parsed service configs, got %d", len(session.results))
+	}
+}
+
+func TestStartValidationSession(t *testing.T) {
+	start := time.Now()
+	session, err := startValidationSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.close()
+	if elapsed := time.Since(start); elapsed >= validationSessionDialTimeout {
+		t.Errorf("expected the session to start within %s, took %s", validationSessionDialTimeout, elapsed)
+	}
+	if session.resolver.CC == nil {
+		t.Error("expected the resolver of the client connection to be built")
+	}
+}
+
+func TestRunValidationTasks(t *testing.T) {
+	p := newTestPlugin(t, pluginOptions{conflict: conflictPreferJSON}, testFile(t, testFreightServiceFile))
+	service := p.gen.Files[len(p.gen.Files)-1].Services[0].Desc
+	newTasks := func() []*validationTask {
+		var tasks []*validationTask
+		for i := 0; i < 10; i++ {
+			i := i
+			task := &validationTask{descriptor: service}
+			task.run = func() error {
+				// Later tasks finish first.
+				time.Sleep(time.Duration(10-i) * time.Millisecond)
+				task.diagnostics.errorf(ruleInvalidServiceConfig, location{file: "a.json"}, "problem %d", i)
+				return nil
+			}
+			tasks = append(tasks, task)
+		}
+		// Tasks without anything to validate concurrently have no run function.
+		noRun := &validationTask{}
+		noRun.diagnostics.infof(ruleDuplicateConfig, location{file: "b.json"}, "problem 10")
+		return append(tasks, noRun)
+	}
+	for _, jobs := range []int{0, 1, 4, 100} {
+		var diagnostics diagnostics
+		if failedFast, err := p.runValidationTasks(&diagnostics, newTasks(), validateOptions{jobs: jobs}); err != nil {
+			t.Fatal(err)
+		} else if failedFast {
+			t.Fatalf("jobs %d: expected validation not to fail fast", jobs)
+		}
+		if len(diagnostics.list) != 11 {
+			t.Fatalf("jobs %d: expected 11 diagnostics, got %d", jobs, len(diagnostics.list))
+		}
+		for i, diagnostic := range diagnostics.list {
+			if expected := fmt.Sprintf("problem %d", i); diagnostic.message != expected {
+				t.Errorf("jobs %d: expected diagnostic %d to be %q, got %q", jobs, i, expected, diagnostic.message)
+			}
+			if expected := i < 10; (diagnostic.descriptor == service) != expected {
+				t.Errorf("jobs %d: expected diagnostic %d to describe the service: %v", jobs, i, expected)
+			}
+		}
+	}
+	t.Run("error", func(t *testing.T) {
+		tasks := newTasks()
+		tasks[3].run = func() error { return errors.New("task 3") }
+		tasks[5].run = func() error { return errors.New("task 5") }
+		var diagnostics diagnostics
+		_, err := p.runValidationTasks(&diagnostics, tasks, validateOptions{jobs: 4})
+		if err == nil || err.Error() != "task 3" {
+			t.Errorf("expected the error of the first failed task, got %v", err)
+		}
+	})
+	t.Run("negative jobs", func(t *testing.T) {
+		var diagnostics diagnostics
+		const expected = "jobs must not be negative: -1"
+		_, err := p.runValidationTasks(&diagnostics, newTasks(), validateOptions{jobs: -1})
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	})
+	t.Run("fail fast", func(t *testing.T) {
+		for _, jobs := range []int{1, 4} {
+			tasks := newTasks()
+			for _, task := range tasks[:3] {
+				task := task
+				// Warnings fail validation only in strict mode.
+				task.run = func() error {
+					task.diagnostics.warnf(ruleTimeoutRange, location{file: "a.json"}, "warning")
+					return nil
+				}
+			}
+			var diagnostics, strictDiagnostics diagnostics
+			failedFast, err := p.runValidationTasks(&diagnostics, tasks, validateOptions{jobs: jobs, failFast: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The fourth task has the first error.
+			if !failedFast || len(diagnostics.list) != 4 || diagnostics.list[3].message != "problem 3" {
+				t.Errorf("jobs %d: expected to fail fast after 4 diagnostics, got %v and %d",
+					jobs, failedFast, len(diagnostics.list))
+			}
+			tasks = newTasks()
+			tasks[0].run = func() error {
+				tasks[0].diagnostics.warnf(ruleTimeoutRange, location{file: "a.json"}, "warning")
+				return nil
+			}
+			opts := validateOptions{jobs: jobs, failFast: true, strict: true}
+			if failedFast, err := p.runValidationTasks(&strictDiagnostics, tasks, opts); err != nil || !failedFast {
+				t.Fatalf("jobs %d: expected to fail fast, got %v and %v", jobs, failedFast, err)
+			}
+			if len(strictDiagnostics.list) != 1 {
+				t.Errorf("jobs %d: expected to fail fast at the strict warning, got %d diagnostics",
+					jobs, len(strictDiagnostics.list))
+			}
+		}
+	})
+}
+
+func BenchmarkValidationDiagnostics(b *testing.B) {
+	dir, files := testManyServices(b, 100)
+	p := newTestPlugin(b, pluginOptions{path: dir, conflict: conflictPreferJSON}, files...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		diagnostics, err := p.validationDiagnostics(validateOptions{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		// The service configs are valid, with warnings at most.
+		if err := report(io.Discard, diagnostics.effective(false)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package plugin
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// develVersion is the version of development builds of the plugin.
+const develVersion = "(devel)"
+
+// version is the version of the plugin, set by release builds with -ldflags="-X <package>.version=<version>".
+var version string
+
+// Version returns the version of the plugin: the version set by release builds, the module version when installed
+// with go install, or (devel).
+func Version() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return develVersion
+}
+
+// VersionInfo returns the version of the plugin and the version of grpc-go service configs are validated with.
+func VersionInfo() string {
+	return fmt.Sprintf("%s (grpc-go v%s)", Version(), grpc.Version)
+}
+
+// generatedFileHeader writes the header of a generated file, with the versions of the plugin and of grpc-go.
+// Development builds omit the plugin version, since it does not identify a release.
+func generatedFileHeader(g *protogen.GeneratedFile) {
+	g.P("// Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.")
+	g.P("// versions:")
+	if version := Version(); version != develVersion {
+		g.P("// \tprotoc-gen-go-grpc-service-config ", version)
+	}
+	g.P("// \tgrpc-go v", grpc.Version)
+	g.P()
+}
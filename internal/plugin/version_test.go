@@ -0,0 +1,45 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+func TestVersionInfo(t *testing.T) {
+	version = "v1.2.3"
+	t.Cleanup(func() { version = "" })
+	if got, expected := VersionInfo(), "v1.2.3 (grpc-go v"+grpc.Version+")"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestGeneratedFileHeader(t *testing.T) {
+	version = "v1.2.3"
+	t.Cleanup(func() { version = "" })
+	dir := writeTestFiles(t, map[string]string{
+		testFreightServiceConfigFile: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+	})
+	p := newTestPlugin(
+		t,
+		pluginOptions{path: dir, conflict: conflictPreferJSON},
+		testFile(t, testFreightServiceFile),
+	)
+	if err := p.generateFromJSON(); err != nil {
+		t.Fatal(err)
+	}
+	files := p.gen.Response().GetFile()
+	if len(files) != 1 {
+		t.Fatalf("expected 1 generated file, got %d", len(files))
+	}
+	expected := "// Code generated by protoc-gen-go-grpc-service-config. DO NOT EDIT.\n" +
+		"// versions:\n" +
+		"// \tprotoc-gen-go-grpc-service-config v1.2.3\n" +
+		"// \tgrpc-go v" + grpc.Version + "\n" +
+		"\n" +
+		"package freightv1\n"
+	if !strings.HasPrefix(files[0].GetContent(), expected) {
+		t.Errorf("expected content starting with:\n%s\ngot:\n%s", expected, files[0].GetContent())
+	}
+}
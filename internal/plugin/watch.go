@@ -0,0 +1,115 @@
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// fileState is the state of a watched file, used to detect changes.
+type fileState struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+// fileStates returns the current state of the files.
+func fileStates(filenames []string) map[string]fileState {
+	result := make(map[string]fileState, len(filenames))
+	for _, filename := range filenames {
+		info, err := os.Stat(filename)
+		if err != nil {
+			result[filename] = fileState{}
+			continue
+		}
+		result[filename] = fileState{modTime: info.ModTime(), size: info.Size(), exists: true}
+	}
+	return result
+}
+
+// watchValidation validates service config files against a descriptor set whenever they change, polling for changes,
+// and prints the diagnostics that appeared and disappeared since the previous run.
+func watchValidation(
+	w io.Writer,
+	descriptorSet string,
+	filenames []string,
+	opts validateOptions,
+	interval time.Duration,
+) error {
+	if err := opts.reportFormat.validate(); err != nil {
+		return err
+	}
+	watched := append([]string{descriptorSet}, filenames...)
+	var states map[string]fileState
+	var previous map[string]struct{}
+	for ; ; time.Sleep(interval) {
+		current := fileStates(watched)
+		if states != nil && !fileStatesChanged(states, current) {
+			continue
+		}
+		states = current
+		files, err := loadDescriptorSet(descriptorSet)
+		if err != nil {
+			fmt.Fprintf(w, "%s: %v\n", time.Now().Format("15:04:05"), err)
+			continue
+		}
+		p := &plugin{files: files}
+		diagnostics, err := p.fileDiagnostics(filenames, opts)
+		if err != nil {
+			return err
+		}
+		result := diagnostics.effective(opts.strict)
+		if opts.reportFile != "" {
+			if err := writeReportFile(os.Stderr, opts.reportFile, opts.reportFormat, result); err != nil {
+				return err
+			}
+		}
+		latest := make(map[string]struct{}, len(result))
+		var errs, warnings int
+		for _, diagnostic := range result {
+			switch diagnostic.severity {
+			case severityError:
+				errs++
+			case severityWarning:
+				warnings++
+			}
+			latest[diagnostic.String()] = struct{}{}
+			if _, ok := previous[diagnostic.String()]; !ok {
+				fmt.Fprintf(w, "+ %s\n", diagnostic)
+			}
+		}
+		resolved := make([]string, 0, len(previous))
+		for diagnostic := range previous {
+			if _, ok := latest[diagnostic]; !ok {
+				resolved = append(resolved, diagnostic)
+			}
+		}
+		sort.Strings(resolved)
+		for _, diagnostic := range resolved {
+			fmt.Fprintf(w, "- %s\n", diagnostic)
+		}
+		previous = latest
+		fmt.Fprintf(
+			w,
+			"%s: validated %d file(s): %d error(s), %d warning(s)\n",
+			time.Now().Format("15:04:05"),
+			len(filenames),
+			errs,
+			warnings,
+		)
+	}
+}
+
+// fileStatesChanged returns true if any file changed between the states.
+func fileStatesChanged(previous, current map[string]fileState) bool {
+	for filename, state := range current {
+		previousState, ok := previous[filename]
+		if !ok || previousState.exists != state.exists || previousState.size != state.size ||
+			!previousState.modTime.Equal(state.modTime) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStates(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"service_config.json": "{}"})
+	filename, missing := filepath.Join(dir, "service_config.json"), filepath.Join(dir, "missing.json")
+	states := fileStates([]string{filename, missing})
+	if state := states[filename]; !state.exists || state.size != 2 {
+		t.Errorf("expected %s to exist with size 2, got %+v", filename, state)
+	}
+	if state := states[missing]; state.exists {
+		t.Errorf("expected %s to not exist, got %+v", missing, state)
+	}
+	if fileStatesChanged(states, fileStates([]string{filename, missing})) {
+		t.Error("expected unchanged files to not be changed")
+	}
+	if err := os.WriteFile(filename, []byte(`{"methodConfig": []}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if !fileStatesChanged(states, fileStates([]string{filename, missing})) {
+		t.Error("expected a rewritten file to be changed")
+	}
+}
+
+func TestFileStatesChanged(t *testing.T) {
+	now := time.Now()
+	previous := map[string]fileState{"a.json": {modTime: now, size: 1, exists: true}}
+	for _, tt := range []struct {
+		name     string
+		current  map[string]fileState
+		expected bool
+	}{
+		{name: "unchanged", current: map[string]fileState{"a.json": {modTime: now, size: 1, exists: true}}},
+		{
+			name:     "modified",
+			current:  map[string]fileState{"a.json": {modTime: now.Add(time.Second), size: 1, exists: true}},
+			expected: true,
+		},
+		{
+			name:     "resized",
+			current:  map[string]fileState{"a.json": {modTime: now, size: 2, exists: true}},
+			expected: true,
+		},
+		{name: "removed", current: map[string]fileState{"a.json": {}}, expected: true},
+		{
+			name: "added",
+			current: map[string]fileState{
+				"a.json": {modTime: now, size: 1, exists: true},
+				"b.json": {modTime: now, size: 1, exists: true},
+			},
+			expected: true,
+		},
+	} {
+		if actual := fileStatesChanged(previous, tt.current); actual != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, actual)
+		}
+	}
+}
+
+func TestFileDiagnostics(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"valid.json":   `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+		"invalid.json": `{"methodConfig": [`,
+	})
+	p := &plugin{}
+	diagnostics, err := p.fileDiagnostics(
+		[]string{filepath.Join(dir, "valid.json"), filepath.Join(dir, "invalid.json")},
+		validateOptions{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnostics.list) != 1 || diagnostics.list[0].rule != ruleInvalidJSON {
+		t.Errorf("expected one %s diagnostic, got %v", ruleInvalidJSON, diagnostics.list)
+	}
+}
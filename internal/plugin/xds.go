@@ -0,0 +1,199 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+	"google.golang.org/grpc/codes"
+)
+
+// xdsRouteConfigurationType is the type URL of xDS RouteConfiguration resources.
+const xdsRouteConfigurationType = "type.googleapis.com/envoy.config.route.v3.RouteConfiguration"
+
+// xdsRetryOnConditions are the gRPC retry conditions of xDS retry policies, by status code. Other status codes can
+// not be retried with xDS.
+var xdsRetryOnConditions = map[codes.Code]string{
+	codes.Canceled:          "cancelled",
+	codes.DeadlineExceeded:  "deadline-exceeded",
+	codes.Internal:          "internal",
+	codes.ResourceExhausted: "resource-exhausted",
+	codes.Unavailable:       "unavailable",
+}
+
+// xdsRouteConfiguration is an xDS RouteConfiguration resource in the protobuf JSON format.
+type xdsRouteConfiguration struct {
+	Type         string           `json:"@type"`
+	Name         string           `json:"name"`
+	VirtualHosts []xdsVirtualHost `json:"virtualHosts"`
+}
+
+// xdsVirtualHost is an xDS VirtualHost in the protobuf JSON format.
+type xdsVirtualHost struct {
+	Name    string     `json:"name"`
+	Domains []string   `json:"domains"`
+	Routes  []xdsRoute `json:"routes"`
+}
+
+// xdsRoute is an xDS Route in the protobuf JSON format.
+type xdsRoute struct {
+	Name  string         `json:"name"`
+	Match xdsRouteMatch  `json:"match"`
+	Route xdsRouteAction `json:"route"`
+}
+
+// xdsRouteMatch is an xDS RouteMatch in the protobuf JSON format.
+type xdsRouteMatch struct {
+	Path string `json:"path"`
+}
+
+// xdsRouteAction is an xDS RouteAction in the protobuf JSON format.
+type xdsRouteAction struct {
+	Cluster string `json:"cluster"`
+	// Timeout is the timeout applied by Envoy.
+	Timeout string `json:"timeout,omitempty"`
+	// MaxStreamDuration is the timeout applied by proxyless gRPC clients.
+	MaxStreamDuration *xdsMaxStreamDuration `json:"maxStreamDuration,omitempty"`
+	RetryPolicy       *xdsRetryPolicy       `json:"retryPolicy,omitempty"`
+}
+
+// xdsMaxStreamDuration is an xDS RouteAction.MaxStreamDuration in the protobuf JSON format.
+type xdsMaxStreamDuration struct {
+	MaxStreamDuration string `json:"maxStreamDuration"`
+}
+
+// xdsRetryPolicy is an xDS RetryPolicy in the protobuf JSON format.
+type xdsRetryPolicy struct {
+	RetryOn      string           `json:"retryOn"`
+	NumRetries   int              `json:"numRetries"`
+	RetryBackOff *xdsRetryBackOff `json:"retryBackOff,omitempty"`
+}
+
+// xdsRetryBackOff is an xDS RetryPolicy.RetryBackOff in the protobuf JSON format.
+type xdsRetryBackOff struct {
+	BaseInterval string `json:"baseInterval"`
+	MaxInterval  string `json:"maxInterval,omitempty"`
+}
+
+// xdsRouteConfigurationFor returns the xDS RouteConfiguration of the resolved service config of a package, with a
+// route for every method of its services that sends calls to the cluster, with the timeout and retry policy of the
+// method config that applies to the method.
+func xdsRouteConfigurationFor(serviceConfig *packageServiceConfig, cluster string) (*xdsRouteConfiguration, error) {
+	var config serviceConfigJSON
+	if err := json.Unmarshal([]byte(serviceConfig.json), &config); err != nil {
+		return nil, err
+	}
+	var routes []xdsRoute
+	for _, service := range serviceConfig.services {
+		for i := 0; i < service.Methods().Len(); i++ {
+			method := service.Methods().Get(i)
+			grpcPath := "/" + string(service.FullName()) + "/" + string(method.Name())
+			route := xdsRoute{
+				Name:  grpcPath,
+				Match: xdsRouteMatch{Path: grpcPath},
+				Route: xdsRouteAction{Cluster: cluster},
+			}
+			if index, ok := config.methodConfigFor(method); ok {
+				if err := route.Route.applyMethodConfig(config.MethodConfigs[index]); err != nil {
+					return nil, fmt.Errorf("%s: methodConfig[%d]: %w", grpcPath, index, err)
+				}
+			}
+			routes = append(routes, route)
+		}
+	}
+	return &xdsRouteConfiguration{
+		Type: xdsRouteConfigurationType,
+		Name: string(serviceConfig.pkg),
+		VirtualHosts: []xdsVirtualHost{
+			{Name: string(serviceConfig.pkg), Domains: []string{"*"}, Routes: routes},
+		},
+	}, nil
+}
+
+// applyMethodConfig sets the timeout and retry policy of the route to the ones of the method config. xDS retry
+// policies back off with a fixed multiplier of 2, so the backoff multiplier of the method config is not translated.
+func (r *xdsRouteAction) applyMethodConfig(methodConfig methodConfigJSON) error {
+	if methodConfig.Timeout != nil {
+		timeout, err := configjson.ParseDuration(*methodConfig.Timeout)
+		if err != nil {
+			return fmt.Errorf("timeout: %w", err)
+		}
+		r.Timeout = configjson.FormatDuration(timeout)
+		r.MaxStreamDuration = &xdsMaxStreamDuration{MaxStreamDuration: configjson.FormatDuration(timeout)}
+	}
+	if methodConfig.RetryPolicy == nil {
+		return nil
+	}
+	maxAttempts, _, err := explainMaxAttempts(methodConfig.RetryPolicy.MaxAttempts)
+	if err != nil {
+		return fmt.Errorf("retryPolicy.maxAttempts: %w", err)
+	}
+	retryOn := make([]string, 0, len(methodConfig.RetryPolicy.RetryableStatusCodes))
+	seen := map[codes.Code]struct{}{}
+	for i, value := range methodConfig.RetryPolicy.RetryableStatusCodes {
+		code, err := statusCodeJSON{value: value}.parse()
+		if err != nil {
+			return fmt.Errorf("retryPolicy.retryableStatusCodes[%d]: %w", i, err)
+		}
+		condition, ok := xdsRetryOnConditions[code]
+		if !ok {
+			return fmt.Errorf(
+				"retryPolicy.retryableStatusCodes[%d]: %s can not be retried with xDS",
+				i,
+				configjson.StatusCodeName(code),
+			)
+		}
+		if _, ok := seen[code]; !ok {
+			seen[code] = struct{}{}
+			retryOn = append(retryOn, condition)
+		}
+	}
+	r.RetryPolicy = &xdsRetryPolicy{RetryOn: strings.Join(retryOn, ","), NumRetries: int(maxAttempts) - 1}
+	if methodConfig.RetryPolicy.InitialBackoff != "" {
+		initialBackoff, err := configjson.ParseDuration(methodConfig.RetryPolicy.InitialBackoff)
+		if err != nil {
+			return fmt.Errorf("retryPolicy.initialBackoff: %w", err)
+		}
+		r.RetryPolicy.RetryBackOff = &xdsRetryBackOff{BaseInterval: configjson.FormatDuration(initialBackoff)}
+		if methodConfig.RetryPolicy.MaxBackoff != "" {
+			maxBackoff, err := configjson.ParseDuration(methodConfig.RetryPolicy.MaxBackoff)
+			if err != nil {
+				return fmt.Errorf("retryPolicy.maxBackoff: %w", err)
+			}
+			r.RetryPolicy.RetryBackOff.MaxInterval = configjson.FormatDuration(maxBackoff)
+		}
+	}
+	return nil
+}
+
+// generateXDSRouteConfigurations writes an xDS RouteConfiguration with the timeouts and retry policies of the
+// resolved service config of every package to the directory, as <dir>/<package path>/route_configuration.json, for
+// migrating clients from static service configs to xDS. Routes send calls to the cluster, which defaults to the
+// package name.
+func (p *plugin) generateXDSRouteConfigurations(dir, cluster string) error {
+	serviceConfigs, err := p.packageServiceConfigs()
+	if err != nil {
+		return err
+	}
+	for _, serviceConfig := range serviceConfigs {
+		packageCluster := cluster
+		if packageCluster == "" {
+			packageCluster = string(serviceConfig.pkg)
+		}
+		routeConfiguration, err := xdsRouteConfigurationFor(serviceConfig, packageCluster)
+		if err != nil {
+			return fmt.Errorf("emit %s xDS RouteConfiguration: %w", serviceConfig.pkg, err)
+		}
+		data, err := json.MarshalIndent(routeConfiguration, "", "  ")
+		if err != nil {
+			return fmt.Errorf("emit %s xDS RouteConfiguration: %w", serviceConfig.pkg, err)
+		}
+		g := p.gen.NewGeneratedFile(path.Join(dir, packagePath(serviceConfig.pkg), "route_configuration.json"), "")
+		if _, err := g.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package plugin
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testRouteServiceFile is a proto file of the freight service with two unary methods, for tests of generated routes.
+const testRouteServiceFile = `
+name: "einride/example/freight/v1/freight_service.proto"
+package: "einride.example.freight.v1"
+options { go_package: "example.com/freight/v1;freightv1" }
+message_type { name: "Shipper" }
+service {
+  name: "FreightService"
+  method {
+    name: "GetShipper"
+    input_type: ".einride.example.freight.v1.Shipper"
+    output_type: ".einride.example.freight.v1.Shipper"
+  }
+  method {
+    name: "CreateShipper"
+    input_type: ".einride.example.freight.v1.Shipper"
+    output_type: ".einride.example.freight.v1.Shipper"
+  }
+}
+`
+
+// testRouteServiceConfig is a service config of testRouteServiceFile, with a default timeout and a retry policy for
+// one method.
+const testRouteServiceConfig = `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s"},
+    {
+      "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+      "timeout": "1s",
+      "retryPolicy": {"maxAttempts": 3, "initialBackoff": "0.1s", "retryableStatusCodes": ["UNAVAILABLE"]}
+    }
+  ]
+}`
+
+func TestXDSRouteActionApplyMethodConfig(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		methodConfig string
+		expected     xdsRouteAction
+		err          string
+	}{
+		{
+			name:         "timeout",
+			methodConfig: `{"timeout": "1.500s"}`,
+			expected: xdsRouteAction{
+				Timeout:           "1.5s",
+				MaxStreamDuration: &xdsMaxStreamDuration{MaxStreamDuration: "1.5s"},
+			},
+		},
+		{
+			name: "retry policy",
+			methodConfig: `{"retryPolicy": {
+				"maxAttempts": 8,
+				"initialBackoff": "0.1s",
+				"maxBackoff": "1s",
+				"retryableStatusCodes": ["UNAVAILABLE", 14, "RESOURCE_EXHAUSTED"]
+			}}`,
+			expected: xdsRouteAction{
+				RetryPolicy: &xdsRetryPolicy{
+					RetryOn:      "unavailable,resource-exhausted",
+					NumRetries:   4,
+					RetryBackOff: &xdsRetryBackOff{BaseInterval: "0.1s", MaxInterval: "1s"},
+				},
+			},
+		},
+		{
+			name:         "retry policy without backoff",
+			methodConfig: `{"retryPolicy": {"maxAttempts": 2, "retryableStatusCodes": ["INTERNAL"]}}`,
+			expected: xdsRouteAction{
+				RetryPolicy: &xdsRetryPolicy{RetryOn: "internal", NumRetries: 1},
+			},
+		},
+		{
+			name:         "status code not retryable with xDS",
+			methodConfig: `{"retryPolicy": {"maxAttempts": 2, "retryableStatusCodes": ["UNAVAILABLE", "ABORTED"]}}`,
+			err:          "retryPolicy.retryableStatusCodes[1]: ABORTED can not be retried with xDS",
+		},
+		{
+			name:         "invalid timeout",
+			methodConfig: `{"timeout": "forever"}`,
+			err:          "timeout: ",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var methodConfig methodConfigJSON
+			if err := json.Unmarshal([]byte(tt.methodConfig), &methodConfig); err != nil {
+				t.Fatal(err)
+			}
+			var actual xdsRouteAction
+			err := actual.applyMethodConfig(methodConfig)
+			if tt.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.expected, actual) {
+				t.Errorf("expected %+v, got %+v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGenerateXDSRouteConfigurations(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{testFreightServiceConfigFile: testRouteServiceConfig})
+	p := newTestPlugin(t, pluginOptions{path: dir, conflict: conflictPreferJSON}, testFile(t, testRouteServiceFile))
+	if err := p.generateXDSRouteConfigurations("xds", "freight"); err != nil {
+		t.Fatal(err)
+	}
+	files := p.gen.Response().GetFile()
+	if len(files) != 1 {
+		t.Fatalf("expected 1 generated file, got %d", len(files))
+	}
+	const expectedName = "xds/einride/example/freight/v1/route_configuration.json"
+	if actual := files[0].GetName(); actual != expectedName {
+		t.Errorf("expected name %s, got %s", expectedName, actual)
+	}
+	const expected = `{
+  "@type": "type.googleapis.com/envoy.config.route.v3.RouteConfiguration",
+  "name": "einride.example.freight.v1",
+  "virtualHosts": [
+    {
+      "name": "einride.example.freight.v1",
+      "domains": [
+        "*"
+      ],
+      "routes": [
+        {
+          "name": "/einride.example.freight.v1.FreightService/GetShipper",
+          "match": {
+            "path": "/einride.example.freight.v1.FreightService/GetShipper"
+          },
+          "route": {
+            "cluster": "freight",
+            "timeout": "1s",
+            "maxStreamDuration": {
+              "maxStreamDuration": "1s"
+            },
+            "retryPolicy": {
+              "retryOn": "unavailable",
+              "numRetries": 2,
+              "retryBackOff": {
+                "baseInterval": "0.1s"
+              }
+            }
+          }
+        },
+        {
+          "name": "/einride.example.freight.v1.FreightService/CreateShipper",
+          "match": {
+            "path": "/einride.example.freight.v1.FreightService/CreateShipper"
+          },
+          "route": {
+            "cluster": "freight",
+            "timeout": "10s",
+            "maxStreamDuration": {
+              "maxStreamDuration": "10s"
+            }
+          }
+        }
+      ]
+    }
+  ]
+}
+`
+	if actual := files[0].GetContent(); actual != expected {
+		t.Errorf("expected content:\n%s\ngot:\n%s", expected, actual)
+	}
+}
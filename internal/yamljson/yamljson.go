@@ -0,0 +1,37 @@
+// Package yamljson converts service configs in YAML to JSON, for the plugin and the runtime packages that read
+// service config files.
+package yamljson
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IsYAMLFile returns true if the file has a YAML file extension.
+func IsYAMLFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// ToJSON converts a YAML document to JSON.
+func ToJSON(data []byte) ([]byte, error) {
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if value == nil {
+		value = map[string]interface{}{}
+	}
+	result, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("convert YAML to JSON: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,44 @@
+package yamljson
+
+import "testing"
+
+func TestToJSON(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+		err      bool
+	}{
+		{input: "", expected: `{}`},
+		{
+			input:    "methodConfig:\n  - name: [{}]\n    timeout: 10s\n",
+			expected: `{"methodConfig":[{"name":[{}],"timeout":"10s"}]}`,
+		},
+		{input: "methodConfig: [", err: true},
+	} {
+		actual, err := ToJSON([]byte(tt.input))
+		if tt.err {
+			if err == nil {
+				t.Errorf("expected %q to be invalid, got %s", tt.input, actual)
+			}
+			continue
+		}
+		if err != nil || string(actual) != tt.expected {
+			t.Errorf("expected %q to be %s, got %s, %v", tt.input, tt.expected, actual, err)
+		}
+	}
+}
+
+func TestIsYAMLFile(t *testing.T) {
+	for _, tt := range []struct {
+		filename string
+		expected bool
+	}{
+		{filename: "service_config.yaml", expected: true},
+		{filename: "service_config.YML", expected: true},
+		{filename: "service_config.json"},
+	} {
+		if actual := IsYAMLFile(tt.filename); actual != tt.expected {
+			t.Errorf("expected IsYAMLFile(%q) to be %v, got %v", tt.filename, tt.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package serviceconfig
+
+import "google.golang.org/grpc"
+
+// CallOptions returns the call options matching the waitForReady and maximum message sizes of the method config, for
+// callers that need per-call overrides consistent with the service config.
+func (c *MethodConfig) CallOptions() []grpc.CallOption {
+	var result []grpc.CallOption
+	if c.WaitForReady != nil {
+		result = append(result, grpc.WaitForReady(*c.WaitForReady))
+	}
+	if c.MaxRequestMessageBytes != nil {
+		result = append(result, grpc.MaxCallSendMsgSize(*c.MaxRequestMessageBytes))
+	}
+	if c.MaxResponseMessageBytes != nil {
+		result = append(result, grpc.MaxCallRecvMsgSize(*c.MaxResponseMessageBytes))
+	}
+	return result
+}
+
+// CallOptions returns the call options matching the waitForReady and maximum message sizes of the full method name,
+// such as "/example.v1.ExampleService/GetExample", in the service config JSON, with the same matching rules as gRPC.
+// Timeouts are not call options in gRPC, use TimeoutFor to derive deadlines.
+func CallOptions(serviceConfig, fullMethod string) ([]grpc.CallOption, error) {
+	config, err := Parse(serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+	methodConfig, ok, err := config.MethodConfigFor(fullMethod)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return methodConfig.CallOptions(), nil
+}
@@ -0,0 +1,55 @@
+package serviceconfig
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+func TestCallOptions(t *testing.T) {
+	const serviceConfig = `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s"},
+    {
+      "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+      "waitForReady": true,
+      "maxRequestMessageBytes": 1024,
+      "maxResponseMessageBytes": 2048
+    }
+  ]
+}`
+	for _, tt := range []struct {
+		name       string
+		fullMethod string
+		expected   []grpc.CallOption
+	}{
+		{
+			name:       "method config with call options",
+			fullMethod: "/einride.example.freight.v1.FreightService/GetShipper",
+			expected: []grpc.CallOption{
+				grpc.WaitForReady(true),
+				grpc.MaxCallSendMsgSize(1024),
+				grpc.MaxCallRecvMsgSize(2048),
+			},
+		},
+		{
+			name:       "method config without call options",
+			fullMethod: "/einride.example.freight.v1.FreightService/ListShippers",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := CallOptions(serviceConfig, tt.fullMethod)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, actual)
+			}
+		})
+	}
+	if _, err := CallOptions(serviceConfig, "GetShipper"); err == nil {
+		t.Error("expected error for invalid full method name")
+	}
+}
@@ -0,0 +1,201 @@
+package serviceconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+	"google.golang.org/grpc/codes"
+)
+
+// Config is a parsed service config, for programs that need to reason about their own service config.
+type Config struct {
+	// LoadBalancingConfig are the load balancing policies, in order of preference.
+	LoadBalancingConfig []LoadBalancingConfig
+	// LoadBalancingPolicy is the deprecated name of the load balancing policy, or empty when not set.
+	LoadBalancingPolicy string
+	// MethodConfigs are the method configs.
+	MethodConfigs []MethodConfig
+	// RetryThrottling is the retry throttling policy, or nil when not set.
+	RetryThrottling *RetryThrottling
+	// HealthCheckConfig is the client-side health checking config, or nil when not set.
+	HealthCheckConfig *HealthCheckConfig
+}
+
+// LoadBalancingConfig is a load balancing policy and its config.
+type LoadBalancingConfig struct {
+	// Name is the name of the load balancing policy, such as round_robin.
+	Name string
+	// Config is the JSON config of the load balancing policy.
+	Config json.RawMessage
+}
+
+// Name is a name a method config applies to. An empty method applies to every method of the service, and an empty
+// service and method applies to every method without a more specific method config.
+type Name struct {
+	Service string
+	Method  string
+}
+
+// MethodConfig is the config of the methods it applies to.
+type MethodConfig struct {
+	// Names are the names the method config applies to.
+	Names []Name
+	// WaitForReady is whether calls wait for the channel to become ready, or nil when not set.
+	WaitForReady *bool
+	// Timeout is the default timeout of calls, or nil when not set.
+	Timeout *time.Duration
+	// MaxRequestMessageBytes is the maximum size of request messages, or nil when not set.
+	MaxRequestMessageBytes *int
+	// MaxResponseMessageBytes is the maximum size of response messages, or nil when not set.
+	MaxResponseMessageBytes *int
+	// RetryPolicy is the retry policy, or nil when not set.
+	RetryPolicy *RetryPolicy
+	// HedgingPolicy is the hedging policy, or nil when not set.
+	HedgingPolicy *HedgingPolicy
+}
+
+// RetryPolicy is a policy for retrying failed calls.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the original call, as configured.
+	// gRPC limits the number of attempts to 5.
+	MaxAttempts int
+	// InitialBackoff is the upper bound of the randomized backoff before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff is the upper bound of the randomized backoff before any retry.
+	MaxBackoff time.Duration
+	// BackoffMultiplier is the factor the upper bound of the backoff grows with after every retry.
+	BackoffMultiplier float64
+	// RetryableStatusCodes are the status codes calls are retried on.
+	RetryableStatusCodes []codes.Code
+}
+
+// HedgingPolicy is a policy for sending hedged calls, which race each other.
+type HedgingPolicy struct {
+	// MaxAttempts is the maximum number of calls sent, including the original call, as configured.
+	// gRPC limits the number of attempts to 5.
+	MaxAttempts int
+	// HedgingDelay is the delay between sending calls.
+	HedgingDelay time.Duration
+	// NonFatalStatusCodes are the status codes that do not cancel the other calls.
+	NonFatalStatusCodes []codes.Code
+}
+
+// RetryThrottling is a policy for throttling retries and hedged calls when many calls fail.
+type RetryThrottling struct {
+	// MaxTokens is the number of tokens, where retries are throttled when fewer than half of them remain.
+	MaxTokens int
+	// TokenRatio is the number of tokens a successful call adds.
+	TokenRatio float64
+}
+
+// HealthCheckConfig is the config of client-side health checking.
+type HealthCheckConfig struct {
+	// ServiceName is the name of the service to health check.
+	ServiceName string
+}
+
+// Parse parses service config JSON into typed Go structs, without depending on gRPC internals.
+// Durations and numbers are parsed in the protobuf JSON format, and status codes by name or number.
+func Parse(serviceConfig string) (*Config, error) {
+	var content configjson.ServiceConfig
+	if err := json.Unmarshal([]byte(serviceConfig), &content); err != nil {
+		return nil, fmt.Errorf("parse service config: %w", err)
+	}
+	var result Config
+	result.LoadBalancingPolicy = content.LoadBalancingPolicy
+	for i, lbConfig := range content.LoadBalancingConfig {
+		if len(lbConfig) != 1 {
+			return nil, fmt.Errorf("parse service config: loadBalancingConfig[%d]: expected exactly one policy", i)
+		}
+		for name, config := range lbConfig {
+			result.LoadBalancingConfig = append(result.LoadBalancingConfig, LoadBalancingConfig{Name: name, Config: config})
+		}
+	}
+	for i, methodConfig := range content.MethodConfig {
+		path := fmt.Sprintf("methodConfig[%d]", i)
+		parsed := MethodConfig{WaitForReady: methodConfig.WaitForReady}
+		for _, name := range methodConfig.Name {
+			parsed.Names = append(parsed.Names, Name(name))
+		}
+		if methodConfig.Timeout != nil {
+			timeout, err := configjson.ParseDuration(*methodConfig.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("parse service config: %s.timeout: %w", path, err)
+			}
+			parsed.Timeout = &timeout
+		}
+		if methodConfig.MaxRequestMessageBytes != "" {
+			n, err := parseInt(methodConfig.MaxRequestMessageBytes)
+			if err != nil {
+				return nil, fmt.Errorf("parse service config: %s.maxRequestMessageBytes: %w", path, err)
+			}
+			parsed.MaxRequestMessageBytes = &n
+		}
+		if methodConfig.MaxResponseMessageBytes != "" {
+			n, err := parseInt(methodConfig.MaxResponseMessageBytes)
+			if err != nil {
+				return nil, fmt.Errorf("parse service config: %s.maxResponseMessageBytes: %w", path, err)
+			}
+			parsed.MaxResponseMessageBytes = &n
+		}
+		if policy := methodConfig.RetryPolicy; policy != nil {
+			var retryPolicy RetryPolicy
+			var err error
+			if retryPolicy.MaxAttempts, err = parseInt(policy.MaxAttempts); err != nil {
+				return nil, fmt.Errorf("parse service config: %s.retryPolicy.maxAttempts: %w", path, err)
+			}
+			if retryPolicy.InitialBackoff, err = configjson.ParseDuration(policy.InitialBackoff); err != nil {
+				return nil, fmt.Errorf("parse service config: %s.retryPolicy.initialBackoff: %w", path, err)
+			}
+			if retryPolicy.MaxBackoff, err = configjson.ParseDuration(policy.MaxBackoff); err != nil {
+				return nil, fmt.Errorf("parse service config: %s.retryPolicy.maxBackoff: %w", path, err)
+			}
+			if retryPolicy.BackoffMultiplier, err = policy.BackoffMultiplier.Float64(); err != nil {
+				return nil, fmt.Errorf("parse service config: %s.retryPolicy.backoffMultiplier: %w", path, err)
+			}
+			retryPolicy.RetryableStatusCodes = policy.RetryableStatusCodes
+			parsed.RetryPolicy = &retryPolicy
+		}
+		if policy := methodConfig.HedgingPolicy; policy != nil {
+			var hedgingPolicy HedgingPolicy
+			var err error
+			if hedgingPolicy.MaxAttempts, err = parseInt(policy.MaxAttempts); err != nil {
+				return nil, fmt.Errorf("parse service config: %s.hedgingPolicy.maxAttempts: %w", path, err)
+			}
+			if policy.HedgingDelay != nil {
+				if hedgingPolicy.HedgingDelay, err = configjson.ParseDuration(*policy.HedgingDelay); err != nil {
+					return nil, fmt.Errorf("parse service config: %s.hedgingPolicy.hedgingDelay: %w", path, err)
+				}
+			}
+			hedgingPolicy.NonFatalStatusCodes = policy.NonFatalStatusCodes
+			parsed.HedgingPolicy = &hedgingPolicy
+		}
+		result.MethodConfigs = append(result.MethodConfigs, parsed)
+	}
+	if throttling := content.RetryThrottling; throttling != nil {
+		var retryThrottling RetryThrottling
+		var err error
+		if retryThrottling.MaxTokens, err = parseInt(throttling.MaxTokens); err != nil {
+			return nil, fmt.Errorf("parse service config: retryThrottling.maxTokens: %w", err)
+		}
+		if retryThrottling.TokenRatio, err = throttling.TokenRatio.Float64(); err != nil {
+			return nil, fmt.Errorf("parse service config: retryThrottling.tokenRatio: %w", err)
+		}
+		result.RetryThrottling = &retryThrottling
+	}
+	if content.HealthCheckConfig != nil {
+		result.HealthCheckConfig = &HealthCheckConfig{ServiceName: content.HealthCheckConfig.ServiceName}
+	}
+	return &result, nil
+}
+
+// parseInt parses a JSON number as an int.
+func parseInt(n json.Number) (int, error) {
+	value, err := n.Int64()
+	if err != nil {
+		return 0, err
+	}
+	return int(value), nil
+}
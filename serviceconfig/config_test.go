@@ -0,0 +1,113 @@
+package serviceconfig
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestParse(t *testing.T) {
+	const serviceConfig = `{
+  "loadBalancingConfig": [{"round_robin": {}}],
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s", "waitForReady": true},
+    {
+      "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+      "timeout": "1.5s",
+      "maxRequestMessageBytes": "1024",
+      "retryPolicy": {
+        "maxAttempts": 3,
+        "initialBackoff": "0.1s",
+        "maxBackoff": "1s",
+        "backoffMultiplier": 2,
+        "retryableStatusCodes": ["UNAVAILABLE"]
+      }
+    },
+    {
+      "name": [{"service": "einride.example.freight.v1.FreightService", "method": "ListShippers"}],
+      "hedgingPolicy": {"maxAttempts": "2", "hedgingDelay": "0.5s", "nonFatalStatusCodes": ["UNAVAILABLE"]}
+    }
+  ],
+  "retryThrottling": {"maxTokens": 10, "tokenRatio": 0.1},
+  "healthCheckConfig": {"serviceName": "einride.example.freight.v1.FreightService"}
+}`
+	config, err := Parse(serviceConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForReady := true
+	defaultTimeout, timeout := 10*time.Second, 1500*time.Millisecond
+	maxRequestMessageBytes := 1024
+	expected := &Config{
+		LoadBalancingConfig: []LoadBalancingConfig{{Name: "round_robin", Config: json.RawMessage(`{}`)}},
+		MethodConfigs: []MethodConfig{
+			{Names: []Name{{}}, WaitForReady: &waitForReady, Timeout: &defaultTimeout},
+			{
+				Names:                  []Name{{Service: "einride.example.freight.v1.FreightService", Method: "GetShipper"}},
+				Timeout:                &timeout,
+				MaxRequestMessageBytes: &maxRequestMessageBytes,
+				RetryPolicy: &RetryPolicy{
+					MaxAttempts:          3,
+					InitialBackoff:       100 * time.Millisecond,
+					MaxBackoff:           time.Second,
+					BackoffMultiplier:    2,
+					RetryableStatusCodes: []codes.Code{codes.Unavailable},
+				},
+			},
+			{
+				Names: []Name{{Service: "einride.example.freight.v1.FreightService", Method: "ListShippers"}},
+				HedgingPolicy: &HedgingPolicy{
+					MaxAttempts:         2,
+					HedgingDelay:        500 * time.Millisecond,
+					NonFatalStatusCodes: []codes.Code{codes.Unavailable},
+				},
+			},
+		},
+		RetryThrottling:   &RetryThrottling{MaxTokens: 10, TokenRatio: 0.1},
+		HealthCheckConfig: &HealthCheckConfig{ServiceName: "einride.example.freight.v1.FreightService"},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("expected:\n%+v\ngot:\n%+v", expected, config)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		serviceConfig string
+		expected      string
+	}{
+		{
+			name:          "invalid JSON",
+			serviceConfig: `{`,
+			expected:      "parse service config: unexpected end of JSON input",
+		},
+		{
+			name:          "invalid timeout",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "forever"}]}`,
+			expected:      "parse service config: methodConfig[0].timeout: ",
+		},
+		{
+			name:          "invalid max attempts",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "retryPolicy": {"maxAttempts": 2.5}}]}`,
+			expected:      "parse service config: methodConfig[0].retryPolicy.maxAttempts: ",
+		},
+		{
+			name:          "several load balancing policies",
+			serviceConfig: `{"loadBalancingConfig": [{"round_robin": {}, "pick_first": {}}]}`,
+			expected:      "parse service config: loadBalancingConfig[0]: expected exactly one policy",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.serviceConfig)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.expected) {
+				t.Errorf("expected error starting with %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
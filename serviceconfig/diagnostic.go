@@ -0,0 +1,58 @@
+package serviceconfig
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Severity is the severity of a diagnostic.
+type Severity int
+
+const (
+	// SeverityInfo is an informational diagnostic that never fails validation.
+	SeverityInfo Severity = iota + 1
+	// SeverityWarning is a diagnostic that does not fail validation, unless running in strict mode.
+	SeverityWarning
+	// SeverityError is a diagnostic that fails validation.
+	SeverityError
+)
+
+// String implements fmt.Stringer.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// Diagnostic is a problem found during validation.
+type Diagnostic struct {
+	// Rule is the ID of the rule that found the problem, such as INVALID_SERVICE_CONFIG.
+	Rule string
+	// Severity is the severity of the diagnostic.
+	Severity Severity
+	// File is the path of the file the problem was found in.
+	File string
+	// Line is the 1-based line number, or 0 when unknown.
+	Line int
+	// Column is the 1-based column number, or 0 when unknown.
+	Column int
+	// JSONPath is the JSON path of the problematic value, such as `methodConfig[0].timeout`, or empty when unknown.
+	JSONPath string
+	// Message describes the problem.
+	Message string
+}
+
+// String implements fmt.Stringer.
+func (d Diagnostic) String() string {
+	location := d.File
+	if d.Line != 0 {
+		location += ":" + strconv.Itoa(d.Line) + ":" + strconv.Itoa(d.Column)
+	}
+	return fmt.Sprintf("%s: %s: %s (%s)", location, d.Severity, d.Message, d.Rule)
+}
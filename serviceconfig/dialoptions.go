@@ -0,0 +1,85 @@
+package serviceconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+	"google.golang.org/grpc"
+)
+
+// Override is a targeted change to the method config of a name, applied by DialOptions, such as bumping the timeout
+// of one method in a canary. The method config the name resolves to is copied into a new method config for exactly
+// that name, so that the other methods it applies to are unaffected.
+type Override struct {
+	name   Name
+	fields map[string]json.RawMessage
+}
+
+// OverrideTimeout overrides the timeout of the method config of the name.
+func OverrideTimeout(name Name, timeout time.Duration) Override {
+	return Override{name: name, fields: map[string]json.RawMessage{
+		"timeout": json.RawMessage(strconv.Quote(configjson.FormatDuration(timeout))),
+	}}
+}
+
+// OverrideWaitForReady overrides whether calls wait for the channel to become ready for the method config of the
+// name.
+func OverrideWaitForReady(name Name, waitForReady bool) Override {
+	return Override{name: name, fields: map[string]json.RawMessage{
+		"waitForReady": json.RawMessage(strconv.FormatBool(waitForReady)),
+	}}
+}
+
+// OverrideMaxRequestMessageBytes overrides the maximum size of request messages of the method config of the name.
+func OverrideMaxRequestMessageBytes(name Name, n int) Override {
+	return Override{name: name, fields: map[string]json.RawMessage{
+		"maxRequestMessageBytes": json.RawMessage(strconv.Itoa(n)),
+	}}
+}
+
+// OverrideMaxResponseMessageBytes overrides the maximum size of response messages of the method config of the name.
+func OverrideMaxResponseMessageBytes(name Name, n int) Override {
+	return Override{name: name, fields: map[string]json.RawMessage{
+		"maxResponseMessageBytes": json.RawMessage(strconv.Itoa(n)),
+	}}
+}
+
+// OverrideNoRetries removes the retry policy and hedging policy of the method config of the name.
+func OverrideNoRetries(name Name) Override {
+	return Override{name: name, fields: map[string]json.RawMessage{
+		"retryPolicy":   nil,
+		"hedgingPolicy": nil,
+	}}
+}
+
+// Apply returns the service config JSON with the overrides applied, in order.
+func Apply(serviceConfig string, overrides ...Override) (string, error) {
+	for _, override := range overrides {
+		var err error
+		serviceConfig, err = configjson.OverrideMethodConfig(
+			serviceConfig,
+			configjson.Name{Service: override.name.Service, Method: override.name.Method},
+			override.fields,
+		)
+		if err != nil {
+			return "", err
+		}
+	}
+	return serviceConfig, nil
+}
+
+// DialOptions returns the dial options that apply the service config JSON with the overrides applied, in order.
+// The service config is parsed up front, since gRPC only reports an invalid default service config when dialing.
+func DialOptions(serviceConfig string, overrides ...Override) ([]grpc.DialOption, error) {
+	serviceConfig, err := Apply(serviceConfig, overrides...)
+	if err != nil {
+		return nil, fmt.Errorf("dial options: %w", err)
+	}
+	if _, err := Parse(serviceConfig); err != nil {
+		return nil, fmt.Errorf("dial options: %w", err)
+	}
+	return []grpc.DialOption{grpc.WithDefaultServiceConfig(serviceConfig)}, nil
+}
@@ -0,0 +1,134 @@
+package serviceconfig
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestApply(t *testing.T) {
+	const serviceConfig = `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s"},
+    {
+      "name": [
+        {"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"},
+        {"service": "einride.example.freight.v1.FreightService", "method": "ListShippers"}
+      ],
+      "timeout": "1s",
+      "retryPolicy": {
+        "maxAttempts": 3,
+        "initialBackoff": "0.1s",
+        "maxBackoff": "1s",
+        "backoffMultiplier": 2,
+        "retryableStatusCodes": ["UNAVAILABLE"]
+      }
+    }
+  ]
+}`
+	getShipper := Name{Service: "einride.example.freight.v1.FreightService", Method: "GetShipper"}
+	listShippers := Name{Service: "einride.example.freight.v1.FreightService", Method: "ListShippers"}
+	freightService := Name{Service: "einride.example.freight.v1.FreightService"}
+	for _, tt := range []struct {
+		name      string
+		overrides []Override
+		// expected are the timeouts and whether a retry policy applies, by name.
+		expected map[Name]expectedMethodConfig
+	}{
+		{
+			name: "no overrides",
+			expected: map[Name]expectedMethodConfig{
+				getShipper:     {timeout: time.Second, retries: true},
+				listShippers:   {timeout: time.Second, retries: true},
+				freightService: {timeout: 10 * time.Second},
+			},
+		},
+		{
+			name:      "timeout of one method",
+			overrides: []Override{OverrideTimeout(getShipper, 30*time.Second)},
+			expected: map[Name]expectedMethodConfig{
+				getShipper:     {timeout: 30 * time.Second, retries: true},
+				listShippers:   {timeout: time.Second, retries: true},
+				freightService: {timeout: 10 * time.Second},
+			},
+		},
+		{
+			name:      "no retries for one method",
+			overrides: []Override{OverrideNoRetries(listShippers)},
+			expected: map[Name]expectedMethodConfig{
+				getShipper:     {timeout: time.Second, retries: true},
+				listShippers:   {timeout: time.Second},
+				freightService: {timeout: 10 * time.Second},
+			},
+		},
+		{
+			name:      "timeout of service",
+			overrides: []Override{OverrideTimeout(freightService, 5*time.Second)},
+			expected: map[Name]expectedMethodConfig{
+				getShipper:     {timeout: time.Second, retries: true},
+				listShippers:   {timeout: time.Second, retries: true},
+				freightService: {timeout: 5 * time.Second},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Apply(serviceConfig, tt.overrides...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			config, err := Parse(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := map[Name]expectedMethodConfig{}
+			for name := range tt.expected {
+				methodConfig := lookupTestMethodConfig(config, name)
+				if methodConfig == nil || methodConfig.Timeout == nil {
+					t.Fatalf("%v: no timeout in %s", name, result)
+				}
+				actual[name] = expectedMethodConfig{timeout: *methodConfig.Timeout, retries: methodConfig.RetryPolicy != nil}
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("expected %v, got %v in:\n%s", tt.expected, actual, result)
+			}
+		})
+	}
+}
+
+// expectedMethodConfig is the timeout of a method config and whether it has a retry policy.
+type expectedMethodConfig struct {
+	timeout time.Duration
+	retries bool
+}
+
+// lookupTestMethodConfig returns the method config the name resolves to, following the gRPC matching rules.
+func lookupTestMethodConfig(config *Config, name Name) *MethodConfig {
+	candidates := []Name{name, {Service: name.Service}, {}}
+	for _, candidate := range candidates {
+		for i := range config.MethodConfigs {
+			for _, methodConfigName := range config.MethodConfigs[i].Names {
+				if methodConfigName == candidate {
+					return &config.MethodConfigs[i]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func TestDialOptions(t *testing.T) {
+	opts, err := DialOptions(`{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts) != 1 {
+		t.Errorf("expected 1 dial option, got %d", len(opts))
+	}
+	if _, err := DialOptions(`{"methodConfig": [{"name": [{}], "timeout": "forever"}]}`); err == nil {
+		t.Error("expected error for invalid service config")
+	}
+	if _, err := DialOptions(`{}`, OverrideTimeout(Name{Method: "GetShipper"}, time.Second)); err == nil {
+		t.Error("expected error for method override without service")
+	}
+}
@@ -0,0 +1,257 @@
+package serviceconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/codes"
+)
+
+// unset is the value of fields that are not set.
+const unset = "<unset>"
+
+// Drift is a difference between the method config of a method in a service config, and the method config a
+// connection actually uses for the method.
+type Drift struct {
+	// Method is the full method name, such as "/example.v1.ExampleService/GetExample".
+	Method string
+	// Field is the name of the method config field, such as "timeout".
+	Field string
+	// Expected is the value of the field in the service config.
+	Expected string
+	// Actual is the value of the field the connection uses.
+	Actual string
+}
+
+// String returns a description of the drift.
+func (d Drift) String() string {
+	return fmt.Sprintf("%s: %s: expected %s, got %s", d.Method, d.Field, d.Expected, d.Actual)
+}
+
+// DetectDrift compares the method configs of the full method names, such as "/example.v1.ExampleService/GetExample",
+// in the service config JSON with the method configs the connection actually uses, and returns the differences.
+// Drift typically means that the name resolver returned a service config that overrides the bundled default.
+// gRPC Go does not implement hedging, so a hedging policy in the service config is always reported as drift.
+func DetectDrift(conn *grpc.ClientConn, serviceConfig string, fullMethods ...string) ([]Drift, error) {
+	return detectDrift(serviceConfig, fullMethods, conn.GetMethodConfig)
+}
+
+// DetectDriftChannelz is like DetectDrift, but inspects the channel with the channelz ID through a channelz service,
+// such as the channelz service of another process. The method configs are read from the last service config the
+// name resolver returned, as recorded in the channel trace, so it fails when the resolver returned no service config
+// or the update is no longer in the trace.
+func DetectDriftChannelz(
+	ctx context.Context,
+	client channelzpb.ChannelzClient,
+	channelID int64,
+	serviceConfig string,
+	fullMethods ...string,
+) ([]Drift, error) {
+	response, err := client.GetChannel(ctx, &channelzpb.GetChannelRequest{ChannelId: channelID})
+	if err != nil {
+		return nil, fmt.Errorf("detect drift of channel %d: %w", channelID, err)
+	}
+	methodConfigs, err := resolvedMethodConfigs(response.GetChannel().GetData().GetTrace())
+	if err != nil {
+		return nil, fmt.Errorf("detect drift of channel %d: %w", channelID, err)
+	}
+	return detectDrift(serviceConfig, fullMethods, func(fullMethod string) grpc.MethodConfig {
+		service, _, _ := splitFullMethod(fullMethod)
+		for _, key := range []string{fullMethod, "/" + service + "/", ""} {
+			if methodConfig, ok := methodConfigs[key]; ok {
+				return methodConfig
+			}
+		}
+		return grpc.MethodConfig{}
+	})
+}
+
+// resolverStateUpdatedPrefix is the prefix of the channel trace events gRPC Go records for resolver updates, which
+// is followed by the resolver state as JSON.
+const resolverStateUpdatedPrefix = "Resolver state updated: "
+
+// resolvedMethodConfigs returns the method configs of the last service config the name resolver returned, recorded in
+// the channel trace, keyed by "/service/method", "/service/", or "" for the default.
+func resolvedMethodConfigs(trace *channelzpb.ChannelTrace) (map[string]grpc.MethodConfig, error) {
+	events := trace.GetEvents()
+	for i := len(events) - 1; i >= 0; i-- {
+		description := events[i].GetDescription()
+		if !strings.HasPrefix(description, resolverStateUpdatedPrefix) {
+			continue
+		}
+		var state struct {
+			ServiceConfig *struct {
+				Config *struct {
+					Methods map[string]grpc.MethodConfig
+				}
+				Err json.RawMessage
+			}
+		}
+		// The resolver state is followed by a summary of the update, which the decoder does not read.
+		decoder := json.NewDecoder(strings.NewReader(strings.TrimPrefix(description, resolverStateUpdatedPrefix)))
+		if err := decoder.Decode(&state); err != nil {
+			return nil, fmt.Errorf("parse resolver state in channel trace: %w", err)
+		}
+		if state.ServiceConfig == nil || state.ServiceConfig.Config == nil {
+			return nil, fmt.Errorf("the name resolver returned no valid service config")
+		}
+		return state.ServiceConfig.Config.Methods, nil
+	}
+	return nil, fmt.Errorf("no resolver state in channel trace")
+}
+
+// detectDrift compares the method configs of the full method names in the service config JSON with the method configs
+// returned by methodConfigFor.
+func detectDrift(
+	serviceConfig string,
+	fullMethods []string,
+	methodConfigFor func(fullMethod string) grpc.MethodConfig,
+) ([]Drift, error) {
+	config, err := Parse(serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+	var result []Drift
+	for _, fullMethod := range fullMethods {
+		expected, ok, err := config.MethodConfigFor(fullMethod)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			expected = &MethodConfig{}
+		}
+		actual := methodConfigFor(fullMethod)
+		var actualRetryPolicy *RetryPolicy
+		if actual.RetryPolicy != nil {
+			actualRetryPolicy = &RetryPolicy{
+				MaxAttempts:       actual.RetryPolicy.MaxAttempts,
+				InitialBackoff:    actual.RetryPolicy.InitialBackoff,
+				MaxBackoff:        actual.RetryPolicy.MaxBackoff,
+				BackoffMultiplier: actual.RetryPolicy.BackoffMultiplier,
+			}
+			for code := range actual.RetryPolicy.RetryableStatusCodes {
+				actualRetryPolicy.RetryableStatusCodes = append(actualRetryPolicy.RetryableStatusCodes, code)
+			}
+		}
+		for _, field := range []struct {
+			name             string
+			expected, actual string
+		}{
+			{name: "waitForReady", expected: formatBool(expected.WaitForReady), actual: formatBool(actual.WaitForReady)},
+			{name: "timeout", expected: formatTimeout(expected.Timeout), actual: formatTimeout(actual.Timeout)},
+			{
+				name:     "maxRequestMessageBytes",
+				expected: formatInt(expected.MaxRequestMessageBytes),
+				actual:   formatInt(actual.MaxReqSize),
+			},
+			{
+				name:     "maxResponseMessageBytes",
+				expected: formatInt(expected.MaxResponseMessageBytes),
+				actual:   formatInt(actual.MaxRespSize),
+			},
+			{
+				name:     "retryPolicy",
+				expected: formatRetryPolicy(expected.RetryPolicy),
+				actual:   formatRetryPolicy(actualRetryPolicy),
+			},
+			// gRPC Go does not implement hedging, so connections never hedge calls.
+			{name: "hedgingPolicy", expected: formatHedgingPolicy(expected.HedgingPolicy), actual: unset},
+		} {
+			if field.expected != field.actual {
+				result = append(result, Drift{
+					Method:   fullMethod,
+					Field:    field.name,
+					Expected: field.expected,
+					Actual:   field.actual,
+				})
+			}
+		}
+	}
+	return result, nil
+}
+
+// FullMethods returns the full method names of the methods and streams of a service, for detecting drift.
+func FullMethods(desc *grpc.ServiceDesc) []string {
+	result := make([]string, 0, len(desc.Methods)+len(desc.Streams))
+	for _, method := range desc.Methods {
+		result = append(result, "/"+desc.ServiceName+"/"+method.MethodName)
+	}
+	for _, stream := range desc.Streams {
+		result = append(result, "/"+desc.ServiceName+"/"+stream.StreamName)
+	}
+	return result
+}
+
+func formatBool(b *bool) string {
+	if b == nil {
+		return unset
+	}
+	return strconv.FormatBool(*b)
+}
+
+func formatTimeout(d *time.Duration) string {
+	if d == nil {
+		return unset
+	}
+	return configjson.FormatDuration(*d)
+}
+
+func formatInt(n *int) string {
+	if n == nil {
+		return unset
+	}
+	return strconv.Itoa(*n)
+}
+
+// formatRetryPolicy formats a retry policy, with the gRPC limit of attempts applied and status codes sorted, so that
+// equivalent retry policies are formatted the same.
+func formatRetryPolicy(p *RetryPolicy) string {
+	if p == nil {
+		return unset
+	}
+	return fmt.Sprintf(
+		"{maxAttempts: %d, initialBackoff: %s, maxBackoff: %s, backoffMultiplier: %s, retryableStatusCodes: [%s]}",
+		p.EffectiveMaxAttempts(),
+		configjson.FormatDuration(p.InitialBackoff),
+		configjson.FormatDuration(p.MaxBackoff),
+		strconv.FormatFloat(p.BackoffMultiplier, 'g', -1, 64),
+		formatStatusCodes(p.RetryableStatusCodes),
+	)
+}
+
+// formatHedgingPolicy formats a hedging policy like formatRetryPolicy.
+func formatHedgingPolicy(p *HedgingPolicy) string {
+	if p == nil {
+		return unset
+	}
+	return fmt.Sprintf(
+		"{maxAttempts: %d, hedgingDelay: %s, nonFatalStatusCodes: [%s]}",
+		p.EffectiveMaxAttempts(),
+		configjson.FormatDuration(p.HedgingDelay),
+		formatStatusCodes(p.NonFatalStatusCodes),
+	)
+}
+
+// formatStatusCodes formats the sorted, deduplicated names of status codes.
+func formatStatusCodes(statusCodes []codes.Code) string {
+	statusCodes = append([]codes.Code(nil), statusCodes...)
+	sort.Slice(statusCodes, func(i, j int) bool {
+		return statusCodes[i] < statusCodes[j]
+	})
+	names := make([]string, 0, len(statusCodes))
+	for i, code := range statusCodes {
+		if i > 0 && code == statusCodes[i-1] {
+			continue
+		}
+		names = append(names, configjson.StatusCodeName(code))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,240 @@
+package serviceconfig
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestDetectDrift(t *testing.T) {
+	const serviceConfig = `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s"},
+    {
+      "name": [{"service": "grpc.health.v1.Health", "method": "Check"}],
+      "timeout": "1s",
+      "retryPolicy": {
+        "maxAttempts": 3,
+        "initialBackoff": "0.1s",
+        "maxBackoff": "1s",
+        "backoffMultiplier": 2,
+        "retryableStatusCodes": ["UNAVAILABLE", "ABORTED"]
+      }
+    }
+  ]
+}`
+	const connServiceConfig = `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s", "maxRequestMessageBytes": 1024},
+    {
+      "name": [{"service": "grpc.health.v1.Health", "method": "Check"}],
+      "timeout": "2s",
+      "retryPolicy": {
+        "maxAttempts": 3,
+        "initialBackoff": "0.1s",
+        "maxBackoff": "1s",
+        "backoffMultiplier": 2,
+        "retryableStatusCodes": ["ABORTED", "UNAVAILABLE"]
+      }
+    }
+  ]
+}`
+	const hedgingServiceConfig = `{
+  "methodConfig": [
+    {
+      "name": [{"service": "grpc.health.v1.Health", "method": "Check"}],
+      "hedgingPolicy": {"maxAttempts": 3, "hedgingDelay": "0.5s", "nonFatalStatusCodes": ["UNAVAILABLE"]}
+    }
+  ]
+}`
+	for _, tt := range []struct {
+		name              string
+		serviceConfig     string
+		connServiceConfig string
+		expected          []Drift
+	}{
+		{
+			name:              "no drift",
+			serviceConfig:     serviceConfig,
+			connServiceConfig: serviceConfig,
+		},
+		{
+			name:              "drift",
+			serviceConfig:     serviceConfig,
+			connServiceConfig: connServiceConfig,
+			expected: []Drift{
+				{Method: "/grpc.health.v1.Health/Check", Field: "timeout", Expected: "1s", Actual: "2s"},
+				{Method: "/grpc.health.v1.Health/Watch", Field: "maxRequestMessageBytes", Expected: unset, Actual: "1024"},
+			},
+		},
+		{
+			name:              "hedging",
+			serviceConfig:     hedgingServiceConfig,
+			connServiceConfig: hedgingServiceConfig,
+			expected: []Drift{
+				{
+					Method:   "/grpc.health.v1.Health/Check",
+					Field:    "hedgingPolicy",
+					Expected: "{maxAttempts: 3, hedgingDelay: 0.5s, nonFatalStatusCodes: [UNAVAILABLE]}",
+					Actual:   unset,
+				},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := grpc.Dial(
+				"passthrough:///localhost:0",
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+				grpc.WithDefaultServiceConfig(tt.connServiceConfig),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			drifts, err := DetectDrift(conn, tt.serviceConfig, FullMethods(&grpc_health_v1.Health_ServiceDesc)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(drifts, tt.expected) {
+				t.Errorf("expected drifts %v, got %v", tt.expected, drifts)
+			}
+		})
+	}
+}
+
+func TestFullMethods(t *testing.T) {
+	expected := []string{"/grpc.health.v1.Health/Check", "/grpc.health.v1.Health/Watch"}
+	if actual := FullMethods(&grpc_health_v1.Health_ServiceDesc); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestDetectDriftChannelz(t *testing.T) {
+	const serviceConfig = `{"methodConfig": [{"name": [{"service": "grpc.health.v1.Health"}], "timeout": "1s"}]}`
+	const resolvedServiceConfig = `{
+  "methodConfig": [
+    {"name": [{"service": "grpc.health.v1.Health"}], "timeout": "1s"},
+    {
+      "name": [{"service": "grpc.health.v1.Health", "method": "Watch"}],
+      "timeout": "2s",
+      "retryPolicy": {
+        "maxAttempts": 3,
+        "initialBackoff": "0.1s",
+        "maxBackoff": "1s",
+        "backoffMultiplier": 2,
+        "retryableStatusCodes": ["UNAVAILABLE"]
+      }
+    }
+  ]
+}`
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	// Registering the channelz service turns on channelz, which records the channel traces.
+	service.RegisterChannelzServiceToServer(server)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+	channelzConn, err := grpc.Dial(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer channelzConn.Close()
+	client := channelzpb.NewChannelzClient(channelzConn)
+	for _, tt := range []struct {
+		name          string
+		serviceConfig string
+		expected      []Drift
+		err           string
+	}{
+		{
+			name:          "drift",
+			serviceConfig: resolvedServiceConfig,
+			expected: []Drift{
+				{Method: "/grpc.health.v1.Health/Watch", Field: "timeout", Expected: "1s", Actual: "2s"},
+				{
+					Method:   "/grpc.health.v1.Health/Watch",
+					Field:    "retryPolicy",
+					Expected: unset,
+					Actual: "{maxAttempts: 3, initialBackoff: 0.1s, maxBackoff: 1s, backoffMultiplier: 2, " +
+						"retryableStatusCodes: [UNAVAILABLE]}",
+				},
+			},
+		},
+		{
+			name:          "no drift",
+			serviceConfig: serviceConfig,
+		},
+		{
+			name: "no resolver service config",
+			err:  "the name resolver returned no valid service config",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r := manual.NewBuilderWithScheme("drift-channelz")
+			target := r.Scheme() + ":///" + strings.ReplaceAll(tt.name, " ", "-")
+			conn, err := grpc.Dial(target, grpc.WithResolvers(r), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			state := resolver.State{}
+			if tt.serviceConfig != "" {
+				state.ServiceConfig = r.CC.ParseServiceConfig(tt.serviceConfig)
+			}
+			r.UpdateState(state)
+			ctx := context.Background()
+			channelID := testChannelID(ctx, t, client, target)
+			drifts, err := DetectDriftChannelz(
+				ctx, client, channelID, serviceConfig, FullMethods(&grpc_health_v1.Health_ServiceDesc)...,
+			)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(drifts, tt.expected) {
+				t.Errorf("expected drifts %v, got %v", tt.expected, drifts)
+			}
+		})
+	}
+}
+
+// testChannelID returns the channelz ID of the top channel with the target.
+func testChannelID(ctx context.Context, t *testing.T, client channelzpb.ChannelzClient, target string) int64 {
+	t.Helper()
+	response, err := client.GetTopChannels(ctx, &channelzpb.GetTopChannelsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, channel := range response.GetChannel() {
+		if channel.GetData().GetTarget() == target {
+			return channel.GetRef().GetChannelId()
+		}
+	}
+	t.Fatalf("no channel with target %s", target)
+	return 0
+}
@@ -0,0 +1,13 @@
+package serviceconfig
+
+import "os"
+
+// EnvironmentVariable is the environment variable that selects the environment of per-environment service configs.
+const EnvironmentVariable = "GRPC_SERVICE_CONFIG_ENV"
+
+// ForEnvironment returns the service config of the environment in the EnvironmentVariable, with the generated
+// ServiceConfigFor function of a package, so that binaries pick the variant of their environment without a switch
+// statement in every client package. An unset EnvironmentVariable returns the ServiceConfig constant.
+func ForEnvironment(serviceConfigFor func(environment string) (string, error)) (string, error) {
+	return serviceConfigFor(os.Getenv(EnvironmentVariable))
+}
@@ -0,0 +1,39 @@
+package serviceconfig
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestForEnvironment(t *testing.T) {
+	serviceConfigFor := func(environment string) (string, error) {
+		switch environment {
+		case "":
+			return "default", nil
+		case "staging":
+			return "staging", nil
+		}
+		return "", fmt.Errorf("no service config for environment %q", environment)
+	}
+	for _, tt := range []struct {
+		environment string
+		expected    string
+		err         bool
+	}{
+		{environment: "", expected: "default"},
+		{environment: "staging", expected: "staging"},
+		{environment: "production", err: true},
+	} {
+		tt := tt
+		t.Run(tt.environment, func(t *testing.T) {
+			t.Setenv(EnvironmentVariable, tt.environment)
+			actual, err := ForEnvironment(serviceConfigFor)
+			if (err != nil) != tt.err {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package serviceconfig
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MethodConfigFor returns the method config that applies to the full method name, such as
+// "/example.v1.ExampleService/GetExample", following the gRPC matching rules: a name matching the method takes
+// precedence over a name matching the service, which takes precedence over the default name.
+// It returns false when no method config applies.
+func (c *Config) MethodConfigFor(fullMethod string) (*MethodConfig, bool, error) {
+	service, method, err := splitFullMethod(fullMethod)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, candidate := range []Name{{Service: service, Method: method}, {Service: service}, {}} {
+		for i := range c.MethodConfigs {
+			for _, name := range c.MethodConfigs[i].Names {
+				if name == candidate {
+					return &c.MethodConfigs[i], true, nil
+				}
+			}
+		}
+	}
+	return nil, false, nil
+}
+
+// TimeoutFor returns the timeout of the full method name, such as "/example.v1.ExampleService/GetExample", in the
+// service config JSON, with the same matching rules as gRPC, so that servers and client wrappers derive deadlines
+// from the same resolution as the client. It returns false when the method has no timeout.
+// The service config is parsed on every call, so programs that look up timeouts per call parse it once with Parse and
+// use Config.TimeoutFor instead.
+func TimeoutFor(serviceConfig, fullMethod string) (time.Duration, bool, error) {
+	config, err := Parse(serviceConfig)
+	if err != nil {
+		return 0, false, err
+	}
+	return config.TimeoutFor(fullMethod)
+}
+
+// TimeoutFor returns the timeout of the full method name, such as "/example.v1.ExampleService/GetExample", with the
+// same matching rules as gRPC. It returns false when the method has no timeout.
+// Like gRPC, it does not fall back to a less specific method config without a timeout.
+func (c *Config) TimeoutFor(fullMethod string) (time.Duration, bool, error) {
+	methodConfig, ok, err := c.MethodConfigFor(fullMethod)
+	if err != nil || !ok || methodConfig.Timeout == nil {
+		return 0, false, err
+	}
+	return *methodConfig.Timeout, true, nil
+}
+
+// splitFullMethod splits a full method name, with or without the leading slash, into its service and method.
+func splitFullMethod(fullMethod string) (string, string, error) {
+	parts := strings.Split(strings.TrimPrefix(fullMethod, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid full method name %q, expected /package.Service/Method", fullMethod)
+	}
+	return parts[0], parts[1], nil
+}
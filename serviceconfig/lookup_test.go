@@ -0,0 +1,83 @@
+package serviceconfig
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeoutFor(t *testing.T) {
+	const serviceConfig = `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s"},
+    {"name": [{"service": "einride.example.freight.v1.FreightService"}], "timeout": "5s"},
+    {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}], "timeout": "1s"},
+    {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "WatchShippers"}]}
+  ]
+}`
+	config, err := Parse(serviceConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name       string
+		fullMethod string
+		expected   time.Duration
+		expectedOK bool
+		err        bool
+	}{
+		{
+			name:       "method",
+			fullMethod: "/einride.example.freight.v1.FreightService/GetShipper",
+			expected:   time.Second,
+			expectedOK: true,
+		},
+		{
+			name:       "method without leading slash",
+			fullMethod: "einride.example.freight.v1.FreightService/GetShipper",
+			expected:   time.Second,
+			expectedOK: true,
+		},
+		{
+			name:       "service",
+			fullMethod: "/einride.example.freight.v1.FreightService/ListShippers",
+			expected:   5 * time.Second,
+			expectedOK: true,
+		},
+		{
+			name:       "default",
+			fullMethod: "/einride.example.shipper.v1.ShipperService/GetShipper",
+			expected:   10 * time.Second,
+			expectedOK: true,
+		},
+		{
+			name:       "method config without timeout",
+			fullMethod: "/einride.example.freight.v1.FreightService/WatchShippers",
+		},
+		{
+			name:       "invalid full method",
+			fullMethod: "/einride.example.freight.v1.FreightService",
+			err:        true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			timeout, ok, err := TimeoutFor(serviceConfig, tt.fullMethod)
+			if (err != nil) != tt.err {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if timeout != tt.expected || ok != tt.expectedOK {
+				t.Errorf("expected %v %v, got %v %v", tt.expected, tt.expectedOK, timeout, ok)
+			}
+			parsedTimeout, parsedOK, parsedErr := config.TimeoutFor(tt.fullMethod)
+			if parsedTimeout != timeout || parsedOK != ok || (parsedErr != nil) != (err != nil) {
+				t.Errorf(
+					"expected Config.TimeoutFor to return %v %v %v, got %v %v %v",
+					timeout, ok, err, parsedTimeout, parsedOK, parsedErr,
+				)
+			}
+		})
+	}
+	if _, ok, err := TimeoutFor(`{}`, "/einride.example.freight.v1.FreightService/GetShipper"); ok || err != nil {
+		t.Errorf("expected no timeout without method configs, got %v %v", ok, err)
+	}
+}
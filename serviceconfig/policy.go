@@ -0,0 +1,104 @@
+package serviceconfig
+
+import (
+	"math"
+	"time"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+)
+
+// MaxAttemptsLimit is the limit of retry and hedging attempts in gRPC. Policies with more attempts are limited to it.
+const MaxAttemptsLimit = configjson.MaxAttemptsLimit
+
+// CallPolicy is the effective retry or hedging policy of a method, for annotating calls with how they are expected to
+// be retried.
+type CallPolicy struct {
+	// RetryPolicy is the retry policy of the method, or nil when calls are not retried.
+	RetryPolicy *RetryPolicy
+	// HedgingPolicy is the hedging policy of the method, or nil when calls are not hedged.
+	HedgingPolicy *HedgingPolicy
+	// RetryThrottling is the retry throttling policy of the service config, or nil when not set.
+	RetryThrottling *RetryThrottling
+}
+
+// MaxAttempts returns the maximum number of attempts of a call, including the original call, after applying the
+// gRPC limit. It returns 1 when calls are neither retried nor hedged.
+func (p CallPolicy) MaxAttempts() int {
+	switch {
+	case p.RetryPolicy != nil:
+		return p.RetryPolicy.EffectiveMaxAttempts()
+	case p.HedgingPolicy != nil:
+		return p.HedgingPolicy.EffectiveMaxAttempts()
+	}
+	return 1
+}
+
+// EffectiveMaxAttempts returns the maximum number of attempts, including the original call, after applying the gRPC
+// limit.
+func (p *RetryPolicy) EffectiveMaxAttempts() int {
+	return effectiveMaxAttempts(p.MaxAttempts)
+}
+
+// BackoffWindows returns the upper bound of the randomized backoff before each retry. gRPC waits a random backoff up to
+// min(InitialBackoff*BackoffMultiplier^(n-1), MaxBackoff) before retry n.
+func (p *RetryPolicy) BackoffWindows() []time.Duration {
+	var result []time.Duration
+	for n := 1; n < p.EffectiveMaxAttempts(); n++ {
+		result = append(result, time.Duration(math.Min(
+			float64(p.InitialBackoff)*math.Pow(p.BackoffMultiplier, float64(n-1)),
+			float64(p.MaxBackoff),
+		)))
+	}
+	return result
+}
+
+// EffectiveMaxAttempts returns the maximum number of calls sent, including the original call, after applying the
+// gRPC limit.
+func (p *HedgingPolicy) EffectiveMaxAttempts() int {
+	return effectiveMaxAttempts(p.MaxAttempts)
+}
+
+// HedgingDelays returns the delay after the original call before each hedged call is sent, unless a call has
+// completed or failed with a fatal status code before.
+func (p *HedgingPolicy) HedgingDelays() []time.Duration {
+	var result []time.Duration
+	for n := 1; n < p.EffectiveMaxAttempts(); n++ {
+		result = append(result, time.Duration(n)*p.HedgingDelay)
+	}
+	return result
+}
+
+// effectiveMaxAttempts applies the gRPC limit to a configured maximum number of attempts.
+func effectiveMaxAttempts(maxAttempts int) int {
+	if maxAttempts > MaxAttemptsLimit {
+		return MaxAttemptsLimit
+	}
+	return maxAttempts
+}
+
+// PolicyFor returns the effective retry or hedging policy of the full method name, such as
+// "/example.v1.ExampleService/GetExample", in the service config JSON, with the same matching rules as gRPC.
+func PolicyFor(serviceConfig, fullMethod string) (CallPolicy, error) {
+	config, err := Parse(serviceConfig)
+	if err != nil {
+		return CallPolicy{}, err
+	}
+	return config.PolicyFor(fullMethod)
+}
+
+// PolicyFor returns the effective retry or hedging policy of the full method name, such as
+// "/example.v1.ExampleService/GetExample", with the same matching rules as gRPC.
+func (c *Config) PolicyFor(fullMethod string) (CallPolicy, error) {
+	methodConfig, ok, err := c.MethodConfigFor(fullMethod)
+	if err != nil || !ok {
+		return CallPolicy{}, err
+	}
+	result := CallPolicy{
+		RetryPolicy:   methodConfig.RetryPolicy,
+		HedgingPolicy: methodConfig.HedgingPolicy,
+	}
+	if result.RetryPolicy != nil || result.HedgingPolicy != nil {
+		result.RetryThrottling = c.RetryThrottling
+	}
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package serviceconfig
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPolicyFor(t *testing.T) {
+	const serviceConfig = `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s"},
+    {
+      "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+      "retryPolicy": {
+        "maxAttempts": 8,
+        "initialBackoff": "0.1s",
+        "maxBackoff": "0.3s",
+        "backoffMultiplier": 2,
+        "retryableStatusCodes": ["UNAVAILABLE"]
+      }
+    },
+    {
+      "name": [{"service": "einride.example.freight.v1.FreightService", "method": "ListShippers"}],
+      "hedgingPolicy": {"maxAttempts": 3, "hedgingDelay": "0.5s"}
+    }
+  ],
+  "retryThrottling": {"maxTokens": 10, "tokenRatio": 0.1}
+}`
+	for _, tt := range []struct {
+		name                   string
+		fullMethod             string
+		expectedMaxAttempts    int
+		expectedBackoffWindows []time.Duration
+		expectedHedgingDelays  []time.Duration
+		expectedThrottling     bool
+	}{
+		{
+			name:                "retry policy limited to 5 attempts",
+			fullMethod:          "/einride.example.freight.v1.FreightService/GetShipper",
+			expectedMaxAttempts: 5,
+			expectedBackoffWindows: []time.Duration{
+				100 * time.Millisecond,
+				200 * time.Millisecond,
+				300 * time.Millisecond,
+				300 * time.Millisecond,
+			},
+			expectedThrottling: true,
+		},
+		{
+			name:                  "hedging policy",
+			fullMethod:            "/einride.example.freight.v1.FreightService/ListShippers",
+			expectedMaxAttempts:   3,
+			expectedHedgingDelays: []time.Duration{500 * time.Millisecond, time.Second},
+			expectedThrottling:    true,
+		},
+		{
+			name:                "no policy",
+			fullMethod:          "/einride.example.freight.v1.FreightService/UpdateShipper",
+			expectedMaxAttempts: 1,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := PolicyFor(serviceConfig, tt.fullMethod)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if policy.MaxAttempts() != tt.expectedMaxAttempts {
+				t.Errorf("expected %d max attempts, got %d", tt.expectedMaxAttempts, policy.MaxAttempts())
+			}
+			var backoffWindows, hedgingDelays []time.Duration
+			if policy.RetryPolicy != nil {
+				backoffWindows = policy.RetryPolicy.BackoffWindows()
+			}
+			if policy.HedgingPolicy != nil {
+				hedgingDelays = policy.HedgingPolicy.HedgingDelays()
+			}
+			if !reflect.DeepEqual(backoffWindows, tt.expectedBackoffWindows) {
+				t.Errorf("expected backoff windows %v, got %v", tt.expectedBackoffWindows, backoffWindows)
+			}
+			if !reflect.DeepEqual(hedgingDelays, tt.expectedHedgingDelays) {
+				t.Errorf("expected hedging delays %v, got %v", tt.expectedHedgingDelays, hedgingDelays)
+			}
+			if (policy.RetryThrottling != nil) != tt.expectedThrottling {
+				t.Errorf("expected retry throttling %v, got %v", tt.expectedThrottling, policy.RetryThrottling)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+// Package serviceconfig parses gRPC service configs at runtime, for programs that need to reason about their own
+// service config, with the same semantics as protoc-gen-go-grpc-service-config.
+// It does not link the plugin. Tools that resolve and validate service configs like the plugin use the
+// serviceconfigcheck package.
+package serviceconfig
+
+import "go.einride.tech/protoc-gen-go-grpc-service-config/internal/configjson"
+
+// Merge merges the override service config JSON into the base service config JSON, with the same semantics as the
+// merge conflict policy and the merge command of the CLI, for programs that compose service configs from multiple
+// sources at startup.
+// Fields in the override replace fields in the base, except for method configs: method configs from both are kept,
+// and names in the override take precedence over the same names in the base.
+func Merge(base, override string) (string, error) {
+	return configjson.Merge(base, override)
+}
@@ -0,0 +1,82 @@
+package serviceconfig
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const testFile = `
+name: "einride/example/freight/v1/freight_service.proto"
+package: "einride.example.freight.v1"
+syntax: "proto3"
+message_type { name: "Shipper" }
+service {
+  name: "FreightService"
+  method {
+    name: "GetShipper"
+    input_type: ".einride.example.freight.v1.Shipper"
+    output_type: ".einride.example.freight.v1.Shipper"
+  }
+}
+`
+
+// testFiles returns the proto files of the test freight service.
+func testFiles(t *testing.T) *protoregistry.Files {
+	t.Helper()
+	var file descriptorpb.FileDescriptorProto
+	if err := prototext.Unmarshal([]byte(testFile), &file); err != nil {
+		t.Fatal(err)
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{&file}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestMerge(t *testing.T) {
+	const base = `{
+  "loadBalancingConfig": [{"round_robin": {}}],
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s"},
+    {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}], "timeout": "1s"}
+  ]
+}`
+	const override = `{
+  "loadBalancingConfig": [{"pick_first": {}}],
+  "methodConfig": [
+    {"name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}], "timeout": "2s"}
+  ]
+}`
+	merged, err := Merge(base, override)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := Parse(merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.LoadBalancingConfig) != 1 || config.LoadBalancingConfig[0].Name != "pick_first" {
+		t.Errorf("expected the load balancing config of the override, got %v", config.LoadBalancingConfig)
+	}
+	for _, tt := range []struct {
+		fullMethod string
+		expected   time.Duration
+	}{
+		{fullMethod: "/einride.example.freight.v1.FreightService/GetShipper", expected: 2 * time.Second},
+		{fullMethod: "/einride.example.freight.v1.FreightService/ListShippers", expected: 10 * time.Second},
+	} {
+		methodConfig, ok, err := config.MethodConfigFor(tt.fullMethod)
+		if err != nil || !ok || methodConfig.Timeout == nil || *methodConfig.Timeout != tt.expected {
+			t.Errorf("%s: expected a timeout of %v in:\n%s", tt.fullMethod, tt.expected, merged)
+		}
+	}
+	if _, err := Merge(base, `{`); err == nil {
+		t.Error("expected error for invalid override")
+	}
+}
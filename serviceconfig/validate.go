@@ -0,0 +1,245 @@
+package serviceconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// defaultValidateSource is the name of validated service configs in diagnostics, unless set with WithSource.
+const defaultValidateSource = "service config"
+
+// ValidateOption configures Validate.
+type ValidateOption func(*validateOptions)
+
+// validateOptions are the options of Validate.
+type validateOptions struct {
+	source string
+	files  *protoregistry.Files
+	strict bool
+	// maxConfigBytes is the maximum size of the compacted service config, or 0 for no limit.
+	maxConfigBytes int
+	// allowedLoadBalancingPolicies are the allowed load balancing policies, or nil to allow every policy.
+	allowedLoadBalancingPolicies map[string]struct{}
+}
+
+// WithSource sets the name of the service config in diagnostics, such as the flag or config server it came from.
+func WithSource(name string) ValidateOption {
+	return func(opts *validateOptions) {
+		opts.source = name
+	}
+}
+
+// WithFiles checks names in the service config against the proto files. Unknown names are warnings.
+func WithFiles(files *protoregistry.Files) ValidateOption {
+	return func(opts *validateOptions) {
+		opts.files = files
+	}
+}
+
+// WithStrict fails validation on warnings.
+func WithStrict() ValidateOption {
+	return func(opts *validateOptions) {
+		opts.strict = true
+	}
+}
+
+// WithMaxConfigBytes limits the size of the compacted service config.
+func WithMaxConfigBytes(n int) ValidateOption {
+	return func(opts *validateOptions) {
+		opts.maxConfigBytes = n
+	}
+}
+
+// WithAllowedLoadBalancingPolicies only allows the named load balancing policies, such as "round_robin", in
+// loadBalancingConfig and loadBalancingPolicy.
+func WithAllowedLoadBalancingPolicies(names ...string) ValidateOption {
+	return func(opts *validateOptions) {
+		if opts.allowedLoadBalancingPolicies == nil {
+			opts.allowedLoadBalancingPolicies = make(map[string]struct{}, len(names))
+		}
+		for _, name := range names {
+			opts.allowedLoadBalancingPolicies[name] = struct{}{}
+		}
+	}
+}
+
+// ValidationError is the error returned by Validate for invalid service configs.
+type ValidationError struct {
+	// Diagnostics are the diagnostics that failed validation.
+	Diagnostics []Diagnostic
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Diagnostics))
+	for _, diagnostic := range e.Diagnostics {
+		messages = append(messages, diagnostic.String())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validate validates service config JSON supplied at startup, such as from flags or config servers, with the service
+// config parser of gRPC Go. It returns a *ValidationError for invalid service configs.
+// Validate does not link the plugin, and only checks what gRPC rejects at runtime, names and the options. The
+// serviceconfigcheck package checks service configs with the full rule set of the plugin.
+func Validate(serviceConfig string, opts ...ValidateOption) error {
+	validateOpts := validateOptions{source: defaultValidateSource}
+	for _, opt := range opts {
+		opt(&validateOpts)
+	}
+	diagnostic := func(rule string, severity Severity, jsonPath, format string, args ...interface{}) Diagnostic {
+		return Diagnostic{
+			Rule:     rule,
+			Severity: severity,
+			File:     validateOpts.source,
+			JSONPath: jsonPath,
+			Message:  fmt.Sprintf(format, args...),
+		}
+	}
+	if err := parseWithGRPC(serviceConfig); err != nil {
+		return &ValidationError{Diagnostics: []Diagnostic{
+			diagnostic("INVALID_SERVICE_CONFIG", SeverityError, "", "invalid service config: %v", err),
+		}}
+	}
+	config, err := Parse(serviceConfig)
+	if err != nil {
+		return &ValidationError{Diagnostics: []Diagnostic{
+			diagnostic("INVALID_SERVICE_CONFIG", SeverityError, "", "invalid service config: %v", err),
+		}}
+	}
+	var diagnostics []Diagnostic
+	if validateOpts.maxConfigBytes > 0 {
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, []byte(serviceConfig)); err != nil {
+			return err
+		}
+		if compacted.Len() > validateOpts.maxConfigBytes {
+			diagnostics = append(diagnostics, diagnostic(
+				"CONFIG_SIZE",
+				SeverityError,
+				"",
+				"service config is %d bytes when compacted, which exceeds the budget of %d bytes",
+				compacted.Len(),
+				validateOpts.maxConfigBytes,
+			))
+		}
+	}
+	if validateOpts.files != nil {
+		for _, name := range danglingNames(config, validateOpts.files) {
+			diagnostics = append(diagnostics, diagnostic(
+				"DANGLING_NAME",
+				SeverityWarning,
+				name.jsonPath,
+				"%s: %s",
+				name.jsonPath,
+				name.message,
+			))
+		}
+	}
+	if validateOpts.allowedLoadBalancingPolicies != nil {
+		notAllowed := func(jsonPath, name string) {
+			diagnostics = append(diagnostics, diagnostic(
+				"LOAD_BALANCING_POLICY_NOT_ALLOWED",
+				SeverityError,
+				jsonPath,
+				"load balancing policy %q is not allowed",
+				name,
+			))
+		}
+		for i, lbConfig := range config.LoadBalancingConfig {
+			if _, ok := validateOpts.allowedLoadBalancingPolicies[lbConfig.Name]; !ok {
+				notAllowed(fmt.Sprintf("loadBalancingConfig[%d]", i), lbConfig.Name)
+			}
+		}
+		if config.LoadBalancingPolicy != "" {
+			// The deprecated loadBalancingPolicy field uses upper case names, such as ROUND_ROBIN.
+			name := strings.ToLower(config.LoadBalancingPolicy)
+			if _, ok := validateOpts.allowedLoadBalancingPolicies[name]; !ok {
+				notAllowed("loadBalancingPolicy", name)
+			}
+		}
+	}
+	var errs []Diagnostic
+	for _, diagnostic := range diagnostics {
+		if validateOpts.strict && diagnostic.Severity == SeverityWarning {
+			diagnostic.Severity = SeverityError
+		}
+		if diagnostic.Severity == SeverityError {
+			errs = append(errs, diagnostic)
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Diagnostics: errs}
+	}
+	return nil
+}
+
+// parseWithGRPC parses a service config with the parser the client connections of gRPC Go apply to service configs
+// from resolvers, and returns the parse error. The client connection never connects, since it has no addresses.
+func parseWithGRPC(serviceConfig string) error {
+	r := manual.NewBuilderWithScheme("grpc-service-config-validate")
+	conn, err := grpc.Dial(
+		r.Scheme()+":///validate",
+		grpc.WithResolvers(r),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return fmt.Errorf("parse service config: %w", err)
+	}
+	defer conn.Close()
+	if r.CC == nil {
+		return fmt.Errorf("parse service config: the resolver of the client connection was not built")
+	}
+	return r.CC.ParseServiceConfig(serviceConfig).Err
+}
+
+// danglingName is a name in a service config that references a service or method not present in the proto files.
+type danglingName struct {
+	jsonPath string
+	message  string
+}
+
+// danglingNames returns every name in the service config that references a service or method not present in the
+// proto files.
+func danglingNames(config *Config, files *protoregistry.Files) []danglingName {
+	var result []danglingName
+	for i, methodConfig := range config.MethodConfigs {
+		for j, name := range methodConfig.Names {
+			if name.Service == "" {
+				continue
+			}
+			jsonPath := fmt.Sprintf("methodConfig[%d].name[%d]", i, j)
+			descriptor, err := files.FindDescriptorByName(protoreflect.FullName(name.Service))
+			if err != nil {
+				result = append(result, danglingName{
+					jsonPath: jsonPath,
+					message:  fmt.Sprintf("references unknown service %s", name.Service),
+				})
+				continue
+			}
+			service, ok := descriptor.(protoreflect.ServiceDescriptor)
+			if !ok {
+				result = append(result, danglingName{
+					jsonPath: jsonPath,
+					message:  fmt.Sprintf("references %s, which is not a service", name.Service),
+				})
+				continue
+			}
+			if name.Method != "" && service.Methods().ByName(protoreflect.Name(name.Method)) == nil {
+				result = append(result, danglingName{
+					jsonPath: jsonPath,
+					message:  fmt.Sprintf("references unknown method %s/%s", name.Service, name.Method),
+				})
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,106 @@
+package serviceconfig
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	const danglingName = `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.ShipperService"}], "timeout": "1s"}
+]}`
+	for _, tt := range []struct {
+		name          string
+		serviceConfig string
+		opts          []ValidateOption
+		// expected are the diagnostics of the validation error, or nil for a valid service config.
+		expected []string
+	}{
+		{
+			name:          "valid",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+		},
+		{
+			name:          "invalid",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "forever"}]}`,
+			opts:          []ValidateOption{WithSource("-service_config")},
+			expected: []string{
+				`-service_config: error: invalid service config: malformed duration "forever" (INVALID_SERVICE_CONFIG)`,
+			},
+		},
+		{
+			name:          "rejected by gRPC",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "1s"}, {"name": [{}], "timeout": "2s"}]}`,
+			expected:      []string{"service config: error: invalid service config: duplicated name (INVALID_SERVICE_CONFIG)"},
+		},
+		{
+			name:          "size",
+			serviceConfig: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+			opts:          []ValidateOption{WithMaxConfigBytes(32)},
+			expected: []string{
+				"service config: error: service config is 48 bytes when compacted, which exceeds the budget of 32 bytes" +
+					" (CONFIG_SIZE)",
+			},
+		},
+		{
+			name:          "warning",
+			serviceConfig: danglingName,
+			opts:          []ValidateOption{WithFiles(testFiles(t))},
+		},
+		{
+			name:          "strict",
+			serviceConfig: danglingName,
+			opts:          []ValidateOption{WithFiles(testFiles(t)), WithStrict()},
+			expected: []string{
+				"service config: error: methodConfig[0].name[0]: references unknown service" +
+					" einride.example.freight.v1.ShipperService (DANGLING_NAME)",
+			},
+		},
+		{
+			name:          "allowed load balancing policy",
+			serviceConfig: `{"loadBalancingConfig": [{"round_robin": {}}]}`,
+			opts:          []ValidateOption{WithAllowedLoadBalancingPolicies("pick_first", "round_robin")},
+		},
+		{
+			name:          "load balancing policy not allowed",
+			serviceConfig: `{"loadBalancingConfig": [{"round_robin": {}}, {"pick_first": {}}]}`,
+			opts:          []ValidateOption{WithAllowedLoadBalancingPolicies("pick_first")},
+			expected: []string{
+				`service config: error: load balancing policy "round_robin" is not allowed` +
+					" (LOAD_BALANCING_POLICY_NOT_ALLOWED)",
+			},
+		},
+		{
+			name:          "deprecated load balancing policy not allowed",
+			serviceConfig: `{"loadBalancingPolicy": "ROUND_ROBIN"}`,
+			opts:          []ValidateOption{WithAllowedLoadBalancingPolicies("pick_first")},
+			expected: []string{
+				`service config: error: load balancing policy "round_robin" is not allowed` +
+					" (LOAD_BALANCING_POLICY_NOT_ALLOWED)",
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.serviceConfig, tt.opts...)
+			if tt.expected == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+			if len(validationErr.Diagnostics) != len(tt.expected) {
+				t.Fatalf("expected %d diagnostics, got %v", len(tt.expected), validationErr.Diagnostics)
+			}
+			for i, diagnostic := range validationErr.Diagnostics {
+				if diagnostic.String() != tt.expected[i] {
+					t.Errorf("expected diagnostic %q, got %q", tt.expected[i], diagnostic)
+				}
+			}
+		})
+	}
+}
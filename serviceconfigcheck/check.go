@@ -0,0 +1,93 @@
+package serviceconfigcheck
+
+import (
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/plugin"
+	"go.einride.tech/protoc-gen-go-grpc-service-config/serviceconfig"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Severity is the severity of a diagnostic.
+type Severity = serviceconfig.Severity
+
+const (
+	// SeverityInfo is an informational diagnostic that never fails validation.
+	SeverityInfo = serviceconfig.SeverityInfo
+	// SeverityWarning is a diagnostic that does not fail validation, unless running in strict mode.
+	SeverityWarning = serviceconfig.SeverityWarning
+	// SeverityError is a diagnostic that fails validation.
+	SeverityError = serviceconfig.SeverityError
+)
+
+// Diagnostic is a problem found during validation, shared with serviceconfig.Validate.
+type Diagnostic = serviceconfig.Diagnostic
+
+// CheckOptions configures Check. The zero value checks service configs without a descriptor set.
+type CheckOptions struct {
+	// Files are the proto files that names in service configs are checked against, or nil to not check names.
+	Files *protoregistry.Files
+	// Strict reports warnings as errors.
+	Strict bool
+	// TargetGRPCGoVersion is the oldest grpc-go release service configs must be compatible with, or empty.
+	TargetGRPCGoVersion string
+	// RequireLossless requires service config JSON files to survive a round trip through the service config proto.
+	RequireLossless bool
+	// MaxConfigBytes is the maximum size of a compacted service config, or 0 for no limit.
+	MaxConfigBytes int
+	// Fix accepts deprecated fields that the fix plugin option rewrites in generated code.
+	Fix bool
+}
+
+// Check validates the content of service configs, independent of the services they apply to, and returns every
+// problem found. The service configs are invalid if any diagnostic has SeverityError.
+func Check(sources []Source, opts CheckOptions) ([]Diagnostic, error) {
+	pluginSources := make([]plugin.Source, 0, len(sources))
+	for _, source := range sources {
+		pluginSources = append(pluginSources, plugin.Source(source))
+	}
+	findings, err := plugin.Check(opts.Files, pluginSources, plugin.CheckOptions{
+		TargetGRPCGoVersion: opts.TargetGRPCGoVersion,
+		RequireLossless:     opts.RequireLossless,
+		MaxConfigBytes:      opts.MaxConfigBytes,
+		Fix:                 opts.Fix,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Diagnostic, 0, len(findings))
+	for _, finding := range findings {
+		diagnostic := Diagnostic{
+			Rule:     finding.Rule,
+			Severity: parseSeverity(finding.Severity),
+			File:     finding.File,
+			Line:     finding.Line,
+			Column:   finding.Column,
+			JSONPath: finding.JSONPath,
+			Message:  finding.Message,
+		}
+		if opts.Strict && diagnostic.Severity == SeverityWarning {
+			diagnostic.Severity = SeverityError
+		}
+		result = append(result, diagnostic)
+	}
+	return result, nil
+}
+
+// HasErrors returns true if any of the diagnostics is an error.
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func parseSeverity(s string) Severity {
+	switch s {
+	case SeverityInfo.String():
+		return SeverityInfo
+	case SeverityWarning.String():
+		return SeverityWarning
+	}
+	return SeverityError
+}
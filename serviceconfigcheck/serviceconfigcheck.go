@@ -0,0 +1,71 @@
+// Package serviceconfigcheck resolves and validates gRPC service configs with the same logic as
+// protoc-gen-go-grpc-service-config, for tools that need to agree with the generated code.
+// It links the plugin, so runtime code that only parses service configs should use the serviceconfig package instead.
+package serviceconfigcheck
+
+import (
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/plugin"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// ConflictPolicy decides which service config to resolve when a package has both a service config JSON file and a
+// default_service_config annotation.
+type ConflictPolicy string
+
+const (
+	// ConflictError fails when a package has both a service config JSON file and an annotation.
+	ConflictError ConflictPolicy = "error"
+	// ConflictPreferJSON resolves the service config JSON file.
+	ConflictPreferJSON ConflictPolicy = "prefer_json"
+	// ConflictPreferAnnotation resolves the default_service_config annotation.
+	ConflictPreferAnnotation ConflictPolicy = "prefer_annotation"
+	// ConflictMerge resolves the annotation merged with the service config JSON file, where the JSON file takes
+	// precedence.
+	ConflictMerge ConflictPolicy = "merge"
+)
+
+// Source is a service config and where it was resolved from.
+type Source struct {
+	// Path is the path of the JSON file or proto file the service config was resolved from.
+	Path string
+	// JSON is the service config JSON.
+	JSON string
+	// Annotation is true when the service config was resolved from a default_service_config file annotation.
+	Annotation bool
+	// Merged is true when the service config was merged from a JSON file and a default_service_config annotation.
+	Merged bool
+}
+
+// Resolver resolves the service configs of services, like the plugin does when generating code.
+type Resolver struct {
+	// Files are the proto files to resolve default_service_config annotations from.
+	Files *protoregistry.Files
+	// Path is the input path of service config JSON files, like the path plugin option.
+	Path string
+	// Conflict is the conflict policy, like the conflict plugin option. Defaults to ConflictPreferJSON.
+	Conflict ConflictPolicy
+}
+
+// Resolve resolves the service config of a service.
+// It returns false when the service has no service config.
+func (r *Resolver) Resolve(service protoreflect.ServiceDescriptor) (Source, bool, error) {
+	conflict := r.Conflict
+	if conflict == "" {
+		conflict = ConflictPreferJSON
+	}
+	source, ok, err := plugin.Resolve(r.Files, r.Path, string(conflict), service)
+	if err != nil || !ok {
+		return Source{}, false, err
+	}
+	return Source(source), true, nil
+}
+
+// ReadFile reads a service config JSON or YAML file. YAML files are converted to JSON.
+func ReadFile(filename string) (Source, error) {
+	source, err := plugin.ReadSource(filename)
+	if err != nil {
+		return Source{}, err
+	}
+	return Source(source), nil
+}
@@ -0,0 +1,163 @@
+package serviceconfigcheck
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const testFile = `
+name: "einride/example/freight/v1/freight_service.proto"
+package: "einride.example.freight.v1"
+syntax: "proto3"
+message_type { name: "Shipper" }
+service {
+  name: "FreightService"
+  method {
+    name: "GetShipper"
+    input_type: ".einride.example.freight.v1.Shipper"
+    output_type: ".einride.example.freight.v1.Shipper"
+  }
+}
+`
+
+// testFiles returns the proto files of the test freight service.
+func testFiles(t *testing.T) *protoregistry.Files {
+	t.Helper()
+	var file descriptorpb.FileDescriptorProto
+	if err := prototext.Unmarshal([]byte(testFile), &file); err != nil {
+		t.Fatal(err)
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{&file}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// writeTestFile writes a file relative to a temporary directory, and returns the path of the file.
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	filename := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestResolver(t *testing.T) {
+	files := testFiles(t)
+	descriptor, err := files.FindDescriptorByName("einride.example.freight.v1.FreightService")
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := descriptor.(protoreflect.ServiceDescriptor)
+	dir := t.TempDir()
+	resolver := Resolver{Files: files, Path: dir}
+	if _, ok, err := resolver.Resolve(service); err != nil || ok {
+		t.Fatalf("expected no service config, got %v, %v", ok, err)
+	}
+	const serviceConfig = `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`
+	filename := writeTestFile(t, dir, "einride/example/freight/v1/freight_grpc_service_config.json", serviceConfig)
+	source, ok, err := resolver.Resolve(service)
+	if err != nil || !ok {
+		t.Fatalf("expected a service config, got %v, %v", ok, err)
+	}
+	if expected := (Source{Path: filename, JSON: serviceConfig}); source != expected {
+		t.Errorf("expected %+v, got %+v", expected, source)
+	}
+	resolver.Conflict = "invalid"
+	if _, _, err := resolver.Resolve(service); err == nil {
+		t.Error("expected an invalid conflict policy to fail")
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := writeTestFile(t, dir, "service_config.yaml", "methodConfig:\n  - name: [{}]\n    timeout: 10s\n")
+	source, err := ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"methodConfig":[{"name":[{}],"timeout":"10s"}]}`; source.JSON != expected {
+		t.Errorf("expected %s, got %s", expected, source.JSON)
+	}
+	if _, err := ReadFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected a missing file to fail")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	files := testFiles(t)
+	const danglingName = `{"methodConfig": [
+  {"name": [{"service": "einride.example.freight.v1.ShipperService"}], "timeout": "1s"}
+]}`
+	for _, tt := range []struct {
+		name        string
+		json        string
+		opts        CheckOptions
+		diagnostics []string
+		hasErrors   bool
+	}{
+		{
+			name: "valid",
+			json: `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+			opts: CheckOptions{Files: files},
+		},
+		{
+			name: "invalid",
+			json: `{"methodConfig": [{"name": [{}], "timeout": "forever"}]}`,
+			diagnostics: []string{
+				`service_config.json:1:1: error: invalid service config: malformed duration "forever" (INVALID_SERVICE_CONFIG)`,
+			},
+			hasErrors: true,
+		},
+		{
+			name: "dangling name",
+			json: danglingName,
+			opts: CheckOptions{Files: files},
+			diagnostics: []string{
+				"service_config.json:2:13: warning: methodConfig[0].name[0]: references unknown service" +
+					" einride.example.freight.v1.ShipperService (DANGLING_NAME)",
+			},
+		},
+		{
+			name: "strict",
+			json: danglingName,
+			opts: CheckOptions{Files: files, Strict: true},
+			diagnostics: []string{
+				"service_config.json:2:13: error: methodConfig[0].name[0]: references unknown service" +
+					" einride.example.freight.v1.ShipperService (DANGLING_NAME)",
+			},
+			hasErrors: true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics, err := Check([]Source{{Path: "service_config.json", JSON: tt.json}}, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(diagnostics) != len(tt.diagnostics) {
+				t.Fatalf("expected %d diagnostics, got %v", len(tt.diagnostics), diagnostics)
+			}
+			for i, diagnostic := range diagnostics {
+				if diagnostic.String() != tt.diagnostics[i] {
+					t.Errorf("expected diagnostic %q, got %q", tt.diagnostics[i], diagnostic)
+				}
+			}
+			if actual := HasErrors(diagnostics); actual != tt.hasErrors {
+				t.Errorf("expected HasErrors to be %v, got %v", tt.hasErrors, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+// Package serviceconfigconnect applies the per-method timeouts and retry policies of a gRPC service config to
+// connect-go clients, so that teams migrating from grpc-go keep the same behavior, driven from the same generated
+// constant. It wraps the HTTP client of connect-go clients, and does not depend on connect-go.
+package serviceconfigconnect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/serviceconfig"
+	"google.golang.org/grpc/codes"
+)
+
+// maxErrorBodyBytes is the maximum size of error response bodies read to find their error code.
+const maxErrorBodyBytes = 64 << 10
+
+// HTTPClient is the HTTP client of connect-go clients, identical to connect.HTTPClient.
+type HTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// NewHTTPClient returns an HTTP client for connect-go clients, such as connect.NewClient or the generated
+// NewExampleServiceClient, that applies the method configs of the service config JSON to calls:
+//
+// The timeout of a method applies to the context of its calls, and to the timeout header sent to the server.
+//
+// The retry policy of a method retries unary calls with the Connect protocol that fail with a retryable status code,
+// or with a transport error, which gRPC reports as UNAVAILABLE, after the same randomized exponential backoff as
+// gRPC. Streaming calls and calls with the gRPC and gRPC-Web protocols are not retried, since their status is only
+// known after the response body has been read. Hedging policies and retry throttling are not supported.
+func NewHTTPClient(client HTTPClient, serviceConfig string) (HTTPClient, error) {
+	config, err := serviceconfig.Parse(serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &httpClient{client: client, config: config}, nil
+}
+
+// httpClient is an HTTP client applying the method configs of a service config.
+type httpClient struct {
+	client HTTPClient
+	config *serviceconfig.Config
+	// methodConfigs caches the method configs of URL paths, where nil means no method config.
+	methodConfigs sync.Map
+}
+
+// methodConfig returns the method config of the procedure of the URL path, which ends with /package.Service/Method,
+// or nil when no method config applies.
+func (c *httpClient) methodConfig(path string) *serviceconfig.MethodConfig {
+	if cached, ok := c.methodConfigs.Load(path); ok {
+		return cached.(*serviceconfig.MethodConfig)
+	}
+	var result *serviceconfig.MethodConfig
+	parts := strings.Split(path, "/")
+	if len(parts) >= 3 {
+		fullMethod := "/" + parts[len(parts)-2] + "/" + parts[len(parts)-1]
+		if methodConfig, ok, err := c.config.MethodConfigFor(fullMethod); err == nil && ok {
+			result = methodConfig
+		}
+	}
+	c.methodConfigs.Store(path, result)
+	return result
+}
+
+// Do implements HTTPClient.
+func (c *httpClient) Do(req *http.Request) (*http.Response, error) {
+	methodConfig := c.methodConfig(req.URL.Path)
+	if methodConfig == nil {
+		return c.client.Do(req)
+	}
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if methodConfig.Timeout != nil {
+		ctx, cancel = context.WithTimeout(ctx, *methodConfig.Timeout)
+	}
+	req = req.Clone(ctx)
+	if deadline, ok := ctx.Deadline(); ok && methodConfig.Timeout != nil {
+		setTimeoutHeader(req, time.Until(deadline))
+	}
+	var resp *http.Response
+	var err error
+	if methodConfig.RetryPolicy != nil && isConnectUnary(req) {
+		resp, err = c.doWithRetries(ctx, req, methodConfig.RetryPolicy)
+	} else {
+		resp, err = c.client.Do(req)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// doWithRetries sends a unary request with the Connect protocol, and retries it according to the retry policy.
+func (c *httpClient) doWithRetries(
+	ctx context.Context,
+	req *http.Request,
+	policy *serviceconfig.RetryPolicy,
+) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	backoffWindows := policy.BackoffWindows()
+	for attempt := 0; ; attempt++ {
+		attemptReq := req.Clone(ctx)
+		if body != nil {
+			attemptReq.Body = io.NopCloser(bytes.NewReader(body))
+			attemptReq.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(body)), nil
+			}
+			attemptReq.ContentLength = int64(len(body))
+		}
+		resp, err := c.client.Do(attemptReq)
+		var code codes.Code
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return nil, err
+		case err != nil:
+			code = codes.Unavailable
+		case resp.StatusCode == http.StatusOK:
+			return resp, nil
+		default:
+			if code, err = connectErrorCode(resp); err != nil {
+				return nil, err
+			}
+		}
+		if attempt >= len(backoffWindows) || !isRetryable(policy, code) {
+			return resp, err
+		}
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		// Like gRPC, the backoff is a random duration up to the backoff window of the retry.
+		timer := time.NewTimer(time.Duration(rand.Int63n(int64(backoffWindows[attempt]) + 1)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isRetryable returns true if the status code is one of the retryable status codes of the retry policy.
+func isRetryable(policy *serviceconfig.RetryPolicy, code codes.Code) bool {
+	for _, retryableCode := range policy.RetryableStatusCodes {
+		if retryableCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// isConnectUnary returns true if the request is a unary call with the Connect protocol.
+func isConnectUnary(req *http.Request) bool {
+	if req.Method == http.MethodGet {
+		return req.URL.Query().Get("connect") == "v1"
+	}
+	contentType := strings.TrimSpace(strings.Split(req.Header.Get("Content-Type"), ";")[0])
+	return contentType == "application/proto" || contentType == "application/json"
+}
+
+// setTimeoutHeader sets the timeout header of the protocol of the request, so that the server observes the same
+// deadline as the client.
+func setTimeoutHeader(req *http.Request, timeout time.Duration) {
+	if timeout < 0 {
+		timeout = 0
+	}
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/grpc") {
+		// gRPC timeouts have at most 8 digits.
+		if milliseconds := timeout.Milliseconds(); milliseconds < 1e8 {
+			req.Header.Set("Grpc-Timeout", strconv.FormatInt(milliseconds, 10)+"m")
+		} else {
+			seconds := int64(math.Min(timeout.Seconds(), 1e8-1))
+			req.Header.Set("Grpc-Timeout", strconv.FormatInt(seconds, 10)+"S")
+		}
+		return
+	}
+	req.Header.Set("Connect-Timeout-Ms", strconv.FormatInt(timeout.Milliseconds(), 10))
+}
+
+// connectErrorCode returns the status code of a Connect protocol unary error response. The start of the response
+// body is read, and replaced so that it can be read again. Without a code in the body, the code is derived from the
+// HTTP status code, like the Connect protocol specifies.
+func connectErrorCode(resp *http.Response) (codes.Code, error) {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	if err != nil {
+		_ = resp.Body.Close()
+		return 0, err
+	}
+	resp.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+	var errorBody struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(body, &errorBody); err == nil && errorBody.Code != "" {
+		var code codes.Code
+		if err := code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(errorBody.Code)))); err == nil {
+			return code, nil
+		}
+	}
+	switch resp.StatusCode {
+	case http.StatusBadRequest:
+		return codes.Internal, nil
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated, nil
+	case http.StatusForbidden:
+		return codes.PermissionDenied, nil
+	case http.StatusNotFound:
+		return codes.Unimplemented, nil
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return codes.Unavailable, nil
+	}
+	return codes.Unknown, nil
+}
+
+// readCloser is a reader with a separate closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// cancelOnClose cancels the context of a call when its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close implements io.Closer.
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -0,0 +1,219 @@
+package serviceconfigconnect
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testServiceConfig = `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s"},
+    {
+      "name": [{"service": "einride.example.freight.v1.FreightService", "method": "GetShipper"}],
+      "timeout": "1s",
+      "retryPolicy": {
+        "maxAttempts": 3,
+        "initialBackoff": "0.001s",
+        "maxBackoff": "0.001s",
+        "backoffMultiplier": 1,
+        "retryableStatusCodes": ["UNAVAILABLE"]
+      }
+    }
+  ]
+}`
+
+// testRequest is a request received by a test server.
+type testRequest struct {
+	body    string
+	headers http.Header
+}
+
+// newTestServer starts a server that responds to requests with the responses in order, as HTTP status codes and
+// bodies, and repeats the last response. It returns the server and a function returning the requests received.
+func newTestServer(t *testing.T, responses ...string) (*httptest.Server, func() []testRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []testRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, testRequest{body: string(body), headers: r.Header.Clone()})
+		response := responses[len(responses)-1]
+		if len(requests) <= len(responses) {
+			response = responses[len(requests)-1]
+		}
+		mu.Unlock()
+		statusCode, responseBody := response[:3], response[4:]
+		code, _ := strconv.Atoi(statusCode)
+		w.WriteHeader(code)
+		_, _ = io.WriteString(w, responseBody)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []testRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]testRequest(nil), requests...)
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	const unavailable = `503 {"code": "unavailable", "message": "try again"}`
+	for _, tt := range []struct {
+		name        string
+		method      string
+		contentType string
+		responses   []string
+		// expectedStatusCode is the HTTP status code of the response returned to the caller.
+		expectedStatusCode int
+		expectedBody       string
+		expectedRequests   int
+		// expectedTimeoutHeader is the name of the expected timeout header, and maxTimeout its largest value.
+		expectedTimeoutHeader string
+		maxTimeout            time.Duration
+	}{
+		{
+			name:                  "retried until success",
+			method:                "GetShipper",
+			contentType:           "application/json",
+			responses:             []string{unavailable, unavailable, "200 {}"},
+			expectedStatusCode:    http.StatusOK,
+			expectedBody:          "{}",
+			expectedRequests:      3,
+			expectedTimeoutHeader: "Connect-Timeout-Ms",
+			maxTimeout:            time.Second,
+		},
+		{
+			name:                  "retries exhausted",
+			method:                "GetShipper",
+			contentType:           "application/proto",
+			responses:             []string{unavailable},
+			expectedStatusCode:    http.StatusServiceUnavailable,
+			expectedBody:          unavailable[4:],
+			expectedRequests:      3,
+			expectedTimeoutHeader: "Connect-Timeout-Ms",
+			maxTimeout:            time.Second,
+		},
+		{
+			name:                  "not retryable code",
+			method:                "GetShipper",
+			contentType:           "application/json",
+			responses:             []string{`404 {"code": "not_found"}`},
+			expectedStatusCode:    http.StatusNotFound,
+			expectedBody:          `{"code": "not_found"}`,
+			expectedRequests:      1,
+			expectedTimeoutHeader: "Connect-Timeout-Ms",
+			maxTimeout:            time.Second,
+		},
+		{
+			name:                  "streaming not retried",
+			method:                "GetShipper",
+			contentType:           "application/connect+json",
+			responses:             []string{unavailable},
+			expectedStatusCode:    http.StatusServiceUnavailable,
+			expectedBody:          unavailable[4:],
+			expectedRequests:      1,
+			expectedTimeoutHeader: "Connect-Timeout-Ms",
+			maxTimeout:            time.Second,
+		},
+		{
+			name:                  "gRPC timeout header",
+			method:                "ListShippers",
+			contentType:           "application/grpc",
+			responses:             []string{"200 "},
+			expectedStatusCode:    http.StatusOK,
+			expectedRequests:      1,
+			expectedTimeoutHeader: "Grpc-Timeout",
+			maxTimeout:            10 * time.Second,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newTestServer(t, tt.responses...)
+			client, err := NewHTTPClient(server.Client(), testServiceConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, err := http.NewRequest(
+				http.MethodPost,
+				server.URL+"/einride.example.freight.v1.FreightService/"+tt.method,
+				strings.NewReader(`{"name": "shippers/1"}`),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", tt.contentType)
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := resp.Body.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.expectedStatusCode || string(body) != tt.expectedBody {
+				t.Errorf("expected %d %q, got %d %q", tt.expectedStatusCode, tt.expectedBody, resp.StatusCode, body)
+			}
+			received := requests()
+			if len(received) != tt.expectedRequests {
+				t.Fatalf("expected %d requests, got %d", tt.expectedRequests, len(received))
+			}
+			for _, request := range received {
+				if request.body != `{"name": "shippers/1"}` {
+					t.Errorf("expected the request body in every attempt, got %q", request.body)
+				}
+				timeout := parseTestTimeout(t, tt.expectedTimeoutHeader, request.headers.Get(tt.expectedTimeoutHeader))
+				if timeout <= 0 || timeout > tt.maxTimeout {
+					t.Errorf("expected a timeout up to %v, got %v", tt.maxTimeout, timeout)
+				}
+			}
+		})
+	}
+}
+
+// parseTestTimeout parses the value of a Connect-Timeout-Ms or Grpc-Timeout header.
+func parseTestTimeout(t *testing.T, header, value string) time.Duration {
+	t.Helper()
+	if header == "Grpc-Timeout" {
+		if !strings.HasSuffix(value, "m") {
+			t.Fatalf("expected a gRPC timeout in milliseconds, got %q", value)
+		}
+		value = strings.TrimSuffix(value, "m")
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		t.Fatalf("invalid %s header %q: %v", header, value, err)
+	}
+	return time.Duration(n) * time.Millisecond
+}
+
+func TestHTTPClientWithoutMethodConfig(t *testing.T) {
+	server, requests := newTestServer(t, "200 {}")
+	client, err := NewHTTPClient(server.Client(), `{}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/einride.example.freight.v1.FreightService/GetShipper", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if received := requests(); len(received) != 1 || received[0].headers.Get("Connect-Timeout-Ms") != "" {
+		t.Errorf("expected 1 request without a timeout header, got %v", received)
+	}
+	if _, err := NewHTTPClient(server.Client(), `{`); err == nil {
+		t.Error("expected error for invalid service config")
+	}
+}
@@ -0,0 +1,68 @@
+package serviceconfigmiddleware
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// callOptions returns the call options that apply the maximum message sizes of the method.
+func (l limits) callOptions() []grpc.CallOption {
+	var result []grpc.CallOption
+	if l.maxRequestMessageBytes != nil {
+		result = append(result, grpc.MaxCallSendMsgSize(*l.maxRequestMessageBytes))
+	}
+	if l.maxResponseMessageBytes != nil {
+		result = append(result, grpc.MaxCallRecvMsgSize(*l.maxResponseMessageBytes))
+	}
+	return result
+}
+
+// withCallOptions returns the call options of the method followed by the options of the call, so that options of
+// the call take precedence.
+func (l limits) withCallOptions(opts []grpc.CallOption) []grpc.CallOption {
+	callOptions := l.callOptions()
+	if len(callOptions) == 0 {
+		return opts
+	}
+	return append(callOptions, opts...)
+}
+
+// UnaryClientInterceptor returns a client interceptor that applies the maximum message sizes of the method configs
+// in the service config JSON as call options, for connections where the service config cannot be installed, such as
+// connections dialed by third-party libraries. Call options of the call take precedence.
+func UnaryClientInterceptor(serviceConfig string) (grpc.UnaryClientInterceptor, error) {
+	e, err := newEnforcer(serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		return invoker(ctx, method, req, reply, cc, e.limits(method).withCallOptions(opts)...)
+	}, nil
+}
+
+// StreamClientInterceptor returns a client interceptor that applies the maximum message sizes of the method configs
+// in the service config JSON as call options on streaming calls. Call options of the call take precedence.
+func StreamClientInterceptor(serviceConfig string) (grpc.StreamClientInterceptor, error) {
+	e, err := newEnforcer(serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		return streamer(ctx, desc, cc, method, e.limits(method).withCallOptions(opts)...)
+	}, nil
+}
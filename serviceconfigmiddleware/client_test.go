@@ -0,0 +1,62 @@
+package serviceconfigmiddleware
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestClientInterceptors(t *testing.T) {
+	const serviceConfig = `{
+  "methodConfig": [
+    {"name": [{"service": "grpc.health.v1.Health", "method": "Check"}], "maxRequestMessageBytes": 16},
+    {"name": [{"service": "grpc.health.v1.Health", "method": "Watch"}], "maxResponseMessageBytes": 1}
+  ]
+}`
+	unaryInterceptor, err := UnaryClientInterceptor(serviceConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamInterceptor, err := StreamClientInterceptor(serviceConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestHealthClient(
+		t,
+		&testHealthServer{},
+		nil,
+		grpc.WithChainUnaryInterceptor(unaryInterceptor),
+		grpc.WithChainStreamInterceptor(streamInterceptor),
+	)
+	ctx := context.Background()
+	largeRequest := &grpc_health_v1.HealthCheckRequest{Service: strings.Repeat("x", 32)}
+	t.Run("request within limit", func(t *testing.T) {
+		if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("request too large", func(t *testing.T) {
+		if _, err := client.Check(ctx, largeRequest); status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("expected ResourceExhausted, got %v", err)
+		}
+	})
+	t.Run("call option takes precedence", func(t *testing.T) {
+		if _, err := client.Check(ctx, largeRequest, grpc.MaxCallSendMsgSize(1024)); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("stream response too large", func(t *testing.T) {
+		stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := stream.Recv(); status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("expected ResourceExhausted, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package serviceconfigmiddleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryableByConfigKey is the recommended metric label, or OpenTelemetry attribute key, for whether the service
+// config has a retry policy for the method of a call.
+const RetryableByConfigKey = "rpc.grpc.retryable_by_config"
+
+// RetryableCodeKey is the recommended metric label, or OpenTelemetry attribute key, for whether the status code of a
+// call is retryable by the retry policy of its method.
+const RetryableCodeKey = "rpc.grpc.retryable_code"
+
+// CallObservation is a completed client call, labeled with the retry behavior configured for its method.
+type CallObservation struct {
+	// FullMethod is the full method name of the call, such as "/example.v1.ExampleService/GetExample".
+	FullMethod string
+	// Code is the status code of the call.
+	Code codes.Code
+	// Duration is the duration of the call, including retries.
+	Duration time.Duration
+	// RetryableByConfig is true when the service config has a retry policy for the method.
+	RetryableByConfig bool
+	// RetryableCode is true when the status code is one of the retryable status codes of the retry policy.
+	RetryableCode bool
+	// HedgedByConfig is true when the service config has a hedging policy for the method.
+	HedgedByConfig bool
+	// MaxAttempts is the maximum number of attempts configured for the method after applying the gRPC limit, or 1
+	// when calls are neither retried nor hedged.
+	MaxAttempts int
+}
+
+// MetricsUnaryClientInterceptor returns a client interceptor that passes every completed call to observe, labeled with
+// the retry behavior configured for its method in the service config JSON, so that dashboards can slice error rates
+// by whether calls are retryable by config. Record the observations with any metrics library.
+func MetricsUnaryClientInterceptor(
+	serviceConfig string,
+	observe func(context.Context, CallObservation),
+) (grpc.UnaryClientInterceptor, error) {
+	e, err := newEnforcer(serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		observe(ctx, e.limits(method).observation(method, status.Code(err), time.Since(start)))
+		return err
+	}, nil
+}
+
+// MetricsStreamClientInterceptor returns a client interceptor that passes every completed streaming call to observe,
+// like MetricsUnaryClientInterceptor. A streaming call completes when it fails to start, when receiving a message
+// fails, including with io.EOF at the end of the stream, or when the response of a call without server streaming is
+// received. Calls that the caller abandons without receiving until the end of the stream are not observed.
+func MetricsStreamClientInterceptor(
+	serviceConfig string,
+	observe func(context.Context, CallObservation),
+) (grpc.StreamClientInterceptor, error) {
+	e, err := newEnforcer(serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			observe(ctx, e.limits(method).observation(method, status.Code(err), time.Since(start)))
+			return nil, err
+		}
+		return &observedClientStream{
+			ClientStream:  stream,
+			serverStreams: desc.ServerStreams,
+			observe: func(code codes.Code) {
+				observe(ctx, e.limits(method).observation(method, code, time.Since(start)))
+			},
+		}, nil
+	}, nil
+}
+
+// observedClientStream is a client stream that observes the call when it completes.
+type observedClientStream struct {
+	grpc.ClientStream
+	serverStreams bool
+	observe       func(codes.Code)
+	once          sync.Once
+}
+
+// RecvMsg implements grpc.ClientStream.
+func (s *observedClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case errors.Is(err, io.EOF):
+		s.once.Do(func() { s.observe(codes.OK) })
+	case err != nil:
+		s.once.Do(func() { s.observe(status.Code(err)) })
+	case !s.serverStreams:
+		s.once.Do(func() { s.observe(codes.OK) })
+	}
+	return err
+}
+
+// observation returns the observation of a completed call of the method.
+func (l limits) observation(fullMethod string, code codes.Code, duration time.Duration) CallObservation {
+	result := CallObservation{
+		FullMethod:     fullMethod,
+		Code:           code,
+		Duration:       duration,
+		HedgedByConfig: l.hedgingPolicy != nil,
+		MaxAttempts:    1,
+	}
+	switch {
+	case l.retryPolicy != nil:
+		result.RetryableByConfig = true
+		result.MaxAttempts = l.retryPolicy.EffectiveMaxAttempts()
+		for _, retryableCode := range l.retryPolicy.RetryableStatusCodes {
+			if retryableCode == code {
+				result.RetryableCode = true
+				break
+			}
+		}
+	case l.hedgingPolicy != nil:
+		result.MaxAttempts = l.hedgingPolicy.EffectiveMaxAttempts()
+	}
+	return result
+}
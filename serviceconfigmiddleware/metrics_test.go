@@ -0,0 +1,225 @@
+package serviceconfigmiddleware
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetricsUnaryClientInterceptor(t *testing.T) {
+	const serviceConfig = `{
+  "methodConfig": [
+    {
+      "name": [{"service": "grpc.health.v1.Health", "method": "Check"}],
+      "retryPolicy": {
+        "maxAttempts": 8,
+        "initialBackoff": "0.1s",
+        "maxBackoff": "1s",
+        "backoffMultiplier": 2,
+        "retryableStatusCodes": ["NOT_FOUND"]
+      }
+    }
+  ]
+}`
+	for _, tt := range []struct {
+		name     string
+		request  *grpc_health_v1.HealthCheckRequest
+		expected CallObservation
+	}{
+		{
+			name:    "ok",
+			request: &grpc_health_v1.HealthCheckRequest{},
+			expected: CallObservation{
+				FullMethod:        "/grpc.health.v1.Health/Check",
+				Code:              codes.OK,
+				RetryableByConfig: true,
+				MaxAttempts:       5,
+			},
+		},
+		{
+			name:    "retryable code",
+			request: &grpc_health_v1.HealthCheckRequest{Service: "missing"},
+			expected: CallObservation{
+				FullMethod:        "/grpc.health.v1.Health/Check",
+				Code:              codes.NotFound,
+				RetryableByConfig: true,
+				RetryableCode:     true,
+				MaxAttempts:       5,
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var observations []CallObservation
+			interceptor, err := MetricsUnaryClientInterceptor(
+				serviceConfig,
+				func(_ context.Context, call CallObservation) {
+					observations = append(observations, call)
+				},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := newTestHealthClient(t, health.NewServer(), nil, grpc.WithChainUnaryInterceptor(interceptor))
+			_, _ = client.Check(context.Background(), tt.request)
+			if len(observations) != 1 {
+				t.Fatalf("expected 1 observation, got %d", len(observations))
+			}
+			actual := observations[0]
+			if actual.Duration <= 0 {
+				t.Errorf("expected a positive duration, got %v", actual.Duration)
+			}
+			actual.Duration = 0
+			if actual != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+// testClientStream is a client stream that receives the results of recv, in order.
+type testClientStream struct {
+	grpc.ClientStream
+	recv []error
+}
+
+// RecvMsg implements grpc.ClientStream.
+func (s *testClientStream) RecvMsg(interface{}) error {
+	if len(s.recv) == 0 {
+		return io.EOF
+	}
+	err := s.recv[0]
+	s.recv = s.recv[1:]
+	return err
+}
+
+func TestMetricsStreamClientInterceptor(t *testing.T) {
+	const serviceConfig = `{
+  "methodConfig": [
+    {
+      "name": [{"service": "grpc.health.v1.Health"}],
+      "retryPolicy": {
+        "maxAttempts": 3,
+        "initialBackoff": "0.1s",
+        "maxBackoff": "1s",
+        "backoffMultiplier": 2,
+        "retryableStatusCodes": ["UNAVAILABLE"]
+      }
+    }
+  ]
+}`
+	const fullMethod = "/grpc.health.v1.Health/Watch"
+	for _, tt := range []struct {
+		name          string
+		serverStreams bool
+		// startErr is the error of starting the stream, if not nil.
+		startErr error
+		// recv are the results of receiving messages, followed by io.EOF.
+		recv     []error
+		expected []codes.Code
+	}{
+		{name: "end of stream", serverStreams: true, recv: []error{nil, nil}, expected: []codes.Code{codes.OK}},
+		{
+			name:          "error",
+			serverStreams: true,
+			recv:          []error{nil, status.Error(codes.Unavailable, "unavailable")},
+			expected:      []codes.Code{codes.Unavailable},
+		},
+		{name: "response without server streaming", recv: []error{nil}, expected: []codes.Code{codes.OK}},
+		{
+			name:     "start error",
+			startErr: status.Error(codes.PermissionDenied, "denied"),
+			expected: []codes.Code{codes.PermissionDenied},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var observations []CallObservation
+			interceptor, err := MetricsStreamClientInterceptor(
+				serviceConfig,
+				func(_ context.Context, call CallObservation) {
+					observations = append(observations, call)
+				},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stream, err := interceptor(
+				context.Background(),
+				&grpc.StreamDesc{ServerStreams: tt.serverStreams},
+				nil,
+				fullMethod,
+				func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+					if tt.startErr != nil {
+						return nil, tt.startErr
+					}
+					return &testClientStream{recv: tt.recv}, nil
+				},
+			)
+			if err == nil {
+				// Receive past the end of the stream, which must not observe the call again.
+				for i := 0; i <= len(tt.recv); i++ {
+					if err := stream.RecvMsg(nil); err != nil && err != io.EOF {
+						break
+					}
+				}
+			}
+			if len(observations) != len(tt.expected) {
+				t.Fatalf("expected %d observations, got %+v", len(tt.expected), observations)
+			}
+			for i, observation := range observations {
+				if observation.FullMethod != fullMethod || observation.Code != tt.expected[i] ||
+					!observation.RetryableByConfig || observation.MaxAttempts != 3 {
+					t.Errorf("expected a retryable %s call of %s, got %+v", tt.expected[i], fullMethod, observation)
+				}
+				if expected := tt.expected[i] == codes.Unavailable; observation.RetryableCode != expected {
+					t.Errorf("expected RetryableCode %v, got %v", expected, observation.RetryableCode)
+				}
+			}
+		})
+	}
+}
+
+func TestMetricsStreamClientInterceptorCanceled(t *testing.T) {
+	observations := make(chan CallObservation, 1)
+	interceptor, err := MetricsStreamClientInterceptor(
+		`{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`,
+		func(_ context.Context, call CallObservation) {
+			observations <- call
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestHealthClient(t, health.NewServer(), nil, grpc.WithChainStreamInterceptor(interceptor))
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Fatalf("expected the stream to be canceled, got %v", err)
+	}
+	observation := <-observations
+	if observation.FullMethod != "/grpc.health.v1.Health/Watch" || observation.Code != codes.Canceled ||
+		observation.RetryableByConfig {
+		t.Errorf("expected a canceled call that is not retryable by config, got %+v", observation)
+	}
+}
+
+func TestLimitsObservation(t *testing.T) {
+	if actual := (limits{}).observation("/a.B/C", codes.Unavailable, 0); actual.MaxAttempts != 1 ||
+		actual.RetryableByConfig || actual.RetryableCode || actual.HedgedByConfig {
+		t.Errorf("expected an observation without retries, got %+v", actual)
+	}
+}
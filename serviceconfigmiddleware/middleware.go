@@ -0,0 +1,183 @@
+// Package serviceconfigmiddleware provides gRPC server interceptors that enforce the per-method timeouts and maximum
+// message sizes of a service config on the server, so that they apply even to clients that do not honor the service
+// config, client interceptors that apply the maximum message sizes on connections without the service config, and
+// client interceptors that label calls for metrics with whether they are retryable by the service config.
+package serviceconfigmiddleware
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/serviceconfig"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// limits are the limits and policies of a method, from its method config.
+type limits struct {
+	timeout                 *time.Duration
+	maxRequestMessageBytes  *int
+	maxResponseMessageBytes *int
+	retryPolicy             *serviceconfig.RetryPolicy
+	hedgingPolicy           *serviceconfig.HedgingPolicy
+}
+
+// enforcer resolves and enforces the limits of methods.
+type enforcer struct {
+	// limitsByName are the limits of the names in the service config, by the first method config of each name.
+	// Only names in the service config are keys, so that the size is bounded by the service config, regardless of
+	// the full method names of calls.
+	limitsByName map[serviceconfig.Name]limits
+}
+
+func newEnforcer(serviceConfig string) (*enforcer, error) {
+	config, err := serviceconfig.Parse(serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+	e := &enforcer{limitsByName: map[serviceconfig.Name]limits{}}
+	for _, methodConfig := range config.MethodConfigs {
+		for _, name := range methodConfig.Names {
+			if _, ok := e.limitsByName[name]; ok {
+				continue
+			}
+			e.limitsByName[name] = limits{
+				timeout:                 methodConfig.Timeout,
+				maxRequestMessageBytes:  methodConfig.MaxRequestMessageBytes,
+				maxResponseMessageBytes: methodConfig.MaxResponseMessageBytes,
+				retryPolicy:             methodConfig.RetryPolicy,
+				hedgingPolicy:           methodConfig.HedgingPolicy,
+			}
+		}
+	}
+	return e, nil
+}
+
+// limits returns the limits of the full method name, following the gRPC matching rules: a name matching the method
+// takes precedence over a name matching the service, which takes precedence over the default name.
+func (e *enforcer) limits(fullMethod string) limits {
+	// Full method names that are not in the /package.Service/Method format have no limits.
+	parts := strings.Split(strings.TrimPrefix(fullMethod, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return limits{}
+	}
+	if result, ok := e.limitsByName[serviceconfig.Name{Service: parts[0], Method: parts[1]}]; ok {
+		return result
+	}
+	if result, ok := e.limitsByName[serviceconfig.Name{Service: parts[0]}]; ok {
+		return result
+	}
+	return e.limitsByName[serviceconfig.Name{}]
+}
+
+// withTimeout returns the context with the timeout of the method applied, unless the context has an earlier
+// deadline.
+func (l limits) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if l.timeout == nil {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, *l.timeout)
+}
+
+// checkRequest returns a ResourceExhausted error if the request message is larger than the maximum size.
+func (l limits) checkRequest(msg interface{}) error {
+	return checkSize(msg, l.maxRequestMessageBytes, "received message")
+}
+
+// checkResponse returns a ResourceExhausted error if the response message is larger than the maximum size.
+func (l limits) checkResponse(msg interface{}) error {
+	return checkSize(msg, l.maxResponseMessageBytes, "sent message")
+}
+
+// checkSize returns a ResourceExhausted error, like the one gRPC returns for messages larger than its global limits,
+// if the message is larger than the maximum size. Messages that are not protobuf messages are not checked.
+func checkSize(msg interface{}, maxBytes *int, kind string) error {
+	if maxBytes == nil {
+		return nil
+	}
+	message, ok := msg.(proto.Message)
+	if !ok {
+		return nil
+	}
+	if size := proto.Size(message); size > *maxBytes {
+		return status.Errorf(codes.ResourceExhausted, "grpc: %s larger than max (%d vs. %d)", kind, size, *maxBytes)
+	}
+	return nil
+}
+
+// UnaryServerInterceptor returns a server interceptor that enforces the timeouts and maximum message sizes of the
+// method configs in the service config JSON on unary calls.
+func UnaryServerInterceptor(serviceConfig string) (grpc.UnaryServerInterceptor, error) {
+	e, err := newEnforcer(serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		l := e.limits(info.FullMethod)
+		if err := l.checkRequest(req); err != nil {
+			return nil, err
+		}
+		ctx, cancel := l.withTimeout(ctx)
+		defer cancel()
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.checkResponse(resp); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}, nil
+}
+
+// StreamServerInterceptor returns a server interceptor that enforces the timeouts and maximum message sizes of the
+// method configs in the service config JSON on streaming calls. The timeout applies to the context of the whole
+// stream, and the maximum message sizes to every message.
+func StreamServerInterceptor(serviceConfig string) (grpc.StreamServerInterceptor, error) {
+	e, err := newEnforcer(serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		l := e.limits(info.FullMethod)
+		ctx, cancel := l.withTimeout(ss.Context())
+		defer cancel()
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx, limits: l})
+	}, nil
+}
+
+// serverStream is a server stream with the limits of its method enforced.
+type serverStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	limits limits
+}
+
+// Context implements grpc.ServerStream.
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// SendMsg implements grpc.ServerStream.
+func (s *serverStream) SendMsg(m interface{}) error {
+	if err := s.limits.checkResponse(m); err != nil {
+		return err
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+// RecvMsg implements grpc.ServerStream.
+func (s *serverStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.limits.checkRequest(m)
+}
@@ -0,0 +1,203 @@
+package serviceconfigmiddleware
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testHealthServer is a health server that records the deadlines of calls.
+type testHealthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	mu sync.Mutex
+	// timeouts are the remaining times until the deadlines of calls, or 0 for calls without a deadline.
+	timeouts []time.Duration
+}
+
+func (s *testHealthServer) recordDeadline(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var timeout time.Duration
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	s.timeouts = append(s.timeouts, timeout)
+}
+
+// lastTimeout returns the remaining time until the deadline of the last call.
+func (s *testHealthServer) lastTimeout() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.timeouts[len(s.timeouts)-1]
+}
+
+// Check implements grpc_health_v1.HealthServer.
+func (s *testHealthServer) Check(
+	ctx context.Context,
+	_ *grpc_health_v1.HealthCheckRequest,
+) (*grpc_health_v1.HealthCheckResponse, error) {
+	s.recordDeadline(ctx)
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+// Watch implements grpc_health_v1.HealthServer.
+func (s *testHealthServer) Watch(
+	_ *grpc_health_v1.HealthCheckRequest,
+	stream grpc_health_v1.Health_WatchServer,
+) error {
+	s.recordDeadline(stream.Context())
+	return stream.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING})
+}
+
+// newTestHealthClient starts the health server with the server options on an in-memory connection, and returns a
+// client of it dialed with the dial options.
+func newTestHealthClient(
+	t *testing.T,
+	healthServer grpc_health_v1.HealthServer,
+	serverOpts []grpc.ServerOption,
+	dialOpts ...grpc.DialOption,
+) grpc_health_v1.HealthClient {
+	t.Helper()
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(serverOpts...)
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	dialOpts = append(
+		dialOpts,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	conn, err := grpc.Dial("bufnet", dialOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func TestServerInterceptors(t *testing.T) {
+	const serviceConfig = `{
+  "methodConfig": [
+    {"name": [{}], "timeout": "10s"},
+    {"name": [{"service": "grpc.health.v1.Health", "method": "Check"}], "timeout": "1s", "maxRequestMessageBytes": 16},
+    {"name": [{"service": "grpc.health.v1.Health", "method": "Watch"}], "timeout": "2s", "maxResponseMessageBytes": 1}
+  ]
+}`
+	unaryInterceptor, err := UnaryServerInterceptor(serviceConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamInterceptor, err := StreamServerInterceptor(serviceConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	healthServer := &testHealthServer{}
+	client := newTestHealthClient(
+		t,
+		healthServer,
+		[]grpc.ServerOption{
+			grpc.ChainUnaryInterceptor(unaryInterceptor),
+			grpc.ChainStreamInterceptor(streamInterceptor),
+		},
+	)
+	ctx := context.Background()
+	t.Run("timeout", func(t *testing.T) {
+		if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+		if timeout := healthServer.lastTimeout(); timeout <= 0 || timeout > time.Second {
+			t.Errorf("expected a deadline within 1s, got %v", timeout)
+		}
+	})
+	t.Run("earlier client deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+		if timeout := healthServer.lastTimeout(); timeout > 100*time.Millisecond {
+			t.Errorf("expected the client deadline within 100ms, got %v", timeout)
+		}
+	})
+	t.Run("request too large", func(t *testing.T) {
+		_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: strings.Repeat("x", 32)})
+		if status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("expected ResourceExhausted, got %v", err)
+		}
+	})
+	t.Run("stream timeout and response too large", func(t *testing.T) {
+		stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = stream.Recv()
+		if status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("expected ResourceExhausted, got %v", err)
+		}
+		if timeout := healthServer.lastTimeout(); timeout <= time.Second || timeout > 2*time.Second {
+			t.Errorf("expected a deadline within 2s, got %v", timeout)
+		}
+	})
+}
+
+func TestServerInterceptorsInvalidServiceConfig(t *testing.T) {
+	const serviceConfig = `{"methodConfig": [{"name": [{}], "timeout": "forever"}]}`
+	if _, err := UnaryServerInterceptor(serviceConfig); err == nil {
+		t.Error("expected error from unary interceptor")
+	}
+	if _, err := StreamServerInterceptor(serviceConfig); err == nil {
+		t.Error("expected error from stream interceptor")
+	}
+}
+
+func TestEnforcerLimits(t *testing.T) {
+	e, err := newEnforcer(`{"methodConfig": [
+  {"name": [{}], "timeout": "10s"},
+  {"name": [{"service": "grpc.health.v1.Health"}], "timeout": "5s"},
+  {"name": [{"service": "grpc.health.v1.Health", "method": "Check"}], "timeout": "1s"},
+  {"name": [{"service": "grpc.health.v1.Health", "method": "Check"}], "timeout": "2s"}
+]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		fullMethod string
+		expected   time.Duration
+	}{
+		{fullMethod: "/grpc.health.v1.Health/Check", expected: time.Second},
+		{fullMethod: "/grpc.health.v1.Health/Watch", expected: 5 * time.Second},
+		{fullMethod: "/einride.example.freight.v1.FreightService/GetShipper", expected: 10 * time.Second},
+		{fullMethod: "/grpc.health.v1.Health"},
+	} {
+		var actual time.Duration
+		if timeout := e.limits(tt.fullMethod).timeout; timeout != nil {
+			actual = *timeout
+		}
+		if actual != tt.expected {
+			t.Errorf("%s: expected a timeout of %v, got %v", tt.fullMethod, tt.expected, actual)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		e.limits("/grpc.health.v1.Health/Unknown" + strings.Repeat("x", i))
+	}
+	if len(e.limitsByName) != 3 {
+		t.Errorf("expected the limits of the 3 names in the service config, got %d", len(e.limitsByName))
+	}
+}
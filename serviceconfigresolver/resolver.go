@@ -0,0 +1,188 @@
+// Package serviceconfigresolver provides a gRPC resolver that serves a service config which can be updated at
+// runtime, from a file or by the program, so that service config changes do not require redeploying clients.
+package serviceconfigresolver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/internal/yamljson"
+	"go.einride.tech/protoc-gen-go-grpc-service-config/serviceconfig"
+	"google.golang.org/grpc/resolver"
+)
+
+// Scheme is the scheme of targets resolved by the resolver, such as "svccfg:///example.com:443".
+const Scheme = "svccfg"
+
+// Builder builds resolvers that serve the current service config of the builder, and pushes updates of the service
+// config to every resolver it has built.
+// The endpoint of the target is passed through as the address, like the passthrough resolver.
+// Register it for a connection with grpc.WithResolvers.
+type Builder struct {
+	mu            sync.Mutex
+	serviceConfig string
+	// version is incremented on every update, so that resolvers never replace a service config with an older one.
+	version   uint64
+	resolvers map[*configResolver]struct{}
+}
+
+var _ resolver.Builder = &Builder{}
+
+// NewBuilder returns a builder serving the service config JSON, such as the ServiceConfig constant of the generated
+// code, until it is updated.
+func NewBuilder(serviceConfig string) (*Builder, error) {
+	if _, err := serviceconfig.Parse(serviceConfig); err != nil {
+		return nil, fmt.Errorf("service config resolver: %w", err)
+	}
+	return &Builder{serviceConfig: serviceConfig, version: 1, resolvers: map[*configResolver]struct{}{}}, nil
+}
+
+// Scheme implements resolver.Builder.
+func (b *Builder) Scheme() string {
+	return Scheme
+}
+
+// Build implements resolver.Builder.
+func (b *Builder) Build(
+	target resolver.Target,
+	cc resolver.ClientConn,
+	_ resolver.BuildOptions,
+) (resolver.Resolver, error) {
+	endpoint := strings.TrimPrefix(target.URL.Path, "/")
+	if endpoint == "" {
+		endpoint = target.URL.Opaque
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("service config resolver: missing endpoint in target %q", target.URL.String())
+	}
+	r := &configResolver{builder: b, cc: cc, endpoint: endpoint}
+	b.mu.Lock()
+	serviceConfig, version := b.serviceConfig, b.version
+	b.resolvers[r] = struct{}{}
+	b.mu.Unlock()
+	// The service config is pushed without holding the lock of the builder, since the connection may call back into
+	// the resolver, such as closing it, while the state is updated.
+	if err := r.update(version, serviceConfig); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// ServiceConfig returns the current service config JSON.
+func (b *Builder) ServiceConfig() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.serviceConfig
+}
+
+// Update validates the service config JSON, and pushes it to every resolver built by the builder.
+// Invalid service configs are rejected, and the current service config is kept. The first error pushing the service
+// config to a connection is returned, after it has been pushed to the other connections.
+func (b *Builder) Update(serviceConfig string) error {
+	if _, err := serviceconfig.Parse(serviceConfig); err != nil {
+		return fmt.Errorf("service config resolver: %w", err)
+	}
+	b.mu.Lock()
+	b.serviceConfig = serviceConfig
+	b.version++
+	version := b.version
+	resolvers := make([]*configResolver, 0, len(b.resolvers))
+	for r := range b.resolvers {
+		resolvers = append(resolvers, r)
+	}
+	b.mu.Unlock()
+	var result error
+	for _, r := range resolvers {
+		if err := r.update(version, serviceConfig); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// WatchFile updates the service config from a service config JSON or YAML file whenever it changes, until the context
+// is done. The file is read immediately, and then polled for changes every interval.
+// Errors reading or updating the service config are passed to onError, if not nil, and the current service config is
+// kept until the file is fixed.
+func (b *Builder) WatchFile(ctx context.Context, filename string, interval time.Duration, onError func(error)) error {
+	var modTime time.Time
+	var size int64 = -1
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if info, err := os.Stat(filename); err != nil {
+			if onError != nil {
+				onError(fmt.Errorf("service config resolver: %w", err))
+			}
+		} else if !info.ModTime().Equal(modTime) || info.Size() != size {
+			modTime, size = info.ModTime(), info.Size()
+			if err := b.updateFromFile(filename); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// updateFromFile updates the service config from a service config JSON or YAML file.
+func (b *Builder) updateFromFile(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("service config resolver: %w", err)
+	}
+	if yamljson.IsYAMLFile(filename) {
+		if data, err = yamljson.ToJSON(data); err != nil {
+			return fmt.Errorf("service config resolver: %s: %w", filename, err)
+		}
+	}
+	return b.Update(string(data))
+}
+
+// configResolver is a resolver serving the service config of its builder.
+type configResolver struct {
+	builder  *Builder
+	cc       resolver.ClientConn
+	endpoint string
+	// mu serializes pushing service configs to the connection.
+	mu sync.Mutex
+	// version is the version of the last service config pushed to the connection.
+	version uint64
+}
+
+// update pushes the service config to the connection, unless a newer version has already been pushed.
+func (r *configResolver) update(version uint64, serviceConfig string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if version <= r.version {
+		return nil
+	}
+	r.version = version
+	parsed := r.cc.ParseServiceConfig(serviceConfig)
+	if parsed.Err != nil {
+		return fmt.Errorf("service config resolver: %w", parsed.Err)
+	}
+	return r.cc.UpdateState(resolver.State{
+		Addresses:     []resolver.Address{{Addr: r.endpoint}},
+		ServiceConfig: parsed,
+	})
+}
+
+// ResolveNow implements resolver.Resolver. The service config is pushed on updates, so there is nothing to resolve.
+func (r *configResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+// Close implements resolver.Resolver. Service configs being pushed when the resolver is closed may still reach the
+// connection, which ignores them after closing the resolver.
+func (r *configResolver) Close() {
+	r.builder.mu.Lock()
+	defer r.builder.mu.Unlock()
+	delete(r.builder.resolvers, r)
+}
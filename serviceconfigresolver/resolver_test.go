@@ -0,0 +1,368 @@
+package serviceconfigresolver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go.einride.tech/protoc-gen-go-grpc-service-config/serviceconfig"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/resolver"
+	grpcserviceconfig "google.golang.org/grpc/serviceconfig"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	testServiceConfig        = `{"methodConfig": [{"name": [{}], "timeout": "10s"}]}`
+	testUpdatedServiceConfig = `{"methodConfig": [{"name": [{}], "timeout": "1s"}]}`
+)
+
+// testClientConn is a client connection that records the states pushed to it.
+type testClientConn struct {
+	resolver.ClientConn
+	mu sync.Mutex
+	// serviceConfigs are the service configs pushed to the connection, in order.
+	serviceConfigs []string
+	// addresses are the addresses of the last state pushed to the connection.
+	addresses []resolver.Address
+	// parseErr is the error of parsing service configs, if not nil.
+	parseErr error
+	// onUpdateState is called after a state is pushed to the connection, if not nil.
+	onUpdateState func()
+}
+
+// ParseServiceConfig implements resolver.ClientConn.
+func (c *testClientConn) ParseServiceConfig(serviceConfig string) *grpcserviceconfig.ParseResult {
+	if c.parseErr != nil {
+		return &grpcserviceconfig.ParseResult{Err: c.parseErr}
+	}
+	return &grpcserviceconfig.ParseResult{Config: testParsedServiceConfig{json: serviceConfig}}
+}
+
+// UpdateState implements resolver.ClientConn.
+func (c *testClientConn) UpdateState(state resolver.State) error {
+	c.mu.Lock()
+	c.serviceConfigs = append(c.serviceConfigs, state.ServiceConfig.Config.(testParsedServiceConfig).json)
+	c.addresses = state.Addresses
+	c.mu.Unlock()
+	if c.onUpdateState != nil {
+		c.onUpdateState()
+	}
+	return nil
+}
+
+// pushedServiceConfigs returns the service configs pushed to the connection, in order.
+func (c *testClientConn) pushedServiceConfigs() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.serviceConfigs...)
+}
+
+// testParsedServiceConfig is a service config parsed by testClientConn.
+type testParsedServiceConfig struct {
+	grpcserviceconfig.Config
+	json string
+}
+
+// testTarget returns the target of the endpoint.
+func testTarget(endpoint string) resolver.Target {
+	return resolver.Target{URL: url.URL{Scheme: Scheme, Path: "/" + endpoint}}
+}
+
+func TestBuilder(t *testing.T) {
+	builder, err := NewBuilder(testServiceConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, second := &testClientConn{}, &testClientConn{}
+	firstResolver, err := builder.Build(testTarget("example.com:443"), first, resolver.BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := builder.Build(testTarget("example.com:443"), second, resolver.BuildOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(first.addresses) != 1 || first.addresses[0].Addr != "example.com:443" {
+		t.Errorf("expected the endpoint as the address, got %v", first.addresses)
+	}
+	if err := builder.Update(`{"methodConfig": [{"name": [{}], "timeout": "forever"}]}`); err == nil {
+		t.Error("expected error for invalid service config")
+	}
+	if err := builder.Update(testUpdatedServiceConfig); err != nil {
+		t.Fatal(err)
+	}
+	firstResolver.Close()
+	if err := builder.Update(testServiceConfig); err != nil {
+		t.Fatal(err)
+	}
+	if actual := builder.ServiceConfig(); actual != testServiceConfig {
+		t.Errorf("expected current service config %s, got %s", testServiceConfig, actual)
+	}
+	assertServiceConfigs(t, first.pushedServiceConfigs(), testServiceConfig, testUpdatedServiceConfig)
+	assertServiceConfigs(
+		t,
+		second.pushedServiceConfigs(),
+		testServiceConfig,
+		testUpdatedServiceConfig,
+		testServiceConfig,
+	)
+	noEndpoint := resolver.Target{URL: url.URL{Scheme: Scheme}}
+	if _, err := builder.Build(noEndpoint, &testClientConn{}, resolver.BuildOptions{}); err == nil {
+		t.Error("expected error for target without endpoint")
+	}
+	if _, err := NewBuilder(`{`); err == nil {
+		t.Error("expected error for invalid initial service config")
+	}
+}
+
+// assertServiceConfigs checks that the service configs pushed to a connection are the expected service configs.
+func assertServiceConfigs(t *testing.T, actual []string, expected ...string) {
+	t.Helper()
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d service configs, got %d: %v", len(expected), len(actual), actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("service config %d: expected %s, got %s", i, expected[i], actual[i])
+		}
+	}
+}
+
+func TestBuilderBuildParseError(t *testing.T) {
+	builder, err := NewBuilder(testServiceConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := &testClientConn{parseErr: errors.New("unsupported")}
+	if _, err := builder.Build(testTarget("example.com:443"), cc, resolver.BuildOptions{}); err == nil {
+		t.Fatal("expected error for a service config the connection can not parse")
+	}
+	if err := builder.Update(testUpdatedServiceConfig); err != nil {
+		t.Errorf("expected the failed resolver to not be updated, got %v", err)
+	}
+	assertServiceConfigs(t, cc.pushedServiceConfigs())
+}
+
+func TestBuilderReentrantUpdateState(t *testing.T) {
+	builder, err := NewBuilder(testServiceConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Connections may call back into the resolver while a state is pushed, such as closing it.
+	var r resolver.Resolver
+	cc := &testClientConn{}
+	cc.onUpdateState = func() {
+		_ = builder.ServiceConfig()
+		if r != nil {
+			r.Close()
+		}
+	}
+	done := make(chan error)
+	go func() {
+		var err error
+		if r, err = builder.Build(testTarget("example.com:443"), cc, resolver.BuildOptions{}); err != nil {
+			done <- err
+			return
+		}
+		done <- builder.Update(testUpdatedServiceConfig)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadlock pushing the service config")
+	}
+	if err := builder.Update(testServiceConfig); err != nil {
+		t.Fatal(err)
+	}
+	assertServiceConfigs(t, cc.pushedServiceConfigs(), testServiceConfig, testUpdatedServiceConfig)
+}
+
+func TestBuilderConcurrentUpdateAndClose(t *testing.T) {
+	builder, err := NewBuilder(testServiceConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 20
+	conns := make([]*testClientConn, n)
+	resolvers := make([]resolver.Resolver, n)
+	for i := range conns {
+		conns[i] = &testClientConn{}
+		if resolvers[i], err = builder.Build(testTarget("example.com:443"), conns[i], resolver.BuildOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		i := i
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			serviceConfig := testServiceConfig
+			if i%2 == 0 {
+				serviceConfig = testUpdatedServiceConfig
+			}
+			if err := builder.Update(serviceConfig); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				resolvers[i].Close()
+			}
+		}()
+	}
+	wg.Wait()
+	// Every open resolver ends up with the current service config, however the updates interleaved.
+	current := builder.ServiceConfig()
+	for i, cc := range conns {
+		pushed := cc.pushedServiceConfigs()
+		if i%2 == 1 && pushed[len(pushed)-1] != current {
+			t.Errorf("connection %d: expected the current service config %s, got %s", i, current, pushed[len(pushed)-1])
+		}
+	}
+	counts := make([]int, n)
+	for i, cc := range conns {
+		counts[i] = len(cc.pushedServiceConfigs())
+	}
+	if err := builder.Update(`{"methodConfig": [{"name": [{}], "timeout": "2s"}]}`); err != nil {
+		t.Fatal(err)
+	}
+	for i, cc := range conns {
+		expected := counts[i]
+		if i%2 == 1 {
+			expected++
+		}
+		if actual := len(cc.pushedServiceConfigs()); actual != expected {
+			t.Errorf("connection %d: expected %d service configs after closing, got %d", i, expected, actual)
+		}
+	}
+}
+
+func TestBuilderWatchFile(t *testing.T) {
+	builder, err := NewBuilder(testServiceConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "service_config.yaml")
+	if err := os.WriteFile(filename, []byte("methodConfig:\n  - name: [{}]\n    timeout: 1s\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- builder.WatchFile(ctx, filename, 10*time.Millisecond, nil)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for builder.ServiceConfig() == testServiceConfig {
+		if time.Now().After(deadline) {
+			t.Fatal("service config not updated from file")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	timeout, ok, err := serviceconfig.TimeoutFor(builder.ServiceConfig(), "/grpc.health.v1.Health/Check")
+	if err != nil || !ok || timeout != time.Second {
+		t.Errorf("expected a timeout of 1s from the file, got %v %v %v", timeout, ok, err)
+	}
+}
+
+func TestBuilderUpdateFromFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, tt := range []struct {
+		name     string
+		filename string
+		content  string
+		err      bool
+	}{
+		{name: "json", filename: "service_config.json", content: testUpdatedServiceConfig},
+		{name: "yaml", filename: "service_config.yml", content: "methodConfig:\n  - name: [{}]\n    timeout: 1s\n"},
+		{name: "invalid yaml", filename: "invalid.yaml", content: "methodConfig: [", err: true},
+		{name: "missing", filename: "missing.json", err: true},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			builder, err := NewBuilder(testServiceConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filename := filepath.Join(dir, tt.filename)
+			if tt.content != "" {
+				if err := os.WriteFile(filename, []byte(tt.content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err = builder.updateFromFile(filename)
+			if tt.err {
+				if err == nil {
+					t.Error("expected error")
+				}
+				if builder.ServiceConfig() != testServiceConfig {
+					t.Errorf("expected the service config to be kept, got %s", builder.ServiceConfig())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			timeout, ok, err := serviceconfig.TimeoutFor(builder.ServiceConfig(), "/grpc.health.v1.Health/Check")
+			if err != nil || !ok || timeout != time.Second {
+				t.Errorf("expected a timeout of 1s from the file, got %v %v %v", timeout, ok, err)
+			}
+		})
+	}
+}
+
+func TestBuilderDial(t *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	builder, err := NewBuilder(testServiceConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := grpc.Dial(
+		Scheme+":///bufnet",
+		grpc.WithResolvers(builder),
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			if address != "bufnet" {
+				t.Errorf("expected the endpoint bufnet as the address, got %s", address)
+			}
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := grpc_health_v1.NewHealthClient(conn)
+	if _, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := builder.Update(testUpdatedServiceConfig); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+}